@@ -0,0 +1,44 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestCompressors(t *testing.T) {
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	for _, compressor := range []string{gzip.Name, zstdName} {
+		t.Run(compressor, func(t *testing.T) {
+			// the decoders are reused by the following calls
+			for i := 0; i < 3; i++ {
+				rsp, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpc.UseCompressor(compressor))
+				require.NoError(t, err)
+				require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, rsp.Status)
+			}
+		})
+	}
+}
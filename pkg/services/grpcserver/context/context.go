@@ -2,6 +2,7 @@ package grpccontext
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -14,6 +15,19 @@ type GRPCServerContext struct {
 	SignedInUser *user.SignedInUser
 	Tracer       tracing.Tracer
 	Logger       log.Logger
+	// TokenExpiry is the expiry of the token the request was authenticated with, nil when it never expires
+	TokenExpiry *time.Time
+	// AuthMethod is how the request was authenticated, like "service_account_token" or "jwt"
+	AuthMethod string
+}
+
+// Logger returns the logger of the request, tagged with the identity of the caller by the
+// logging interceptor, or the fallback outside of authenticated gRPC requests
+func Logger(ctx context.Context, fallback log.Logger) log.Logger {
+	if grpcContext := FromContext(ctx); grpcContext != nil && grpcContext.Logger != nil {
+		return grpcContext.Logger
+	}
+	return fallback
 }
 
 func FromContext(ctx context.Context) *GRPCServerContext {
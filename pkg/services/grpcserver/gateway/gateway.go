@@ -0,0 +1,314 @@
+package gateway
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/grpcserver"
+	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// PathPrefix is the HTTP path the gRPC methods are served under, like /apis/entity.EntityStore/Read
+const PathPrefix = "/apis"
+
+// Gateway lets clients without gRPC call the methods of the gRPC server as JSON over HTTP, the
+// grpc-gateway convention for methods without HTTP annotations: POST /apis/package.Service/Method
+// with the request as JSON body. Server streams are sent as newline delimited JSON messages,
+// client streams are not available. The same paths serve the gRPC-Web clients, like the
+// browsers, by their content type.
+//
+// The calls are forwarded to the gRPC server on its default listener with their authorization
+// header, so they go through the same authenticator and interceptors as the gRPC clients. They
+// are marked with GatewayMetadataKey, so the local nonce is never accepted for them.
+type Gateway struct {
+	cfg      *setting.Cfg
+	features featuremgmt.FeatureToggles
+	server   grpcserver.Provider
+	logger   log.Logger
+	mux      *runtime.ServeMux
+
+	registerOnce sync.Once
+	registerErr  error
+
+	mu   sync.Mutex
+	conn *grpc.ClientConn
+}
+
+func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, grpcServerProvider grpcserver.Provider, routeRegister routing.RouteRegister) *Gateway {
+	g := &Gateway{
+		cfg:      cfg,
+		features: features,
+		server:   grpcServerProvider,
+		logger:   log.New("grpc-gateway"),
+		mux:      runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher)),
+	}
+	if !g.IsDisabled() {
+		routeRegister.Any(PathPrefix+"/*", http.HandlerFunc(g.ServeHTTP))
+	}
+	return g
+}
+
+func (g *Gateway) IsDisabled() bool {
+	return !g.features.IsEnabled(featuremgmt.FlagGrpcServer)
+}
+
+// Run closes the connection to the gRPC server when the context is done
+func (g *Gateway) Run(ctx context.Context) error {
+	<-ctx.Done()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.conn != nil {
+		_ = g.conn.Close()
+		g.conn = nil
+	}
+	return ctx.Err()
+}
+
+// ServeHTTP registers the methods on first use, the services register themselves on the
+// gRPC server when they are provided, after the gateway
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.registerOnce.Do(func() {
+		g.registerErr = g.register()
+		if g.registerErr != nil {
+			g.logger.Error("failed to register the gRPC methods", "error", g.registerErr)
+		}
+	})
+	if g.registerErr != nil {
+		http.Error(w, "gRPC gateway unavailable", http.StatusInternalServerError)
+		return
+	}
+	if isGRPCWeb(r) {
+		g.serveGRPCWeb(w, r)
+		return
+	}
+	g.mux.ServeHTTP(w, r)
+}
+
+func (g *Gateway) register() error {
+	info := g.server.GetServer().GetServiceInfo()
+	services := make([]string, 0, len(info))
+	for service := range info {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	for _, service := range services {
+		d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+		if err != nil {
+			// services without a registered descriptor, like the ones of the tests
+			g.logger.Debug("skipping service without descriptor", "service", service)
+			continue
+		}
+		sd, ok := d.(protoreflect.ServiceDescriptor)
+		if !ok {
+			continue
+		}
+		for _, m := range info[service].Methods {
+			md := sd.Methods().ByName(protoreflect.Name(m.Name))
+			if md == nil || m.IsClientStream {
+				continue
+			}
+			input, err := protoregistry.GlobalTypes.FindMessageByName(md.Input().FullName())
+			if err != nil {
+				return err
+			}
+			output, err := protoregistry.GlobalTypes.FindMessageByName(md.Output().FullName())
+			if err != nil {
+				return err
+			}
+			method := &method{
+				fullMethod: fmt.Sprintf("/%s/%s", service, m.Name),
+				input:      input,
+				output:     output,
+				stream:     m.IsServerStream,
+			}
+			if err := g.mux.HandlePath(http.MethodPost, PathPrefix+method.fullMethod, g.handler(method)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// authMetadataKeys are the metadata the HTTP clients must not set with Grpc-Metadata- headers,
+// the credentials are only taken from the Authorization header
+var authMetadataKeys = map[string]bool{
+	"authorization":                 true,
+	interceptors.GatewayMetadataKey: true,
+}
+
+// isAuthMetadataHeader reports if the header would set one of the authMetadataKeys
+func isAuthMetadataHeader(key string) bool {
+	key = strings.ToLower(key)
+	return strings.HasPrefix(key, "grpc-metadata-") && authMetadataKeys[strings.TrimPrefix(key, "grpc-metadata-")]
+}
+
+// incomingHeaderMatcher drops the Grpc-Metadata- headers of the credentials, which checkAuthorization
+// rejects already
+func incomingHeaderMatcher(key string) (string, bool) {
+	if isAuthMetadataHeader(key) {
+		return "", false
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// checkAuthorization only accepts a single bearer token: the gRPC server sees the calls coming
+// from the local host, the local nonce must not be accepted from the HTTP clients
+func checkAuthorization(header http.Header) error {
+	values := header.Values("Authorization")
+	if len(values) > 1 {
+		return status.Error(codes.Unauthenticated, "only one authorization header is accepted")
+	}
+	for _, auth := range values {
+		if !strings.HasPrefix(auth, "Bearer ") {
+			return status.Error(codes.Unauthenticated, `only "Bearer " tokens are accepted`)
+		}
+	}
+	for key := range header {
+		if isAuthMetadataHeader(key) {
+			return status.Errorf(codes.Unauthenticated, "the %s header is not accepted", key)
+		}
+	}
+	return nil
+}
+
+type method struct {
+	fullMethod string
+	input      protoreflect.MessageType
+	output     protoreflect.MessageType
+	stream     bool
+}
+
+func (g *Gateway) handler(m *method) runtime.HandlerFunc {
+	pattern := PathPrefix + m.fullMethod
+	return func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		inbound, outbound := runtime.MarshalerForRequest(g.mux, r)
+
+		if err := checkAuthorization(r.Header); err != nil {
+			runtime.HTTPError(ctx, g.mux, outbound, w, r, err)
+			return
+		}
+
+		ctx, err := runtime.AnnotateContext(ctx, g.mux, r, m.fullMethod, runtime.WithHTTPPathPattern(pattern))
+		if err != nil {
+			runtime.HTTPError(ctx, g.mux, outbound, w, r, err)
+			return
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, interceptors.GatewayMetadataKey, "true")
+
+		req := m.input.New().Interface()
+		if err := inbound.NewDecoder(r.Body).Decode(req); err != nil && !errors.Is(err, io.EOF) {
+			runtime.HTTPError(ctx, g.mux, outbound, w, r, status.Errorf(codes.InvalidArgument, "%v", err))
+			return
+		}
+
+		conn, err := g.dial()
+		if err != nil {
+			runtime.HTTPError(ctx, g.mux, outbound, w, r, err)
+			return
+		}
+
+		if m.stream {
+			g.forwardStream(ctx, conn, m, req, outbound, w, r)
+			return
+		}
+
+		var md runtime.ServerMetadata
+		resp := m.output.New().Interface()
+		err = conn.Invoke(ctx, m.fullMethod, req, resp, grpc.Header(&md.HeaderMD), grpc.Trailer(&md.TrailerMD))
+		ctx = runtime.NewServerMetadataContext(ctx, md)
+		if err != nil {
+			runtime.HTTPError(ctx, g.mux, outbound, w, r, err)
+			return
+		}
+		runtime.ForwardResponseMessage(ctx, g.mux, outbound, w, r, resp)
+	}
+}
+
+func (g *Gateway) forwardStream(ctx context.Context, conn *grpc.ClientConn, m *method, req proto.Message, outbound runtime.Marshaler, w http.ResponseWriter, r *http.Request) {
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, m.fullMethod)
+	if err == nil {
+		err = stream.SendMsg(req)
+	}
+	if err == nil {
+		err = stream.CloseSend()
+	}
+	var md runtime.ServerMetadata
+	if err == nil {
+		md.HeaderMD, err = stream.Header()
+	}
+	ctx = runtime.NewServerMetadataContext(ctx, md)
+	if err != nil {
+		runtime.HTTPError(ctx, g.mux, outbound, w, r, err)
+		return
+	}
+	runtime.ForwardResponseStream(ctx, g.mux, outbound, w, r, func() (proto.Message, error) {
+		resp := m.output.New().Interface()
+		err := stream.RecvMsg(resp)
+		return resp, err
+	})
+}
+
+// dial connects to the default listener of the gRPC server once it is listening
+func (g *Gateway) dial() (*grpc.ClientConn, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.conn != nil {
+		return g.conn, nil
+	}
+
+	address := g.server.GetAddress()
+	if address == "" {
+		return nil, status.Error(codes.Unavailable, "gRPC server is not listening yet")
+	}
+	if g.cfg.GRPCServerNetwork == "unix" {
+		address = "unix:" + address
+	}
+
+	creds := insecure.NewCredentials()
+	if g.cfg.GRPCServerTLSConfig != nil {
+		if g.cfg.GRPCServerClientCAFile != "" {
+			return nil, status.Error(codes.Unavailable, "the gateway is not available when the gRPC server requires client certificates")
+		}
+		// the connection is to the local server, whose certificate is for the external name
+		// nolint:gosec
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})
+	}
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if g.cfg.GRPCServerRequireCompressionAbove > 0 {
+		// the large responses are only sent to compressed calls
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	}
+	conn, err := grpc.Dial(address, opts...)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to connect to the gRPC server: %v", err)
+	}
+	g.conn = conn
+	return conn, nil
+}
@@ -0,0 +1,140 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/grpcserver"
+	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeProvider struct {
+	grpcserver.Provider
+	server  *grpc.Server
+	address string
+
+	mu sync.Mutex
+	md metadata.MD
+}
+
+func (p *fakeProvider) GetServer() *grpc.Server {
+	return p.server
+}
+
+func (p *fakeProvider) GetAddress() string {
+	return p.address
+}
+
+// lastMetadata returns the metadata of the last unary call the gRPC server received
+func (p *fakeProvider) lastMetadata() metadata.MD {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.md
+}
+
+func setupGateway(t *testing.T) (*Gateway, *fakeProvider) {
+	t.Helper()
+	provider := &fakeProvider{}
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		provider.mu.Lock()
+		provider.md = md
+		provider.mu.Unlock()
+		return handler(ctx, req)
+	}))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	cfg := setting.NewCfg()
+	cfg.GRPCServerNetwork = "tcp"
+	provider.server = server
+	provider.address = listener.Addr().String()
+	g := ProvideService(cfg, featuremgmt.WithFeatures(featuremgmt.FlagGrpcServer), provider, routing.NewRouteRegister())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		_ = g.Run(ctx)
+	}()
+	return g, provider
+}
+
+func post(g *Gateway, ctx context.Context, path, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)).WithContext(ctx)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGateway(t *testing.T) {
+	g, provider := setupGateway(t)
+
+	t.Run("unary methods are served as JSON", func(t *testing.T) {
+		rec := post(g, context.Background(), "/apis/grpc.health.v1.Health/Check", `{"service": ""}`, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.JSONEq(t, `{"status": "SERVING"}`, rec.Body.String())
+	})
+
+	t.Run("gRPC errors are mapped to HTTP status codes", func(t *testing.T) {
+		rec := post(g, context.Background(), "/apis/grpc.health.v1.Health/Check", `{"service": "unknown"}`, nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Contains(t, rec.Body.String(), "unknown service")
+	})
+
+	t.Run("unknown methods are not found", func(t *testing.T) {
+		rec := post(g, context.Background(), "/apis/grpc.health.v1.Health/Unknown", `{}`, nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("server streams are sent as JSON lines", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		rec := post(g, ctx, "/apis/grpc.health.v1.Health/Watch", `{"service": ""}`, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `{"result":{"status":"SERVING"}}`)
+	})
+
+	t.Run("only bearer tokens are forwarded", func(t *testing.T) {
+		for name, header := range map[string]http.Header{
+			"nonce":                  {"Authorization": {"Nonce abc"}},
+			"second value":           {"Authorization": {"Bearer glsa_abc", "Nonce abc"}},
+			"metadata header":        {"Grpc-Metadata-Authorization": {"Nonce abc"}},
+			"metadata header casing": {"Grpc-Metadata-AUTHORIZATION": {"Nonce abc"}},
+			"gateway mark":           {"Grpc-Metadata-X-Grafana-Grpc-Gateway": {"false"}},
+		} {
+			rec := post(g, context.Background(), "/apis/grpc.health.v1.Health/Check", `{}`, header)
+			require.Equal(t, http.StatusUnauthorized, rec.Code, name)
+			require.Contains(t, rec.Body.String(), `"code":16`, name)
+		}
+
+		rec := post(g, context.Background(), "/apis/grpc.health.v1.Health/Check", `{}`, http.Header{"Authorization": {"Bearer glsa_abc"}})
+		require.Equal(t, http.StatusOK, rec.Code)
+		md := provider.lastMetadata()
+		require.Equal(t, []string{"Bearer glsa_abc"}, md["authorization"])
+		require.Equal(t, []string{"true"}, md[interceptors.GatewayMetadataKey])
+	})
+}
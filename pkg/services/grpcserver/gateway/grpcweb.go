@@ -0,0 +1,235 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
+)
+
+const (
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+
+	// the flag of the last frame of a response, holding the trailers
+	grpcWebTrailerFlag = 0x80
+	// the default limit of the messages received by the gRPC server
+	defaultMaxRecvMsgSize = 4 << 20
+)
+
+// the headers of the browser that are sent as gRPC metadata, every other header is dropped
+var grpcWebForwardedHeaders = []string{
+	"authorization",
+	"traceparent",
+	"tracestate",
+	"baggage",
+	"x-request-id",
+}
+
+func isGRPCWeb(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), grpcWebContentType)
+}
+
+// rawCodec passes the messages through as bytes, the gateway does not decode them
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return *b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// serveGRPCWeb serves the gRPC-Web protocol of the browser clients, in its binary and base64
+// text variants, without a translation proxy like envoy. The request is forwarded to the gRPC
+// server and the response is sent back as frames, with the status in a last trailer frame.
+// Browsers can not send client streams, the calls have a single request message.
+func (g *Gateway) serveGRPCWeb(w http.ResponseWriter, r *http.Request) {
+	text := strings.HasPrefix(r.Header.Get("Content-Type"), grpcWebTextContentType)
+	contentType := grpcWebContentType + "+proto"
+	var body io.Reader = r.Body
+	if text {
+		contentType = grpcWebTextContentType + "+proto"
+		body = base64.NewDecoder(base64.StdEncoding, r.Body)
+	}
+	w.Header().Set("Content-Type", contentType)
+	fullMethod := strings.TrimPrefix(r.URL.Path, PathPrefix)
+
+	if err := checkAuthorization(r.Header); err != nil {
+		writeGRPCWebStatus(w, status.Convert(err))
+		return
+	}
+
+	maxSize := g.cfg.GRPCServerMaxRecvMsgSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxRecvMsgSize
+	}
+	req, err := readGRPCWebFrame(body, maxSize)
+	if err != nil {
+		writeGRPCWebStatus(w, status.Convert(err))
+		return
+	}
+
+	conn, err := g.dial()
+	if err != nil {
+		writeGRPCWebStatus(w, status.Convert(err))
+		return
+	}
+
+	ctx := metadata.NewOutgoingContext(r.Context(), grpcWebMetadata(r))
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, fullMethod, grpc.ForceCodec(rawCodec{}))
+	if err == nil {
+		err = stream.SendMsg(&req)
+	}
+	if err == nil {
+		err = stream.CloseSend()
+	}
+	if err != nil {
+		writeGRPCWebStatus(w, status.Convert(err))
+		return
+	}
+
+	// the headers are sent with the first message, or with the status of a failed call
+	header, _ := stream.Header()
+	for k, v := range header {
+		for _, value := range v {
+			w.Header().Add(k, value)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+
+	frames := &grpcWebFrameWriter{w: w, text: text}
+	for {
+		var msg []byte
+		err = stream.RecvMsg(&msg)
+		if err != nil {
+			break
+		}
+		if frames.write(0, msg) != nil {
+			// the client is gone, the call is canceled with the request context
+			return
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	_ = frames.write(grpcWebTrailerFlag, grpcWebTrailer(status.Convert(err), stream.Trailer()))
+}
+
+// readGRPCWebFrame reads the length prefixed request message
+func readGRPCWebFrame(body io.Reader, maxSize int) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(body, prefix[:]); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to read the request frame: %v", err)
+	}
+	if prefix[0] != 0 {
+		return nil, status.Error(codes.Unimplemented, "compressed requests are not supported")
+	}
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if uint64(size) > uint64(maxSize) {
+		return nil, status.Errorf(codes.ResourceExhausted, "request message larger than max (%d vs. %d)", size, maxSize)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(body, msg); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to read the request message: %v", err)
+	}
+	return msg, nil
+}
+
+// grpcWebMetadata returns the grpcWebForwardedHeaders of the request, with the mark of the gateway
+func grpcWebMetadata(r *http.Request) metadata.MD {
+	md := metadata.Pairs(interceptors.GatewayMetadataKey, "true")
+	for _, k := range grpcWebForwardedHeaders {
+		if v := r.Header.Values(k); len(v) > 0 {
+			md.Append(k, v...)
+		}
+	}
+	return md
+}
+
+// grpcWebTrailer returns the trailers as an HTTP/1 header block
+func grpcWebTrailer(st *status.Status, trailer metadata.MD) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "grpc-status: %d\r\n", st.Code())
+	if st.Message() != "" {
+		fmt.Fprintf(&buf, "grpc-message: %s\r\n", encodeGRPCMessage(st.Message()))
+	}
+	for k, v := range trailer {
+		for _, value := range v {
+			fmt.Fprintf(&buf, "%s: %s\r\n", strings.ToLower(k), value)
+		}
+	}
+	return buf.Bytes()
+}
+
+// writeGRPCWebStatus sends a trailers only response, for the calls that fail before the gRPC server is called
+func writeGRPCWebStatus(w http.ResponseWriter, st *status.Status) {
+	w.Header().Set("grpc-status", strconv.Itoa(int(st.Code())))
+	if st.Message() != "" {
+		w.Header().Set("grpc-message", encodeGRPCMessage(st.Message()))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// encodeGRPCMessage percent encodes the message like the gRPC HTTP/2 protocol
+func encodeGRPCMessage(msg string) string {
+	var sb strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+type grpcWebFrameWriter struct {
+	w    http.ResponseWriter
+	text bool
+}
+
+// write sends a length prefixed frame, every frame is encoded on its own in the text variant
+func (f *grpcWebFrameWriter) write(flag byte, data []byte) error {
+	frame := make([]byte, 5+len(data))
+	frame[0] = flag
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(data)))
+	copy(frame[5:], data)
+	if f.text {
+		frame = []byte(base64.StdEncoding.EncodeToString(frame))
+	}
+	if _, err := f.w.Write(frame); err != nil {
+		return err
+	}
+	if flusher, ok := f.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
+)
+
+func grpcWebRequest(t *testing.T, g *Gateway, contentType string, msg proto.Message, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	data, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	frame := make([]byte, 5+len(data))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(data)))
+	copy(frame[5:], data)
+	if contentType == grpcWebTextContentType {
+		frame = []byte(base64.StdEncoding.EncodeToString(frame))
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/apis/grpc.health.v1.Health/Check", bytes.NewReader(frame)).WithContext(context.Background())
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	return rec
+}
+
+type grpcWebFrame struct {
+	flag byte
+	data []byte
+}
+
+func readGRPCWebFrames(t *testing.T, body io.Reader) []grpcWebFrame {
+	t.Helper()
+	var frames []grpcWebFrame
+	for {
+		var prefix [5]byte
+		if _, err := io.ReadFull(body, prefix[:]); err == io.EOF {
+			return frames
+		} else {
+			require.NoError(t, err)
+		}
+		data := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+		_, err := io.ReadFull(body, data)
+		require.NoError(t, err)
+		frames = append(frames, grpcWebFrame{flag: prefix[0], data: data})
+	}
+}
+
+func TestGRPCWeb(t *testing.T) {
+	g, provider := setupGateway(t)
+
+	for _, contentType := range []string{grpcWebContentType, grpcWebTextContentType} {
+		t.Run(contentType, func(t *testing.T) {
+			rec := grpcWebRequest(t, g, contentType, &grpc_health_v1.HealthCheckRequest{}, nil)
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.Equal(t, contentType+"+proto", rec.Header().Get("Content-Type"))
+
+			var body io.Reader = rec.Body
+			if contentType == grpcWebTextContentType {
+				// the frames are encoded on their own, padding included
+				var decoded []byte
+				for _, chunk := range regexp.MustCompile(`[^=]+=*`).FindAllString(rec.Body.String(), -1) {
+					data, err := base64.StdEncoding.DecodeString(chunk)
+					require.NoError(t, err)
+					decoded = append(decoded, data...)
+				}
+				body = bytes.NewReader(decoded)
+			}
+			frames := readGRPCWebFrames(t, body)
+			require.Len(t, frames, 2)
+
+			require.Equal(t, byte(0), frames[0].flag)
+			resp := &grpc_health_v1.HealthCheckResponse{}
+			require.NoError(t, proto.Unmarshal(frames[0].data, resp))
+			require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+
+			require.Equal(t, byte(grpcWebTrailerFlag), frames[1].flag)
+			require.Contains(t, string(frames[1].data), "grpc-status: 0\r\n")
+		})
+	}
+
+	t.Run("errors are sent in the trailer", func(t *testing.T) {
+		rec := grpcWebRequest(t, g, grpcWebContentType, &grpc_health_v1.HealthCheckRequest{Service: "unknown"}, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		frames := readGRPCWebFrames(t, rec.Body)
+		require.Len(t, frames, 1)
+		require.Equal(t, byte(grpcWebTrailerFlag), frames[0].flag)
+		require.Contains(t, string(frames[0].data), "grpc-status: 5\r\n")
+		require.Contains(t, string(frames[0].data), "grpc-message: unknown service\r\n")
+	})
+
+	t.Run("only bearer tokens and the allowed headers are forwarded", func(t *testing.T) {
+		for name, header := range map[string]http.Header{
+			"nonce":           {"Authorization": {"Nonce abc"}},
+			"second value":    {"Authorization": {"Bearer glsa_abc", "Nonce abc"}},
+			"metadata header": {"Grpc-Metadata-Authorization": {"Nonce abc"}},
+		} {
+			rec := grpcWebRequest(t, g, grpcWebContentType, &grpc_health_v1.HealthCheckRequest{}, header)
+			require.Equal(t, "16", rec.Header().Get("grpc-status"), name)
+		}
+
+		rec := grpcWebRequest(t, g, grpcWebContentType, &grpc_health_v1.HealthCheckRequest{}, http.Header{
+			"Authorization":          {"Bearer glsa_abc"},
+			"Traceparent":            {"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
+			"X-Grafana-Grpc-Gateway": {"false"},
+			"X-Custom":               {"value"},
+		})
+		require.Equal(t, http.StatusOK, rec.Code)
+		md := provider.lastMetadata()
+		require.Equal(t, []string{"Bearer glsa_abc"}, md["authorization"])
+		require.Len(t, md["traceparent"], 1)
+		require.Equal(t, []string{"true"}, md[interceptors.GatewayMetadataKey])
+		require.Empty(t, md["x-custom"])
+	})
+
+	t.Run("malformed requests get a trailers only response", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/apis/grpc.health.v1.Health/Check", bytes.NewReader([]byte{0, 0})).WithContext(context.Background())
+		req.Header.Set("Content-Type", grpcWebContentType)
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "3", rec.Header().Get("grpc-status"))
+		require.Empty(t, rec.Body.Bytes())
+	})
+}
+
+func TestEncodeGRPCMessage(t *testing.T) {
+	require.Equal(t, "100%25 done", encodeGRPCMessage("100% done"))
+	require.Equal(t, "line%0Abreak", encodeGRPCMessage("line\nbreak"))
+}
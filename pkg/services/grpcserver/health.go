@@ -2,36 +2,126 @@ package grpcserver
 
 import (
 	"context"
+	"sort"
+	"sync"
+	"time"
 
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+// DatabaseHealthService is the name the serving status of the database is reported with
+const DatabaseHealthService = "grafana.database"
+
+// HealthCheck returns an error when the subsystem can not serve requests
+type HealthCheck func(ctx context.Context) error
+
 // HealthService implements GRPC Health Checking Protocol:
 // https://github.com/grpc/grpc/blob/master/doc/health-checking.md
-// It also demonstrates how to override authentication for a service – in this
-// case we are disabling any auth in AuthFuncOverride.
+// It also demonstrates how a service declares its auth requirement – in this
+// case the health checks do not require any auth.
+//
+// Every subsystem registered with AddCheck is reported as a service, checked every
+// GRPCServerHealthCheckInterval. The server as a whole, the empty service name, is
+// serving when all of them are.
 type HealthService struct {
 	cfg          *setting.Cfg
 	healthServer *healthServer
+	logger       log.Logger
+
+	mu     sync.Mutex
+	checks map[string]HealthCheck
 }
 
 type healthServer struct {
 	*health.Server
 }
 
-// AuthFuncOverride for no auth for health service.
-func (s *healthServer) AuthFuncOverride(ctx context.Context, _ string) (context.Context, error) {
-	return ctx, nil
-}
-
-func ProvideHealthService(cfg *setting.Cfg, grpcServerProvider Provider) (*HealthService, error) {
+func ProvideHealthService(cfg *setting.Cfg, grpcServerProvider Provider, authPolicy *interceptors.AuthPolicy, readOnly *interceptors.ReadOnlyMethods, sqlStore db.DB) (*HealthService, error) {
 	hs := &healthServer{health.NewServer()}
 	grpc_health_v1.RegisterHealthServer(grpcServerProvider.GetServer(), hs)
-	return &HealthService{
+	authPolicy.SetService(grpc_health_v1.Health_ServiceDesc.ServiceName, interceptors.AuthUnauthenticated)
+	readOnly.SetService(grpc_health_v1.Health_ServiceDesc.ServiceName)
+	s := &HealthService{
 		cfg:          cfg,
 		healthServer: hs,
-	}, nil
+		logger:       log.New("grpc-server-health"),
+		checks:       map[string]HealthCheck{},
+	}
+	s.AddCheck(DatabaseHealthService, func(ctx context.Context) error {
+		return sqlStore.WithDbSession(ctx, func(session *db.Session) error {
+			_, err := session.Exec("SELECT 1")
+			return err
+		})
+	})
+	return s, nil
+}
+
+// AddCheck registers the check of a service, it is not serving until it is checked
+func (s *HealthService) AddCheck(service string, check HealthCheck) {
+	s.mu.Lock()
+	s.checks[service] = check
+	s.mu.Unlock()
+	s.healthServer.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	s.healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+}
+
+// Run checks the services until the context is done, they are then all reported as
+// not serving so the clients stop sending requests while the server shuts down.
+func (s *HealthService) Run(ctx context.Context) error {
+	interval := s.cfg.GRPCServerHealthCheckInterval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.check(ctx, interval)
+		select {
+		case <-ctx.Done():
+			s.healthServer.Shutdown()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *HealthService) check(ctx context.Context, timeout time.Duration) {
+	s.mu.Lock()
+	services := make([]string, 0, len(s.checks))
+	for service := range s.checks {
+		services = append(services, service)
+	}
+	s.mu.Unlock()
+	sort.Strings(services)
+
+	overall := grpc_health_v1.HealthCheckResponse_SERVING
+	for _, service := range services {
+		s.mu.Lock()
+		check := s.checks[service]
+		s.mu.Unlock()
+
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := check(checkCtx)
+		cancel()
+
+		status := grpc_health_v1.HealthCheckResponse_SERVING
+		if err != nil {
+			s.logger.Warn("service is not serving", "service", service, "error", err)
+			status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+			overall = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+		s.healthServer.SetServingStatus(service, status)
+	}
+	s.healthServer.SetServingStatus("", overall)
+}
+
+func (s *HealthService) IsDisabled() bool {
+	if s.cfg == nil {
+		return true
+	}
+	return !s.cfg.IsFeatureToggleEnabled(featuremgmt.FlagGrpcServer)
 }
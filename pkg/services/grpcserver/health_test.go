@@ -0,0 +1,66 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeProvider struct {
+	Provider
+	server *grpc.Server
+}
+
+func (p *fakeProvider) GetServer() *grpc.Server {
+	return p.server
+}
+
+func TestHealthService(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.GRPCServerHealthCheckInterval = time.Second
+	s, err := ProvideHealthService(cfg, &fakeProvider{server: grpc.NewServer()}, interceptors.ProvideAuthPolicy(), interceptors.ProvideReadOnlyMethods(), db.InitTestDB(t))
+	require.NoError(t, err)
+
+	status := func(service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		rsp, err := s.healthServer.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return rsp.Status
+	}
+
+	var entityErr error
+	s.AddCheck("entity.EntityStore", func(ctx context.Context) error {
+		return entityErr
+	})
+	// not serving until checked
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, status("entity.EntityStore"))
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, status(""))
+
+	s.check(context.Background(), time.Second)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, status(DatabaseHealthService))
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, status("entity.EntityStore"))
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, status(""))
+
+	entityErr = errors.New("no entity table")
+	s.check(context.Background(), time.Second)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, status(DatabaseHealthService))
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, status("entity.EntityStore"))
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, status(""))
+
+	t.Run("shutdown", func(t *testing.T) {
+		entityErr = nil
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, s.Run(ctx), context.Canceled)
+		require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, status(DatabaseHealthService))
+		require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, status(""))
+	})
+}
@@ -0,0 +1,229 @@
+package interceptors
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const redacted = "[REDACTED]"
+
+// metadata keys and message fields containing these are never logged
+var sensitiveNames = []string{"authorization", "cookie", "token", "secret", "password", "apikey", "api_key", "private_key"}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range sensitiveNames {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessLog logs every call with its caller, duration, status and request size, and with
+// log_request_bodies the request itself. The credentials in the metadata and the fields of
+// the request with sensitive names are redacted, the bytes fields are logged with their size only.
+type AccessLog struct {
+	logger         log.Logger
+	contextHandler grpccontext.ContextHandler
+	bodies         bool
+}
+
+// NewAccessLog returns nil when log_requests is not set
+func NewAccessLog(cfg *setting.Cfg, contextHandler grpccontext.ContextHandler) *AccessLog {
+	if !cfg.GRPCServerLogRequests {
+		return nil
+	}
+	return &AccessLog{
+		logger:         log.New("grpc-server-access"),
+		contextHandler: contextHandler,
+		bodies:         cfg.GRPCServerLogRequestBodies,
+	}
+}
+
+// withServerContext adds the server context the authenticator sets the user in, so the caller
+// is known once the call is done, even when the auth interceptor returns another context
+func withServerContext(ctx context.Context, contextHandler grpccontext.ContextHandler) context.Context {
+	if grpccontext.FromContext(ctx) != nil {
+		return ctx
+	}
+	return contextHandler.SetUser(ctx, nil)
+}
+
+func (l *AccessLog) log(ctx context.Context, method string, start time.Time, req interface{}, err error) {
+	code := status.Code(err)
+	params := []interface{}{"method", method, "code", code.String(), "duration", time.Since(start)}
+	if p, ok := peer.FromContext(ctx); ok {
+		params = append(params, "remote_addr", p.Addr.String())
+	}
+	if grpcContext := grpccontext.FromContext(ctx); grpcContext != nil && grpcContext.SignedInUser != nil {
+		u := grpcContext.SignedInUser
+		params = append(params, "orgID", u.OrgID, "userID", u.UserID, "authMethod", grpcContext.AuthMethod, "anonymous", u.IsAnonymous)
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		params = append(params, "metadata", redactMetadata(md))
+	}
+	if msg, ok := req.(proto.Message); ok {
+		params = append(params, "request_size", proto.Size(msg))
+		if l.bodies {
+			redactedMsg, sizes := redactMessage(msg)
+			if body, err := protojson.Marshal(redactedMsg); err == nil {
+				params = append(params, "request", string(body))
+			}
+			if sizes != "" {
+				params = append(params, "request_bytes", sizes)
+			}
+		}
+	}
+	if err != nil {
+		params = append(params, "error", status.Convert(err).Message())
+	}
+
+	switch code {
+	case codes.OK:
+		l.logger.Info("Request completed", params...)
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		l.logger.Error("Request failed", params...)
+	default:
+		l.logger.Warn("Request failed", params...)
+	}
+}
+
+// redactMetadata returns the metadata as sorted key=value pairs, the loggers do not support maps
+func redactMetadata(md metadata.MD) string {
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v := strings.Join(md[k], ",")
+		if isSensitive(k) {
+			v = redacted
+		}
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, " ")
+}
+
+// redactMessage returns a copy of the message with the sensitive fields redacted, in nested messages too.
+// The bytes fields, like the body of the entities, are removed and returned as sorted field=size pairs.
+func redactMessage(msg proto.Message) (proto.Message, string) {
+	c := proto.Clone(msg)
+	sizes := map[string]int{}
+	redactFields(c.ProtoReflect(), "", sizes)
+
+	fields := make([]string, 0, len(sizes))
+	for f := range sizes {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	pairs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		pairs = append(pairs, f+"="+strconv.Itoa(sizes[f]))
+	}
+	return c, strings.Join(pairs, " ")
+}
+
+func redactFields(m protoreflect.Message, prefix string, sizes map[string]int) {
+	// the message can not be changed while ranging over it
+	var fields []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fields = append(fields, fd)
+		return true
+	})
+
+	for _, fd := range fields {
+		v := m.Get(fd)
+		path := prefix + string(fd.Name())
+		switch {
+		case isSensitive(string(fd.Name())):
+			if fd.Kind() == protoreflect.StringKind && !fd.IsList() && !fd.IsMap() {
+				m.Set(fd, protoreflect.ValueOfString(redacted))
+			} else {
+				m.Clear(fd)
+			}
+		case fd.IsMap():
+			switch fd.MapValue().Kind() {
+			case protoreflect.MessageKind:
+				v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+					redactFields(mv.Message(), path+"."+k.String()+".", sizes)
+					return true
+				})
+			case protoreflect.BytesKind:
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					sizes[path] += len(mv.Bytes())
+					return true
+				})
+				m.Clear(fd)
+			}
+		case fd.IsList():
+			switch fd.Kind() {
+			case protoreflect.MessageKind:
+				for i := 0; i < v.List().Len(); i++ {
+					redactFields(v.List().Get(i).Message(), path+"."+strconv.Itoa(i)+".", sizes)
+				}
+			case protoreflect.BytesKind:
+				for i := 0; i < v.List().Len(); i++ {
+					sizes[path] += len(v.List().Get(i).Bytes())
+				}
+				m.Clear(fd)
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			redactFields(v.Message(), path+".", sizes)
+		case fd.Kind() == protoreflect.BytesKind:
+			sizes[path] += len(v.Bytes())
+			m.Clear(fd)
+		}
+	}
+}
+
+// AccessLogUnaryInterceptor must run before the auth interceptor, so the calls that fail to authenticate are logged
+func AccessLogUnaryInterceptor(l *AccessLog) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if l == nil {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		ctx = withServerContext(ctx, l.contextHandler)
+		resp, err := handler(ctx, req)
+		l.log(ctx, info.FullMethod, start, req, err)
+		return resp, err
+	}
+}
+
+// AccessLogStreamInterceptor logs streams when they are closed, without their messages
+func AccessLogStreamInterceptor(l *AccessLog) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if l == nil {
+			return handler(srv, stream)
+		}
+		start := time.Now()
+		ctx := withServerContext(stream.Context(), l.contextHandler)
+		err := handler(srv, &tracingServerStream{ServerStream: stream, ctx: ctx})
+		l.log(ctx, info.FullMethod, start, nil, err)
+		return err
+	}
+}
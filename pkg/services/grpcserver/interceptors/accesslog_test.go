@@ -0,0 +1,136 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/store/entity"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// loginRequest builds a message with sensitive fields, next to a nested one
+func loginRequest(t *testing.T) protoreflect.Message {
+	t.Helper()
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("login.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Login"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("name"), JsonName: proto.String("name"), Number: proto.Int32(1), Type: str, Label: optional},
+				{Name: proto.String("password"), JsonName: proto.String("password"), Number: proto.Int32(2), Type: str, Label: optional},
+				{Name: proto.String("nested"), JsonName: proto.String("nested"), Number: proto.Int32(3), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".test.Login"), Label: optional},
+			},
+		}},
+	}, nil)
+	require.NoError(t, err)
+	desc := file.Messages().Get(0)
+	inner := dynamicpb.NewMessage(desc)
+	inner.Set(desc.Fields().ByName("name"), protoreflect.ValueOfString("inner"))
+	inner.Set(desc.Fields().ByName("password"), protoreflect.ValueOfString("hunter3"))
+	msg := dynamicpb.NewMessage(desc)
+	msg.Set(desc.Fields().ByName("name"), protoreflect.ValueOfString("admin"))
+	msg.Set(desc.Fields().ByName("password"), protoreflect.ValueOfString("hunter2"))
+	msg.Set(desc.Fields().ByName("nested"), protoreflect.ValueOfMessage(inner))
+	return msg
+}
+
+func TestAccessLogUnaryInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.NewNopLogger()
+	logger.Swap(gokitlog.NewLogfmtLogger(&buf))
+	contextHandler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+	accessLog := &AccessLog{logger: logger, contextHandler: contextHandler, bodies: true}
+	interceptor := AccessLogUnaryInterceptor(accessLog)
+
+	req := loginRequest(t).Interface()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer glsa_secret", "user-agent", "grpcurl"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Login"}
+
+	t.Run("logs the caller set by the authenticator", func(t *testing.T) {
+		buf.Reset()
+		_, err := interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			// like the auth interceptor, which returns a new context
+			ctx = contextHandler.SetUser(ctx, &user.SignedInUser{OrgID: 2, UserID: 10})
+			grpccontext.FromContext(ctx).AuthMethod = authMethodIntrospection
+			return nil, nil
+		})
+		require.NoError(t, err)
+		line := buf.String()
+		require.Contains(t, line, "method=/test.Service/Login code=OK")
+		require.Contains(t, line, "orgID=2 userID=10 authMethod=introspection")
+		require.Contains(t, line, "request_size=")
+		require.Contains(t, line, "user-agent=grpcurl")
+		require.Contains(t, line, "authorization=[REDACTED]")
+		require.Contains(t, line, "admin")
+		require.NotContains(t, line, "glsa_secret")
+		require.NotContains(t, line, "hunter2")
+		require.NotContains(t, line, "hunter3")
+	})
+
+	t.Run("logs failed calls", func(t *testing.T) {
+		buf.Reset()
+		_, err := interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		})
+		require.Error(t, err)
+		require.Contains(t, buf.String(), "code=Unauthenticated")
+		require.Contains(t, buf.String(), `error="invalid token"`)
+		require.NotContains(t, buf.String(), "orgID")
+	})
+
+	t.Run("the request is not changed", func(t *testing.T) {
+		fd := req.ProtoReflect().Descriptor().Fields().ByName("password")
+		require.Equal(t, "hunter2", req.ProtoReflect().Get(fd).String())
+	})
+
+	t.Run("logs the size of the bytes fields only", func(t *testing.T) {
+		buf.Reset()
+		body := []byte(`{"title":"private dashboard"}`)
+		write := &entity.WriteEntityRequest{
+			GRN:     &entity.GRN{UID: "abc", Kind: "dashboard"},
+			Body:    body,
+			Comment: "update",
+		}
+		info := &grpc.UnaryServerInfo{FullMethod: "/entity.EntityStore/Write"}
+		_, err := interceptor(ctx, write, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+		line := buf.String()
+		require.Contains(t, line, "update")
+		require.Contains(t, line, `request_bytes="body=29"`)
+		require.NotContains(t, line, "private dashboard")
+		require.NotContains(t, line, base64.StdEncoding.EncodeToString(body))
+		require.Equal(t, body, write.Body)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		buf.Reset()
+		_, err := AccessLogUnaryInterceptor(nil)(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+		require.Empty(t, buf.String())
+	})
+}
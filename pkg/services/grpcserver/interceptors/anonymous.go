@@ -0,0 +1,71 @@
+package interceptors
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// AnonymousAccess maps calls without an authorization header to the anonymous org and role
+// of [auth.anonymous], like anonymous access to the HTTP API. Only the methods listed in
+// [grpc_server] anonymous_methods are allowed, every other method still requires a token.
+// Anonymous access is read only, the listed methods that the services did not declare read
+// only still require a token.
+type AnonymousAccess struct {
+	methods    map[string]bool
+	readOnly   *ReadOnlyMethods
+	orgName    string
+	orgRole    org.RoleType
+	orgService org.Service
+
+	mu        sync.Mutex
+	cachedOrg *org.Org
+}
+
+func ProvideAnonymousAccess(cfg *setting.Cfg, orgService org.Service, readOnly *ReadOnlyMethods) *AnonymousAccess {
+	if len(cfg.GRPCServerAnonymousMethods) == 0 {
+		return nil
+	}
+	methods := make(map[string]bool, len(cfg.GRPCServerAnonymousMethods))
+	for _, m := range cfg.GRPCServerAnonymousMethods {
+		methods[m] = true
+	}
+	role := org.RoleType(cfg.AnonymousOrgRole)
+	if !role.IsValid() {
+		role = org.RoleViewer
+	}
+	return &AnonymousAccess{
+		methods:    methods,
+		readOnly:   readOnly,
+		orgName:    cfg.AnonymousOrgName,
+		orgRole:    role,
+		orgService: orgService,
+	}
+}
+
+// allowed reports if the method of the call is in the allowlist and declared read only, the
+// services declare their methods after the allowlist is read
+func (a *AnonymousAccess) allowed(ctx context.Context) bool {
+	method, ok := grpc.Method(ctx)
+	return ok && a.methods[method] && a.readOnly.IsReadOnly(method)
+}
+
+// org returns the anonymous org, it is looked up until it exists and then kept
+func (a *AnonymousAccess) org(ctx context.Context) (*org.Org, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cachedOrg != nil {
+		return a.cachedOrg, nil
+	}
+
+	o, err := a.orgService.GetByName(ctx, &org.GetOrgByNameQuery{Name: a.orgName})
+	if err != nil {
+		return nil, err
+	}
+	a.cachedOrg = o
+	return o, nil
+}
@@ -0,0 +1,109 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeServerTransportStream struct {
+	grpc.ServerTransportStream
+	method string
+}
+
+func (s *fakeServerTransportStream) Method() string {
+	return s.method
+}
+
+func TestAnonymousAccess(t *testing.T) {
+	cfg := setting.NewCfg()
+	readOnly := ProvideReadOnlyMethods()
+	require.Nil(t, ProvideAnonymousAccess(cfg, nil, readOnly))
+
+	cfg.GRPCServerAnonymousMethods = []string{"/entity.EntityStore/Read"}
+	cfg.AnonymousOrgName = "Public"
+	cfg.AnonymousOrgRole = "Viewer"
+	orgService := &countingOrgService{FakeOrgService: orgtest.NewOrgServiceFake()}
+	orgService.ExpectedOrg = &org.Org{ID: 3, Name: "Public"}
+
+	handler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+	readOnly.SetMethods("/entity.EntityStore/Read")
+	a := ProvideAuthenticator(nil, nil, accesscontrolmock.New(), handler, nil, ProvideAnonymousAccess(cfg, orgService, readOnly), nil, nil)
+
+	call := func(method string, md metadata.MD) context.Context {
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), &fakeServerTransportStream{method: method})
+		return metadata.NewIncomingContext(ctx, md)
+	}
+
+	t.Run("allows listed methods without credentials", func(t *testing.T) {
+		ctx, err := a.Authenticate(call("/entity.EntityStore/Read", metadata.MD{}))
+		require.NoError(t, err)
+		u := handler.GetUser(ctx)
+		require.True(t, u.IsAnonymous)
+		require.Equal(t, int64(3), u.OrgID)
+		require.Equal(t, org.RoleViewer, u.OrgRole)
+		require.Equal(t, authMethodAnonymous, grpccontext.FromContext(ctx).AuthMethod)
+	})
+
+	t.Run("requires credentials for other methods", func(t *testing.T) {
+		_, err := a.Authenticate(call("/entity.EntityStore/Write", metadata.MD{}))
+		require.Error(t, err)
+	})
+
+	t.Run("does not downgrade invalid credentials", func(t *testing.T) {
+		_, err := a.Authenticate(call("/entity.EntityStore/Read", metadata.Pairs("authorization", "Basic abc")))
+		require.Error(t, err)
+	})
+
+	t.Run("looks up the anonymous org once", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			_, err := a.Authenticate(call("/entity.EntityStore/Read", metadata.MD{}))
+			require.NoError(t, err)
+		}
+		require.Equal(t, 1, orgService.calls)
+	})
+}
+
+func TestAnonymousAccessIsReadOnly(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.GRPCServerAnonymousMethods = []string{"/entity.EntityStore/Read", "/apikeymigration.APIKeyMigration/MigrateAPIKeys"}
+	// even with an admin anonymous role, the methods not declared read only require credentials
+	cfg.AnonymousOrgRole = "Admin"
+	orgService := orgtest.NewOrgServiceFake()
+	orgService.ExpectedOrg = &org.Org{ID: 3}
+	readOnly := ProvideReadOnlyMethods()
+	readOnly.SetMethods("/entity.EntityStore/Read")
+
+	handler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+	a := ProvideAuthenticator(nil, nil, accesscontrolmock.New(), handler, nil, ProvideAnonymousAccess(cfg, orgService, readOnly), nil, nil)
+
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), &fakeServerTransportStream{method: "/apikeymigration.APIKeyMigration/MigrateAPIKeys"})
+	_, err := a.Authenticate(metadata.NewIncomingContext(ctx, metadata.MD{}))
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = grpc.NewContextWithServerTransportStream(context.Background(), &fakeServerTransportStream{method: "/entity.EntityStore/Read"})
+	_, err = a.Authenticate(metadata.NewIncomingContext(ctx, metadata.MD{}))
+	require.NoError(t, err)
+}
+
+type countingOrgService struct {
+	*orgtest.FakeOrgService
+	calls int
+}
+
+func (s *countingOrgService) GetByName(ctx context.Context, query *org.GetOrgByNameQuery) (*org.Org, error) {
+	s.calls++
+	return s.FakeOrgService.GetByName(ctx, query)
+}
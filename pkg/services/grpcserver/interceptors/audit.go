@@ -0,0 +1,147 @@
+package interceptors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+)
+
+// AuditResource is implemented by the requests that name the resource they change
+type AuditResource interface {
+	AuditResource() string
+}
+
+// AuditEvent describes a call to a mutating method, the caller is unknown when
+// the call failed to authenticate
+type AuditEvent struct {
+	Time        time.Time
+	Method      string
+	Resource    string
+	OrgID       int64
+	UserID      int64
+	Login       string
+	AuthMethod  string
+	IsAnonymous bool
+	Code        codes.Code
+	Error       string
+}
+
+// AuditSink receives the audit events, like the audit log or the annotations of an org
+type AuditSink interface {
+	Audit(ctx context.Context, event AuditEvent)
+}
+
+// Auditor sends an event to every registered sink for the calls to mutating methods,
+// so entity writes through gRPC are traceable like the ones of the HTTP API. Every method
+// the services did not declare in ReadOnlyMethods is mutating. The events are logged by
+// the "grpc-server-audit" logger, other sinks are added with AddSink.
+type Auditor struct {
+	contextHandler grpccontext.ContextHandler
+	readOnly       *ReadOnlyMethods
+
+	mu    sync.RWMutex
+	sinks []AuditSink
+}
+
+func ProvideAuditor(contextHandler grpccontext.ContextHandler, readOnly *ReadOnlyMethods) *Auditor {
+	return &Auditor{
+		contextHandler: contextHandler,
+		readOnly:       readOnly,
+		sinks:          []AuditSink{&logAuditSink{logger: log.New("grpc-server-audit")}},
+	}
+}
+
+func (a *Auditor) AddSink(sink AuditSink) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sinks = append(a.sinks, sink)
+}
+
+func (a *Auditor) audit(ctx context.Context, method string, req interface{}, err error) {
+	event := AuditEvent{
+		Time:   time.Now(),
+		Method: method,
+		Code:   status.Code(err),
+	}
+	if r, ok := req.(AuditResource); ok {
+		event.Resource = r.AuditResource()
+	}
+	if grpcContext := grpccontext.FromContext(ctx); grpcContext != nil && grpcContext.SignedInUser != nil {
+		u := grpcContext.SignedInUser
+		event.OrgID = u.OrgID
+		event.UserID = u.UserID
+		event.Login = u.Login
+		event.AuthMethod = grpcContext.AuthMethod
+		event.IsAnonymous = u.IsAnonymous
+	}
+	if err != nil {
+		event.Error = status.Convert(err).Message()
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, sink := range a.sinks {
+		sink.Audit(ctx, event)
+	}
+}
+
+type logAuditSink struct {
+	logger log.Logger
+}
+
+func (s *logAuditSink) Audit(_ context.Context, event AuditEvent) {
+	params := []interface{}{
+		"method", event.Method,
+		"resource", event.Resource,
+		"orgID", event.OrgID,
+		"userID", event.UserID,
+		"login", event.Login,
+		"authMethod", event.AuthMethod,
+		"anonymous", event.IsAnonymous,
+		"code", event.Code.String(),
+	}
+	if event.Error != "" {
+		params = append(params, "error", event.Error)
+	}
+	s.logger.Info("Audit", params...)
+}
+
+// AuditUnaryInterceptor must run before the auth and the recovery interceptors, so the calls
+// that fail to authenticate or panic are audited too. The server context is added before the
+// authentication, the caller set by the auth interceptor is then known once the call is done.
+func AuditUnaryInterceptor(a *Auditor) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if a == nil || a.readOnly.IsReadOnly(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx = withServerContext(ctx, a.contextHandler)
+		resp, err := handler(ctx, req)
+		a.audit(ctx, info.FullMethod, req, err)
+		return resp, err
+	}
+}
+
+// AuditStreamInterceptor audits the mutating streams when they are closed, without a resource
+func AuditStreamInterceptor(a *Auditor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if a == nil || a.readOnly.IsReadOnly(info.FullMethod) {
+			return handler(srv, stream)
+		}
+		ctx := withServerContext(stream.Context(), a.contextHandler)
+		err := handler(srv, &tracingServerStream{ServerStream: stream, ctx: ctx})
+		a.audit(ctx, info.FullMethod, nil, err)
+		return err
+	}
+}
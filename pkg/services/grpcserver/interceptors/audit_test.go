@@ -0,0 +1,120 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/store/entity"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type recordingAuditSink struct {
+	events []AuditEvent
+}
+
+func (s *recordingAuditSink) Audit(_ context.Context, event AuditEvent) {
+	s.events = append(s.events, event)
+}
+
+func TestAuditUnaryInterceptor(t *testing.T) {
+	contextHandler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+	readOnly := ProvideReadOnlyMethods()
+	readOnly.SetMethods("/entity.EntityStore/Read")
+	auditor := ProvideAuditor(contextHandler, readOnly)
+	sink := &recordingAuditSink{}
+	auditor.AddSink(sink)
+
+	// authenticates in the handler, like the auth interceptor running after the auditor
+	authenticated := func(err error) grpc.UnaryHandler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			ctx = contextHandler.SetUser(ctx, &user.SignedInUser{OrgID: 2, UserID: 3, Login: "sa-writer"})
+			grpccontext.FromContext(ctx).AuthMethod = authMethodServiceAccountToken
+			return nil, err
+		}
+	}
+	interceptor := AuditUnaryInterceptor(auditor)
+	req := &entity.WriteEntityRequest{GRN: &entity.GRN{TenantId: 2, Kind: "dashboard", UID: "abc"}}
+
+	t.Run("mutating calls are audited", func(t *testing.T) {
+		sink.events = nil
+		_, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/entity.EntityStore/Write"}, authenticated(nil))
+		require.NoError(t, err)
+		require.Len(t, sink.events, 1)
+		event := sink.events[0]
+		require.Equal(t, "/entity.EntityStore/Write", event.Method)
+		require.Equal(t, "grn:2/dashboard/abc", event.Resource)
+		require.Equal(t, int64(2), event.OrgID)
+		require.Equal(t, int64(3), event.UserID)
+		require.Equal(t, "sa-writer", event.Login)
+		require.Equal(t, authMethodServiceAccountToken, event.AuthMethod)
+		require.Equal(t, codes.OK, event.Code)
+	})
+
+	t.Run("failed calls are audited with their status", func(t *testing.T) {
+		sink.events = nil
+		_, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/entity.EntityStore/Write"}, authenticated(status.Error(codes.PermissionDenied, "denied")))
+		require.Error(t, err)
+		require.Len(t, sink.events, 1)
+		require.Equal(t, codes.PermissionDenied, sink.events[0].Code)
+		require.Equal(t, "denied", sink.events[0].Error)
+	})
+
+	t.Run("read only calls are not audited", func(t *testing.T) {
+		sink.events = nil
+		_, err := interceptor(context.Background(), &entity.ReadEntityRequest{}, &grpc.UnaryServerInfo{FullMethod: "/entity.EntityStore/Read"}, authenticated(nil))
+		require.NoError(t, err)
+		require.Empty(t, sink.events)
+	})
+
+	t.Run("calls of methods not declared read only are audited", func(t *testing.T) {
+		sink.events = nil
+		_, err := interceptor(context.Background(), &entity.ReadEntityRequest{}, &grpc.UnaryServerInfo{FullMethod: "/entity.EntityStore/Import"}, authenticated(nil))
+		require.NoError(t, err)
+		require.Len(t, sink.events, 1)
+	})
+}
+
+func TestAuditStreamInterceptor(t *testing.T) {
+	contextHandler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+	readOnly := ProvideReadOnlyMethods()
+	readOnly.SetMethods("/entity.EntityStore/Watch")
+	auditor := ProvideAuditor(contextHandler, readOnly)
+	sink := &recordingAuditSink{}
+	auditor.AddSink(sink)
+	interceptor := AuditStreamInterceptor(auditor)
+	stream := &fakeServerStream{ctx: context.Background()}
+
+	// the migration creates service accounts and tokens, whatever its name
+	info := &grpc.StreamServerInfo{FullMethod: "/apikeymigration.APIKeyMigration/MigrateAPIKeys"}
+	err := interceptor(nil, stream, info, func(srv interface{}, stream grpc.ServerStream) error {
+		contextHandler.SetUser(stream.Context(), &user.SignedInUser{OrgID: 2, UserID: 3, Login: "admin"})
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, sink.events, 1)
+	require.Equal(t, "/apikeymigration.APIKeyMigration/MigrateAPIKeys", sink.events[0].Method)
+	require.Equal(t, "admin", sink.events[0].Login)
+
+	sink.events = nil
+	info = &grpc.StreamServerInfo{FullMethod: "/entity.EntityStore/Watch"}
+	require.NoError(t, interceptor(nil, stream, info, func(srv interface{}, stream grpc.ServerStream) error {
+		return nil
+	}))
+	require.Empty(t, sink.events)
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
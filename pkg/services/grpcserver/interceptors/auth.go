@@ -2,12 +2,16 @@ package interceptors
 
 import (
 	"context"
+	"errors"
 	"strings"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"gopkg.in/square/go-jose.v2/jwt"
 
+	"github.com/grafana/grafana/pkg/components/apikeygen"
 	apikeygenprefix "github.com/grafana/grafana/pkg/components/apikeygenprefixed"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -25,16 +29,24 @@ type Authenticator interface {
 type authenticator struct {
 	contextHandler grpccontext.ContextHandler
 	logger         log.Logger
+	localNonce     *LocalNonce
+	anonymous      *AnonymousAccess
+	jwtAuth        *JWTAuth
+	introspection  *TokenIntrospection
 
 	APIKeyService        apikey.Service
 	UserService          user.Service
 	AccessControlService accesscontrol.Service
 }
 
-func ProvideAuthenticator(apiKeyService apikey.Service, userService user.Service, accessControlService accesscontrol.Service, contextHandler grpccontext.ContextHandler) Authenticator {
+func ProvideAuthenticator(apiKeyService apikey.Service, userService user.Service, accessControlService accesscontrol.Service, contextHandler grpccontext.ContextHandler, localNonce *LocalNonce, anonymous *AnonymousAccess, jwtAuth *JWTAuth, introspection *TokenIntrospection) Authenticator {
 	return &authenticator{
 		contextHandler: contextHandler,
 		logger:         log.New("grpc-server-authenticator"),
+		localNonce:     localNonce,
+		anonymous:      anonymous,
+		jwtAuth:        jwtAuth,
+		introspection:  introspection,
 
 		AccessControlService: accessControlService,
 		APIKeyService:        apiKeyService,
@@ -50,12 +62,51 @@ func (a *authenticator) Authenticate(ctx context.Context) (context.Context, erro
 
 const tokenPrefix = "Bearer "
 
+// serviceAccountTokenID is the service ID of the glsa_ tokens, like the ServiceID of the service account API
+const serviceAccountTokenID = "sa"
+
+var (
+	errLegacyAPIKey = status.Error(codes.Unauthenticated, "only service account tokens are accepted")
+	errTokenExpired = status.Error(codes.Unauthenticated, "token expired")
+	errTokenRevoked = status.Error(codes.Unauthenticated, "token revoked")
+)
+
+const noncePrefix = "Nonce "
+
+// the methods the calls are authenticated with, logged next to the user ID since
+// the users of the JWTs, the introspected tokens and the nonce are not service accounts
+const (
+	authMethodServiceAccountToken = "service_account_token"
+	authMethodJWT                 = "jwt"
+	authMethodIntrospection       = "introspection"
+	authMethodNonce               = "nonce"
+	authMethodAnonymous           = "anonymous"
+)
+
+// localNonceUser is the identity of requests authenticated with the local nonce
+var localNonceUser = user.SignedInUser{
+	Login:            "grpc-local-nonce",
+	Name:             "Local tooling",
+	OrgID:            1,
+	OrgRole:          org.RoleAdmin,
+	IsGrafanaAdmin:   true,
+	IsServiceAccount: true,
+}
+
 func (a *authenticator) tokenAuth(ctx context.Context) (context.Context, error) {
+	if a.anonymous != nil && !hasAuthorization(ctx) && a.anonymous.allowed(ctx) {
+		return a.anonymousAuth(ctx)
+	}
+
 	auth, err := extractAuthorization(ctx)
 	if err != nil {
 		return ctx, err
 	}
 
+	if a.localNonce != nil && strings.HasPrefix(auth, noncePrefix) {
+		return a.nonceAuth(ctx, strings.TrimPrefix(auth, noncePrefix))
+	}
+
 	if !strings.HasPrefix(auth, tokenPrefix) {
 		return ctx, status.Error(codes.Unauthenticated, `missing "Bearer " prefix in "authorization" value`)
 	}
@@ -67,56 +118,228 @@ func (a *authenticator) tokenAuth(ctx context.Context) (context.Context, error)
 
 	newCtx := purgeHeader(ctx, "authorization")
 
-	signedInUser, err := a.getSignedInUser(ctx, token)
+	// the failed calls are logged by the access log, without the token
+	var signedInUser *user.SignedInUser
+	var expiry *time.Time
+	var method string
+	if a.jwtAuth != nil && isJWT(token) {
+		method = authMethodJWT
+		signedInUser, expiry, err = a.getJWTSignedInUser(ctx, token)
+	} else if a.introspection != nil && !isGrafanaToken(token) {
+		method = authMethodIntrospection
+		signedInUser, expiry, err = a.getIntrospectedSignedInUser(ctx, token)
+	} else {
+		method = authMethodServiceAccountToken
+		signedInUser, expiry, err = a.getSignedInUser(ctx, token)
+	}
 	if err != nil {
-		a.logger.Warn("request with invalid token", "error", err, "token", token)
+		if errors.Is(err, errLegacyAPIKey) || errors.Is(err, errTokenExpired) || errors.Is(err, errTokenRevoked) {
+			return ctx, err
+		}
 		return ctx, status.Error(codes.Unauthenticated, "invalid token")
 	}
 
 	newCtx = a.contextHandler.SetUser(newCtx, signedInUser)
+	if grpcContext := grpccontext.FromContext(newCtx); grpcContext != nil {
+		grpcContext.TokenExpiry = expiry
+		grpcContext.AuthMethod = method
+	}
 
 	return newCtx, nil
 }
 
-func (a *authenticator) getSignedInUser(ctx context.Context, token string) (*user.SignedInUser, error) {
+func (a *authenticator) setUser(ctx context.Context, u *user.SignedInUser, method string) context.Context {
+	ctx = a.contextHandler.SetUser(ctx, u)
+	if grpcContext := grpccontext.FromContext(ctx); grpcContext != nil {
+		grpcContext.AuthMethod = method
+	}
+	return ctx
+}
+
+func (a *authenticator) nonceAuth(ctx context.Context, value string) (context.Context, error) {
+	if !isLocalPeer(ctx) {
+		return ctx, status.Error(codes.Unauthenticated, "nonce authentication is only allowed from the local host")
+	}
+	if !a.localNonce.valid(value, time.Now()) {
+		return ctx, status.Error(codes.Unauthenticated, "invalid nonce")
+	}
+
+	u := localNonceUser
+	u.Permissions = map[int64]map[string][]string{}
+	newCtx := purgeHeader(ctx, "authorization")
+	return a.setUser(newCtx, &u, authMethodNonce), nil
+}
+
+// anonymousAuth is only used for calls without credentials, invalid credentials are
+// never downgraded to anonymous access
+func (a *authenticator) anonymousAuth(ctx context.Context) (context.Context, error) {
+	o, err := a.anonymous.org(ctx)
+	if err != nil {
+		a.logger.Error("anonymous access organization error", "org_name", a.anonymous.orgName, "error", err)
+		return ctx, status.Error(codes.Unauthenticated, "anonymous access is not available")
+	}
+
+	u := &user.SignedInUser{
+		OrgID:       o.ID,
+		OrgName:     o.Name,
+		OrgRole:     a.anonymous.orgRole,
+		IsAnonymous: true,
+		Permissions: map[int64]map[string][]string{},
+	}
+	permissions, err := a.AccessControlService.GetUserPermissions(ctx, u, accesscontrol.Options{})
+	if err != nil {
+		a.logger.Error("failed fetching permissions for anonymous user", "error", err)
+	}
+	u.Permissions[u.OrgID] = accesscontrol.GroupScopesByAction(permissions)
+
+	return a.setUser(ctx, u, authMethodAnonymous), nil
+}
+
+// isGrafanaToken reports if the token is a service account token or a legacy API key, which
+// are never sent to the introspection endpoint of the identity provider
+func isGrafanaToken(token string) bool {
+	if strings.HasPrefix(token, apikeygenprefix.GrafanaPrefix) {
+		return true
+	}
+	_, err := apikeygen.Decode(token)
+	return err == nil
+}
+
+// getSignedInUser returns the service account of a glsa_ token, the legacy API keys
+// are not accepted since new installs no longer create them
+func (a *authenticator) getSignedInUser(ctx context.Context, token string) (*user.SignedInUser, *time.Time, error) {
+	if !strings.HasPrefix(token, apikeygenprefix.GrafanaPrefix) {
+		return nil, nil, errLegacyAPIKey
+	}
 	decoded, err := apikeygenprefix.Decode(token)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if decoded.ServiceID != serviceAccountTokenID {
+		return nil, nil, status.Error(codes.Unauthenticated, "not a service account token")
 	}
 
 	hash, err := decoded.Hash()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	apikey, err := a.APIKeyService.GetAPIKeyByHash(ctx, hash)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if apikey == nil || apikey.ServiceAccountId == nil {
-		return nil, status.Error(codes.Unauthenticated, "api key does not have a service account")
+		return nil, nil, status.Error(codes.Unauthenticated, "api key does not have a service account")
+	}
+	if apikey.Expires != nil && *apikey.Expires <= time.Now().Unix() {
+		return nil, nil, errTokenExpired
+	}
+	if apikey.IsRevoked != nil && *apikey.IsRevoked {
+		return nil, nil, errTokenRevoked
 	}
 
+	// non-blocking update of the last use of the token, like the HTTP API
+	go func(id int64) {
+		if err := a.APIKeyService.UpdateAPIKeyLastUsedDate(context.Background(), id); err != nil {
+			a.logger.Warn("failed to update last use date for token", "id", id, "error", err)
+		}
+	}(apikey.ID)
+
 	querySignedInUser := user.GetSignedInUserQuery{UserID: *apikey.ServiceAccountId, OrgID: apikey.OrgID}
 	signedInUser, err := a.UserService.GetSignedInUserWithCacheCtx(ctx, &querySignedInUser)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if signedInUser == nil {
-		return nil, status.Error(codes.Unauthenticated, "service account not found")
+		return nil, nil, status.Error(codes.Unauthenticated, "service account not found")
 	}
 
 	if !signedInUser.HasRole(org.RoleAdmin) {
-		return nil, status.Error(codes.PermissionDenied, "service account does not have admin role")
+		return nil, nil, status.Error(codes.PermissionDenied, "service account does not have admin role")
 	}
 
 	// disabled service accounts are not allowed to access the API
 	if signedInUser.IsDisabled {
-		return nil, status.Error(codes.PermissionDenied, "service account is disabled")
+		return nil, nil, status.Error(codes.PermissionDenied, "service account is disabled")
+	}
+
+	a.setPermissions(ctx, signedInUser)
+
+	var expiry *time.Time
+	if apikey.Expires != nil {
+		t := time.Unix(*apikey.Expires, 0)
+		expiry = &t
+	}
+	return signedInUser, expiry, nil
+}
+
+// getJWTSignedInUser returns the user of a verified JWT, looked up by the username and email claims
+// like the JWT authentication of the HTTP API, or by the verified email of the OIDC ID tokens.
+// Users are not created, they must have signed in before.
+func (a *authenticator) getJWTSignedInUser(ctx context.Context, token string) (*user.SignedInUser, *time.Time, error) {
+	identity, err := a.jwtAuth.verify(ctx, token)
+	if err != nil {
+		if errors.Is(err, jwt.ErrExpired) {
+			return nil, nil, errTokenExpired
+		}
+		a.logger.Debug("failed to verify JWT", "error", err)
+		return nil, nil, err
+	}
+	if identity.login == "" && identity.email == "" {
+		return nil, nil, status.Error(codes.Unauthenticated, "no username or email claim in token")
+	}
+
+	signedInUser, err := a.lookupUser(ctx, user.GetSignedInUserQuery{Login: identity.login, Email: identity.email})
+	if err != nil {
+		return nil, nil, err
+	}
+	return signedInUser, &identity.expiry, nil
+}
+
+// getIntrospectedSignedInUser returns the user with the verified email the identity provider
+// returned for an opaque access token
+func (a *authenticator) getIntrospectedSignedInUser(ctx context.Context, token string) (*user.SignedInUser, *time.Time, error) {
+	introspected, err := a.introspection.introspect(ctx, token)
+	if err != nil {
+		if !errors.Is(err, errTokenExpired) {
+			a.logger.Debug("failed to introspect token", "error", err)
+		}
+		return nil, nil, err
+	}
+
+	signedInUser, err := a.lookupUser(ctx, user.GetSignedInUserQuery{Email: introspected.email})
+	if err != nil {
+		return nil, nil, err
+	}
+	return signedInUser, introspected.expiry, nil
+}
+
+// lookupUser returns the enabled user of the query, with its permissions, for the identities
+// asserted by an external token
+func (a *authenticator) lookupUser(ctx context.Context, query user.GetSignedInUserQuery) (*user.SignedInUser, error) {
+	signedInUser, err := a.UserService.GetSignedInUserWithCacheCtx(ctx, &query)
+	if err != nil {
+		return nil, err
+	}
+	if signedInUser == nil {
+		return nil, status.Error(codes.Unauthenticated, "user not found")
+	}
+	if signedInUser.IsDisabled {
+		return nil, status.Error(codes.PermissionDenied, "user is disabled")
 	}
+	// the services have no permission checks of their own, the users of external tokens
+	// need the admin role like the service accounts
+	if !signedInUser.HasRole(org.RoleAdmin) {
+		return nil, status.Error(codes.PermissionDenied, "user does not have admin role")
+	}
+
+	a.setPermissions(ctx, signedInUser)
+	return signedInUser, nil
+}
 
+func (a *authenticator) setPermissions(ctx context.Context, signedInUser *user.SignedInUser) {
 	if signedInUser.Permissions == nil {
 		signedInUser.Permissions = make(map[int64]map[string][]string)
 	}
@@ -128,8 +351,6 @@ func (a *authenticator) getSignedInUser(ctx context.Context, token string) (*use
 		}
 		signedInUser.Permissions[signedInUser.OrgID] = accesscontrol.GroupScopesByAction(permissions)
 	}
-
-	return signedInUser, nil
 }
 
 func extractAuthorization(ctx context.Context) (string, error) {
@@ -150,6 +371,11 @@ func extractAuthorization(ctx context.Context) (string, error) {
 	return authHeaders[0], nil
 }
 
+func hasAuthorization(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && len(md["authorization"]) > 0
+}
+
 func purgeHeader(ctx context.Context, header string) context.Context {
 	md, _ := metadata.FromIncomingContext(ctx)
 	mdCopy := md.Copy()
@@ -0,0 +1,135 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	grpcAuth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+// AuthRequirement is what a method requires from the caller
+type AuthRequirement int
+
+const (
+	// AuthAuthenticated requires a valid token, a local nonce or anonymous access, it is the default
+	AuthAuthenticated AuthRequirement = iota
+	// AuthAdmin requires an authenticated Grafana server admin
+	AuthAdmin
+	// AuthUnauthenticated calls are not authenticated, like the health checks
+	AuthUnauthenticated
+	// AuthOrgAdmin requires an authenticated admin of the org of the caller, or a server admin
+	AuthOrgAdmin
+)
+
+// AuthPolicy holds the requirements the services declare for their methods when they are
+// registered. The requirement of a method overrides the one of its service, the methods of
+// the services without a requirement must be authenticated.
+type AuthPolicy struct {
+	mu       sync.RWMutex
+	services map[string]AuthRequirement
+	methods  map[string]AuthRequirement
+}
+
+func ProvideAuthPolicy() *AuthPolicy {
+	return &AuthPolicy{
+		services: map[string]AuthRequirement{},
+		methods:  map[string]AuthRequirement{},
+	}
+}
+
+// SetService sets the requirement of all the methods of a service, like "grpc.health.v1.Health"
+func (p *AuthPolicy) SetService(service string, requirement AuthRequirement) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.services[service] = requirement
+}
+
+// SetMethod sets the requirement of a full method name, like "/entity.EntityStore/AdminWrite"
+func (p *AuthPolicy) SetMethod(fullMethod string, requirement AuthRequirement) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.methods[fullMethod] = requirement
+}
+
+func (p *AuthPolicy) requirement(fullMethod string) AuthRequirement {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if r, ok := p.methods[fullMethod]; ok {
+		return r
+	}
+	service := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(service, "/"); i >= 0 {
+		service = service[:i]
+	}
+	if r, ok := p.services[service]; ok {
+		return r
+	}
+	return AuthAuthenticated
+}
+
+// authenticate applies the requirement of the method, the services implementing
+// ServiceAuthFuncOverride authenticate their calls themselves
+func (p *AuthPolicy) authenticate(ctx context.Context, srv interface{}, fullMethod string, authenticator Authenticator) (context.Context, error) {
+	requirement := p.requirement(fullMethod)
+	if requirement == AuthUnauthenticated {
+		return ctx, nil
+	}
+
+	var err error
+	if override, ok := srv.(grpcAuth.ServiceAuthFuncOverride); ok {
+		ctx, err = override.AuthFuncOverride(ctx, fullMethod)
+	} else {
+		ctx, err = authenticator.Authenticate(ctx)
+	}
+	if err != nil {
+		return ctx, err
+	}
+
+	switch requirement {
+	case AuthAdmin:
+		grpcContext := grpccontext.FromContext(ctx)
+		if grpcContext == nil || grpcContext.SignedInUser == nil || !grpcContext.SignedInUser.IsGrafanaAdmin {
+			return ctx, status.Error(codes.PermissionDenied, "method requires a server admin")
+		}
+	case AuthOrgAdmin:
+		grpcContext := grpccontext.FromContext(ctx)
+		if grpcContext == nil || grpcContext.SignedInUser == nil ||
+			!(grpcContext.SignedInUser.IsGrafanaAdmin || grpcContext.SignedInUser.HasRole(org.RoleAdmin)) {
+			return ctx, status.Error(codes.PermissionDenied, "method requires an org admin")
+		}
+	}
+	return ctx, nil
+}
+
+// AuthUnaryInterceptor authenticates the calls with the requirement of their method
+func AuthUnaryInterceptor(authenticator Authenticator, policy *AuthPolicy) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		newCtx, err := policy.authenticate(ctx, info.Server, info.FullMethod, authenticator)
+		if err != nil {
+			return nil, err
+		}
+		return handler(newCtx, req)
+	}
+}
+
+func AuthStreamInterceptor(authenticator Authenticator, policy *AuthPolicy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		newCtx, err := policy.authenticate(stream.Context(), srv, info.FullMethod, authenticator)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &tracingServerStream{ServerStream: stream, ctx: newCtx})
+	}
+}
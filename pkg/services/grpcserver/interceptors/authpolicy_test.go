@@ -0,0 +1,92 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type fakeAuthenticator struct {
+	contextHandler grpccontext.ContextHandler
+	user           *user.SignedInUser
+	calls          int
+}
+
+func (a *fakeAuthenticator) Authenticate(ctx context.Context) (context.Context, error) {
+	a.calls++
+	if a.user == nil {
+		return ctx, status.Error(codes.Unauthenticated, "token required")
+	}
+	return a.contextHandler.SetUser(ctx, a.user), nil
+}
+
+func TestAuthPolicy(t *testing.T) {
+	policy := ProvideAuthPolicy()
+	policy.SetService("grpc.health.v1.Health", AuthUnauthenticated)
+	policy.SetService("entity.EntityStoreAdmin", AuthAdmin)
+	policy.SetMethod("/entity.EntityStore/AdminWrite", AuthAdmin)
+
+	require.Equal(t, AuthUnauthenticated, policy.requirement("/grpc.health.v1.Health/Check"))
+	require.Equal(t, AuthAdmin, policy.requirement("/entity.EntityStoreAdmin/AdminWrite"))
+	require.Equal(t, AuthAdmin, policy.requirement("/entity.EntityStore/AdminWrite"))
+	require.Equal(t, AuthAuthenticated, policy.requirement("/entity.EntityStore/Write"))
+	require.Equal(t, AuthAuthenticated, policy.requirement("/grpc.health.v1.HealthOther/Check"))
+}
+
+func TestAuthUnaryInterceptor(t *testing.T) {
+	contextHandler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+	policy := ProvideAuthPolicy()
+	policy.SetService("grpc.health.v1.Health", AuthUnauthenticated)
+	policy.SetMethod("/entity.EntityStore/AdminWrite", AuthAdmin)
+	policy.SetMethod("/apikeymigration.APIKeyMigration/MigrateAPIKeys", AuthOrgAdmin)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	call := func(authenticator *fakeAuthenticator, method string) error {
+		_, err := AuthUnaryInterceptor(authenticator, policy)(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	t.Run("unauthenticated methods skip the authenticator", func(t *testing.T) {
+		authenticator := &fakeAuthenticator{contextHandler: contextHandler}
+		require.NoError(t, call(authenticator, "/grpc.health.v1.Health/Check"))
+		require.Equal(t, 0, authenticator.calls)
+	})
+
+	t.Run("other methods are authenticated", func(t *testing.T) {
+		authenticator := &fakeAuthenticator{contextHandler: contextHandler}
+		require.Equal(t, codes.Unauthenticated, status.Code(call(authenticator, "/entity.EntityStore/Write")))
+
+		authenticator.user = &user.SignedInUser{OrgID: 1, UserID: 2}
+		require.NoError(t, call(authenticator, "/entity.EntityStore/Write"))
+	})
+
+	t.Run("admin methods require a server admin", func(t *testing.T) {
+		authenticator := &fakeAuthenticator{contextHandler: contextHandler, user: &user.SignedInUser{OrgID: 1, UserID: 2}}
+		require.Equal(t, codes.PermissionDenied, status.Code(call(authenticator, "/entity.EntityStore/AdminWrite")))
+
+		authenticator.user = &user.SignedInUser{OrgID: 1, UserID: 2, IsGrafanaAdmin: true}
+		require.NoError(t, call(authenticator, "/entity.EntityStore/AdminWrite"))
+	})
+
+	t.Run("org admin methods require an org admin", func(t *testing.T) {
+		authenticator := &fakeAuthenticator{contextHandler: contextHandler, user: &user.SignedInUser{OrgID: 1, UserID: 2, OrgRole: org.RoleEditor}}
+		require.Equal(t, codes.PermissionDenied, status.Code(call(authenticator, "/apikeymigration.APIKeyMigration/MigrateAPIKeys")))
+
+		authenticator.user = &user.SignedInUser{OrgID: 1, UserID: 2, OrgRole: org.RoleAdmin}
+		require.NoError(t, call(authenticator, "/apikeymigration.APIKeyMigration/MigrateAPIKeys"))
+
+		authenticator.user = &user.SignedInUser{OrgID: 1, UserID: 2, OrgRole: org.RoleViewer, IsGrafanaAdmin: true}
+		require.NoError(t, call(authenticator, "/apikeymigration.APIKeyMigration/MigrateAPIKeys"))
+	})
+}
@@ -0,0 +1,100 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// recvCompressor is implemented by the transport stream of the calls, the server compresses
+// the responses with the compressor of the request
+type recvCompressor interface {
+	RecvCompress() string
+}
+
+func requestCompressed(ctx context.Context) bool {
+	stream, ok := grpc.ServerTransportStreamFromContext(ctx).(recvCompressor)
+	if !ok {
+		return false
+	}
+	compressor := stream.RecvCompress()
+	return compressor != "" && compressor != "identity"
+}
+
+// CompressionPolicy rejects the responses larger than require_compression_above to the calls
+// that are not compressed, the server can only compress the responses of compressed requests.
+// The clients must then use gzip or zstd for the methods returning large responses, like the
+// entity searches. The size is only known once the method ran, so only the read only methods are
+// checked: a write would be applied although the client is told that the call failed.
+type CompressionPolicy struct {
+	minSize  int
+	readOnly *ReadOnlyMethods
+}
+
+// NewCompressionPolicy returns nil when compression is not required
+func NewCompressionPolicy(cfg *setting.Cfg, readOnly *ReadOnlyMethods) *CompressionPolicy {
+	if cfg.GRPCServerRequireCompressionAbove <= 0 {
+		return nil
+	}
+	return &CompressionPolicy{minSize: cfg.GRPCServerRequireCompressionAbove, readOnly: readOnly}
+}
+
+func (p *CompressionPolicy) applies(fullMethod string) bool {
+	return p != nil && p.readOnly.IsReadOnly(fullMethod)
+}
+
+func (p *CompressionPolicy) check(ctx context.Context, msg interface{}) error {
+	pm, ok := msg.(proto.Message)
+	if !ok {
+		return nil
+	}
+	if size := proto.Size(pm); size > p.minSize && !requestCompressed(ctx) {
+		return status.Errorf(codes.FailedPrecondition, "response of %d bytes requires compression, call with the gzip or zstd compressor", size)
+	}
+	return nil
+}
+
+func CompressionUnaryInterceptor(p *CompressionPolicy) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil || !p.applies(info.FullMethod) {
+			return resp, err
+		}
+		if err := p.check(ctx, resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// CompressionStreamInterceptor checks every message sent on the streams of the read only methods
+func CompressionStreamInterceptor(p *CompressionPolicy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !p.applies(info.FullMethod) {
+			return handler(srv, stream)
+		}
+		return handler(srv, &compressionServerStream{ServerStream: stream, policy: p})
+	}
+}
+
+type compressionServerStream struct {
+	grpc.ServerStream
+	policy *CompressionPolicy
+}
+
+func (s *compressionServerStream) SendMsg(msg interface{}) error {
+	if err := s.policy.check(s.Context(), msg); err != nil {
+		return err
+	}
+	return s.ServerStream.SendMsg(msg)
+}
@@ -0,0 +1,81 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// fakeTransportStream reports the compressor of the request like the transport stream of the server
+type fakeTransportStream struct {
+	grpc.ServerTransportStream
+	compressor string
+}
+
+func (s *fakeTransportStream) RecvCompress() string {
+	return s.compressor
+}
+
+func TestCompressionUnaryInterceptor(t *testing.T) {
+	require.Nil(t, NewCompressionPolicy(setting.NewCfg(), ProvideReadOnlyMethods()))
+
+	cfg := setting.NewCfg()
+	cfg.GRPCServerRequireCompressionAbove = 100
+	readOnly := ProvideReadOnlyMethods()
+	readOnly.SetMethods("/entity.EntityStore/Search")
+	interceptor := CompressionUnaryInterceptor(NewCompressionPolicy(cfg, readOnly))
+	info := &grpc.UnaryServerInfo{FullMethod: "/entity.EntityStore/Search"}
+	respond := func(size int) grpc.UnaryHandler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			return wrapperspb.String(strings.Repeat("x", size)), nil
+		}
+	}
+
+	_, err := interceptor(context.Background(), nil, info, respond(10))
+	require.NoError(t, err)
+
+	_, err = interceptor(context.Background(), nil, info, respond(1000))
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), &fakeTransportStream{compressor: "identity"})
+	_, err = interceptor(ctx, nil, info, respond(1000))
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	ctx = grpc.NewContextWithServerTransportStream(context.Background(), &fakeTransportStream{compressor: "zstd"})
+	resp, err := interceptor(ctx, nil, info, respond(1000))
+	require.NoError(t, err)
+	require.Len(t, resp.(*wrapperspb.StringValue).Value, 1000)
+}
+
+func TestCompressionNotRequiredForWrites(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.GRPCServerRequireCompressionAbove = 100
+	readOnly := ProvideReadOnlyMethods()
+	readOnly.SetMethods("/entity.EntityStore/Search")
+	interceptor := CompressionUnaryInterceptor(NewCompressionPolicy(cfg, readOnly))
+
+	// the write is applied before the size of its response is known, it must not be reported as failed
+	writes := 0
+	write := func(ctx context.Context, req interface{}) (interface{}, error) {
+		writes++
+		return wrapperspb.String(strings.Repeat("x", 1000)), nil
+	}
+	resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/entity.EntityStore/Write"}, write)
+	require.NoError(t, err)
+	require.Len(t, resp.(*wrapperspb.StringValue).Value, 1000)
+	require.Equal(t, 1, writes)
+
+	// only the read only methods, without side effects, are rejected
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/entity.EntityStore/Search"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.String(strings.Repeat("x", 1000)), nil
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
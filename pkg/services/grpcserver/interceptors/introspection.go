@@ -0,0 +1,200 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	errTokenInactive                = errors.New("token is not active")
+	errTooManyIntrospectionRequests = errors.New("too many token introspection requests")
+)
+
+const (
+	// the inactive tokens and the failed introspections are cached for a short time, so that the
+	// same bad token does not reach the identity provider on every call
+	introspectionFailureCacheTTL = 10 * time.Second
+	// bounds the requests to the identity provider, the tokens that are not cached are rejected above it
+	introspectionRequestsPerSecond = 50
+	introspectionRequestsBurst     = 100
+	// bounds the shared introspection, which does not end with the call that started it
+	introspectionTimeout = 10 * time.Second
+)
+
+// TokenIntrospection checks opaque OAuth2 access tokens with the RFC 7662 introspection endpoint
+// of the identity provider, authenticated as the client of GRPCServerIntrospectionClientID. The
+// active tokens are cached, so a revoked token can still be used for up to the cache TTL. The
+// concurrent calls with the same token share one request, and the requests are rate limited.
+// The shared request is not bound to the call that started it, so a canceled call does not fail
+// the others waiting for the same token.
+type TokenIntrospection struct {
+	url          string
+	clientID     string
+	clientSecret string
+	audience     string
+	emailField   string
+	cacheTTL     time.Duration
+	cache        *localcache.CacheService
+	client       *http.Client
+	limiter      *rate.Limiter
+	requests     singleflight.Group
+}
+
+// introspectedToken is the user of an active token, with the expiry of the token when the
+// identity provider returned one
+type introspectedToken struct {
+	email  string
+	expiry *time.Time
+}
+
+// introspectionResult is the cached result of an introspection, failed or not
+type introspectionResult struct {
+	token *introspectedToken
+	err   error
+}
+
+func ProvideTokenIntrospection(cfg *setting.Cfg) *TokenIntrospection {
+	if cfg.GRPCServerIntrospectionURL == "" {
+		return nil
+	}
+	return &TokenIntrospection{
+		url:          cfg.GRPCServerIntrospectionURL,
+		clientID:     cfg.GRPCServerIntrospectionClientID,
+		clientSecret: cfg.GRPCServerIntrospectionClientSecret,
+		audience:     cfg.GRPCServerIntrospectionAudience,
+		emailField:   cfg.GRPCServerIntrospectionEmailField,
+		cacheTTL:     cfg.GRPCServerIntrospectionCacheTTL,
+		cache:        localcache.New(cfg.GRPCServerIntrospectionCacheTTL, 10*time.Minute),
+		client:       &http.Client{Timeout: 10 * time.Second},
+		limiter:      rate.NewLimiter(introspectionRequestsPerSecond, introspectionRequestsBurst),
+	}
+}
+
+func (i *TokenIntrospection) introspect(ctx context.Context, token string) (*introspectedToken, error) {
+	// the tokens are never kept in memory, only their hash
+	sum := sha256.Sum256([]byte(token))
+	cacheKey := hex.EncodeToString(sum[:])
+	if cached, ok := i.cache.Get(cacheKey); ok {
+		result := cached.(*introspectionResult)
+		return result.token, result.err
+	}
+
+	ch := i.requests.DoChan(cacheKey, func() (interface{}, error) {
+		if !i.limiter.Allow() {
+			return &introspectionResult{err: errTooManyIntrospectionRequests}, nil
+		}
+		checkCtx, cancel := context.WithTimeout(context.Background(), introspectionTimeout)
+		defer cancel()
+
+		result := &introspectionResult{}
+		result.token, result.err = i.check(checkCtx, token)
+		if result.err == nil {
+			ttl := i.cacheTTL
+			if result.token.expiry != nil && time.Until(*result.token.expiry) < ttl {
+				ttl = time.Until(*result.token.expiry)
+			}
+			if ttl > 0 {
+				i.cache.Set(cacheKey, result, ttl)
+			}
+		} else {
+			i.cache.Set(cacheKey, result, introspectionFailureCacheTTL)
+		}
+		return result, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		result := res.Val.(*introspectionResult)
+		return result.token, result.err
+	}
+}
+
+// check asks the identity provider about the token and returns its user when it is active
+func (i *TokenIntrospection) check(ctx context.Context, token string) (*introspectedToken, error) {
+	rsp, err := i.request(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if active, _ := rsp["active"].(bool); !active {
+		return nil, errTokenInactive
+	}
+
+	introspected := &introspectedToken{}
+	if exp, ok := rsp["exp"].(float64); ok {
+		expiry := time.Unix(int64(exp), 0)
+		if !expiry.After(time.Now()) {
+			return nil, errTokenExpired
+		}
+		introspected.expiry = &expiry
+	}
+	if i.audience != "" && !hasAudience(rsp["aud"], i.audience) {
+		return nil, fmt.Errorf("token audience does not include %q", i.audience)
+	}
+	// like the OIDC ID tokens, the users are only matched by the email the provider verified
+	if verified, _ := rsp["email_verified"].(bool); !verified {
+		return nil, errors.New("no verified email in introspection response")
+	}
+	introspected.email, _ = rsp[i.emailField].(string)
+	if introspected.email == "" {
+		return nil, fmt.Errorf("no %q in introspection response", i.emailField)
+	}
+	return introspected, nil
+}
+
+func (i *TokenIntrospection) request(ctx context.Context, token string) (map[string]interface{}, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(i.clientID), url.QueryEscape(i.clientSecret))
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from the introspection endpoint", resp.StatusCode)
+	}
+	var rsp map[string]interface{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+// hasAudience reports if the aud of the response, a string or an array of strings, includes the audience
+func hasAudience(aud interface{}, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
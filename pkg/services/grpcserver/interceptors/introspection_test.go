@@ -0,0 +1,244 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/components/apikeygen"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestProvideTokenIntrospection(t *testing.T) {
+	cfg := setting.NewCfg()
+	require.Nil(t, ProvideTokenIntrospection(cfg))
+
+	cfg.GRPCServerIntrospectionURL = "https://idp.example.com/oauth2/introspect"
+	require.NotNil(t, ProvideTokenIntrospection(cfg))
+}
+
+func TestTokenIntrospection(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	var mu sync.Mutex
+	calls := map[string]int{}
+	idp := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != "grafana" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		token := r.PostFormValue("token")
+		mu.Lock()
+		calls[token]++
+		mu.Unlock()
+		rsp := map[string]interface{}{"active": false}
+		switch token {
+		case "active":
+			rsp = map[string]interface{}{"active": true, "sub": "ci-id", "email": "ci@example.com", "email_verified": true, "exp": exp, "aud": []string{"grafana-grpc", "other"}}
+		case "other-audience":
+			rsp = map[string]interface{}{"active": true, "sub": "ci-id", "email": "ci@example.com", "email_verified": true, "exp": exp, "aud": "other"}
+		case "expired":
+			rsp = map[string]interface{}{"active": true, "sub": "ci-id", "email": "ci@example.com", "email_verified": true, "exp": time.Now().Add(-time.Minute).Unix(), "aud": "grafana-grpc"}
+		case "no-email":
+			rsp = map[string]interface{}{"active": true, "sub": "ci-id", "email_verified": true, "aud": "grafana-grpc"}
+		case "admin-subject":
+			rsp = map[string]interface{}{"active": true, "sub": "admin", "username": "admin", "email": "ci@example.com", "aud": "grafana-grpc"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(rsp)
+	}))
+	t.Cleanup(idp.Close)
+
+	introspection := &TokenIntrospection{
+		url:          idp.URL,
+		clientID:     "grafana",
+		clientSecret: "secret",
+		audience:     "grafana-grpc",
+		emailField:   "email",
+		cacheTTL:     time.Minute,
+		cache:        localcache.New(time.Minute, time.Minute),
+		client:       idp.Client(),
+		limiter:      rate.NewLimiter(rate.Inf, 1),
+	}
+	handler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+	users := &fakeJWTUserService{role: org.RoleAdmin}
+	serviceAccountID := int64(1)
+	keys := newFakeAPIKey(&apikey.APIKey{ID: 1, OrgID: 1, ServiceAccountId: &serviceAccountID}, nil)
+	a := ProvideAuthenticator(keys, users, accesscontrolmock.New(), handler, nil, nil, nil, introspection)
+
+	t.Run("maps active tokens to the user with the verified email", func(t *testing.T) {
+		ctx, err := a.Authenticate(bearer("active"))
+		require.NoError(t, err)
+		require.Equal(t, user.GetSignedInUserQuery{Email: "ci@example.com", OrgID: users.query.OrgID}, users.query)
+		require.Equal(t, int64(42), handler.GetUser(ctx).UserID)
+		require.Equal(t, exp, grpccontext.FromContext(ctx).TokenExpiry.Unix())
+		require.Equal(t, authMethodIntrospection, grpccontext.FromContext(ctx).AuthMethod)
+
+		_, err = a.Authenticate(bearer("active"))
+		require.NoError(t, err)
+		require.Equal(t, 1, calls["active"])
+	})
+
+	t.Run("rejects inactive tokens", func(t *testing.T) {
+		for _, token := range []string{"inactive", "other-audience", "no-email"} {
+			_, err := a.Authenticate(bearer(token))
+			require.Equal(t, codes.Unauthenticated, status.Code(err), token)
+			require.Equal(t, "invalid token", status.Convert(err).Message(), token)
+		}
+
+		_, err := a.Authenticate(bearer("inactive"))
+		require.Error(t, err)
+		require.Equal(t, 1, calls["inactive"], "inactive tokens are cached")
+	})
+
+	t.Run("never matches the subject against the logins", func(t *testing.T) {
+		users := &fakeJWTUserService{role: org.RoleAdmin}
+		a := ProvideAuthenticator(keys, users, accesscontrolmock.New(), handler, nil, nil, nil, introspection)
+		_, err := a.Authenticate(bearer("admin-subject"))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.Empty(t, users.query)
+	})
+
+	t.Run("reports expired tokens", func(t *testing.T) {
+		_, err := a.Authenticate(bearer("expired"))
+		require.Equal(t, "token expired", status.Convert(err).Message())
+	})
+
+	t.Run("rejects users without admin role", func(t *testing.T) {
+		a := ProvideAuthenticator(keys, &fakeJWTUserService{role: org.RoleViewer}, accesscontrolmock.New(), handler, nil, nil, nil, introspection)
+		_, err := a.Authenticate(bearer("active"))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("never sends legacy API keys to the identity provider", func(t *testing.T) {
+		legacy, err := apikeygen.New(1, "legacy")
+		require.NoError(t, err)
+		_, err = a.Authenticate(bearer(legacy.ClientSecret))
+		require.Equal(t, "only service account tokens are accepted", status.Convert(err).Message())
+		mu.Lock()
+		defer mu.Unlock()
+		require.Zero(t, calls[legacy.ClientSecret])
+	})
+
+	t.Run("still accepts service account tokens", func(t *testing.T) {
+		a := ProvideAuthenticator(keys, &fakeUserService{OrgRole: org.RoleAdmin}, accesscontrolmock.New(), handler, nil, nil, nil, introspection)
+		ctx, err := setupContext()
+		require.NoError(t, err)
+		_, err = a.Authenticate(ctx)
+		require.NoError(t, err)
+		require.Len(t, calls, 6)
+	})
+}
+
+func TestTokenIntrospectionRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls int32
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"active": true, "email": "ci@example.com", "email_verified": true})
+	}))
+	t.Cleanup(idp.Close)
+
+	newIntrospection := func(limiter *rate.Limiter) *TokenIntrospection {
+		return &TokenIntrospection{
+			url:        idp.URL,
+			emailField: "email",
+			cacheTTL:   time.Minute,
+			cache:      localcache.New(time.Minute, time.Minute),
+			client:     idp.Client(),
+			limiter:    limiter,
+		}
+	}
+
+	t.Run("concurrent calls with the same token share one request", func(t *testing.T) {
+		introspection := newIntrospection(rate.NewLimiter(rate.Inf, 1))
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				introspected, err := introspection.introspect(context.Background(), "token")
+				assert.NoError(t, err)
+				assert.Equal(t, "ci@example.com", introspected.email)
+			}()
+			if i == 0 {
+				<-started
+			}
+		}
+		close(release)
+		wg.Wait()
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("a canceled call does not fail the others sharing its request", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var calls int32
+		idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				close(started)
+				<-release
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"active": true, "email": "ci@example.com", "email_verified": true})
+		}))
+		t.Cleanup(idp.Close)
+		introspection := newIntrospection(rate.NewLimiter(rate.Inf, 1))
+		introspection.url = idp.URL
+		introspection.client = idp.Client()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan error, 1)
+		go func() {
+			_, err := introspection.introspect(ctx, "token")
+			first <- err
+		}()
+		<-started
+
+		second := make(chan *introspectedToken, 1)
+		go func() {
+			introspected, err := introspection.introspect(context.Background(), "token")
+			assert.NoError(t, err)
+			second <- introspected
+		}()
+
+		cancel()
+		require.ErrorIs(t, <-first, context.Canceled)
+		close(release)
+		introspected := <-second
+		require.NotNil(t, introspected)
+		require.Equal(t, "ci@example.com", introspected.email)
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("requests above the rate limit are rejected", func(t *testing.T) {
+		introspection := newIntrospection(rate.NewLimiter(0, 1))
+		_, err := introspection.introspect(context.Background(), "first")
+		require.NoError(t, err)
+		_, err = introspection.introspect(context.Background(), "second")
+		require.ErrorIs(t, err, errTooManyIntrospectionRequests)
+		require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+}
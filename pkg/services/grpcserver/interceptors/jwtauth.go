@@ -0,0 +1,263 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	authJWT "github.com/grafana/grafana/pkg/services/auth/jwt"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// oidcKeysRefreshInterval limits how often the keys of the OIDC provider are fetched again
+// for a token signed with an unknown key
+const oidcKeysRefreshInterval = time.Minute
+
+// oidcLeeway is the clock skew allowed for the time claims of the OIDC tokens
+const oidcLeeway = 30 * time.Second
+
+// oidcKeysFetchTimeout bounds the shared fetch of the keys, which does not end with the call that started it
+const oidcKeysFetchTimeout = 10 * time.Second
+
+var (
+	errTokenMissingExpiry   = errors.New("token without expiry")
+	errOIDCEmailNotVerified = errors.New("token without verified email")
+)
+
+// JWTAuth verifies the JWTs sent as bearer tokens, so short-lived tokens can be used instead
+// of service account tokens. Tokens issued by GRPCServerOIDCIssuer are verified with the keys
+// of the provider, every other token with the keys of [auth.jwt]. Tokens must have an expiry.
+type JWTAuth struct {
+	jwtService    authJWT.JWTService
+	usernameClaim string
+	emailClaim    string
+
+	oidc *oidcVerifier
+}
+
+// jwtIdentity is the user a verified token was issued for
+type jwtIdentity struct {
+	login  string
+	email  string
+	expiry time.Time
+}
+
+func ProvideJWTAuth(cfg *setting.Cfg, jwtService authJWT.JWTService) *JWTAuth {
+	a := &JWTAuth{}
+	if cfg.GRPCServerJWTAuthEnabled && cfg.JWTAuthEnabled {
+		a.jwtService = jwtService
+		a.usernameClaim = cfg.JWTAuthUsernameClaim
+		a.emailClaim = cfg.JWTAuthEmailClaim
+	}
+	if cfg.GRPCServerOIDCIssuer != "" {
+		a.oidc = &oidcVerifier{
+			issuer:     cfg.GRPCServerOIDCIssuer,
+			audience:   cfg.GRPCServerOIDCAudience,
+			emailClaim: cfg.GRPCServerOIDCEmailClaim,
+			client:     &http.Client{Timeout: 10 * time.Second},
+		}
+	}
+	if a.jwtService == nil && a.oidc == nil {
+		return nil
+	}
+	return a
+}
+
+// isJWT reports if the token has the three parts of a compact JWT, the service account
+// tokens and legacy API keys never contain a dot
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+func (a *JWTAuth) verify(ctx context.Context, token string) (*jwtIdentity, error) {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil {
+		return nil, err
+	}
+	var unverified jwt.Claims
+	if err := parsed.UnsafeClaimsWithoutVerification(&unverified); err != nil {
+		return nil, err
+	}
+
+	if a.oidc != nil && unverified.Issuer == a.oidc.issuer {
+		return a.oidc.verify(ctx, parsed)
+	}
+	if a.jwtService == nil {
+		return nil, fmt.Errorf("unexpected issuer %q", unverified.Issuer)
+	}
+
+	claims, err := a.jwtService.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, errTokenMissingExpiry
+	}
+	expiry := jwt.NumericDate(exp)
+	return &jwtIdentity{
+		login:  stringClaim(claims, a.usernameClaim),
+		email:  stringClaim(claims, a.emailClaim),
+		expiry: expiry.Time(),
+	}, nil
+}
+
+func stringClaim(claims map[string]interface{}, key string) string {
+	if key == "" {
+		return ""
+	}
+	value, _ := claims[key].(string)
+	return value
+}
+
+// oidcVerifier verifies the ID tokens of an external OIDC provider with the keys published
+// in its discovery document. The users are matched by the email the provider verified, never by
+// the subject or the names the users can change at the provider: the subject is only unique at
+// the provider and could equal the login of any Grafana user, such as admin.
+type oidcVerifier struct {
+	issuer     string
+	audience   string
+	emailClaim string
+	client     *http.Client
+
+	mu        sync.Mutex
+	keys      jose.JSONWebKeySet
+	fetchedAt time.Time
+	fetches   singleflight.Group
+}
+
+func (v *oidcVerifier) verify(ctx context.Context, token *jwt.JSONWebToken) (*jwtIdentity, error) {
+	if len(token.Headers) == 0 {
+		return nil, errors.New("token without header")
+	}
+	keys, err := v.key(ctx, token.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var registered jwt.Claims
+	var claims map[string]interface{}
+	for _, key := range keys {
+		if err = token.Claims(key, &registered, &claims); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if registered.Expiry == nil {
+		return nil, errTokenMissingExpiry
+	}
+	expected := jwt.Expected{Issuer: v.issuer, Audience: jwt.Audience{v.audience}, Time: time.Now()}
+	if err := registered.ValidateWithLeeway(expected, oidcLeeway); err != nil {
+		return nil, err
+	}
+
+	if verified, _ := claims["email_verified"].(bool); !verified {
+		return nil, errOIDCEmailNotVerified
+	}
+	email := stringClaim(claims, v.emailClaim)
+	if email == "" {
+		return nil, errOIDCEmailNotVerified
+	}
+	return &jwtIdentity{email: email, expiry: registered.Expiry.Time()}, nil
+}
+
+// key returns the keys with the ID, the keys of the provider are fetched again when none
+// match, to follow key rotations. The fetch is shared by the concurrent calls and made outside
+// the lock, so the tokens signed with the known keys are still verified meanwhile. It is not bound
+// to the call that started it, so a canceled call does not fail the others waiting for the keys.
+func (v *oidcVerifier) key(ctx context.Context, kid string) ([]jose.JSONWebKey, error) {
+	v.mu.Lock()
+	keys := v.keys.Key(kid)
+	refresh := time.Since(v.fetchedAt) >= oidcKeysRefreshInterval
+	v.mu.Unlock()
+	if len(keys) > 0 {
+		return keys, nil
+	}
+	if !refresh {
+		return nil, errors.New("no keys found")
+	}
+
+	ch := v.fetches.DoChan("keys", func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.Background(), oidcKeysFetchTimeout)
+		defer cancel()
+
+		set, err := v.fetchKeys(fetchCtx)
+		if err != nil {
+			return nil, err
+		}
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		v.keys = *set
+		v.fetchedAt = time.Now()
+		return nil, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	keys = v.keys.Key(kid)
+	if len(keys) == 0 {
+		return nil, errors.New("no keys found")
+	}
+	return keys, nil
+}
+
+func (v *oidcVerifier) fetchKeys(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := v.getJSON(ctx, strings.TrimSuffix(v.issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, err
+	}
+	// the issuer must match exactly, see OpenID Connect Discovery 4.3
+	if discovery.Issuer != v.issuer {
+		return nil, fmt.Errorf("discovery document issuer %q does not match %q", discovery.Issuer, v.issuer)
+	}
+	if !strings.HasPrefix(discovery.JWKSURI, "https://") {
+		return nil, errors.New("jwks_uri must have https scheme")
+	}
+
+	var set jose.JSONWebKeySet
+	if err := v.getJSON(ctx, discovery.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+func (v *oidcVerifier) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
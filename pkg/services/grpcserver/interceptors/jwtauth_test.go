@@ -0,0 +1,341 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	authJWT "github.com/grafana/grafana/pkg/services/auth/jwt"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestProvideJWTAuth(t *testing.T) {
+	cfg := setting.NewCfg()
+	require.Nil(t, ProvideJWTAuth(cfg, authJWT.NewFakeJWTService()))
+
+	// [auth.jwt] must be enabled for the HTTP API too
+	cfg.GRPCServerJWTAuthEnabled = true
+	require.Nil(t, ProvideJWTAuth(cfg, authJWT.NewFakeJWTService()))
+	cfg.JWTAuthEnabled = true
+	require.NotNil(t, ProvideJWTAuth(cfg, authJWT.NewFakeJWTService()))
+
+	cfg = setting.NewCfg()
+	cfg.GRPCServerOIDCIssuer = "https://accounts.example.com"
+	a := ProvideJWTAuth(cfg, authJWT.NewFakeJWTService())
+	require.NotNil(t, a)
+	require.Nil(t, a.jwtService)
+}
+
+func TestJWTAuthentication(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	handler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+
+	t.Run("accepts tokens verified by [auth.jwt]", func(t *testing.T) {
+		exp := time.Now().Add(5 * time.Minute).Truncate(time.Second)
+		jwtService := &authJWT.FakeJWTService{VerifyProvider: func(ctx context.Context, token string) (authJWT.JWTClaims, error) {
+			return authJWT.JWTClaims{"sub": "42", "login": "alice", "exp": float64(exp.Unix())}, nil
+		}}
+		users := &fakeJWTUserService{role: org.RoleAdmin}
+		a := ProvideAuthenticator(nil, users, accesscontrolmock.New(), handler, nil, nil, newJWTAuth(jwtService, nil), nil)
+
+		ctx, err := a.Authenticate(bearer(signJWT(t, key, "k1", jwt.Claims{Subject: "42"})))
+		require.NoError(t, err)
+		require.Equal(t, "alice", users.query.Login)
+		require.Equal(t, int64(42), handler.GetUser(ctx).UserID)
+		require.Equal(t, exp, *grpccontext.FromContext(ctx).TokenExpiry)
+		require.Equal(t, authMethodJWT, grpccontext.FromContext(ctx).AuthMethod)
+		md, _ := metadata.FromIncomingContext(ctx)
+		require.Empty(t, md["authorization"])
+	})
+
+	t.Run("rejects tokens without expiry", func(t *testing.T) {
+		jwtService := &authJWT.FakeJWTService{VerifyProvider: func(ctx context.Context, token string) (authJWT.JWTClaims, error) {
+			return authJWT.JWTClaims{"sub": "42", "login": "alice"}, nil
+		}}
+		a := ProvideAuthenticator(nil, &fakeJWTUserService{role: org.RoleAdmin}, accesscontrolmock.New(), handler, nil, nil, newJWTAuth(jwtService, nil), nil)
+
+		_, err := a.Authenticate(bearer(signJWT(t, key, "k1", jwt.Claims{Subject: "42"})))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.Equal(t, "invalid token", status.Convert(err).Message())
+	})
+
+	t.Run("reports expired tokens", func(t *testing.T) {
+		jwtService := &authJWT.FakeJWTService{VerifyProvider: func(ctx context.Context, token string) (authJWT.JWTClaims, error) {
+			return nil, jwt.ErrExpired
+		}}
+		a := ProvideAuthenticator(nil, &fakeJWTUserService{role: org.RoleAdmin}, accesscontrolmock.New(), handler, nil, nil, newJWTAuth(jwtService, nil), nil)
+
+		_, err := a.Authenticate(bearer(signJWT(t, key, "k1", jwt.Claims{Subject: "42"})))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.Equal(t, "token expired", status.Convert(err).Message())
+	})
+
+	t.Run("verifies tokens of the OIDC provider", func(t *testing.T) {
+		fetches := 0
+		provider := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/.well-known/openid-configuration":
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"issuer":   "https://" + r.Host,
+					"jwks_uri": "https://" + r.Host + "/keys",
+				})
+			case "/keys":
+				fetches++
+				_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+					{Key: &key.PublicKey, KeyID: "k1", Algorithm: string(jose.RS256), Use: "sig"},
+				}})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(provider.Close)
+
+		verifier := &oidcVerifier{
+			issuer:     provider.URL,
+			audience:   "grafana-grpc",
+			emailClaim: "email",
+			client:     provider.Client(),
+		}
+		users := &fakeJWTUserService{role: org.RoleAdmin}
+		a := ProvideAuthenticator(nil, users, accesscontrolmock.New(), handler, nil, nil, newJWTAuth(nil, verifier), nil)
+
+		type oidcClaims struct {
+			jwt.Claims
+			Email             string `json:"email"`
+			EmailVerified     bool   `json:"email_verified"`
+			PreferredUsername string `json:"preferred_username"`
+		}
+		claims := func(audience string, expiry time.Time) interface{} {
+			return oidcClaims{
+				Claims: jwt.Claims{
+					Issuer:   provider.URL,
+					Subject:  "alice-id",
+					Audience: jwt.Audience{audience},
+					Expiry:   jwt.NewNumericDate(expiry),
+				},
+				Email:             "alice@example.com",
+				EmailVerified:     true,
+				PreferredUsername: "admin",
+			}
+		}
+
+		ctx, err := a.Authenticate(bearer(signJWT(t, key, "k1", claims("grafana-grpc", time.Now().Add(time.Minute)))))
+		require.NoError(t, err)
+		require.Equal(t, user.GetSignedInUserQuery{Email: "alice@example.com", OrgID: users.query.OrgID}, users.query)
+		require.Equal(t, int64(42), handler.GetUser(ctx).UserID)
+
+		// the subject is only unique at the provider, it must not match the login of a Grafana user
+		users.query = user.GetSignedInUserQuery{}
+		unverified := claims("grafana-grpc", time.Now().Add(time.Minute)).(oidcClaims)
+		unverified.Subject = "admin"
+		unverified.EmailVerified = false
+		_, err = a.Authenticate(bearer(signJWT(t, key, "k1", unverified)))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.Empty(t, users.query)
+
+		noEmail := claims("grafana-grpc", time.Now().Add(time.Minute)).(oidcClaims)
+		noEmail.Subject = "admin"
+		noEmail.Email = ""
+		_, err = a.Authenticate(bearer(signJWT(t, key, "k1", noEmail)))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.Empty(t, users.query)
+
+		_, err = a.Authenticate(bearer(signJWT(t, key, "k1", claims("other", time.Now().Add(time.Minute)))))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = a.Authenticate(bearer(signJWT(t, key, "k1", claims("grafana-grpc", time.Now().Add(-time.Hour)))))
+		require.Equal(t, "token expired", status.Convert(err).Message())
+
+		// tokens signed with an unknown key do not refetch the keys every time
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		_, err = a.Authenticate(bearer(signJWT(t, other, "k2", claims("grafana-grpc", time.Now().Add(time.Minute)))))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.Equal(t, 1, fetches)
+
+		// tokens of other issuers are not accepted without [auth.jwt]
+		_, err = a.Authenticate(bearer(signJWT(t, key, "k1", jwt.Claims{Issuer: "https://evil.example.com", Expiry: jwt.NewNumericDate(time.Now().Add(time.Minute))})))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("rejects disabled users", func(t *testing.T) {
+		jwtService := &authJWT.FakeJWTService{VerifyProvider: func(ctx context.Context, token string) (authJWT.JWTClaims, error) {
+			return authJWT.JWTClaims{"sub": "42", "login": "alice", "exp": float64(time.Now().Add(time.Minute).Unix())}, nil
+		}}
+		a := ProvideAuthenticator(nil, &fakeJWTUserService{role: org.RoleAdmin, disabled: true}, accesscontrolmock.New(), handler, nil, nil, newJWTAuth(jwtService, nil), nil)
+
+		_, err := a.Authenticate(bearer(signJWT(t, key, "k1", jwt.Claims{Subject: "42"})))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("rejects users without admin role", func(t *testing.T) {
+		jwtService := &authJWT.FakeJWTService{VerifyProvider: func(ctx context.Context, token string) (authJWT.JWTClaims, error) {
+			return authJWT.JWTClaims{"sub": "42", "login": "alice", "exp": float64(time.Now().Add(time.Minute).Unix())}, nil
+		}}
+		a := ProvideAuthenticator(nil, &fakeJWTUserService{role: org.RoleViewer}, accesscontrolmock.New(), handler, nil, nil, newJWTAuth(jwtService, nil), nil)
+
+		_, err := a.Authenticate(bearer(signJWT(t, key, "k1", jwt.Claims{Subject: "42"})))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.Equal(t, "invalid token", status.Convert(err).Message())
+	})
+}
+
+func TestOIDCVerifierKeys(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var fetches int32
+	provider := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"issuer":   "https://" + r.Host,
+				"jwks_uri": "https://" + r.Host + "/keys",
+			})
+		case "/keys":
+			if atomic.AddInt32(&fetches, 1) == 1 {
+				close(started)
+				<-release
+			}
+			_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "k2", Key: []byte("secret")}}})
+		}
+	}))
+	t.Cleanup(provider.Close)
+
+	verifier := &oidcVerifier{
+		issuer: provider.URL,
+		client: provider.Client(),
+		keys:   jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "k1", Key: []byte("secret")}}},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keys, err := verifier.key(context.Background(), "k2")
+			assert.NoError(t, err)
+			assert.Len(t, keys, 1)
+		}()
+		if i == 0 {
+			<-started
+		}
+	}
+
+	// the known keys are served while the keys are fetched
+	keys, err := verifier.key(context.Background(), "k1")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	close(release)
+	wg.Wait()
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+}
+
+func TestOIDCVerifierKeysCanceledCall(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var fetches int32
+	provider := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"issuer":   "https://" + r.Host,
+				"jwks_uri": "https://" + r.Host + "/keys",
+			})
+		case "/keys":
+			switch atomic.AddInt32(&fetches, 1) {
+			case 1:
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			case 2:
+				close(started)
+				<-release
+			}
+			_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "k2", Key: []byte("secret")}}})
+		}
+	}))
+	t.Cleanup(provider.Close)
+
+	verifier := &oidcVerifier{
+		issuer: provider.URL,
+		client: provider.Client(),
+	}
+
+	// a failed fetch does not delay the next one
+	_, err := verifier.key(context.Background(), "k2")
+	require.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := verifier.key(ctx, "k2")
+		first <- err
+	}()
+	<-started
+
+	second := make(chan []jose.JSONWebKey, 1)
+	go func() {
+		keys, err := verifier.key(context.Background(), "k2")
+		assert.NoError(t, err)
+		second <- keys
+	}()
+
+	cancel()
+	require.ErrorIs(t, <-first, context.Canceled)
+	close(release)
+	require.Len(t, <-second, 1)
+	require.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func newJWTAuth(jwtService authJWT.JWTService, verifier *oidcVerifier) *JWTAuth {
+	return &JWTAuth{jwtService: jwtService, usernameClaim: "login", oidc: verifier}
+}
+
+func signJWT(t *testing.T, key *rsa.PrivateKey, kid string, claims interface{}) string {
+	t.Helper()
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, (&jose.SignerOptions{}).WithHeader("kid", kid))
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	require.NoError(t, err)
+	return token
+}
+
+func bearer(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+type fakeJWTUserService struct {
+	user.Service
+	role     org.RoleType
+	disabled bool
+	query    user.GetSignedInUserQuery
+}
+
+func (f *fakeJWTUserService) GetSignedInUserWithCacheCtx(ctx context.Context, query *user.GetSignedInUserQuery) (*user.SignedInUser, error) {
+	f.query = *query
+	return &user.SignedInUser{UserID: 42, OrgID: 1, OrgRole: f.role, Login: query.Login, Email: query.Email, IsDisabled: f.disabled}, nil
+}
@@ -0,0 +1,154 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// the health checks are never shed, the load balancers would stop sending any call
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// LoadShedder limits the calls running at once, so a burst of automation traffic is rejected
+// fast with UNAVAILABLE instead of starving the HTTP server. Unary calls over the limit wait
+// in a bounded queue for up to the queue timeout. Streams, like the watches, can stay open
+// for hours, they have their own limit and are never queued.
+type LoadShedder struct {
+	requests     chan struct{}
+	streams      chan struct{}
+	maxQueued    int64
+	queueTimeout time.Duration
+	queued       int64
+
+	inFlight   *prometheus.GaugeVec
+	queueDepth prometheus.Gauge
+	shed       *prometheus.CounterVec
+}
+
+// NewLoadShedder returns nil when neither calls nor streams are limited
+func NewLoadShedder(cfg *setting.Cfg, reg prometheus.Registerer) *LoadShedder {
+	if cfg.GRPCServerMaxInFlightRequests <= 0 && cfg.GRPCServerMaxOpenStreams <= 0 {
+		return nil
+	}
+	s := &LoadShedder{
+		maxQueued:    int64(cfg.GRPCServerMaxQueuedRequests),
+		queueTimeout: cfg.GRPCServerQueueTimeout,
+		inFlight: mustRegisterOrGet(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "grpc_server",
+			Name:      "in_flight_requests",
+			Help:      "Number of unary calls and streams running on the gRPC server.",
+		}, []string{"type"})).(*prometheus.GaugeVec),
+		queueDepth: mustRegisterOrGet(reg, prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "grpc_server",
+			Name:      "queued_requests",
+			Help:      "Number of unary calls waiting for one of the calls running on the gRPC server to complete.",
+		})).(prometheus.Gauge),
+		shed: mustRegisterOrGet(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "grpc_server",
+			Name:      "shed_requests_total",
+			Help:      "Number of calls rejected by the gRPC server because too many were running.",
+		}, []string{"method"})).(*prometheus.CounterVec),
+	}
+	if cfg.GRPCServerMaxInFlightRequests > 0 {
+		s.requests = make(chan struct{}, cfg.GRPCServerMaxInFlightRequests)
+	}
+	if cfg.GRPCServerMaxOpenStreams > 0 {
+		s.streams = make(chan struct{}, cfg.GRPCServerMaxOpenStreams)
+	}
+	return s
+}
+
+func (s *LoadShedder) reject(method string) error {
+	s.shed.WithLabelValues(method).Inc()
+	return status.Error(codes.Unavailable, "server overloaded, retry later")
+}
+
+// acquireRequest takes a slot for a unary call, waiting in the queue when there is room in it
+func (s *LoadShedder) acquireRequest(ctx context.Context, method string) error {
+	select {
+	case s.requests <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if atomic.AddInt64(&s.queued, 1) > s.maxQueued {
+		atomic.AddInt64(&s.queued, -1)
+		return s.reject(method)
+	}
+	s.queueDepth.Inc()
+	defer func() {
+		atomic.AddInt64(&s.queued, -1)
+		s.queueDepth.Dec()
+	}()
+
+	timer := time.NewTimer(s.queueTimeout)
+	defer timer.Stop()
+	select {
+	case s.requests <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return s.reject(method)
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+func (s *LoadShedder) acquireStream(method string) error {
+	select {
+	case s.streams <- struct{}{}:
+		return nil
+	default:
+		return s.reject(method)
+	}
+}
+
+// LoadShedUnaryInterceptor must run before the auth interceptor, which reads the database
+func LoadShedUnaryInterceptor(s *LoadShedder) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if s == nil || s.requests == nil || strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(ctx, req)
+		}
+		if err := s.acquireRequest(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		s.inFlight.WithLabelValues("unary").Inc()
+		defer func() {
+			<-s.requests
+			s.inFlight.WithLabelValues("unary").Dec()
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func LoadShedStreamInterceptor(s *LoadShedder) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if s == nil || s.streams == nil || strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(srv, stream)
+		}
+		if err := s.acquireStream(info.FullMethod); err != nil {
+			return err
+		}
+		s.inFlight.WithLabelValues("stream").Inc()
+		defer func() {
+			<-s.streams
+			s.inFlight.WithLabelValues("stream").Dec()
+		}()
+		return handler(srv, stream)
+	}
+}
@@ -0,0 +1,124 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestLoadShedUnaryInterceptor(t *testing.T) {
+	require.Nil(t, NewLoadShedder(setting.NewCfg(), prometheus.NewRegistry()))
+
+	cfg := setting.NewCfg()
+	cfg.GRPCServerMaxInFlightRequests = 1
+	cfg.GRPCServerMaxQueuedRequests = 1
+	cfg.GRPCServerQueueTimeout = time.Minute
+	shedder := NewLoadShedder(cfg, prometheus.NewRegistry())
+	interceptor := LoadShedUnaryInterceptor(shedder)
+	info := &grpc.UnaryServerInfo{FullMethod: "/entity.EntityStore/Write"}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	blocking := func(ctx context.Context, req interface{}) (interface{}, error) {
+		started <- struct{}{}
+		<-release
+		return "ok", nil
+	}
+	done := make(chan error, 2)
+	go func() {
+		_, err := interceptor(context.Background(), nil, info, blocking)
+		done <- err
+	}()
+	<-started
+
+	// the second call waits in the queue
+	go func() {
+		_, err := interceptor(context.Background(), nil, info, blocking)
+		done <- err
+	}()
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(shedder.queueDepth) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// the queue is full
+	_, err := interceptor(context.Background(), nil, info, blocking)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Equal(t, 1.0, testutil.ToFloat64(shedder.shed.WithLabelValues(info.FullMethod)))
+
+	// the health checks are never shed
+	resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "serving", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "serving", resp)
+
+	// the queued call runs once the first one completes
+	release <- struct{}{}
+	<-started
+	release <- struct{}{}
+	require.NoError(t, <-done)
+	require.NoError(t, <-done)
+	require.Equal(t, 0.0, testutil.ToFloat64(shedder.queueDepth))
+	require.Equal(t, 0.0, testutil.ToFloat64(shedder.inFlight.WithLabelValues("unary")))
+}
+
+func TestLoadShedQueueTimeout(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.GRPCServerMaxInFlightRequests = 1
+	cfg.GRPCServerMaxQueuedRequests = 1
+	cfg.GRPCServerQueueTimeout = 50 * time.Millisecond
+	shedder := NewLoadShedder(cfg, prometheus.NewRegistry())
+
+	require.NoError(t, shedder.acquireRequest(context.Background(), "/entity.EntityStore/Write"))
+	err := shedder.acquireRequest(context.Background(), "/entity.EntityStore/Write")
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	// the calls canceled while queued are not counted as shed
+	cfg.GRPCServerQueueTimeout = time.Minute
+	shedder = NewLoadShedder(cfg, prometheus.NewRegistry())
+	require.NoError(t, shedder.acquireRequest(context.Background(), "/entity.EntityStore/Write"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = shedder.acquireRequest(ctx, "/entity.EntityStore/Write")
+	require.Equal(t, codes.Canceled, status.Code(err))
+	require.Equal(t, 0.0, testutil.ToFloat64(shedder.shed.WithLabelValues("/entity.EntityStore/Write")))
+}
+
+func TestLoadShedStreamInterceptor(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.GRPCServerMaxOpenStreams = 1
+	shedder := NewLoadShedder(cfg, prometheus.NewRegistry())
+	interceptor := LoadShedStreamInterceptor(shedder)
+	info := &grpc.StreamServerInfo{FullMethod: "/entity.EntityStore/Watch"}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- interceptor(nil, nil, info, func(srv interface{}, stream grpc.ServerStream) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	// streams are never queued
+	err := interceptor(nil, nil, info, func(srv interface{}, stream grpc.ServerStream) error {
+		return nil
+	})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	close(release)
+	require.NoError(t, <-done)
+	require.NoError(t, interceptor(nil, nil, info, func(srv interface{}, stream grpc.ServerStream) error {
+		return nil
+	}))
+}
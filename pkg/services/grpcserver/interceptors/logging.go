@@ -0,0 +1,86 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/go-kit/log/level"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var logLevels = map[string]level.Option{
+	"trace":    level.AllowDebug(),
+	"debug":    level.AllowDebug(),
+	"info":     level.AllowInfo(),
+	"warn":     level.AllowWarn(),
+	"error":    level.AllowError(),
+	"critical": level.AllowError(),
+}
+
+// LoggingPolicy replaces the logger of authenticated requests with one that is
+// tagged with the identity of the caller, and applies per org log levels.
+//
+// The levels can only make the logger quieter than the "grpc-server-handler"
+// logger filter, so to debug a single org set that filter to debug and the
+// default grpc_server log_level to info.
+type LoggingPolicy struct {
+	logger       *log.ConcreteLogger
+	defaultLevel string
+	orgLevels    map[int64]string
+}
+
+func NewLoggingPolicy(cfg *setting.Cfg) *LoggingPolicy {
+	return &LoggingPolicy{
+		logger:       log.New("grpc-server-handler"),
+		defaultLevel: cfg.GRPCServerLogLevel,
+		orgLevels:    cfg.GRPCServerOrgLogLevels,
+	}
+}
+
+func (p *LoggingPolicy) loggerFor(ctx context.Context) (*grpccontext.GRPCServerContext, log.Logger) {
+	grpcContext := grpccontext.FromContext(ctx)
+	if grpcContext == nil || grpcContext.SignedInUser == nil {
+		return nil, nil
+	}
+
+	u := grpcContext.SignedInUser
+	logger := p.logger.New("orgID", u.OrgID, "userID", u.UserID, "authMethod", grpcContext.AuthMethod)
+
+	lvl := p.defaultLevel
+	if orgLevel, ok := p.orgLevels[u.OrgID]; ok {
+		lvl = orgLevel
+	}
+	if opt, ok := logLevels[lvl]; ok {
+		logger.Swap(level.NewFilter(logger.GetLogger(), opt))
+	}
+	return grpcContext, logger
+}
+
+func (p *LoggingPolicy) apply(ctx context.Context) {
+	if grpcContext, logger := p.loggerFor(ctx); grpcContext != nil {
+		grpcContext.Logger = logger
+	}
+}
+
+// LoggingUnaryInterceptor must run after authentication, requests without a signed in user are left unchanged.
+func LoggingUnaryInterceptor(policy *LoggingPolicy) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		policy.apply(ctx)
+		return handler(ctx, req)
+	}
+}
+
+func LoggingStreamInterceptor(policy *LoggingPolicy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		policy.apply(stream.Context())
+		return handler(srv, stream)
+	}
+}
@@ -0,0 +1,79 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestLoggingUnaryInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	base := log.NewNopLogger()
+	base.Swap(gokitlog.NewLogfmtLogger(&buf))
+
+	policy := &LoggingPolicy{
+		logger:       base,
+		defaultLevel: "info",
+		orgLevels:    map[int64]string{2: "error"},
+	}
+	interceptor := LoggingUnaryInterceptor(policy)
+	contextHandler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+
+	call := func(orgID int64) {
+		ctx := contextHandler.SetUser(context.Background(), &user.SignedInUser{OrgID: orgID, UserID: 10})
+		grpccontext.FromContext(ctx).AuthMethod = authMethodJWT
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			logger := grpccontext.FromContext(ctx).Logger
+			logger.Debug("debug message")
+			logger.Info("info message")
+			logger.Error("error message")
+			return nil, nil
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("tags log lines with the caller identity", func(t *testing.T) {
+		buf.Reset()
+		call(1)
+		require.Contains(t, buf.String(), "orgID=1 userID=10 authMethod=jwt")
+		require.Contains(t, buf.String(), "info message")
+		require.NotContains(t, buf.String(), "debug message")
+	})
+
+	t.Run("handlers log with the tagged logger of the request", func(t *testing.T) {
+		buf.Reset()
+		var fallback bytes.Buffer
+		handlerLog := log.NewNopLogger()
+		handlerLog.Swap(gokitlog.NewLogfmtLogger(&fallback))
+
+		ctx := contextHandler.SetUser(context.Background(), &user.SignedInUser{OrgID: 1, UserID: 10})
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			grpccontext.Logger(ctx, handlerLog).Info("handler message")
+			return nil, nil
+		})
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "orgID=1 userID=10")
+		require.Contains(t, buf.String(), `msg="handler message"`)
+		require.Empty(t, fallback.String())
+
+		// outside of the requests the handlers keep their own logger
+		grpccontext.Logger(context.Background(), handlerLog).Info("background message")
+		require.Contains(t, fallback.String(), "background message")
+	})
+
+	t.Run("applies org log level overrides", func(t *testing.T) {
+		buf.Reset()
+		call(2)
+		require.NotContains(t, buf.String(), "info message")
+		require.Contains(t, buf.String(), "error message")
+	})
+}
@@ -0,0 +1,125 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Metrics of the calls to the gRPC server, by full method name and status code
+type Metrics struct {
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	messageBytes *prometheus.HistogramVec
+}
+
+// NewMetrics registers the metrics, or gets the ones already registered by another server
+// of the same process, like in the integration tests
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	return &Metrics{
+		requests: mustRegisterOrGet(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "grpc_server",
+			Name:      "requests_total",
+			Help:      "Number of calls to the gRPC server by method and status code.",
+		}, []string{"method", "code"})).(*prometheus.CounterVec),
+		duration: mustRegisterOrGet(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "grpc_server",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the calls to the gRPC server by method and status code, streams last until they are closed.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"})).(*prometheus.HistogramVec),
+		messageBytes: mustRegisterOrGet(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "grpc_server",
+			Name:      "message_size_bytes",
+			Help:      "Size of the messages received and sent by the gRPC server by method.",
+			Buckets:   prometheus.ExponentialBuckets(64, 4, 10),
+		}, []string{"method", "direction"})).(*prometheus.HistogramVec),
+	}
+}
+
+func mustRegisterOrGet(reg prometheus.Registerer, c prometheus.Collector) prometheus.Collector {
+	if err := reg.Register(c); err != nil {
+		promError := prometheus.AlreadyRegisteredError{}
+		if errors.As(err, &promError) {
+			return promError.ExistingCollector
+		}
+		panic(err)
+	}
+	return c
+}
+
+func (m *Metrics) observeCall(method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	m.requests.WithLabelValues(method, code).Inc()
+	m.duration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+}
+
+// observeMessage records the size of the protobuf messages, other messages are ignored
+func (m *Metrics) observeMessage(method, direction string, msg interface{}) {
+	if pm, ok := msg.(proto.Message); ok {
+		m.messageBytes.WithLabelValues(method, direction).Observe(float64(proto.Size(pm)))
+	}
+}
+
+// MetricsUnaryInterceptor must run before the auth interceptor, so the calls that fail
+// to authenticate are counted with their status code
+func MetricsUnaryInterceptor(m *Metrics) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		start := time.Now()
+		m.observeMessage(info.FullMethod, "received", req)
+		resp, err = handler(ctx, req)
+		if err == nil {
+			m.observeMessage(info.FullMethod, "sent", resp)
+		}
+		m.observeCall(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func MetricsStreamInterceptor(m *Metrics) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, &metricsServerStream{
+			ServerStream: stream,
+			metrics:      m,
+			method:       info.FullMethod,
+		})
+		m.observeCall(info.FullMethod, start, err)
+		return err
+	}
+}
+
+type metricsServerStream struct {
+	grpc.ServerStream
+	metrics *Metrics
+	method  string
+}
+
+func (s *metricsServerStream) RecvMsg(msg interface{}) error {
+	err := s.ServerStream.RecvMsg(msg)
+	if err == nil {
+		s.metrics.observeMessage(s.method, "received", msg)
+	}
+	return err
+}
+
+func (s *metricsServerStream) SendMsg(msg interface{}) error {
+	err := s.ServerStream.SendMsg(msg)
+	if err == nil {
+		s.metrics.observeMessage(s.method, "sent", msg)
+	}
+	return err
+}
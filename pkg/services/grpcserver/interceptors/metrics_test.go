@@ -0,0 +1,40 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetricsUnaryInterceptor(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+	// a second server of the process gets the same metrics
+	require.Same(t, m.requests, NewMetrics(reg).requests)
+
+	interceptor := MetricsUnaryInterceptor(m)
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+	req := &grpc_health_v1.HealthCheckRequest{Service: "grafana.database"}
+
+	_, err := interceptor(context.Background(), req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+	})
+	require.NoError(t, err)
+	_, err = interceptor(context.Background(), req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Unauthenticated, "no token")
+	})
+	require.Error(t, err)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(info.FullMethod, "OK")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(info.FullMethod, "Unauthenticated")))
+	require.Equal(t, 2, testutil.CollectAndCount(m.duration))
+	// the sizes of the received requests and of the sent response
+	require.Equal(t, 2, testutil.CollectAndCount(m.messageBytes))
+}
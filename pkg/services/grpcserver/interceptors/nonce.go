@@ -0,0 +1,147 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// LocalNonce lets tooling running on the same host, like grafana-cli or the support bundle
+// collector, authenticate with a short lived nonce the server writes to a file only readable
+// by the grafana user. Nonces are only accepted from loopback and unix socket connections.
+//
+// A new nonce is written every half TTL, the previous one stays valid until it expires.
+type LocalNonce struct {
+	path   string
+	ttl    time.Duration
+	logger log.Logger
+
+	mu       sync.RWMutex
+	current  nonce
+	previous nonce
+}
+
+type nonce struct {
+	value   string
+	expires time.Time
+}
+
+func ProvideLocalNonce(cfg *setting.Cfg) *LocalNonce {
+	if cfg.GRPCServerLocalNonceFile == "" {
+		return nil
+	}
+	return &LocalNonce{
+		path:   cfg.GRPCServerLocalNonceFile,
+		ttl:    cfg.GRPCServerLocalNonceTTL,
+		logger: log.New("grpc-server-local-nonce"),
+	}
+}
+
+// Run writes the nonce file and rotates it until the context is done, the file is removed on exit.
+func (n *LocalNonce) Run(ctx context.Context) error {
+	if err := n.rotate(time.Now()); err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(n.path); err != nil && !os.IsNotExist(err) {
+			n.logger.Warn("failed to remove nonce file", "path", n.path, "error", err)
+		}
+	}()
+
+	ticker := time.NewTicker(n.ttl / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			if err := n.rotate(now); err != nil {
+				n.logger.Error("failed to rotate nonce", "path", n.path, "error", err)
+			}
+		}
+	}
+}
+
+func (n *LocalNonce) rotate(now time.Time) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	next := nonce{value: hex.EncodeToString(b), expires: now.Add(n.ttl)}
+
+	// write to a temporary file first, so readers never see a partial nonce
+	tmp, err := os.CreateTemp(filepath.Dir(n.path), ".grpc-nonce-*")
+	if err != nil {
+		return fmt.Errorf("failed to create nonce file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if err := tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if _, err := tmp.WriteString(next.value); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), n.path); err != nil {
+		return fmt.Errorf("failed to write nonce file: %w", err)
+	}
+
+	n.mu.Lock()
+	n.previous = n.current
+	n.current = next
+	n.mu.Unlock()
+	return nil
+}
+
+func (n *LocalNonce) valid(value string, now time.Time) bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	for _, candidate := range []nonce{n.current, n.previous} {
+		if candidate.value == "" || now.After(candidate.expires) {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(candidate.value), []byte(value)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// GatewayMetadataKey is set by the gateway on the calls it forwards for the HTTP clients, they
+// come from the local host but are never local callers
+const GatewayMetadataKey = "x-grafana-grpc-gateway"
+
+// isLocalPeer reports if the request comes from the same host, and not through the gateway
+func isLocalPeer(ctx context.Context) bool {
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md[GatewayMetadataKey]) > 0 {
+		return false
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return false
+	}
+	switch addr := p.Addr.(type) {
+	case *net.UnixAddr:
+		return true
+	case *net.TCPAddr:
+		return addr.IP.IsLoopback()
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+)
+
+func TestLocalNonce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grpc.nonce")
+	n := &LocalNonce{path: path, ttl: time.Minute, logger: log.NewNopLogger()}
+
+	now := time.Now()
+	require.NoError(t, n.rotate(now))
+	first, err := os.ReadFile(path)
+	require.NoError(t, err)
+	stat, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+
+	require.NoError(t, n.rotate(now.Add(30*time.Second)))
+	second, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+
+	// the previous nonce stays valid until it expires
+	require.True(t, n.valid(string(first), now.Add(45*time.Second)))
+	require.False(t, n.valid(string(first), now.Add(2*time.Minute)))
+	require.True(t, n.valid(string(second), now.Add(45*time.Second)))
+	require.False(t, n.valid("", now))
+	require.False(t, n.valid("nope", now))
+
+	t.Run("authenticates local peers only", func(t *testing.T) {
+		tracer := tracing.InitializeTracerForTest()
+		handler := grpccontext.ProvideContextHandler(tracer)
+		a := ProvideAuthenticator(nil, nil, nil, handler, n, nil, nil, nil)
+
+		withNonce := func(addr net.Addr) context.Context {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+			return metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Nonce "+string(second)))
+		}
+
+		ctx, err := a.Authenticate(withNonce(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 5000}))
+		require.NoError(t, err)
+		require.True(t, handler.GetUser(ctx).IsGrafanaAdmin)
+		md, _ := metadata.FromIncomingContext(ctx)
+		require.Empty(t, md["authorization"])
+
+		_, err = a.Authenticate(withNonce(&net.UnixAddr{Name: "@", Net: "unix"}))
+		require.NoError(t, err)
+
+		_, err = a.Authenticate(withNonce(&net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 5000}))
+		require.Error(t, err)
+
+		// the calls of the gateway come from the local host on behalf of HTTP clients
+		ctx = peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 5000}})
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Nonce "+string(second), GatewayMetadataKey, "true"))
+		_, err = a.Authenticate(ctx)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
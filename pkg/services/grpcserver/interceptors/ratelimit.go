@@ -0,0 +1,180 @@
+package interceptors
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// limiters idle for longer are removed, their bucket is full again by then
+const rateLimiterIdleTTL = 10 * time.Minute
+
+// RateLimiter limits the calls of every service account token and of every org with token
+// buckets. A call must fit in both, a call rejected by one does not use the other.
+// Calls without a service account, like the local tooling ones, only count for their org.
+type RateLimiter struct {
+	tokens *limiterSet
+	orgs   *limiterSet
+}
+
+// NewRateLimiter returns nil when no limit is configured
+func NewRateLimiter(cfg *setting.Cfg) *RateLimiter {
+	tokens := newLimiterSet(cfg.GRPCServerRateLimitPerToken, cfg.GRPCServerRateLimitPerTokenBurst)
+	orgs := newLimiterSet(cfg.GRPCServerRateLimitPerOrg, cfg.GRPCServerRateLimitPerOrgBurst)
+	if tokens == nil && orgs == nil {
+		return nil
+	}
+	return &RateLimiter{tokens: tokens, orgs: orgs}
+}
+
+type limiterSet struct {
+	limit rate.Limit
+	burst int
+
+	mu        sync.Mutex
+	limiters  map[int64]*keyLimiter
+	lastPrune time.Time
+}
+
+type keyLimiter struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+func newLimiterSet(perSecond float64, burst int) *limiterSet {
+	if perSecond <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(perSecond)))
+	}
+	return &limiterSet{
+		limit:    rate.Limit(perSecond),
+		burst:    burst,
+		limiters: map[int64]*keyLimiter{},
+	}
+}
+
+// reserve takes a token from the bucket of the key, the reservation must be
+// canceled when the call is rejected
+func (s *limiterSet) reserve(key int64, now time.Time) *rate.Reservation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastPrune) > rateLimiterIdleTTL {
+		for k, l := range s.limiters {
+			if now.Sub(l.lastUsed) > rateLimiterIdleTTL {
+				delete(s.limiters, k)
+			}
+		}
+		s.lastPrune = now
+	}
+
+	l, ok := s.limiters[key]
+	if !ok {
+		l = &keyLimiter{limiter: rate.NewLimiter(s.limit, s.burst)}
+		s.limiters[key] = l
+	}
+	l.lastUsed = now
+	return l.limiter.ReserveN(now, 1)
+}
+
+// allow returns how long to wait before retrying when the call is over a limit
+func (r *RateLimiter) allow(ctx context.Context, now time.Time) (time.Duration, string) {
+	grpcContext := grpccontext.FromContext(ctx)
+	if grpcContext == nil || grpcContext.SignedInUser == nil {
+		return 0, ""
+	}
+	u := grpcContext.SignedInUser
+
+	var reservations []*rate.Reservation
+	cancel := func() {
+		for _, res := range reservations {
+			res.CancelAt(now)
+		}
+	}
+	check := func(set *limiterSet, key int64, scope string) (time.Duration, string) {
+		if set == nil {
+			return 0, ""
+		}
+		res := set.reserve(key, now)
+		reservations = append(reservations, res)
+		if !res.OK() {
+			return rateLimiterIdleTTL, scope
+		}
+		if delay := res.DelayFrom(now); delay > 0 {
+			return delay, scope
+		}
+		return 0, ""
+	}
+
+	if !u.IsAnonymous && u.UserID > 0 {
+		if delay, scope := check(r.tokens, u.UserID, "token"); delay > 0 {
+			cancel()
+			return delay, scope
+		}
+	}
+	if delay, scope := check(r.orgs, u.OrgID, "org"); delay > 0 {
+		cancel()
+		return delay, scope
+	}
+	return 0, ""
+}
+
+// limit returns a RESOURCE_EXHAUSTED error with a RetryInfo detail, and sets the
+// retry-after header for the clients that do not read the details
+func (r *RateLimiter) limit(ctx context.Context) error {
+	delay, scope := r.allow(ctx, time.Now())
+	if delay == 0 {
+		return nil
+	}
+	seconds := int64(math.Ceil(delay.Seconds()))
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.FormatInt(seconds, 10)))
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("%s rate limit exceeded, retry in %ds", scope, seconds))
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// RateLimitUnaryInterceptor must run after authentication, calls without a signed in user are not limited
+func RateLimitUnaryInterceptor(limiter *RateLimiter) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if limiter != nil {
+			if err := limiter.limit(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStreamInterceptor counts the opening of a stream as a call, not its messages
+func RateLimitStreamInterceptor(limiter *RateLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if limiter != nil {
+			if err := limiter.limit(stream.Context()); err != nil {
+				return err
+			}
+		}
+		return handler(srv, stream)
+	}
+}
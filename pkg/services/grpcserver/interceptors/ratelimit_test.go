@@ -0,0 +1,75 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRateLimiter(t *testing.T) {
+	require.Nil(t, NewRateLimiter(setting.NewCfg()))
+
+	cfg := setting.NewCfg()
+	cfg.GRPCServerRateLimitPerToken = 1
+	cfg.GRPCServerRateLimitPerTokenBurst = 2
+	cfg.GRPCServerRateLimitPerOrg = 1
+	cfg.GRPCServerRateLimitPerOrgBurst = 3
+	limiter := NewRateLimiter(cfg)
+
+	contextHandler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+	ctxFor := func(u *user.SignedInUser) context.Context {
+		return contextHandler.SetUser(context.Background(), u)
+	}
+	a := ctxFor(&user.SignedInUser{OrgID: 1, UserID: 10})
+	b := ctxFor(&user.SignedInUser{OrgID: 1, UserID: 11})
+	other := ctxFor(&user.SignedInUser{OrgID: 2, UserID: 12})
+
+	now := time.Now()
+	allowed := func(ctx context.Context) bool {
+		delay, _ := limiter.allow(ctx, now)
+		return delay == 0
+	}
+
+	require.True(t, allowed(a))
+	require.True(t, allowed(a))
+	delay, scope := limiter.allow(a, now)
+	require.Equal(t, "token", scope)
+	require.Equal(t, time.Second, delay)
+
+	// the rejected call of a did not use the bucket of the org
+	require.True(t, allowed(b))
+	delay, scope = limiter.allow(b, now)
+	require.Equal(t, "org", scope)
+	require.Equal(t, time.Second, delay)
+	require.True(t, allowed(other))
+
+	// calls without a signed in user are not limited
+	require.True(t, allowed(context.Background()))
+
+	now = now.Add(time.Second)
+	require.True(t, allowed(b))
+
+	t.Run("error", func(t *testing.T) {
+		ctx := ctxFor(&user.SignedInUser{OrgID: 3, UserID: 13})
+		require.NoError(t, limiter.limit(ctx))
+		require.NoError(t, limiter.limit(ctx))
+		err := limiter.limit(ctx)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, codes.ResourceExhausted, st.Code())
+		require.Equal(t, "token rate limit exceeded, retry in 1s", st.Message())
+		require.Len(t, st.Details(), 1)
+		retry := st.Details()[0].(*errdetails.RetryInfo)
+		require.InDelta(t, time.Second, retry.RetryDelay.AsDuration(), float64(100*time.Millisecond))
+	})
+}
@@ -0,0 +1,52 @@
+package interceptors
+
+import (
+	"strings"
+	"sync"
+)
+
+// ReadOnlyMethods holds the methods the services declare as read only when they are registered.
+// The methods that are not declared are treated as changing resources, whatever their name: they
+// are audited and can not be called anonymously.
+type ReadOnlyMethods struct {
+	mu       sync.RWMutex
+	services map[string]bool
+	methods  map[string]bool
+}
+
+func ProvideReadOnlyMethods() *ReadOnlyMethods {
+	return &ReadOnlyMethods{
+		services: map[string]bool{},
+		methods:  map[string]bool{},
+	}
+}
+
+// SetService declares all the methods of a service read only, like "grpc.health.v1.Health"
+func (r *ReadOnlyMethods) SetService(service string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.services[service] = true
+}
+
+// SetMethods declares full method names read only, like "/entity.EntityStore/Read"
+func (r *ReadOnlyMethods) SetMethods(fullMethods ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range fullMethods {
+		r.methods[m] = true
+	}
+}
+
+// IsReadOnly reports if the method or its service was declared read only
+func (r *ReadOnlyMethods) IsReadOnly(fullMethod string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.methods[fullMethod] {
+		return true
+	}
+	service := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(service, "/"); i >= 0 {
+		service = service[:i]
+	}
+	return r.services[service]
+}
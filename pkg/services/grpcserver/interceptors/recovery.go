@@ -0,0 +1,39 @@
+package interceptors
+
+import (
+	"context"
+	"runtime/debug"
+
+	grpcRecovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+)
+
+var recoveryLogger = log.New("grpc-server-recovery")
+
+// recoverPanic logs the panic of a handler with its stack, the client only gets an
+// INTERNAL status so the panic value, which can hold request data, is not sent back
+func recoverPanic(ctx context.Context, p interface{}) error {
+	params := []interface{}{"panic", p, "stack", string(debug.Stack())}
+	if method, ok := grpc.Method(ctx); ok {
+		params = append(params, "method", method)
+	}
+	if grpcContext := grpccontext.FromContext(ctx); grpcContext != nil && grpcContext.SignedInUser != nil {
+		params = append(params, "orgID", grpcContext.SignedInUser.OrgID, "userID", grpcContext.SignedInUser.UserID, "authMethod", grpcContext.AuthMethod)
+	}
+	recoveryLogger.Error("Handler panicked", params...)
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// RecoveryUnaryInterceptor must run before the auth interceptor, which reads the database
+func RecoveryUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return grpcRecovery.UnaryServerInterceptor(grpcRecovery.WithRecoveryHandlerContext(recoverPanic))
+}
+
+func RecoveryStreamInterceptor() grpc.StreamServerInterceptor {
+	return grpcRecovery.StreamServerInterceptor(grpcRecovery.WithRecoveryHandlerContext(recoverPanic))
+}
@@ -3,6 +3,8 @@ package interceptors
 import (
 	"context"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"google.golang.org/grpc"
 
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -10,7 +12,25 @@ import (
 
 const tracingPrefix = "gRPC Server "
 
+// otelgrpcOptions skips the health checks, probes would add a trace every few seconds
+func otelgrpcOptions() []otelgrpc.Option {
+	return []otelgrpc.Option{otelgrpc.WithInterceptorFilter(filters.Not(filters.HealthCheck()))}
+}
+
+// usesOpentelemetry reports whether the global tracer provider and propagator used by
+// otelgrpc are the ones of the tracer
+func usesOpentelemetry(tracer tracing.Tracer) bool {
+	_, ok := tracer.(*tracing.Opentelemetry)
+	return ok
+}
+
+// TracingUnaryInterceptor starts a span for every call. With OpenTelemetry the otelgrpc
+// interceptor is used, which continues the trace of the client and records the status code.
+// It must run before the auth interceptor so the database spans of the auth are in the trace.
 func TracingUnaryInterceptor(tracer tracing.Tracer) grpc.UnaryServerInterceptor {
+	if usesOpentelemetry(tracer) {
+		return otelgrpc.UnaryServerInterceptor(otelgrpcOptions()...)
+	}
 	return func(
 		ctx context.Context,
 		req interface{},
@@ -25,6 +45,9 @@ func TracingUnaryInterceptor(tracer tracing.Tracer) grpc.UnaryServerInterceptor
 }
 
 func TracingStreamInterceptor(tracer tracing.Tracer) grpc.StreamServerInterceptor {
+	if usesOpentelemetry(tracer) {
+		return otelgrpc.StreamServerInterceptor(otelgrpcOptions()...)
+	}
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		ctx, span := tracer.Start(stream.Context(), tracingPrefix+info.FullMethod)
 		defer span.End()
@@ -0,0 +1,54 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestTracingUnaryInterceptor(t *testing.T) {
+	tracer := tracing.InitializeTracerForTest()
+	recorder := tracetest.NewSpanRecorder()
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	interceptor := TracingUnaryInterceptor(tracer)
+	call := func(method string) trace.SpanContext {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+			"traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		))
+		var spanCtx trace.SpanContext
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			spanCtx = trace.SpanContextFromContext(ctx)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		return spanCtx
+	}
+
+	// the trace of the client is continued
+	spanCtx := call("/entity.EntityStore/Read")
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanCtx.TraceID().String())
+	require.NotEqual(t, "00f067aa0ba902b7", spanCtx.SpanID().String())
+	require.Len(t, recorder.Ended(), 1)
+	require.Equal(t, "entity.EntityStore/Read", recorder.Ended()[0].Name())
+
+	// health checks are not traced
+	call("/grpc.health.v1.Health/Check")
+	require.Len(t, recorder.Ended(), 1)
+}
@@ -0,0 +1,114 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// validatorAll is implemented by messages generated with protoc-gen-validate,
+// ValidateAll collects all violations instead of returning on the first one.
+type validatorAll interface {
+	ValidateAll() error
+}
+
+type validator interface {
+	Validate() error
+}
+
+// fieldError is implemented by the per-field errors generated by protoc-gen-validate.
+type fieldError interface {
+	Field() string
+	Reason() string
+}
+
+// multiError is implemented by the error returned from ValidateAll.
+type multiError interface {
+	AllErrors() []error
+}
+
+// ValidationUnaryInterceptor rejects requests whose message fails validation with
+// codes.InvalidArgument and a BadRequest detail listing the field violations.
+// Messages without validation rules are passed through.
+func ValidationUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := validateRequest(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ValidationStreamInterceptor validates every message received on a stream.
+func ValidationStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingServerStream{ServerStream: stream})
+	}
+}
+
+type validatingServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validateRequest(m)
+}
+
+func validateRequest(req interface{}) error {
+	var err error
+	switch v := req.(type) {
+	case validatorAll:
+		err = v.ValidateAll()
+	case validator:
+		err = v.Validate()
+	default:
+		return nil
+	}
+	if err == nil {
+		return nil
+	}
+	return validationStatus(err).Err()
+}
+
+func validationStatus(err error) *status.Status {
+	var errs []error
+	var me multiError
+	if errors.As(err, &me) {
+		errs = me.AllErrors()
+	} else {
+		errs = []error{err}
+	}
+
+	br := &errdetails.BadRequest{}
+	for _, e := range errs {
+		var fe fieldError
+		if errors.As(e, &fe) {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       fe.Field(),
+				Description: fe.Reason(),
+			})
+			continue
+		}
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Description: e.Error(),
+		})
+	}
+
+	st := status.New(codes.InvalidArgument, err.Error())
+	if withDetails, derr := st.WithDetails(br); derr == nil {
+		return withDetails
+	}
+	return st
+}
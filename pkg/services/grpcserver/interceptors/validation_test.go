@@ -0,0 +1,111 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/services/store/entity"
+)
+
+func TestValidationUnaryInterceptor(t *testing.T) {
+	interceptor := ValidationUnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	t.Run("passes messages without rules", func(t *testing.T) {
+		rsp, err := interceptor(context.Background(), struct{}{}, info, handler)
+		require.NoError(t, err)
+		require.Equal(t, "ok", rsp)
+	})
+
+	t.Run("passes valid messages", func(t *testing.T) {
+		rsp, err := interceptor(context.Background(), &fakeRequest{}, info, handler)
+		require.NoError(t, err)
+		require.Equal(t, "ok", rsp)
+	})
+
+	t.Run("rejects invalid messages with field violations", func(t *testing.T) {
+		req := &fakeRequest{errs: []error{
+			fakeFieldError{field: "uid", reason: "value is required"},
+			fakeFieldError{field: "title", reason: "value length must be at most 100 runes"},
+		}}
+		_, err := interceptor(context.Background(), req, info, handler)
+		require.Error(t, err)
+
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+		require.Len(t, st.Details(), 1)
+
+		br, ok := st.Details()[0].(*errdetails.BadRequest)
+		require.True(t, ok)
+		require.Len(t, br.FieldViolations, 2)
+		require.Equal(t, "uid", br.FieldViolations[0].Field)
+		require.Equal(t, "value is required", br.FieldViolations[0].Description)
+	})
+
+	t.Run("checks the rules of the entity requests", func(t *testing.T) {
+		_, err := interceptor(context.Background(), &entity.WriteEntityRequest{GRN: &entity.GRN{Kind: "dashboard", UID: "abc"}}, info, handler)
+		require.NoError(t, err)
+
+		for name, req := range map[string]interface{}{
+			"missing GRN":    &entity.DeleteEntityRequest{},
+			"missing kind":   &entity.ReadEntityRequest{GRN: &entity.GRN{UID: "abc"}},
+			"too long UID":   &entity.WriteEntityRequest{GRN: &entity.GRN{Kind: "dashboard", UID: strings.Repeat("a", 41)}},
+			"invalid UID":    &entity.WriteEntityRequest{GRN: &entity.GRN{Kind: "dashboard", UID: "a/b"}},
+			"empty batch":    &entity.BatchReadEntityRequest{},
+			"negative limit": &entity.EntitySearchRequest{Limit: -1},
+		} {
+			_, err := interceptor(context.Background(), req, info, handler)
+			st := status.Convert(err)
+			require.Equal(t, codes.InvalidArgument, st.Code(), name)
+			require.Len(t, st.Details(), 1, name)
+		}
+	})
+}
+
+type fakeRequest struct {
+	errs []error
+}
+
+func (r *fakeRequest) ValidateAll() error {
+	if len(r.errs) == 0 {
+		return nil
+	}
+	return fakeMultiError(r.errs)
+}
+
+type fakeMultiError []error
+
+func (m fakeMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (m fakeMultiError) AllErrors() []error {
+	return m
+}
+
+type fakeFieldError struct {
+	field  string
+	reason string
+}
+
+func (e fakeFieldError) Error() string {
+	return "invalid " + e.field + ": " + e.reason
+}
+
+func (e fakeFieldError) Field() string  { return e.field }
+func (e fakeFieldError) Reason() string { return e.reason }
@@ -1,16 +1,17 @@
 package grpcserver
 
 import (
-	"context"
-
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 
+	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 // ReflectionService implements the gRPC Server Reflection Protocol:
 // https://github.com/grpc/grpc/blob/master/doc/server-reflection.md
+// It is only registered when enable_reflection is set in [grpc_server], since it lists every
+// service and message of the server to anyone who can connect.
 type ReflectionService struct {
 	cfg              *setting.Cfg
 	reflectionServer *reflectionServer
@@ -20,14 +21,15 @@ type reflectionServer struct {
 	grpc_reflection_v1alpha.ServerReflectionServer
 }
 
-// AuthFuncOverride no auth for reflection service.
-func (s *reflectionServer) AuthFuncOverride(ctx context.Context, _ string) (context.Context, error) {
-	return ctx, nil
-}
-
-func ProvideReflectionService(cfg *setting.Cfg, grpcServerProvider Provider) (*ReflectionService, error) {
+func ProvideReflectionService(cfg *setting.Cfg, grpcServerProvider Provider, authPolicy *interceptors.AuthPolicy, readOnly *interceptors.ReadOnlyMethods) (*ReflectionService, error) {
+	if !cfg.GRPCServerEnableReflection {
+		return &ReflectionService{cfg: cfg}, nil
+	}
 	re := &reflectionServer{reflection.NewServer(reflection.ServerOptions{Services: grpcServerProvider.GetServer()})}
 	grpc_reflection_v1alpha.RegisterServerReflectionServer(grpcServerProvider.GetServer(), re)
+	// no auth for reflection service
+	authPolicy.SetService(grpc_reflection_v1alpha.ServerReflection_ServiceDesc.ServiceName, interceptors.AuthUnauthenticated)
+	readOnly.SetService(grpc_reflection_v1alpha.ServerReflection_ServiceDesc.ServiceName)
 	return &ReflectionService{
 		cfg:              cfg,
 		reflectionServer: re,
@@ -0,0 +1,25 @@
+package grpcserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestReflectionService(t *testing.T) {
+	cfg := setting.NewCfg()
+	server := grpc.NewServer()
+	_, err := ProvideReflectionService(cfg, &fakeProvider{server: server}, interceptors.ProvideAuthPolicy(), interceptors.ProvideReadOnlyMethods())
+	require.NoError(t, err)
+	require.NotContains(t, server.GetServiceInfo(), "grpc.reflection.v1alpha.ServerReflection")
+
+	cfg.GRPCServerEnableReflection = true
+	server = grpc.NewServer()
+	_, err = ProvideReflectionService(cfg, &fakeProvider{server: server}, interceptors.ProvideAuthPolicy(), interceptors.ProvideReadOnlyMethods())
+	require.NoError(t, err)
+	require.Contains(t, server.GetServiceInfo(), "grpc.reflection.v1alpha.ServerReflection")
+}
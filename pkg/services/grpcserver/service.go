@@ -2,19 +2,24 @@ package grpcserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
-	grpcAuth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
 	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -26,39 +31,79 @@ type Provider interface {
 }
 
 type GPRCServerService struct {
-	cfg     *setting.Cfg
-	logger  log.Logger
-	server  *grpc.Server
+	cfg        *setting.Cfg
+	logger     log.Logger
+	server     *grpc.Server
+	localNonce *interceptors.LocalNonce
+	certs      *certReloader
+
+	// the address is set by Run and read by the clients from other goroutines
+	mu      sync.RWMutex
 	address string
 }
 
-func ProvideService(cfg *setting.Cfg, authenticator interceptors.Authenticator, tracer tracing.Tracer) (Provider, error) {
+func ProvideService(cfg *setting.Cfg, authenticator interceptors.Authenticator, tracer tracing.Tracer, localNonce *interceptors.LocalNonce, contextHandler grpccontext.ContextHandler, auditor *interceptors.Auditor, authPolicy *interceptors.AuthPolicy, readOnly *interceptors.ReadOnlyMethods) (Provider, error) {
 	s := &GPRCServerService{
-		cfg:    cfg,
-		logger: log.New("grpc-server"),
+		cfg:        cfg,
+		logger:     log.New("grpc-server"),
+		localNonce: localNonce,
 	}
 
 	var opts []grpc.ServerOption
-
-	// Default auth is admin token check, but this can be overridden by
-	// services which implement ServiceAuthFuncOverride interface.
+	loggingPolicy := interceptors.NewLoggingPolicy(cfg)
+	metrics := interceptors.NewMetrics(prometheus.DefaultRegisterer)
+	rateLimiter := interceptors.NewRateLimiter(cfg)
+	accessLog := interceptors.NewAccessLog(cfg, contextHandler)
+	compression := interceptors.NewCompressionPolicy(cfg, readOnly)
+	loadShedder := interceptors.NewLoadShedder(cfg, prometheus.DefaultRegisterer)
+
+	// Default auth is admin token check, services declare the methods that require a server
+	// admin or no auth in the AuthPolicy when they are registered. Services which implement
+	// the ServiceAuthFuncOverride interface still authenticate their calls themselves.
 	// See https://github.com/grpc-ecosystem/go-grpc-middleware/blob/master/auth/auth.go#L30.
 	opts = append(opts, []grpc.ServerOption{
 		grpc.UnaryInterceptor(
 			grpc_middleware.ChainUnaryServer(
-				grpcAuth.UnaryServerInterceptor(authenticator.Authenticate),
 				interceptors.TracingUnaryInterceptor(tracer),
+				interceptors.MetricsUnaryInterceptor(metrics),
+				interceptors.AccessLogUnaryInterceptor(accessLog),
+				interceptors.LoadShedUnaryInterceptor(loadShedder),
+				interceptors.AuditUnaryInterceptor(auditor),
+				interceptors.RecoveryUnaryInterceptor(),
+				interceptors.AuthUnaryInterceptor(authenticator, authPolicy),
+				interceptors.RateLimitUnaryInterceptor(rateLimiter),
+				interceptors.LoggingUnaryInterceptor(loggingPolicy),
+				interceptors.ValidationUnaryInterceptor(),
+				interceptors.CompressionUnaryInterceptor(compression),
 			),
 		),
 		grpc.StreamInterceptor(
 			grpc_middleware.ChainStreamServer(
 				interceptors.TracingStreamInterceptor(tracer),
-				grpcAuth.StreamServerInterceptor(authenticator.Authenticate),
+				interceptors.MetricsStreamInterceptor(metrics),
+				interceptors.AccessLogStreamInterceptor(accessLog),
+				interceptors.LoadShedStreamInterceptor(loadShedder),
+				interceptors.AuditStreamInterceptor(auditor),
+				interceptors.RecoveryStreamInterceptor(),
+				interceptors.AuthStreamInterceptor(authenticator, authPolicy),
+				interceptors.RateLimitStreamInterceptor(rateLimiter),
+				interceptors.LoggingStreamInterceptor(loggingPolicy),
+				interceptors.ValidationStreamInterceptor(),
+				interceptors.CompressionStreamInterceptor(compression),
 			),
 		),
 	}...)
 
-	if s.cfg.GRPCServerTLSConfig != nil {
+	opts = append(opts, connectionOptions(cfg)...)
+
+	if cfg.GRPCServerCertFile != "" {
+		certs, err := newCertReloader(cfg, s.logger)
+		if err != nil {
+			return nil, fmt.Errorf("GRPC server: %w", err)
+		}
+		s.certs = certs
+		opts = append(opts, grpc.Creds(credentials.NewTLS(certs.tlsConfig())))
+	} else if s.cfg.GRPCServerTLSConfig != nil {
 		opts = append(opts, grpc.Creds(credentials.NewTLS(cfg.GRPCServerTLSConfig)))
 	}
 
@@ -66,37 +111,121 @@ func ProvideService(cfg *setting.Cfg, authenticator interceptors.Authenticator,
 	return s, nil
 }
 
-func (s *GPRCServerService) Run(ctx context.Context) error {
-	s.logger.Info("Running GRPC server", "address", s.cfg.GRPCServerAddress, "network", s.cfg.GRPCServerNetwork, "tls", s.cfg.GRPCServerTLSConfig != nil)
-
-	listener, err := net.Listen(s.cfg.GRPCServerNetwork, s.cfg.GRPCServerAddress)
-	if err != nil {
-		return fmt.Errorf("GRPC server: failed to listen: %w", err)
+// connectionOptions returns the message size limits, stream limit and keepalive parameters of the
+// settings, the gRPC defaults are kept for the ones that are not set
+func connectionOptions(cfg *setting.Cfg) []grpc.ServerOption {
+	var opts []grpc.ServerOption
+	if cfg.GRPCServerMaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(cfg.GRPCServerMaxRecvMsgSize))
 	}
+	if cfg.GRPCServerMaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(cfg.GRPCServerMaxSendMsgSize))
+	}
+	if cfg.GRPCServerMaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(cfg.GRPCServerMaxConcurrentStreams))
+	}
+	opts = append(opts,
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     cfg.GRPCServerMaxConnectionIdle,
+			MaxConnectionAge:      cfg.GRPCServerMaxConnectionAge,
+			MaxConnectionAgeGrace: cfg.GRPCServerMaxConnectionAgeGrace,
+			Time:                  cfg.GRPCServerKeepaliveTime,
+			Timeout:               cfg.GRPCServerKeepaliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.GRPCServerKeepaliveMinTime,
+			PermitWithoutStream: cfg.GRPCServerKeepalivePermitWithoutStream,
+		}),
+	)
+	return opts
+}
 
-	s.address = listener.Addr().String()
+func (s *GPRCServerService) Run(ctx context.Context) error {
+	listeners := append([]setting.GRPCServerListener{{
+		Name:    "default",
+		Network: s.cfg.GRPCServerNetwork,
+		Address: s.cfg.GRPCServerAddress,
+	}}, s.cfg.GRPCServerListeners...)
+
+	serveErr := make(chan error, len(listeners)+2)
+	if s.localNonce != nil {
+		go func() {
+			if err := s.localNonce.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				serveErr <- err
+			}
+		}()
+	}
+	if s.certs != nil && s.cfg.GRPCServerCertReloadInterval > 0 {
+		go func() {
+			if err := s.certs.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				serveErr <- err
+			}
+		}()
+	}
+	for i, l := range listeners {
+		s.logger.Info("Running GRPC server", "listener", l.Name, "address", l.Address, "network", l.Network, "tls", s.cfg.GRPCServerTLSConfig != nil)
 
-	serveErr := make(chan error, 1)
-	go func() {
-		s.logger.Info("GRPC server: starting")
-		err := s.server.Serve(listener)
+		listener, err := net.Listen(l.Network, l.Address)
 		if err != nil {
-			backend.Logger.Error("GRPC server: failed to serve", "err", err)
-			serveErr <- err
+			s.server.Stop()
+			return fmt.Errorf("GRPC server: failed to listen on %s: %w", l.Name, err)
+		}
+
+		// the default listener is the address used by clients inside grafana
+		if i == 0 {
+			s.mu.Lock()
+			s.address = listener.Addr().String()
+			s.mu.Unlock()
 		}
-	}()
+
+		go func(name string) {
+			s.logger.Info("GRPC server: starting", "listener", name)
+			err := s.server.Serve(listener)
+			if err != nil {
+				backend.Logger.Error("GRPC server: failed to serve", "listener", name, "err", err)
+				serveErr <- err
+			}
+		}(l.Name)
+	}
 
 	select {
 	case err := <-serveErr:
 		backend.Logger.Error("GRPC server: failed to serve", "err", err)
+		s.server.Stop()
 		return err
 	case <-ctx.Done():
 	}
 	s.logger.Warn("GRPC server: shutting down")
-	s.server.Stop()
+	s.stop()
 	return ctx.Err()
 }
 
+// stop waits for the calls in flight to complete, the connections of the ones still
+// running after the shutdown timeout, like the watch streams, are then closed
+func (s *GPRCServerService) stop() {
+	timeout := s.cfg.GRPCServerShutdownTimeout
+	if timeout <= 0 {
+		s.server.Stop()
+		return
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-stopped:
+	case <-timer.C:
+		s.logger.Warn("GRPC server: calls still running after the shutdown timeout, closing their connections", "timeout", timeout)
+		s.server.Stop()
+		<-stopped
+	}
+}
+
 func (s *GPRCServerService) IsDisabled() bool {
 	if s.cfg == nil {
 		return true
@@ -109,5 +238,7 @@ func (s *GPRCServerService) GetServer() *grpc.Server {
 }
 
 func (s *GPRCServerService) GetAddress() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.address
 }
@@ -0,0 +1,131 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// slowServer serves a method that runs until it is released
+func slowServer(t *testing.T, started chan<- struct{}, release <-chan struct{}) (*grpc.Server, *grpc.ClientConn) {
+	t.Helper()
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Slow",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Call",
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := &emptypb.Empty{}
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				started <- struct{}{}
+				select {
+				case <-release:
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+				return in, nil
+			},
+		}},
+	}, struct{}{})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return server, conn
+}
+
+func TestGracefulStop(t *testing.T) {
+	call := func(conn *grpc.ClientConn) <-chan error {
+		done := make(chan error, 1)
+		go func() {
+			done <- conn.Invoke(context.Background(), "/test.Slow/Call", &emptypb.Empty{}, &emptypb.Empty{})
+		}()
+		return done
+	}
+
+	t.Run("calls in flight complete", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		server, conn := slowServer(t, started, release)
+		cfg := setting.NewCfg()
+		cfg.GRPCServerShutdownTimeout = time.Minute
+		s := &GPRCServerService{cfg: cfg, logger: log.NewNopLogger(), server: server}
+
+		done := call(conn)
+		<-started
+		stopped := make(chan struct{})
+		go func() {
+			s.stop()
+			close(stopped)
+		}()
+
+		// the server waits for the call
+		select {
+		case <-stopped:
+			t.Fatal("server stopped before the call completed")
+		case <-time.After(100 * time.Millisecond):
+		}
+		close(release)
+		require.NoError(t, <-done)
+		<-stopped
+	})
+
+	t.Run("calls still running after the timeout are canceled", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		defer close(release)
+		server, conn := slowServer(t, started, release)
+		cfg := setting.NewCfg()
+		cfg.GRPCServerShutdownTimeout = 100 * time.Millisecond
+		s := &GPRCServerService{cfg: cfg, logger: log.NewNopLogger(), server: server}
+
+		done := call(conn)
+		<-started
+		s.stop()
+		require.Equal(t, codes.Unavailable, status.Code(<-done))
+	})
+}
+
+func TestGetAddress(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.GRPCServerNetwork = "tcp"
+	cfg.GRPCServerAddress = "127.0.0.1:0"
+	s := &GPRCServerService{cfg: cfg, logger: log.NewNopLogger(), server: grpc.NewServer()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+
+	// the clients read the address while the server starts
+	require.Eventually(t, func() bool {
+		return s.GetAddress() != ""
+	}, 5*time.Second, 10*time.Millisecond)
+	conn, err := net.Dial("tcp", s.GetAddress())
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
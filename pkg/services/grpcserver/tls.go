@@ -0,0 +1,155 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// certReloader serves the TLS certificate and client CA of the server from their files, and
+// reloads them when they change on disk, so rotated certificates are used by the following
+// connections without a restart. The previous certificate is kept when the new files are invalid,
+// like when the certificate is written before its key.
+type certReloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	interval     time.Duration
+	logger       log.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+func newCertReloader(cfg *setting.Cfg, logger log.Logger) (*certReloader, error) {
+	r := &certReloader{
+		certFile:     cfg.GRPCServerCertFile,
+		keyFile:      cfg.GRPCServerKeyFile,
+		clientCAFile: cfg.GRPCServerClientCAFile,
+		interval:     cfg.GRPCServerCertReloadInterval,
+		logger:       logger,
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+	return files
+}
+
+func (r *certReloader) load() error {
+	modTimes := map[string]time.Time{}
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("error loading X509 key pair: %w", err)
+	}
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("error reading client CA: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in client CA %s", r.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	r.mu.Unlock()
+	return nil
+}
+
+// changed reports whether a file was modified since it was loaded, the files of kubernetes
+// secrets are symlinks that are swapped, so their target is checked
+func (r *certReloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			// the file is being replaced, it is checked again next time
+			continue
+		}
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run checks the files every interval until the context is done
+func (r *certReloader) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			r.reload()
+		}
+	}
+}
+
+func (r *certReloader) reload() {
+	if !r.changed() {
+		return
+	}
+	if err := r.load(); err != nil {
+		r.logger.Error("failed to reload TLS certificate, the previous one is still used", "cert", r.certFile, "error", err)
+		return
+	}
+	r.logger.Info("reloaded TLS certificate", "cert", r.certFile)
+}
+
+// tlsConfig returns the config of the server, every handshake gets the current certificate
+func (r *certReloader) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetConfigForClient: r.configForClient,
+	}
+}
+
+func (r *certReloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*r.cert},
+		ClientAuth:   tls.NoClientCert,
+		// the config replaces the one of the grpc credentials, which negotiates HTTP/2
+		NextProtos: []string{"h2"},
+	}
+	if r.clientCAs != nil {
+		config.ClientCAs = r.clientCAs
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
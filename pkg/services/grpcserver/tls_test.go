@@ -0,0 +1,113 @@
+package grpcserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// writeCert writes a self signed certificate and its key, with the given modification time
+func writeCert(t *testing.T, dir, name string, modTime time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func servedCommonName(t *testing.T, r *certReloader) string {
+	t.Helper()
+	config, err := r.configForClient(nil)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+	return cert.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeCert(t, dir, "first", now.Add(-time.Minute))
+
+	cfg := setting.NewCfg()
+	cfg.GRPCServerCertFile = filepath.Join(dir, "cert.pem")
+	cfg.GRPCServerKeyFile = filepath.Join(dir, "key.pem")
+	r, err := newCertReloader(cfg, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, "first", servedCommonName(t, r))
+
+	config, err := r.configForClient(nil)
+	require.NoError(t, err)
+	require.Nil(t, config.ClientCAs)
+	require.Equal(t, []string{"h2"}, config.NextProtos)
+
+	t.Run("not changed", func(t *testing.T) {
+		require.False(t, r.changed())
+	})
+
+	t.Run("rotated", func(t *testing.T) {
+		writeCert(t, dir, "second", now)
+		require.True(t, r.changed())
+		r.reload()
+		require.Equal(t, "second", servedCommonName(t, r))
+		require.False(t, r.changed())
+	})
+
+	t.Run("invalid files keep the previous certificate", func(t *testing.T) {
+		keyFile := filepath.Join(dir, "key.pem")
+		require.NoError(t, os.WriteFile(keyFile, []byte("partial"), 0600))
+		require.NoError(t, os.Chtimes(keyFile, now.Add(time.Minute), now.Add(time.Minute)))
+		r.reload()
+		require.Equal(t, "second", servedCommonName(t, r))
+	})
+
+	t.Run("client CA", func(t *testing.T) {
+		caDir := t.TempDir()
+		writeCert(t, caDir, "ca", now)
+		cfg.GRPCServerClientCAFile = filepath.Join(caDir, "cert.pem")
+		writeCert(t, dir, "third", now)
+		r, err := newCertReloader(cfg, log.NewNopLogger())
+		require.NoError(t, err)
+		config, err := r.configForClient(nil)
+		require.NoError(t, err)
+		require.NotNil(t, config.ClientCAs)
+		require.Equal(t, "RequireAndVerifyClientCert", config.ClientAuth.String())
+	})
+
+	t.Run("missing files", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.GRPCServerCertFile = filepath.Join(dir, "missing.pem")
+		cfg.GRPCServerKeyFile = filepath.Join(dir, "key.pem")
+		_, err := newCertReloader(cfg, log.NewNopLogger())
+		require.Error(t, err)
+	})
+}
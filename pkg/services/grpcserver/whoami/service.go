@@ -0,0 +1,65 @@
+package whoami
+
+import (
+	"context"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/services/grpcserver"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
+)
+
+var _ WhoAmIServer = &Service{}
+
+// Service returns the identity resolved from the credential of the caller.
+type Service struct {
+	contextHandler grpccontext.ContextHandler
+}
+
+func ProvideService(grpcServerProvider grpcserver.Provider, contextHandler grpccontext.ContextHandler, readOnly *interceptors.ReadOnlyMethods) *Service {
+	s := &Service{
+		contextHandler: contextHandler,
+	}
+	RegisterWhoAmIServer(grpcServerProvider.GetServer(), s)
+	readOnly.SetService(WhoAmI_ServiceDesc.ServiceName)
+	return s
+}
+
+func (s *Service) WhoAmI(ctx context.Context, _ *WhoAmIRequest) (*WhoAmIResponse, error) {
+	signedInUser := s.contextHandler.GetUser(ctx)
+	if signedInUser == nil {
+		return nil, status.Error(codes.Unauthenticated, "missing signed in user")
+	}
+
+	rsp := &WhoAmIResponse{
+		ServiceAccountId: signedInUser.UserID,
+		Login:            signedInUser.Login,
+		Name:             signedInUser.Name,
+		OrgId:            signedInUser.OrgID,
+		OrgRole:          string(signedInUser.OrgRole),
+		IsGrafanaAdmin:   signedInUser.IsGrafanaAdmin,
+		Permissions:      []*Permission{},
+	}
+
+	if grpcContext := grpccontext.FromContext(ctx); grpcContext != nil && grpcContext.TokenExpiry != nil {
+		rsp.ExpiresAt = grpcContext.TokenExpiry.UnixMilli()
+	}
+
+	permissions := signedInUser.Permissions[signedInUser.OrgID]
+	actions := make([]string, 0, len(permissions))
+	for action := range permissions {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		rsp.Permissions = append(rsp.Permissions, &Permission{
+			Action: action,
+			Scopes: permissions[action],
+		})
+	}
+
+	return rsp, nil
+}
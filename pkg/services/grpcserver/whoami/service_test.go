@@ -0,0 +1,59 @@
+package whoami
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestService_WhoAmI(t *testing.T) {
+	handler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+	s := &Service{contextHandler: handler}
+
+	t.Run("returns the signed in service account", func(t *testing.T) {
+		ctx := handler.SetUser(context.Background(), &user.SignedInUser{
+			UserID:  3,
+			OrgID:   2,
+			Login:   "sa-automation",
+			OrgRole: org.RoleAdmin,
+			Permissions: map[int64]map[string][]string{
+				2: {
+					"folders:read":    {"folders:*"},
+					"dashboards:read": {"dashboards:*", "folders:*"},
+				},
+			},
+		})
+		expiry := time.UnixMilli(1700000000000)
+		grpccontext.FromContext(ctx).TokenExpiry = &expiry
+
+		rsp, err := s.WhoAmI(ctx, &WhoAmIRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), rsp.ServiceAccountId)
+		require.Equal(t, int64(2), rsp.OrgId)
+		require.Equal(t, "Admin", rsp.OrgRole)
+		require.Equal(t, int64(1700000000000), rsp.ExpiresAt)
+		require.Len(t, rsp.Permissions, 2)
+		require.Equal(t, "dashboards:read", rsp.Permissions[0].Action)
+		require.Equal(t, []string{"dashboards:*", "folders:*"}, rsp.Permissions[0].Scopes)
+	})
+
+	t.Run("tokens without expiry", func(t *testing.T) {
+		ctx := handler.SetUser(context.Background(), &user.SignedInUser{UserID: 3, OrgID: 2})
+		rsp, err := s.WhoAmI(ctx, &WhoAmIRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int64(0), rsp.ExpiresAt)
+		require.Empty(t, rsp.Permissions)
+	})
+
+	t.Run("requires a signed in user", func(t *testing.T) {
+		_, err := s.WhoAmI(context.Background(), &WhoAmIRequest{})
+		require.Error(t, err)
+	})
+}
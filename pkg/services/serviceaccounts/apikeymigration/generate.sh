@@ -0,0 +1,25 @@
+#!/bin/bash
+
+# To compile all protobuf files in this repository, run
+# "mage protobuf" at the top-level.
+
+set -eu
+
+#DST_DIR=../genproto/entity
+DST_DIR=./
+
+SOURCE="${BASH_SOURCE[0]}"
+while [ -h "$SOURCE" ] ; do SOURCE="$(readlink "$SOURCE")"; done
+DIR="$( cd -P "$( dirname "$SOURCE" )" && pwd )"
+
+cd "$DIR"
+
+# validate/validate.proto of protoc-gen-validate, for the request validation rules
+PGV_DIR="$(go list -m -f '{{.Dir}}' github.com/envoyproxy/protoc-gen-validate)"
+
+protoc -I ./ -I "${PGV_DIR}" \
+  --go_out=${DST_DIR} \
+  --go-grpc_out=${DST_DIR} --go-grpc_opt=require_unimplemented_servers=false \
+  --validate_out="lang=go:${DST_DIR}" \
+  apikeymigration.proto
+  
\ No newline at end of file
@@ -0,0 +1,141 @@
+package apikeymigration
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/grpcserver"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts/manager"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// keyMigrator converts a single API key into a service account token
+type keyMigrator interface {
+	MigrateApiKey(ctx context.Context, orgID, keyID int64) error
+}
+
+var _ APIKeyMigrationServer = &Service{}
+
+// Service exposes the API key to service account migration to admins over gRPC.
+type Service struct {
+	log            log.Logger
+	apiKeyService  apikey.Service
+	migrator       keyMigrator
+	contextHandler grpccontext.ContextHandler
+	accessControl  accesscontrol.AccessControl
+}
+
+func ProvideService(grpcServerProvider grpcserver.Provider, authPolicy *interceptors.AuthPolicy, apiKeyService apikey.Service, serviceAccounts *manager.ServiceAccountsService,
+	contextHandler grpccontext.ContextHandler, accessControl accesscontrol.AccessControl) *Service {
+	s := &Service{
+		log:            log.New("apikey-migration"),
+		apiKeyService:  apiKeyService,
+		migrator:       serviceAccounts,
+		contextHandler: contextHandler,
+		accessControl:  accessControl,
+	}
+	// the migration creates service accounts and tokens, it is not declared read only so it is audited
+	authPolicy.SetService(APIKeyMigration_ServiceDesc.ServiceName, interceptors.AuthOrgAdmin)
+	RegisterAPIKeyMigrationServer(grpcServerProvider.GetServer(), s)
+	return s
+}
+
+// MigrateAPIKeys migrates the legacy API keys of the requested orgs and streams the outcome for every key.
+// Failing keys are reported and skipped so a single bad key does not block the remaining ones.
+func (s *Service) MigrateAPIKeys(req *MigrateAPIKeysRequest, stream APIKeyMigration_MigrateAPIKeysServer) error {
+	ctx := stream.Context()
+	signedInUser := s.contextHandler.GetUser(ctx)
+	if signedInUser == nil {
+		return status.Error(codes.Unauthenticated, "missing signed in user")
+	}
+	allowed, err := s.canMigrate(ctx, signedInUser)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to evaluate permissions: %s", err)
+	}
+	if !allowed {
+		return status.Error(codes.PermissionDenied, "migrating API keys requires permission to create service accounts")
+	}
+
+	orgIDs := req.OrgIds
+	if !signedInUser.IsGrafanaAdmin {
+		// without server admin rights, only the org of the caller can be migrated
+		for _, orgID := range orgIDs {
+			if orgID != signedInUser.OrgID {
+				return status.Errorf(codes.PermissionDenied, "migrating API keys of org %d requires server admin", orgID)
+			}
+		}
+		orgIDs = []int64{signedInUser.OrgID}
+	}
+
+	keys, err := s.listKeys(ctx, orgIDs)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to list API keys: %s", err)
+	}
+
+	total := int64(len(keys))
+	for i, key := range keys {
+		progress := &MigrateAPIKeysProgress{
+			OrgId:     key.OrgID,
+			KeyId:     key.ID,
+			KeyName:   key.Name,
+			Status:    MigrateAPIKeysProgress_MIGRATED,
+			Processed: int64(i + 1),
+			Total:     total,
+		}
+
+		if req.DryRun {
+			progress.Status = MigrateAPIKeysProgress_PENDING
+		} else if err := s.migrator.MigrateApiKey(ctx, key.OrgID, key.ID); err != nil {
+			grpccontext.Logger(ctx, s.log).Warn("failed to migrate API key", "orgID", key.OrgID, "keyID", key.ID, "error", err)
+			progress.Status = MigrateAPIKeysProgress_FAILED
+			progress.Error = err.Error()
+		}
+
+		if err := stream.Send(progress); err != nil {
+			return err
+		}
+	}
+
+	grpccontext.Logger(ctx, s.log).Info("API key migration finished", "keys", total, "dryRun", req.DryRun, "userID", signedInUser.UserID)
+	return nil
+}
+
+// canMigrate applies the permissions of the migration endpoints of the HTTP API, org admins
+// when access control is disabled
+func (s *Service) canMigrate(ctx context.Context, signedInUser *user.SignedInUser) (bool, error) {
+	if s.accessControl.IsDisabled() {
+		return signedInUser.IsGrafanaAdmin || signedInUser.HasRole(org.RoleAdmin), nil
+	}
+	return s.accessControl.Evaluate(ctx, signedInUser, accesscontrol.EvalPermission(serviceaccounts.ActionCreate))
+}
+
+func (s *Service) listKeys(ctx context.Context, orgIDs []int64) ([]*apikey.APIKey, error) {
+	if len(orgIDs) == 0 {
+		// -1 lists the keys of all orgs
+		return s.apiKeyService.GetAllAPIKeys(ctx, -1)
+	}
+
+	keys := []*apikey.APIKey{}
+	seen := make(map[int64]bool, len(orgIDs))
+	for _, orgID := range orgIDs {
+		if seen[orgID] {
+			continue
+		}
+		seen[orgID] = true
+		orgKeys, err := s.apiKeyService.GetAllAPIKeys(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, orgKeys...)
+	}
+	return keys, nil
+}
@@ -0,0 +1,151 @@
+package apikeymigration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestService_MigrateAPIKeys(t *testing.T) {
+	allowed := actest.FakeAccessControl{ExpectedEvaluate: true}
+	keys := []*apikey.APIKey{
+		{ID: 1, OrgID: 1, Name: "a"},
+		{ID: 2, OrgID: 1, Name: "b"},
+		{ID: 3, OrgID: 2, Name: "c"},
+	}
+
+	setup := func(u *user.SignedInUser, failing map[int64]bool, ac accesscontrol.AccessControl) (*Service, context.Context, *fakeMigrator) {
+		handler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+		m := &fakeMigrator{failing: failing}
+		s := &Service{
+			log:            log.NewNopLogger(),
+			apiKeyService:  &fakeAPIKeys{keys: keys},
+			migrator:       m,
+			contextHandler: handler,
+			accessControl:  ac,
+		}
+		return s, handler.SetUser(context.Background(), u), m
+	}
+
+	t.Run("dry run does not migrate", func(t *testing.T) {
+		s, ctx, m := setup(&user.SignedInUser{OrgID: 1, IsGrafanaAdmin: true}, nil, allowed)
+		stream := &fakeStream{ctx: ctx}
+		require.NoError(t, s.MigrateAPIKeys(&MigrateAPIKeysRequest{DryRun: true}, stream))
+		require.Len(t, stream.sent, 3)
+		require.Empty(t, m.migrated)
+		for _, p := range stream.sent {
+			require.Equal(t, MigrateAPIKeysProgress_PENDING, p.Status)
+			require.Equal(t, int64(3), p.Total)
+		}
+	})
+
+	t.Run("failed keys are reported and skipped", func(t *testing.T) {
+		s, ctx, m := setup(&user.SignedInUser{OrgID: 1, IsGrafanaAdmin: true}, map[int64]bool{2: true}, allowed)
+		stream := &fakeStream{ctx: ctx}
+		require.NoError(t, s.MigrateAPIKeys(&MigrateAPIKeysRequest{OrgIds: []int64{1}}, stream))
+		require.Len(t, stream.sent, 2)
+		require.Equal(t, []int64{1}, m.migrated)
+		require.Equal(t, MigrateAPIKeysProgress_FAILED, stream.sent[1].Status)
+		require.NotEmpty(t, stream.sent[1].Error)
+	})
+
+	t.Run("logs with the logger of the request", func(t *testing.T) {
+		s, ctx, _ := setup(&user.SignedInUser{OrgID: 1, IsGrafanaAdmin: true}, map[int64]bool{2: true}, allowed)
+		var buf bytes.Buffer
+		requestLogger := log.NewNopLogger()
+		requestLogger.Swap(gokitlog.NewLogfmtLogger(&buf))
+		grpccontext.FromContext(ctx).Logger = requestLogger.New("orgID", 1, "serviceAccountID", 10)
+
+		require.NoError(t, s.MigrateAPIKeys(&MigrateAPIKeysRequest{OrgIds: []int64{1}}, &fakeStream{ctx: ctx}))
+		require.Contains(t, buf.String(), `orgID=1 serviceAccountID=10`)
+		require.Contains(t, buf.String(), `msg="failed to migrate API key"`)
+	})
+
+	t.Run("org admins can only migrate their own org", func(t *testing.T) {
+		s, ctx, _ := setup(&user.SignedInUser{OrgID: 1, OrgRole: org.RoleAdmin}, nil, allowed)
+		require.Error(t, s.MigrateAPIKeys(&MigrateAPIKeysRequest{OrgIds: []int64{2}}, &fakeStream{ctx: ctx}))
+
+		stream := &fakeStream{ctx: ctx}
+		require.NoError(t, s.MigrateAPIKeys(&MigrateAPIKeysRequest{DryRun: true}, stream))
+		require.Len(t, stream.sent, 2)
+	})
+
+	t.Run("callers without permission to create service accounts are denied", func(t *testing.T) {
+		s, ctx, m := setup(&user.SignedInUser{OrgID: 1, OrgRole: org.RoleEditor}, nil, actest.FakeAccessControl{ExpectedEvaluate: false})
+		stream := &fakeStream{ctx: ctx}
+		require.Equal(t, codes.PermissionDenied, status.Code(s.MigrateAPIKeys(&MigrateAPIKeysRequest{}, stream)))
+		require.Empty(t, stream.sent)
+		require.Empty(t, m.migrated)
+
+		// org admins without access control
+		s, ctx, _ = setup(&user.SignedInUser{OrgID: 1, OrgRole: org.RoleEditor}, nil, actest.FakeAccessControl{ExpectedDisabled: true})
+		require.Equal(t, codes.PermissionDenied, status.Code(s.MigrateAPIKeys(&MigrateAPIKeysRequest{}, &fakeStream{ctx: ctx})))
+		s, ctx, _ = setup(&user.SignedInUser{OrgID: 1, OrgRole: org.RoleAdmin}, nil, actest.FakeAccessControl{ExpectedDisabled: true})
+		require.NoError(t, s.MigrateAPIKeys(&MigrateAPIKeysRequest{DryRun: true}, &fakeStream{ctx: ctx}))
+	})
+}
+
+func TestMigrateAPIKeysRequest_Validate(t *testing.T) {
+	require.NoError(t, (&MigrateAPIKeysRequest{OrgIds: []int64{1, 2}}).ValidateAll())
+	require.Error(t, (&MigrateAPIKeysRequest{OrgIds: []int64{0}}).ValidateAll())
+	require.Error(t, (&MigrateAPIKeysRequest{OrgIds: []int64{1, 1}}).ValidateAll())
+}
+
+type fakeAPIKeys struct {
+	apikey.Service
+	keys []*apikey.APIKey
+}
+
+func (f *fakeAPIKeys) GetAllAPIKeys(_ context.Context, orgID int64) ([]*apikey.APIKey, error) {
+	result := []*apikey.APIKey{}
+	for _, k := range f.keys {
+		if orgID == -1 || k.OrgID == orgID {
+			result = append(result, k)
+		}
+	}
+	return result, nil
+}
+
+type fakeMigrator struct {
+	failing  map[int64]bool
+	migrated []int64
+}
+
+func (f *fakeMigrator) MigrateApiKey(_ context.Context, _, keyID int64) error {
+	if f.failing[keyID] {
+		return errors.New("failed")
+	}
+	f.migrated = append(f.migrated, keyID)
+	return nil
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*MigrateAPIKeysProgress
+}
+
+func (f *fakeStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeStream) Send(p *MigrateAPIKeysProgress) error {
+	f.sent = append(f.sent, p)
+	return nil
+}
@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdmissionViolation describes why a field of the entity body is not valid
+type AdmissionViolation struct {
+	// Path of the field in the body, eg: panels[2].title
+	Field       string
+	Description string
+}
+
+// AdmissionError is returned when an admission hook rejects a write. It is sent to gRPC
+// clients as codes.InvalidArgument with a BadRequest detail listing the violations.
+type AdmissionError struct {
+	Kind       string
+	Violations []AdmissionViolation
+}
+
+func NewAdmissionError(kind string, violations ...AdmissionViolation) *AdmissionError {
+	return &AdmissionError{
+		Kind:       kind,
+		Violations: violations,
+	}
+}
+
+func (e *AdmissionError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		if v.Field == "" {
+			msgs = append(msgs, v.Description)
+			continue
+		}
+		msgs = append(msgs, v.Field+": "+v.Description)
+	}
+	return fmt.Sprintf("invalid %s: %s", e.Kind, strings.Join(msgs, ", "))
+}
+
+// GRPCStatus is used by the gRPC server to send the error
+func (e *AdmissionError) GRPCStatus() *status.Status {
+	br := &errdetails.BadRequest{}
+	for _, v := range e.Violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+
+	st := status.New(codes.InvalidArgument, e.Error())
+	if withDetails, err := st.WithDetails(br); err == nil {
+		return withDetails
+	}
+	return st
+}
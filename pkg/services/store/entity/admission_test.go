@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdmissionError(t *testing.T) {
+	err := NewAdmissionError(StandardKindDashboard,
+		AdmissionViolation{Field: "title", Description: "dashboard title cannot be empty"},
+		AdmissionViolation{Description: "too large"},
+	)
+	require.Equal(t, "invalid dashboard: title: dashboard title cannot be empty, too large", err.Error())
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.InvalidArgument, st.Code())
+	br, ok := st.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	require.Len(t, br.FieldViolations, 2)
+	require.Equal(t, "title", br.FieldViolations[0].Field)
+}
@@ -45,6 +45,10 @@ func (i fakeEntityStore) Search(ctx context.Context, r *entity.EntitySearchReque
 	return nil, fmt.Errorf("unimplemented")
 }
 
+func (i fakeEntityStore) SearchStream(*entity.EntitySearchRequest, entity.EntityStore_SearchStreamServer) error {
+	return fmt.Errorf("unimplemented")
+}
+
 func (i fakeEntityStore) Watch(*entity.EntityWatchRequest, entity.EntityStore_WatchServer) error {
 	return fmt.Errorf("unimplemented")
 }
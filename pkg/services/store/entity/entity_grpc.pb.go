@@ -29,6 +29,8 @@ type EntityStoreClient interface {
 	Delete(ctx context.Context, in *DeleteEntityRequest, opts ...grpc.CallOption) (*DeleteEntityResponse, error)
 	History(ctx context.Context, in *EntityHistoryRequest, opts ...grpc.CallOption) (*EntityHistoryResponse, error)
 	Search(ctx context.Context, in *EntitySearchRequest, opts ...grpc.CallOption) (*EntitySearchResponse, error)
+	// Streams all the matching entities in pages, the next_page_token of every page is the cursor to resume from
+	SearchStream(ctx context.Context, in *EntitySearchRequest, opts ...grpc.CallOption) (EntityStore_SearchStreamClient, error)
 	Watch(ctx context.Context, in *EntityWatchRequest, opts ...grpc.CallOption) (EntityStore_WatchClient, error)
 	// TEMPORARY... while we split this into a new service (see below)
 	AdminWrite(ctx context.Context, in *AdminWriteEntityRequest, opts ...grpc.CallOption) (*WriteEntityResponse, error)
@@ -96,8 +98,40 @@ func (c *entityStoreClient) Search(ctx context.Context, in *EntitySearchRequest,
 	return out, nil
 }
 
+func (c *entityStoreClient) SearchStream(ctx context.Context, in *EntitySearchRequest, opts ...grpc.CallOption) (EntityStore_SearchStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &EntityStore_ServiceDesc.Streams[0], "/entity.EntityStore/SearchStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &entityStoreSearchStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type EntityStore_SearchStreamClient interface {
+	Recv() (*EntitySearchResponse, error)
+	grpc.ClientStream
+}
+
+type entityStoreSearchStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *entityStoreSearchStreamClient) Recv() (*EntitySearchResponse, error) {
+	m := new(EntitySearchResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *entityStoreClient) Watch(ctx context.Context, in *EntityWatchRequest, opts ...grpc.CallOption) (EntityStore_WatchClient, error) {
-	stream, err := c.cc.NewStream(ctx, &EntityStore_ServiceDesc.Streams[0], "/entity.EntityStore/Watch", opts...)
+	stream, err := c.cc.NewStream(ctx, &EntityStore_ServiceDesc.Streams[1], "/entity.EntityStore/Watch", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -147,6 +181,8 @@ type EntityStoreServer interface {
 	Delete(context.Context, *DeleteEntityRequest) (*DeleteEntityResponse, error)
 	History(context.Context, *EntityHistoryRequest) (*EntityHistoryResponse, error)
 	Search(context.Context, *EntitySearchRequest) (*EntitySearchResponse, error)
+	// Streams all the matching entities in pages, the next_page_token of every page is the cursor to resume from
+	SearchStream(*EntitySearchRequest, EntityStore_SearchStreamServer) error
 	Watch(*EntityWatchRequest, EntityStore_WatchServer) error
 	// TEMPORARY... while we split this into a new service (see below)
 	AdminWrite(context.Context, *AdminWriteEntityRequest) (*WriteEntityResponse, error)
@@ -174,6 +210,9 @@ func (UnimplementedEntityStoreServer) History(context.Context, *EntityHistoryReq
 func (UnimplementedEntityStoreServer) Search(context.Context, *EntitySearchRequest) (*EntitySearchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
 }
+func (UnimplementedEntityStoreServer) SearchStream(*EntitySearchRequest, EntityStore_SearchStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method SearchStream not implemented")
+}
 func (UnimplementedEntityStoreServer) Watch(*EntityWatchRequest, EntityStore_WatchServer) error {
 	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
 }
@@ -300,6 +339,27 @@ func _EntityStore_Search_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EntityStore_SearchStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EntitySearchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EntityStoreServer).SearchStream(m, &entityStoreSearchStreamServer{stream})
+}
+
+type EntityStore_SearchStreamServer interface {
+	Send(*EntitySearchResponse) error
+	grpc.ServerStream
+}
+
+type entityStoreSearchStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *entityStoreSearchStreamServer) Send(m *EntitySearchResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _EntityStore_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(EntityWatchRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -376,6 +436,11 @@ var EntityStore_ServiceDesc = grpc.ServiceDesc{
 		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SearchStream",
+			Handler:       _EntityStore_SearchStream_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "Watch",
 			Handler:       _EntityStore_Watch_Handler,
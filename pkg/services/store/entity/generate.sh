@@ -14,8 +14,12 @@ DIR="$( cd -P "$( dirname "$SOURCE" )" && pwd )"
 
 cd "$DIR"
 
-protoc -I ./ \
+# validate/validate.proto of protoc-gen-validate, for the request validation rules
+PGV_DIR="$(go list -m -f '{{.Dir}}' github.com/envoyproxy/protoc-gen-validate)"
+
+protoc -I ./ -I "${PGV_DIR}" \
   --go_out=${DST_DIR} \
   --go-grpc_out=${DST_DIR} --go-grpc_opt=require_unimplemented_servers=false \
+  --validate_out="lang=go:${DST_DIR}" \
   entity.proto
   
\ No newline at end of file
@@ -19,3 +19,23 @@ func (x *GRN) Equals(b *GRN) bool {
 func (x *GRN) ToGRNString() string {
 	return fmt.Sprintf("grn:%d/%s/%s", x.TenantId, x.Kind, x.UID)
 }
+
+// The resources written by the mutating requests, reported in the audit events of the gRPC server
+func (x *WriteEntityRequest) AuditResource() string {
+	return auditResource(x.GetGRN())
+}
+
+func (x *AdminWriteEntityRequest) AuditResource() string {
+	return auditResource(x.GetGRN())
+}
+
+func (x *DeleteEntityRequest) AuditResource() string {
+	return auditResource(x.GetGRN())
+}
+
+func auditResource(grn *GRN) string {
+	if grn == nil {
+		return ""
+	}
+	return grn.ToGRNString()
+}
@@ -135,3 +135,8 @@ type EntityExternalReference struct {
 // EntitySummaryBuilder will read an object, validate it, and return a summary, sanitized payload, or an error
 // This should not include values that depend on system state, only the raw object
 type EntitySummaryBuilder = func(ctx context.Context, uid string, body []byte) (*EntitySummary, []byte, error)
+
+// EntityAdmissionHook runs before an entity is written, with the request of every write path.
+// It can modify the request, like scrubbing secrets from the body, and rejects the write by
+// returning an error. Return an *AdmissionError to report the fields that are not valid.
+type EntityAdmissionHook = func(ctx context.Context, r *AdminWriteEntityRequest) error
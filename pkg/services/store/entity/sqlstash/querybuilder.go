@@ -7,6 +7,7 @@ type selectQuery struct {
 	from     string   // FROM object
 	limit    int
 	oneExtra bool
+	orderBy  []string // ORDER BY xyz
 
 	where []string
 	args  []interface{}
@@ -62,6 +63,11 @@ func (q *selectQuery) toQuery() (string, []interface{}) {
 		}
 	}
 
+	if len(q.orderBy) > 0 {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(strings.Join(q.orderBy, ","))
+	}
+
 	if q.limit > 0 || q.oneExtra {
 		limit := q.limit
 		if limit < 1 {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -14,6 +15,8 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/slugify"
 	"github.com/grafana/grafana/pkg/services/grpcserver"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
 	"github.com/grafana/grafana/pkg/services/sqlstore/session"
 	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/store/entity"
@@ -26,7 +29,7 @@ import (
 var _ entity.EntityStoreServer = &sqlEntityServer{}
 var _ entity.EntityStoreAdminServer = &sqlEntityServer{}
 
-func ProvideSQLEntityServer(db db.DB, cfg *setting.Cfg, grpcServerProvider grpcserver.Provider, kinds kind.KindRegistry, resolver resolver.EntityReferenceResolver) entity.EntityStoreServer {
+func ProvideSQLEntityServer(db db.DB, cfg *setting.Cfg, grpcServerProvider grpcserver.Provider, authPolicy *interceptors.AuthPolicy, readOnly *interceptors.ReadOnlyMethods, health *grpcserver.HealthService, kinds kind.KindRegistry, resolver resolver.EntityReferenceResolver) entity.EntityStoreServer {
 	entityServer := &sqlEntityServer{
 		sess:     db.GetSqlxSession(),
 		log:      log.New("sql-entity-server"),
@@ -34,9 +37,35 @@ func ProvideSQLEntityServer(db db.DB, cfg *setting.Cfg, grpcServerProvider grpcs
 		resolver: resolver,
 	}
 	entity.RegisterEntityStoreServer(grpcServerProvider.GetServer(), entityServer)
+	readOnly.SetMethods(
+		"/entity.EntityStore/Read",
+		"/entity.EntityStore/BatchRead",
+		"/entity.EntityStore/History",
+		"/entity.EntityStore/Search",
+		"/entity.EntityStore/SearchStream",
+		"/entity.EntityStore/Watch",
+	)
+	// the admin writes set the history of the entities, like their creator
+	authPolicy.SetMethod("/entity.EntityStore/AdminWrite", interceptors.AuthAdmin)
+	authPolicy.SetService(entity.EntityStoreAdmin_ServiceDesc.ServiceName, interceptors.AuthAdmin)
+	health.AddCheck("entity.EntityStore", entityServer.healthy)
 	return entityServer
 }
 
+// healthy checks the entity table can be read, it is missing until the migrations ran
+func (s *sqlEntityServer) healthy(ctx context.Context) error {
+	rows, err := s.sess.Query(ctx, "SELECT 1 FROM entity WHERE 1 = 0")
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// logger returns the logger of the request, tagged with the caller
+func (s *sqlEntityServer) logger(ctx context.Context) log.Logger {
+	return grpccontext.Logger(ctx, s.log)
+}
+
 type sqlEntityServer struct {
 	log      log.Logger
 	sess     *session.SessionDB
@@ -120,17 +149,9 @@ func (s *sqlEntityServer) validateGRN(ctx context.Context, grn *entity.GRN) (*en
 		return nil, fmt.Errorf("tenant ID does not match userID")
 	}
 
-	if grn.Kind == "" {
-		return nil, fmt.Errorf("GRN missing kind")
-	}
-	if grn.UID == "" {
-		return nil, fmt.Errorf("GRN missing UID")
-	}
-	if len(grn.UID) > 40 {
-		return nil, fmt.Errorf("GRN UID is too long (>40)")
-	}
-	if strings.ContainsAny(grn.UID, "/#$@?") {
-		return nil, fmt.Errorf("invalid character in GRN")
+	// the rules of entity.proto, the in-process callers do not go through the validation interceptor
+	if err := grn.Validate(); err != nil {
+		return nil, err
 	}
 	return grn, nil
 }
@@ -221,8 +242,8 @@ func (s *sqlEntityServer) readFromHistory(ctx context.Context, r *entity.ReadEnt
 }
 
 func (s *sqlEntityServer) BatchRead(ctx context.Context, b *entity.BatchReadEntityRequest) (*entity.BatchReadEntityResponse, error) {
-	if len(b.Batch) < 1 {
-		return nil, fmt.Errorf("missing querires")
+	if err := b.Validate(); err != nil {
+		return nil, err
 	}
 
 	first := b.Batch[0]
@@ -277,6 +298,9 @@ func (s *sqlEntityServer) AdminWrite(ctx context.Context, r *entity.AdminWriteEn
 	if err != nil {
 		return nil, err
 	}
+	if err := s.admit(ctx, r); err != nil {
+		return nil, err
+	}
 	oid := grn.ToGRNString()
 
 	timestamp := time.Now().UnixMilli()
@@ -459,6 +483,7 @@ func (s *sqlEntityServer) AdminWrite(ctx context.Context, r *entity.AdminWriteEn
 	rsp.SummaryJson = summary.marshaled
 	if err != nil {
 		rsp.Status = entity.WriteEntityResponse_ERROR
+		s.logger(ctx).Error("failed to write entity", "grn", oid, "error", err)
 	}
 	return rsp, err
 }
@@ -590,6 +615,23 @@ func (s *sqlEntityServer) writeSearchInfo(
 	return nil
 }
 
+// admit runs the admission hooks of the kind, errors that do not say which fields are
+// invalid are still returned as an entity.AdmissionError
+func (s *sqlEntityServer) admit(ctx context.Context, r *entity.AdminWriteEntityRequest) error {
+	for _, hook := range s.kinds.GetAdmissionHooks(r.GRN.Kind) {
+		err := hook(ctx, r)
+		if err == nil {
+			continue
+		}
+		var admissionErr *entity.AdmissionError
+		if errors.As(err, &admissionErr) {
+			return admissionErr
+		}
+		return entity.NewAdmissionError(r.GRN.Kind, entity.AdmissionViolation{Description: err.Error()})
+	}
+	return nil
+}
+
 func (s *sqlEntityServer) prepare(ctx context.Context, r *entity.AdminWriteEntityRequest) (*summarySupport, []byte, error) {
 	grn := r.GRN
 	builder := s.kinds.GetSummaryBuilder(grn.Kind)
@@ -630,6 +672,9 @@ func (s *sqlEntityServer) Delete(ctx context.Context, r *entity.DeleteEntityRequ
 		rsp.OK, err = doDelete(ctx, tx, grn)
 		return err
 	})
+	if err != nil {
+		s.logger(ctx).Error("failed to delete entity", "grn", grn.ToGRNString(), "error", err)
+	}
 	return rsp, err
 }
 
@@ -716,36 +761,83 @@ func (s *sqlEntityServer) Search(ctx context.Context, r *entity.EntitySearchRequ
 		return nil, fmt.Errorf("missing user in context")
 	}
 
-	if r.NextPageToken != "" || len(r.Sort) > 0 {
+	if len(r.Sort) > 0 {
 		return nil, fmt.Errorf("not yet supported")
 	}
 
-	fields := []string{
-		"grn", "tenant_id", "kind", "uid",
-		"version", "folder", "slug", "errors", // errors are always returned
-		"size", "updated_at", "updated_by",
-		"name", "description", // basic summary
+	return s.searchPage(ctx, user.OrgID, r, r.NextPageToken, int(r.Limit))
+}
+
+const (
+	defaultSearchPageSize = 100
+	maxSearchPageSize     = 1000
+)
+
+// SearchStream sends all the entities matching the request in pages of r.PageSize.
+// r.Limit caps the total number of results, when zero every matching entity is sent.
+func (s *sqlEntityServer) SearchStream(r *entity.EntitySearchRequest, stream entity.EntityStore_SearchStreamServer) error {
+	ctx := stream.Context()
+	user, err := appcontext.User(ctx)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("missing user in context")
 	}
 
-	if r.WithBody {
-		fields = append(fields, "body")
+	if len(r.Sort) > 0 {
+		return fmt.Errorf("not yet supported")
 	}
 
-	if r.WithLabels {
-		fields = append(fields, "labels")
+	pageSize := int(r.PageSize)
+	if pageSize <= 0 {
+		pageSize = defaultSearchPageSize
 	}
-	if r.WithFields {
-		fields = append(fields, "fields")
+	if pageSize > maxSearchPageSize {
+		pageSize = maxSearchPageSize
+	}
+
+	total, err := s.searchCount(ctx, user.OrgID, r)
+	if err != nil {
+		return err
 	}
 
-	entityQuery := selectQuery{
-		fields:   fields,
-		from:     "entity", // the table
-		args:     []interface{}{},
-		limit:    int(r.Limit),
-		oneExtra: true, // request one more than the limit (and show next token if it exists)
+	token := r.NextPageToken
+	sent := 0
+	for {
+		limit := pageSize
+		if r.Limit > 0 && int(r.Limit)-sent < limit {
+			limit = int(r.Limit) - sent
+		}
+
+		rsp, err := s.searchPage(ctx, user.OrgID, r, token, limit)
+		if err != nil {
+			return err
+		}
+		if sent == 0 {
+			rsp.TotalEstimate = total
+		}
+		sent += len(rsp.Results)
+
+		if err := stream.Send(rsp); err != nil {
+			return err
+		}
+		// when the limit is reached, the last token still allows resuming
+		if rsp.NextPageToken == "" || (r.Limit > 0 && sent >= int(r.Limit)) {
+			return nil
+		}
+		token = rsp.NextPageToken
 	}
-	entityQuery.addWhere("tenant_id", user.OrgID)
+}
+
+// searchQuery returns the query for the entities matching the request, without paging
+func searchQuery(tenantID int64, r *entity.EntitySearchRequest, fields []string) *selectQuery {
+	entityQuery := &selectQuery{
+		fields: fields,
+		from:   "entity", // the table
+		args:   []interface{}{},
+	}
+	entityQuery.addWhere("tenant_id", tenantID)
 
 	if len(r.Kind) > 0 {
 		entityQuery.addWhereIn("kind", r.Kind)
@@ -770,13 +862,57 @@ func (s *sqlEntityServer) Search(ctx context.Context, r *entity.EntitySearchRequ
 
 		entityQuery.addWhereInSubquery("grn", query, args)
 	}
+	return entityQuery
+}
 
-	query, args := entityQuery.toQuery()
+// searchCount estimates the number of matching entities, entities may be added
+// or removed while the pages are read
+func (s *sqlEntityServer) searchCount(ctx context.Context, tenantID int64, r *entity.EntitySearchRequest) (int64, error) {
+	query, args := searchQuery(tenantID, r, []string{"COUNT(*)"}).toQuery()
+
+	var total int64
+	rows, err := s.sess.Query(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = rows.Close() }()
+	if rows.Next() {
+		err = rows.Scan(&total)
+	}
+	return total, err
+}
+
+// searchPage returns up to limit entities, starting at the entity with the token GRN.
+// The results are sorted by GRN, so the token is the GRN of the first entity in the next page.
+func (s *sqlEntityServer) searchPage(ctx context.Context, tenantID int64, r *entity.EntitySearchRequest, token string, limit int) (*entity.EntitySearchResponse, error) {
+	fields := []string{
+		"grn", "tenant_id", "kind", "uid",
+		"version", "folder", "slug", "errors", // errors are always returned
+		"size", "updated_at", "updated_by",
+		"name", "description", // basic summary
+	}
 
-	fmt.Printf("\n\n-------------\n")
-	fmt.Printf("%s\n", query)
-	fmt.Printf("%v\n", args)
-	fmt.Printf("\n-------------\n\n")
+	if r.WithBody {
+		fields = append(fields, "body")
+	}
+
+	if r.WithLabels {
+		fields = append(fields, "labels")
+	}
+	if r.WithFields {
+		fields = append(fields, "fields")
+	}
+
+	entityQuery := searchQuery(tenantID, r, fields)
+	entityQuery.limit = limit
+	entityQuery.oneExtra = true // request one more than the limit (and show next token if it exists)
+	entityQuery.orderBy = []string{"grn"}
+	if token != "" {
+		entityQuery.args = append(entityQuery.args, token)
+		entityQuery.where = append(entityQuery.where, "grn>=?")
+	}
+
+	query, args := entityQuery.toQuery()
 
 	rows, err := s.sess.Query(ctx, query, args...)
 	if err != nil {
@@ -3,13 +3,18 @@ package entity_server_tests
 import (
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/store/entity"
@@ -393,6 +398,39 @@ func TestIntegrationEntityServer(t *testing.T) {
 		}, version)
 	})
 
+	t.Run("should be able to stream search results in pages", func(t *testing.T) {
+		stream, err := testCtx.client.SearchStream(ctx, &entity.EntitySearchRequest{
+			Kind:     []string{kind, entity.StandardKindPlaylist},
+			PageSize: 3,
+		})
+		require.NoError(t, err)
+
+		pages := []*entity.EntitySearchResponse{}
+		for {
+			page, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			pages = append(pages, page)
+		}
+
+		require.Len(t, pages, 2)
+		require.Equal(t, int64(4), pages[0].TotalEstimate)
+		require.Len(t, pages[0].Results, 3)
+		require.Equal(t, "uid4", pages[1].Results[0].GRN.UID)
+		require.Equal(t, "", pages[1].NextPageToken)
+
+		// resume from the cursor of the first page
+		search, err := testCtx.client.Search(ctx, &entity.EntitySearchRequest{
+			Kind:          []string{kind, entity.StandardKindPlaylist},
+			NextPageToken: pages[0].NextPageToken,
+		})
+		require.NoError(t, err)
+		require.Len(t, search.Results, 1)
+		require.Equal(t, "uid4", search.Results[0].GRN.UID)
+	})
+
 	t.Run("should be able to filter objects based on their labels", func(t *testing.T) {
 		kind := entity.StandardKindDashboard
 		_, err := testCtx.client.Write(ctx, &entity.WriteEntityRequest{
@@ -476,4 +514,24 @@ func TestIntegrationEntityServer(t *testing.T) {
 		require.NotNil(t, search)
 		require.Len(t, search.Results, 0)
 	})
+	t.Run("should reject dashboards failing admission", func(t *testing.T) {
+		_, err := testCtx.client.Write(ctx, &entity.WriteEntityRequest{
+			GRN: &entity.GRN{
+				Kind: entity.StandardKindDashboard,
+				UID:  "no-title",
+			},
+			Body: []byte(`{"title":" ","panels":{}}`),
+		})
+		require.Error(t, err)
+
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+		require.Len(t, st.Details(), 1)
+		br, ok := st.Details()[0].(*errdetails.BadRequest)
+		require.True(t, ok)
+		require.Len(t, br.FieldViolations, 2)
+		require.Equal(t, "title", br.FieldViolations[0].Field)
+		require.Equal(t, "panels", br.FieldViolations[1].Field)
+	})
 }
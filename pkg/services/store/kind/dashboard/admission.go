@@ -0,0 +1,73 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/store/entity"
+)
+
+// GetEntityAdmissionHook checks dashboards written to the entity store the same way
+// the HTTP API checks dashboards before saving them
+func GetEntityAdmissionHook() entity.EntityAdmissionHook {
+	return func(ctx context.Context, r *entity.AdminWriteEntityRequest) error {
+		// only the checked fields are decoded, the other values keep their exact bytes,
+		// like the large integers that would lose precision as float64
+		var parsed map[string]json.RawMessage
+		if err := json.Unmarshal(r.Body, &parsed); err != nil || parsed == nil {
+			return entity.NewAdmissionError(entity.StandardKindDashboard, entity.AdmissionViolation{
+				Description: "body must be a JSON object",
+			})
+		}
+
+		violations := []entity.AdmissionViolation{}
+		var title string
+		if err := json.Unmarshal(parsed["title"], &title); err != nil || strings.TrimSpace(title) == "" {
+			violations = append(violations, entity.AdmissionViolation{
+				Field:       "title",
+				Description: "dashboard title cannot be empty",
+			})
+		}
+		if v, ok := parsed["panels"]; ok && !isNull(v) {
+			var panels []json.RawMessage
+			if err := json.Unmarshal(v, &panels); err != nil {
+				violations = append(violations, entity.AdmissionViolation{
+					Field:       "panels",
+					Description: "must be an array",
+				})
+			}
+		}
+		if v, ok := parsed["schemaVersion"]; ok && !isNull(v) {
+			var schemaVersion float64
+			if err := json.Unmarshal(v, &schemaVersion); err != nil {
+				violations = append(violations, entity.AdmissionViolation{
+					Field:       "schemaVersion",
+					Description: "must be a number",
+				})
+			}
+		}
+		if len(violations) > 0 {
+			return entity.NewAdmissionError(entity.StandardKindDashboard, violations...)
+		}
+
+		// the title is trimmed when saving from the UI, the body is left as is otherwise
+		if trimmed := strings.TrimSpace(title); trimmed != title {
+			v, err := json.Marshal(trimmed)
+			if err != nil {
+				return err
+			}
+			parsed["title"] = v
+			body, err := json.Marshal(parsed)
+			if err != nil {
+				return err
+			}
+			r.Body = body
+		}
+		return nil
+	}
+}
+
+func isNull(v json.RawMessage) bool {
+	return string(v) == "null"
+}
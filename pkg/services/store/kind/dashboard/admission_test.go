@@ -0,0 +1,64 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/store/entity"
+)
+
+func TestEntityAdmissionHook(t *testing.T) {
+	hook := GetEntityAdmissionHook()
+
+	t.Run("valid", func(t *testing.T) {
+		r := &entity.AdminWriteEntityRequest{Body: []byte(`{"title":"Hello","panels":[],"schemaVersion":37}`)}
+		require.NoError(t, hook(context.Background(), r))
+		require.JSONEq(t, `{"title":"Hello","panels":[],"schemaVersion":37}`, string(r.Body))
+	})
+
+	t.Run("trims the title", func(t *testing.T) {
+		r := &entity.AdminWriteEntityRequest{Body: []byte(`{"title":" Hello "}`)}
+		require.NoError(t, hook(context.Background(), r))
+		require.JSONEq(t, `{"title":"Hello"}`, string(r.Body))
+	})
+
+	t.Run("keeps the body when the title is not trimmed", func(t *testing.T) {
+		body := `{"title":"Hello","version":9007199254740993,"id":1}`
+		r := &entity.AdminWriteEntityRequest{Body: []byte(body)}
+		require.NoError(t, hook(context.Background(), r))
+		require.Equal(t, body, string(r.Body))
+	})
+
+	t.Run("keeps large integers when trimming the title", func(t *testing.T) {
+		r := &entity.AdminWriteEntityRequest{Body: []byte(`{"title":" Hello ","version":9007199254740993,"panels":[{"id":9007199254740995}]}`)}
+		require.NoError(t, hook(context.Background(), r))
+		require.Contains(t, string(r.Body), `"version":9007199254740993`)
+		require.Contains(t, string(r.Body), `"id":9007199254740995`)
+		require.Contains(t, string(r.Body), `"title":"Hello"`)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for body, fields := range map[string][]string{
+			`[]`:                                 {""},
+			`{}`:                                 {"title"},
+			`{"title":""}`:                       {"title"},
+			`{"title":"a","panels":{}}`:          {"panels"},
+			`{"title":1,"schemaVersion":"37"}`:   {"title", "schemaVersion"},
+			`{"title":"a","panels":[],"x":"y"}x`: {""},
+		} {
+			err := hook(context.Background(), &entity.AdminWriteEntityRequest{Body: []byte(body)})
+			var admissionErr *entity.AdmissionError
+			require.True(t, errors.As(err, &admissionErr), body)
+			require.Equal(t, entity.StandardKindDashboard, admissionErr.Kind)
+
+			actual := []string{}
+			for _, v := range admissionErr.Violations {
+				actual = append(actual, v.Field)
+			}
+			require.Equal(t, fields, actual, body)
+		}
+	})
+}
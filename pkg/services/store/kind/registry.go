@@ -26,6 +26,11 @@ type KindRegistry interface {
 	GetInfo(kind string) (entity.EntityKindInfo, error)
 	GetFromExtension(suffix string) (entity.EntityKindInfo, error)
 	GetKinds() []entity.EntityKindInfo
+
+	// RegisterAdmissionHook adds a hook that runs before every write of the kind
+	RegisterAdmissionHook(kind string, hook entity.EntityAdmissionHook) error
+	// GetAdmissionHooks returns the hooks in the order they were registered
+	GetAdmissionHooks(kind string) []entity.EntityAdmissionHook
 }
 
 func NewKindRegistry() KindRegistry {
@@ -37,6 +42,7 @@ func NewKindRegistry() KindRegistry {
 	kinds[entity.StandardKindDashboard] = &kindValues{
 		info:    dashboard.GetEntityKindInfo(),
 		builder: dashboard.GetEntitySummaryBuilder(),
+		hooks:   []entity.EntityAdmissionHook{dashboard.GetEntityAdmissionHook()},
 	}
 	kinds[entity.StandardKindSnapshot] = &kindValues{
 		info:    snapshot.GetEntityKindInfo(),
@@ -93,6 +99,7 @@ func ProvideService(cfg *setting.Cfg, renderer rendering.Service) KindRegistry {
 type kindValues struct {
 	info    entity.EntityKindInfo
 	builder entity.EntitySummaryBuilder
+	hooks   []entity.EntityAdmissionHook
 }
 
 type registry struct {
@@ -181,3 +188,33 @@ func (r *registry) GetKinds() []entity.EntityKindInfo {
 
 	return r.info // returns a copy of the array
 }
+
+func (r *registry) RegisterAdmissionHook(kind string, hook entity.EntityAdmissionHook) error {
+	if hook == nil {
+		return fmt.Errorf("invalid hook")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	v, ok := r.kinds[kind]
+	if !ok {
+		return fmt.Errorf("not found")
+	}
+	// copy so the hooks returned before are not modified
+	hooks := make([]entity.EntityAdmissionHook, 0, len(v.hooks)+1)
+	hooks = append(hooks, v.hooks...)
+	v.hooks = append(hooks, hook)
+	return nil
+}
+
+func (r *registry) GetAdmissionHooks(kind string) []entity.EntityAdmissionHook {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	v, ok := r.kinds[kind]
+	if ok {
+		return v.hooks
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package kind
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -49,3 +50,26 @@ func TestKindRegistry(t *testing.T) {
 	require.Equal(t, "PNG", info.Name)
 	require.True(t, info.IsRaw)
 }
+
+func TestKindRegistryAdmissionHooks(t *testing.T) {
+	registry := NewKindRegistry()
+	require.Len(t, registry.GetAdmissionHooks(entity.StandardKindDashboard), 1)
+	require.Empty(t, registry.GetAdmissionHooks(entity.StandardKindPlaylist))
+
+	calls := []string{}
+	hook := func(name string) entity.EntityAdmissionHook {
+		return func(ctx context.Context, r *entity.AdminWriteEntityRequest) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+	require.NoError(t, registry.RegisterAdmissionHook(entity.StandardKindPlaylist, hook("a")))
+	require.NoError(t, registry.RegisterAdmissionHook(entity.StandardKindPlaylist, hook("b")))
+	require.Error(t, registry.RegisterAdmissionHook("unknown", hook("c")))
+	require.Error(t, registry.RegisterAdmissionHook(entity.StandardKindPlaylist, nil))
+
+	for _, h := range registry.GetAdmissionHooks(entity.StandardKindPlaylist) {
+		require.NoError(t, h(context.Background(), &entity.AdminWriteEntityRequest{}))
+	}
+	require.Equal(t, []string{"a", "b"}, calls)
+}
@@ -6,10 +6,12 @@ package setting
 import (
 	"bytes"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
@@ -523,6 +525,84 @@ type Cfg struct {
 	GRPCServerNetwork   string
 	GRPCServerAddress   string
 	GRPCServerTLSConfig *tls.Config
+	// Files of the TLS certificate, key and optional client CA, they are reloaded every
+	// GRPCServerCertReloadInterval when they change on disk
+	GRPCServerCertFile           string
+	GRPCServerKeyFile            string
+	GRPCServerClientCAFile       string
+	GRPCServerCertReloadInterval time.Duration
+	// Additional addresses the GRPC server listens on, next to GRPCServerNetwork/GRPCServerAddress
+	GRPCServerListeners []GRPCServerListener
+	// Nonce file local tooling can authenticate with, disabled when empty
+	GRPCServerLocalNonceFile string
+	GRPCServerLocalNonceTTL  time.Duration
+	// Full method names, like /entity.EntityStore/Read, that can be called without credentials
+	// as the anonymous org and role of [auth.anonymous]. Only the methods the services declare
+	// read only can be called anonymously, the other listed methods still require credentials.
+	GRPCServerAnonymousMethods []string
+	// Accepts the JWTs verified by [auth.jwt] as bearer tokens, and the ID tokens issued by the
+	// GRPCServerOIDCIssuer OIDC provider for GRPCServerOIDCAudience when the issuer is set. The users
+	// of the ID tokens are matched by the GRPCServerOIDCEmailClaim, tokens without an email the
+	// provider verified are rejected.
+	GRPCServerJWTAuthEnabled bool
+	GRPCServerOIDCIssuer     string
+	GRPCServerOIDCAudience   string
+	GRPCServerOIDCEmailClaim string
+	// Opaque OAuth2 access tokens are checked with the RFC 7662 introspection endpoint of the identity
+	// provider when GRPCServerIntrospectionURL is set, and mapped to the user whose email is the
+	// GRPCServerIntrospectionEmailField of the response, when the response has email_verified. The
+	// subject and username are never matched against the logins, the identity provider can issue any
+	// of them, such as admin. Active tokens are cached for GRPCServerIntrospectionCacheTTL.
+	GRPCServerIntrospectionURL          string
+	GRPCServerIntrospectionClientID     string
+	GRPCServerIntrospectionClientSecret string
+	GRPCServerIntrospectionAudience     string
+	GRPCServerIntrospectionEmailField   string
+	GRPCServerIntrospectionCacheTTL     time.Duration
+	// Registers the gRPC server reflection service, for tools like grpcurl. Enabled by default in development.
+	GRPCServerEnableReflection bool
+	// Calls per second allowed for every service account token and every org, and the size of
+	// their bursts. Disabled when 0, the burst defaults to one second of calls.
+	GRPCServerRateLimitPerToken      float64
+	GRPCServerRateLimitPerTokenBurst int
+	GRPCServerRateLimitPerOrg        float64
+	GRPCServerRateLimitPerOrgBurst   int
+	// Logs every call to the gRPC server, with the redacted request when GRPCServerLogRequestBodies is set
+	GRPCServerLogRequests      bool
+	GRPCServerLogRequestBodies bool
+	// How long the calls in flight can take to complete on shutdown, before their connections
+	// are closed. The server stops immediately when 0.
+	GRPCServerShutdownTimeout time.Duration
+	// How often the subsystems reported by the gRPC health service are checked
+	GRPCServerHealthCheckInterval time.Duration
+	// Limits of the messages received and sent in bytes, the gRPC defaults of 4MB and 2GB when 0
+	GRPCServerMaxRecvMsgSize int
+	GRPCServerMaxSendMsgSize int
+	// Unary calls and streams running at once, the calls over the limit wait in a queue of
+	// GRPCServerMaxQueuedRequests for up to GRPCServerQueueTimeout. Unlimited when 0.
+	GRPCServerMaxInFlightRequests int
+	GRPCServerMaxQueuedRequests   int
+	GRPCServerQueueTimeout        time.Duration
+	GRPCServerMaxOpenStreams      int
+	// HTTP/2 streams of every connection, unlimited when 0
+	GRPCServerMaxConcurrentStreams uint32
+	// Responses of the read only methods larger than this many bytes are rejected when the request is not
+	// compressed. Disabled when 0.
+	GRPCServerRequireCompressionAbove int
+	// Keepalive pings sent to idle clients and their timeout, the gRPC defaults of 2h and 20s when 0
+	GRPCServerKeepaliveTime    time.Duration
+	GRPCServerKeepaliveTimeout time.Duration
+	// Minimum interval of the pings of clients, and whether they may ping without an active stream
+	GRPCServerKeepaliveMinTime             time.Duration
+	GRPCServerKeepalivePermitWithoutStream bool
+	// Connections idle or open for longer are closed, so load balancers see the new servers.
+	// Unlimited when 0.
+	GRPCServerMaxConnectionIdle     time.Duration
+	GRPCServerMaxConnectionAge      time.Duration
+	GRPCServerMaxConnectionAgeGrace time.Duration
+	// Log level for gRPC handlers, and overrides by org ID
+	GRPCServerLogLevel     string
+	GRPCServerOrgLogLevels map[int64]string
 
 	CustomResponseHeaders map[string]string
 }
@@ -1686,51 +1766,253 @@ func readGRPCServerSettings(cfg *Cfg, iniFile *ini.File) error {
 			Certificates: []tls.Certificate{serverCert},
 			ClientAuth:   tls.NoClientCert,
 		}
+		cfg.GRPCServerCertFile = certFile
+		cfg.GRPCServerKeyFile = keyFile
+
+		// clients must present a certificate signed by the client CA when it is set
+		clientCAFile := server.Key("client_ca_file").String()
+		if clientCAFile != "" {
+			pem, err := os.ReadFile(clientCAFile)
+			if err != nil {
+				return fmt.Errorf("%s error reading client CA: %w", errPrefix, err)
+			}
+			clientCAs := x509.NewCertPool()
+			if !clientCAs.AppendCertsFromPEM(pem) {
+				return fmt.Errorf("%s no certificate found in client CA %s", errPrefix, clientCAFile)
+			}
+			cfg.GRPCServerTLSConfig.ClientCAs = clientCAs
+			cfg.GRPCServerTLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+			cfg.GRPCServerClientCAFile = clientCAFile
+		}
+	}
+	cfg.GRPCServerCertReloadInterval = server.Key("cert_reload_interval").MustDuration(time.Minute)
+	if cfg.GRPCServerCertReloadInterval < 0 {
+		return fmt.Errorf("%s cert_reload_interval must not be negative", errPrefix)
 	}
 
 	cfg.GRPCServerNetwork = valueAsString(server, "network", "tcp")
-	cfg.GRPCServerAddress = valueAsString(server, "address", "")
-	switch cfg.GRPCServerNetwork {
+	address, err := grpcServerListenerAddress(errPrefix, cfg.GRPCServerNetwork, valueAsString(server, "address", ""))
+	if err != nil {
+		return err
+	}
+	cfg.GRPCServerAddress = address
+
+	// listeners = ipv6 mgmt
+	// reads the [grpc_server.listener.ipv6] and [grpc_server.listener.mgmt] sections
+	cfg.GRPCServerListeners = []GRPCServerListener{}
+	for _, name := range util.SplitString(server.Key("listeners").String()) {
+		section := iniFile.Section("grpc_server.listener." + name)
+		if !section.Key("enabled").MustBool(true) {
+			continue
+		}
+		listenerPrefix := fmt.Sprintf("%s listener %s:", errPrefix, name)
+		network := valueAsString(section, "network", "tcp")
+		rawAddress := valueAsString(section, "address", "")
+		if network == "tcp" && rawAddress == "" {
+			return fmt.Errorf("%s address is required", listenerPrefix)
+		}
+		address, err := grpcServerListenerAddress(listenerPrefix, network, rawAddress)
+		if err != nil {
+			return err
+		}
+		cfg.GRPCServerListeners = append(cfg.GRPCServerListeners, GRPCServerListener{
+			Name:    name,
+			Network: network,
+			Address: address,
+		})
+	}
+
+	cfg.GRPCServerLocalNonceFile = valueAsString(server, "local_nonce_file", "")
+	cfg.GRPCServerLocalNonceTTL = server.Key("local_nonce_ttl").MustDuration(10 * time.Minute)
+	if cfg.GRPCServerLocalNonceTTL < 2*time.Second {
+		return fmt.Errorf("%s local_nonce_ttl must be at least 2s", errPrefix)
+	}
+
+	cfg.GRPCServerRateLimitPerToken = server.Key("rate_limit_per_token").MustFloat64(0)
+	cfg.GRPCServerRateLimitPerTokenBurst = server.Key("rate_limit_per_token_burst").MustInt(0)
+	cfg.GRPCServerRateLimitPerOrg = server.Key("rate_limit_per_org").MustFloat64(0)
+	cfg.GRPCServerRateLimitPerOrgBurst = server.Key("rate_limit_per_org_burst").MustInt(0)
+	if cfg.GRPCServerRateLimitPerToken < 0 || cfg.GRPCServerRateLimitPerOrg < 0 {
+		return fmt.Errorf("%s rate limits must not be negative", errPrefix)
+	}
+
+	cfg.GRPCServerLogRequestBodies = server.Key("log_request_bodies").MustBool(false)
+	cfg.GRPCServerLogRequests = server.Key("log_requests").MustBool(false) || cfg.GRPCServerLogRequestBodies
+
+	cfg.GRPCServerEnableReflection = server.Key("enable_reflection").MustBool(cfg.Env == Dev)
+	cfg.GRPCServerHealthCheckInterval = server.Key("health_check_interval").MustDuration(10 * time.Second)
+	if cfg.GRPCServerHealthCheckInterval <= 0 {
+		return fmt.Errorf("%s health_check_interval must be positive", errPrefix)
+	}
+
+	// grafana-server waits 30s for all the services to stop
+	cfg.GRPCServerShutdownTimeout = server.Key("shutdown_timeout").MustDuration(20 * time.Second)
+	if cfg.GRPCServerShutdownTimeout < 0 {
+		return fmt.Errorf("%s shutdown_timeout must not be negative", errPrefix)
+	}
+
+	cfg.GRPCServerMaxRecvMsgSize = server.Key("max_recv_msg_size").MustInt(0)
+	cfg.GRPCServerMaxSendMsgSize = server.Key("max_send_msg_size").MustInt(0)
+	if cfg.GRPCServerMaxRecvMsgSize < 0 || cfg.GRPCServerMaxSendMsgSize < 0 {
+		return fmt.Errorf("%s message size limits must not be negative", errPrefix)
+	}
+	cfg.GRPCServerMaxInFlightRequests = server.Key("max_in_flight_requests").MustInt(0)
+	cfg.GRPCServerMaxQueuedRequests = server.Key("max_queued_requests").MustInt(0)
+	cfg.GRPCServerQueueTimeout = server.Key("queue_timeout").MustDuration(time.Second)
+	cfg.GRPCServerMaxOpenStreams = server.Key("max_open_streams").MustInt(0)
+	maxConcurrentStreams := server.Key("max_concurrent_streams").MustInt64(0)
+	if cfg.GRPCServerMaxInFlightRequests < 0 || cfg.GRPCServerMaxQueuedRequests < 0 || cfg.GRPCServerQueueTimeout < 0 ||
+		cfg.GRPCServerMaxOpenStreams < 0 || maxConcurrentStreams < 0 || maxConcurrentStreams > math.MaxUint32 {
+		return fmt.Errorf("%s invalid concurrency limits", errPrefix)
+	}
+	cfg.GRPCServerMaxConcurrentStreams = uint32(maxConcurrentStreams)
+
+	cfg.GRPCServerRequireCompressionAbove = server.Key("require_compression_above").MustInt(0)
+	if cfg.GRPCServerRequireCompressionAbove < 0 {
+		return fmt.Errorf("%s require_compression_above must not be negative", errPrefix)
+	}
+	cfg.GRPCServerKeepaliveTime = server.Key("keepalive_time").MustDuration(0)
+	cfg.GRPCServerKeepaliveTimeout = server.Key("keepalive_timeout").MustDuration(0)
+	cfg.GRPCServerKeepaliveMinTime = server.Key("keepalive_min_time").MustDuration(5 * time.Minute)
+	cfg.GRPCServerKeepalivePermitWithoutStream = server.Key("keepalive_permit_without_stream").MustBool(false)
+	cfg.GRPCServerMaxConnectionIdle = server.Key("max_connection_idle").MustDuration(0)
+	cfg.GRPCServerMaxConnectionAge = server.Key("max_connection_age").MustDuration(0)
+	cfg.GRPCServerMaxConnectionAgeGrace = server.Key("max_connection_age_grace").MustDuration(0)
+	for key, d := range map[string]time.Duration{
+		"keepalive_time":           cfg.GRPCServerKeepaliveTime,
+		"keepalive_timeout":        cfg.GRPCServerKeepaliveTimeout,
+		"keepalive_min_time":       cfg.GRPCServerKeepaliveMinTime,
+		"max_connection_idle":      cfg.GRPCServerMaxConnectionIdle,
+		"max_connection_age":       cfg.GRPCServerMaxConnectionAge,
+		"max_connection_age_grace": cfg.GRPCServerMaxConnectionAgeGrace,
+	} {
+		if d < 0 {
+			return fmt.Errorf("%s %s must not be negative", errPrefix, key)
+		}
+	}
+
+	cfg.GRPCServerAnonymousMethods = util.SplitString(server.Key("anonymous_methods").String())
+	for _, method := range cfg.GRPCServerAnonymousMethods {
+		if !strings.HasPrefix(method, "/") || strings.Count(method, "/") != 2 {
+			return fmt.Errorf("%s invalid anonymous method %s, expected /package.Service/Method", errPrefix, method)
+		}
+	}
+
+	cfg.GRPCServerJWTAuthEnabled = server.Key("jwt_auth_enabled").MustBool(false)
+	cfg.GRPCServerOIDCIssuer = valueAsString(server, "oidc_issuer", "")
+	cfg.GRPCServerOIDCAudience = valueAsString(server, "oidc_audience", "")
+	cfg.GRPCServerOIDCEmailClaim = valueAsString(server, "oidc_email_claim", "email")
+	if cfg.GRPCServerOIDCIssuer != "" {
+		issuer, err := url.Parse(cfg.GRPCServerOIDCIssuer)
+		if err != nil || issuer.Scheme != "https" || issuer.Host == "" {
+			return fmt.Errorf("%s oidc_issuer must be a https URL", errPrefix)
+		}
+		if cfg.GRPCServerOIDCAudience == "" {
+			return fmt.Errorf("%s oidc_audience is required with oidc_issuer", errPrefix)
+		}
+	}
+
+	cfg.GRPCServerIntrospectionURL = valueAsString(server, "introspection_url", "")
+	cfg.GRPCServerIntrospectionClientID = valueAsString(server, "introspection_client_id", "")
+	cfg.GRPCServerIntrospectionClientSecret = valueAsString(server, "introspection_client_secret", "")
+	cfg.GRPCServerIntrospectionAudience = valueAsString(server, "introspection_audience", "")
+	cfg.GRPCServerIntrospectionEmailField = valueAsString(server, "introspection_email_field", "email")
+	cfg.GRPCServerIntrospectionCacheTTL = server.Key("introspection_cache_ttl").MustDuration(time.Minute)
+	if cfg.GRPCServerIntrospectionURL != "" {
+		introspectionURL, err := url.Parse(cfg.GRPCServerIntrospectionURL)
+		if err != nil || introspectionURL.Scheme != "https" || introspectionURL.Host == "" {
+			return fmt.Errorf("%s introspection_url must be a https URL", errPrefix)
+		}
+		if cfg.GRPCServerIntrospectionClientID == "" {
+			return fmt.Errorf("%s introspection_client_id is required with introspection_url", errPrefix)
+		}
+	}
+	if cfg.GRPCServerIntrospectionCacheTTL < 0 {
+		return fmt.Errorf("%s introspection_cache_ttl must not be negative", errPrefix)
+	}
+
+	cfg.GRPCServerLogLevel = strings.ToLower(valueAsString(server, "log_level", ""))
+	if cfg.GRPCServerLogLevel != "" && !validGRPCServerLogLevel(cfg.GRPCServerLogLevel) {
+		return fmt.Errorf("%s unsupported log level %s", errPrefix, cfg.GRPCServerLogLevel)
+	}
+	// org_log_levels = 1:debug 3:error
+	cfg.GRPCServerOrgLogLevels = map[int64]string{}
+	for _, orgLevel := range util.SplitString(server.Key("org_log_levels").String()) {
+		parts := strings.SplitN(orgLevel, ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("%s invalid org log level %s, expected orgID:level", errPrefix, orgLevel)
+		}
+		orgID, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s invalid org ID in org log level %s", errPrefix, orgLevel)
+		}
+		lvl := strings.ToLower(parts[1])
+		if !validGRPCServerLogLevel(lvl) {
+			return fmt.Errorf("%s unsupported log level %s for org %d", errPrefix, lvl, orgID)
+		}
+		cfg.GRPCServerOrgLogLevels[orgID] = lvl
+	}
+	return nil
+}
+
+type GRPCServerListener struct {
+	Name    string
+	Network string
+	Address string
+}
+
+// grpcServerListenerAddress validates the network and returns the address to listen on, a unix
+// socket left by a previous run is removed and a temporary socket is used when the address is empty
+func grpcServerListenerAddress(errPrefix, network, address string) (string, error) {
+	switch network {
 	case "unix":
-		if cfg.GRPCServerAddress != "" {
+		if address != "" {
 			// Explicitly provided path for unix domain socket.
-			if stat, err := os.Stat(cfg.GRPCServerAddress); os.IsNotExist(err) {
+			if stat, err := os.Stat(address); os.IsNotExist(err) {
 				// File does not exist - nice, nothing to do.
 			} else if err != nil {
-				return fmt.Errorf("%s error getting stat for a file: %s", errPrefix, cfg.GRPCServerAddress)
+				return "", fmt.Errorf("%s error getting stat for a file: %s", errPrefix, address)
 			} else {
 				if stat.Mode()&fs.ModeSocket == 0 {
-					return fmt.Errorf("%s file %s already exists and is not a unix domain socket", errPrefix, cfg.GRPCServerAddress)
+					return "", fmt.Errorf("%s file %s already exists and is not a unix domain socket", errPrefix, address)
 				}
 				// Unix domain socket file, should be safe to remove.
-				err := os.Remove(cfg.GRPCServerAddress)
+				err := os.Remove(address)
 				if err != nil {
-					return fmt.Errorf("%s can't remove unix socket file: %s", errPrefix, cfg.GRPCServerAddress)
+					return "", fmt.Errorf("%s can't remove unix socket file: %s", errPrefix, address)
 				}
 			}
 		} else {
 			// Use temporary file path for a unix domain socket.
 			tf, err := os.CreateTemp("", "gf_grpc_server_api")
 			if err != nil {
-				return fmt.Errorf("%s error creating tmp file: %v", errPrefix, err)
+				return "", fmt.Errorf("%s error creating tmp file: %v", errPrefix, err)
 			}
 			unixPath := tf.Name()
 			if err := tf.Close(); err != nil {
-				return fmt.Errorf("%s error closing tmp file: %v", errPrefix, err)
+				return "", fmt.Errorf("%s error closing tmp file: %v", errPrefix, err)
 			}
 			if err := os.Remove(unixPath); err != nil {
-				return fmt.Errorf("%s error removing tmp file: %v", errPrefix, err)
+				return "", fmt.Errorf("%s error removing tmp file: %v", errPrefix, err)
 			}
-			cfg.GRPCServerAddress = unixPath
+			address = unixPath
 		}
 	case "tcp":
-		if cfg.GRPCServerAddress == "" {
-			cfg.GRPCServerAddress = "127.0.0.1:10000"
+		if address == "" {
+			address = "127.0.0.1:10000"
 		}
 	default:
-		return fmt.Errorf("%s unsupported network %s", errPrefix, cfg.GRPCServerNetwork)
+		return "", fmt.Errorf("%s unsupported network %s", errPrefix, network)
 	}
-	return nil
+	return address, nil
+}
+
+func validGRPCServerLogLevel(lvl string) bool {
+	switch lvl {
+	case "trace", "debug", "info", "warn", "error", "critical":
+		return true
+	}
+	return false
 }
 
 // IsLegacyAlertingEnabled returns whether the legacy alerting is enabled or not.
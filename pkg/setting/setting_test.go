@@ -767,3 +767,245 @@ func TestAlertingEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestGRPCServerListenersSettings(t *testing.T) {
+	f, err := ini.Load([]byte(`
+[grpc_server]
+address = 127.0.0.1:10000
+listeners = ipv6 mgmt disabled
+
+[grpc_server.listener.ipv6]
+address = [::1]:10000
+
+[grpc_server.listener.mgmt]
+address = 10.0.0.5:10001
+
+[grpc_server.listener.disabled]
+enabled = false
+address = 0.0.0.0:10002
+`))
+	require.NoError(t, err)
+	cfg := NewCfg()
+	require.NoError(t, readGRPCServerSettings(cfg, f))
+	require.Equal(t, "127.0.0.1:10000", cfg.GRPCServerAddress)
+	require.Equal(t, []GRPCServerListener{
+		{Name: "ipv6", Network: "tcp", Address: "[::1]:10000"},
+		{Name: "mgmt", Network: "tcp", Address: "10.0.0.5:10001"},
+	}, cfg.GRPCServerListeners)
+
+	f, err = ini.Load([]byte(`
+[grpc_server]
+listeners = ipv6
+`))
+	require.NoError(t, err)
+	require.Error(t, readGRPCServerSettings(NewCfg(), f))
+}
+
+func TestGRPCServerAnonymousMethodsSettings(t *testing.T) {
+	f, err := ini.Load([]byte(`
+[grpc_server]
+anonymous_methods = /entity.EntityStore/Read /entity.EntityStore/Search
+`))
+	require.NoError(t, err)
+	cfg := NewCfg()
+	require.NoError(t, readGRPCServerSettings(cfg, f))
+	require.Equal(t, []string{"/entity.EntityStore/Read", "/entity.EntityStore/Search"}, cfg.GRPCServerAnonymousMethods)
+
+	f, err = ini.Load([]byte(`
+[grpc_server]
+anonymous_methods = entity.EntityStore.Read
+`))
+	require.NoError(t, err)
+	require.Error(t, readGRPCServerSettings(NewCfg(), f))
+}
+
+func TestGRPCServerIntervalSettings(t *testing.T) {
+	cfg := NewCfg()
+	require.NoError(t, readGRPCServerSettings(cfg, ini.Empty()))
+	require.Equal(t, time.Minute, cfg.GRPCServerCertReloadInterval)
+	require.Equal(t, 10*time.Second, cfg.GRPCServerHealthCheckInterval)
+	require.Equal(t, 20*time.Second, cfg.GRPCServerShutdownTimeout)
+	require.False(t, cfg.GRPCServerEnableReflection)
+	require.Empty(t, cfg.GRPCServerCertFile)
+
+	f, err := ini.Load([]byte(`
+[grpc_server]
+cert_reload_interval = -1s
+`))
+	require.NoError(t, err)
+	require.Error(t, readGRPCServerSettings(NewCfg(), f))
+
+	f, err = ini.Load([]byte(`
+[grpc_server]
+health_check_interval = 0s
+`))
+	require.NoError(t, err)
+	require.Error(t, readGRPCServerSettings(NewCfg(), f))
+
+	f, err = ini.Load([]byte(`
+[grpc_server]
+shutdown_timeout = -1s
+`))
+	require.NoError(t, err)
+	require.Error(t, readGRPCServerSettings(NewCfg(), f))
+
+	f, err = ini.Load([]byte(`
+[grpc_server]
+rate_limit_per_token = 5
+rate_limit_per_org = -1
+`))
+	require.NoError(t, err)
+	require.Error(t, readGRPCServerSettings(NewCfg(), f))
+}
+
+func TestGRPCServerConnectionSettings(t *testing.T) {
+	cfg := NewCfg()
+	require.NoError(t, readGRPCServerSettings(cfg, ini.Empty()))
+	require.Zero(t, cfg.GRPCServerMaxRecvMsgSize)
+	require.Zero(t, cfg.GRPCServerRequireCompressionAbove)
+	require.Zero(t, cfg.GRPCServerMaxInFlightRequests)
+	require.Equal(t, time.Second, cfg.GRPCServerQueueTimeout)
+	require.Zero(t, cfg.GRPCServerMaxConcurrentStreams)
+	require.Zero(t, cfg.GRPCServerKeepaliveTime)
+	require.Equal(t, 5*time.Minute, cfg.GRPCServerKeepaliveMinTime)
+	require.Zero(t, cfg.GRPCServerMaxConnectionAge)
+
+	f, err := ini.Load([]byte(`
+[grpc_server]
+max_recv_msg_size = 16777216
+max_send_msg_size = 33554432
+require_compression_above = 1048576
+max_in_flight_requests = 100
+max_queued_requests = 50
+queue_timeout = 500ms
+max_open_streams = 20
+max_concurrent_streams = 10
+keepalive_time = 1m
+keepalive_timeout = 10s
+keepalive_min_time = 30s
+keepalive_permit_without_stream = true
+max_connection_idle = 5m
+max_connection_age = 30m
+max_connection_age_grace = 1m
+`))
+	require.NoError(t, err)
+	cfg = NewCfg()
+	require.NoError(t, readGRPCServerSettings(cfg, f))
+	require.Equal(t, 16<<20, cfg.GRPCServerMaxRecvMsgSize)
+	require.Equal(t, 32<<20, cfg.GRPCServerMaxSendMsgSize)
+	require.Equal(t, 1<<20, cfg.GRPCServerRequireCompressionAbove)
+	require.Equal(t, 100, cfg.GRPCServerMaxInFlightRequests)
+	require.Equal(t, 50, cfg.GRPCServerMaxQueuedRequests)
+	require.Equal(t, 500*time.Millisecond, cfg.GRPCServerQueueTimeout)
+	require.Equal(t, 20, cfg.GRPCServerMaxOpenStreams)
+	require.Equal(t, uint32(10), cfg.GRPCServerMaxConcurrentStreams)
+	require.Equal(t, time.Minute, cfg.GRPCServerKeepaliveTime)
+	require.Equal(t, 10*time.Second, cfg.GRPCServerKeepaliveTimeout)
+	require.Equal(t, 30*time.Second, cfg.GRPCServerKeepaliveMinTime)
+	require.True(t, cfg.GRPCServerKeepalivePermitWithoutStream)
+	require.Equal(t, 5*time.Minute, cfg.GRPCServerMaxConnectionIdle)
+	require.Equal(t, 30*time.Minute, cfg.GRPCServerMaxConnectionAge)
+	require.Equal(t, time.Minute, cfg.GRPCServerMaxConnectionAgeGrace)
+
+	f, err = ini.Load([]byte(`
+[grpc_server]
+max_recv_msg_size = -1
+`))
+	require.NoError(t, err)
+	require.Error(t, readGRPCServerSettings(NewCfg(), f))
+
+	f, err = ini.Load([]byte(`
+[grpc_server]
+max_connection_age = -1m
+`))
+	require.NoError(t, err)
+	require.Error(t, readGRPCServerSettings(NewCfg(), f))
+
+	f, err = ini.Load([]byte(`
+[grpc_server]
+max_concurrent_streams = 5000000000
+`))
+	require.NoError(t, err)
+	require.Error(t, readGRPCServerSettings(NewCfg(), f))
+}
+
+func TestGRPCServerJWTSettings(t *testing.T) {
+	cfg := NewCfg()
+	require.NoError(t, readGRPCServerSettings(cfg, ini.Empty()))
+	require.False(t, cfg.GRPCServerJWTAuthEnabled)
+	require.Empty(t, cfg.GRPCServerOIDCIssuer)
+	require.Equal(t, "email", cfg.GRPCServerOIDCEmailClaim)
+
+	f, err := ini.Load([]byte(`
+[grpc_server]
+jwt_auth_enabled = true
+oidc_issuer = https://accounts.example.com/
+oidc_audience = grafana-grpc
+oidc_email_claim = mail
+`))
+	require.NoError(t, err)
+	cfg = NewCfg()
+	require.NoError(t, readGRPCServerSettings(cfg, f))
+	require.True(t, cfg.GRPCServerJWTAuthEnabled)
+	require.Equal(t, "https://accounts.example.com/", cfg.GRPCServerOIDCIssuer)
+	require.Equal(t, "grafana-grpc", cfg.GRPCServerOIDCAudience)
+	require.Equal(t, "mail", cfg.GRPCServerOIDCEmailClaim)
+
+	f, err = ini.Load([]byte(`
+[grpc_server]
+oidc_issuer = http://accounts.example.com
+oidc_audience = grafana-grpc
+`))
+	require.NoError(t, err)
+	require.Error(t, readGRPCServerSettings(NewCfg(), f))
+
+	f, err = ini.Load([]byte(`
+[grpc_server]
+oidc_issuer = https://accounts.example.com
+`))
+	require.NoError(t, err)
+	require.Error(t, readGRPCServerSettings(NewCfg(), f))
+}
+
+func TestGRPCServerIntrospectionSettings(t *testing.T) {
+	cfg := NewCfg()
+	require.NoError(t, readGRPCServerSettings(cfg, ini.Empty()))
+	require.Empty(t, cfg.GRPCServerIntrospectionURL)
+	require.Equal(t, "email", cfg.GRPCServerIntrospectionEmailField)
+	require.Equal(t, time.Minute, cfg.GRPCServerIntrospectionCacheTTL)
+
+	f, err := ini.Load([]byte(`
+[grpc_server]
+introspection_url = https://idp.example.com/oauth2/introspect
+introspection_client_id = grafana
+introspection_client_secret = secret
+introspection_audience = grafana-grpc
+introspection_email_field = mail
+introspection_cache_ttl = 30s
+`))
+	require.NoError(t, err)
+	cfg = NewCfg()
+	require.NoError(t, readGRPCServerSettings(cfg, f))
+	require.Equal(t, "https://idp.example.com/oauth2/introspect", cfg.GRPCServerIntrospectionURL)
+	require.Equal(t, "grafana", cfg.GRPCServerIntrospectionClientID)
+	require.Equal(t, "secret", cfg.GRPCServerIntrospectionClientSecret)
+	require.Equal(t, "grafana-grpc", cfg.GRPCServerIntrospectionAudience)
+	require.Equal(t, "mail", cfg.GRPCServerIntrospectionEmailField)
+	require.Equal(t, 30*time.Second, cfg.GRPCServerIntrospectionCacheTTL)
+
+	for _, section := range []string{`
+[grpc_server]
+introspection_url = http://idp.example.com/oauth2/introspect
+introspection_client_id = grafana
+`, `
+[grpc_server]
+introspection_url = https://idp.example.com/oauth2/introspect
+`, `
+[grpc_server]
+introspection_cache_ttl = -1m
+`} {
+		f, err = ini.Load([]byte(section))
+		require.NoError(t, err)
+		require.Error(t, readGRPCServerSettings(NewCfg(), f))
+	}
+}
@@ -28,22 +28,6 @@ type Tag struct {
 
 type Select []QueryPart
 
-type Response struct {
-	Results []Result
-	Error   string
-}
-
-type Result struct {
-	Series   []Row
-	Messages []*Message
-	Error    string
-}
-
-type Message struct {
-	Level string `json:"level,omitempty"`
-	Text  string `json:"text,omitempty"`
-}
-
 type Row struct {
 	Name    string            `json:"name,omitempty"`
 	Tags    map[string]string `json:"tags,omitempty"`
@@ -1,7 +1,6 @@
 package influxdb
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"regexp"
@@ -11,6 +10,9 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/grafana/grafana/pkg/util/converter"
 )
 
 type ResponseParser struct{}
@@ -22,121 +24,72 @@ var (
 func (rp *ResponseParser) Parse(buf io.ReadCloser, queries []Query) *backend.QueryDataResponse {
 	resp := backend.NewQueryDataResponse()
 
-	response, jsonErr := parseJSON(buf)
-	if jsonErr != nil {
-		resp.Responses["A"] = backend.DataResponse{Error: jsonErr}
-		return resp
-	}
-
-	if response.Error != "" {
-		resp.Responses["A"] = backend.DataResponse{Error: fmt.Errorf(response.Error)}
+	iter := jsoniter.Parse(jsoniter.ConfigDefault, buf, 1024)
+	results, err := converter.ReadInfluxQLResult(iter, converter.Options{})
+	if err != nil {
+		resp.Responses["A"] = backend.DataResponse{Error: err}
 		return resp
 	}
 
-	for i, result := range response.Results {
-		if result.Error != "" {
-			resp.Responses[queries[i].RefID] = backend.DataResponse{Error: fmt.Errorf(result.Error)}
+	for i, result := range results {
+		if result.Error != nil {
+			resp.Responses[queries[i].RefID] = backend.DataResponse{Error: result.Error}
 		} else {
-			resp.Responses[queries[i].RefID] = backend.DataResponse{Frames: transformRows(result.Series, queries[i])}
+			resp.Responses[queries[i].RefID] = backend.DataResponse{Frames: transformFrames(result.Frames, queries[i])}
 		}
 	}
 
 	return resp
 }
 
-func parseJSON(buf io.ReadCloser) (Response, error) {
-	var response Response
-	dec := json.NewDecoder(buf)
-	dec.UseNumber()
-
-	err := dec.Decode(&response)
-	return response, err
-}
-
-func transformRows(rows []Row, query Query) data.Frames {
-	frames := data.Frames{}
-	for _, row := range rows {
-		var hasTimeCol = false
-
-		for _, column := range row.Columns {
-			if strings.ToLower(column) == "time" {
-				hasTimeCol = true
+// transformFrames names the frames read by the converter like the legacy influxdb response,
+// the series are returned as "time" and "value" fields and the tables as a "value" string field
+func transformFrames(frames data.Frames, query Query) data.Frames {
+	transformed := make(data.Frames, 0, len(frames))
+	for _, frame := range frames {
+		if frame.Meta == nil || frame.Meta.Type != data.FrameTypeTimeSeriesMulti {
+			// SHOW TAG VALUES returns the tag keys next to the values
+			column := 0
+			if strings.Contains(strings.ToLower(query.RawQuery), strings.ToLower("SHOW TAG VALUES")) {
+				column = 1
 			}
-		}
-
-		if !hasTimeCol {
 			var values []string
-
-			for _, valuePair := range row.Values {
-				if strings.Contains(strings.ToLower(query.RawQuery), strings.ToLower("SHOW TAG VALUES")) {
-					if len(valuePair) >= 2 {
-						values = append(values, valuePair[1].(string))
-					}
-				} else {
-					if len(valuePair) >= 1 {
-						values = append(values, valuePair[0].(string))
+			if column < len(frame.Fields) {
+				f := frame.Fields[column]
+				for i := 0; i < f.Len(); i++ {
+					if v, ok := f.ConcreteAt(i); ok {
+						values = append(values, fmt.Sprint(v))
 					}
 				}
 			}
+			transformed = append(transformed, data.NewFrame(frame.Name, data.NewField("value", nil, values)))
+			continue
+		}
 
-			field := data.NewField("value", nil, values)
-			frames = append(frames, data.NewFrame(row.Name, field))
-		} else {
-			for colIndex, column := range row.Columns {
-				if column == "time" {
-					continue
-				}
+		row := Row{Name: frame.Name, Tags: frame.Fields[1].Labels}
+		name := formatFrameName(row, frame.Fields[1].Name, query)
+
+		// the converter shares the time field between the frames of a series, every frame
+		// gets its own like in the legacy response so the frames can be changed separately
+		timeField := copyTimeField(frame.Fields[0])
+		timeField.Name = "time"
+		valueField := frame.Fields[1]
+		valueField.Name = "value"
+		valueField.SetConfig(&data.FieldConfig{DisplayNameFromDS: name})
+		newFrame := newDataFrame(name, query.RawQuery, timeField, valueField)
+		newFrame.Meta.Notices = frame.Meta.Notices
+		transformed = append(transformed, newFrame)
+	}
 
-				var timeArray []time.Time
-				var floatArray []*float64
-				var stringArray []string
-				var boolArray []bool
-				valType := typeof(row.Values, colIndex)
-				name := formatFrameName(row, column, query)
-
-				for _, valuePair := range row.Values {
-					timestamp, timestampErr := parseTimestamp(valuePair[0])
-					// we only add this row if the timestamp is valid
-					if timestampErr == nil {
-						timeArray = append(timeArray, timestamp)
-						if valType == "string" {
-							value := valuePair[colIndex].(string)
-							stringArray = append(stringArray, value)
-						} else if valType == "json.Number" {
-							value := parseNumber(valuePair[colIndex])
-							floatArray = append(floatArray, value)
-						} else if valType == "bool" {
-							value := valuePair[colIndex].(bool)
-							boolArray = append(boolArray, value)
-						} else if valType == "null" {
-							floatArray = append(floatArray, nil)
-						}
-					}
-				}
+	return transformed
+}
 
-				timeField := data.NewField("time", nil, timeArray)
-				if valType == "string" {
-					valueField := data.NewField("value", row.Tags, stringArray)
-					valueField.SetConfig(&data.FieldConfig{DisplayNameFromDS: name})
-					frames = append(frames, newDataFrame(name, query.RawQuery, timeField, valueField))
-				} else if valType == "json.Number" {
-					valueField := data.NewField("value", row.Tags, floatArray)
-					valueField.SetConfig(&data.FieldConfig{DisplayNameFromDS: name})
-					frames = append(frames, newDataFrame(name, query.RawQuery, timeField, valueField))
-				} else if valType == "bool" {
-					valueField := data.NewField("value", row.Tags, boolArray)
-					valueField.SetConfig(&data.FieldConfig{DisplayNameFromDS: name})
-					frames = append(frames, newDataFrame(name, query.RawQuery, timeField, valueField))
-				} else if valType == "null" {
-					valueField := data.NewField("value", row.Tags, floatArray)
-					valueField.SetConfig(&data.FieldConfig{DisplayNameFromDS: name})
-					frames = append(frames, newDataFrame(name, query.RawQuery, timeField, valueField))
-				}
-			}
-		}
+func copyTimeField(f *data.Field) *data.Field {
+	times := make([]time.Time, f.Len())
+	for i := range times {
+		times[i], _ = f.At(i).(time.Time)
 	}
-
-	return frames
+	return data.NewField(f.Name, f.Labels, times)
 }
 
 func newDataFrame(name string, queryString string, timeField *data.Field, valueField *data.Field) *data.Frame {
@@ -201,54 +154,3 @@ func buildFrameNameFromQuery(row Row, column string) string {
 
 	return fmt.Sprintf("%s.%s%s", row.Name, column, tagText)
 }
-
-func parseTimestamp(value interface{}) (time.Time, error) {
-	timestampNumber, ok := value.(json.Number)
-	if !ok {
-		return time.Time{}, fmt.Errorf("timestamp-value has invalid type: %#v", value)
-	}
-	timestampInMilliseconds, err := timestampNumber.Int64()
-	if err != nil {
-		return time.Time{}, err
-	}
-
-	// currently in the code the influxdb-timestamps are requested with
-	// milliseconds-precision, meaning these values are milliseconds
-	t := time.UnixMilli(timestampInMilliseconds).UTC()
-
-	return t, nil
-}
-
-func typeof(values [][]interface{}, colIndex int) string {
-	for _, value := range values {
-		if value != nil && value[colIndex] != nil {
-			return fmt.Sprintf("%T", value[colIndex])
-		}
-	}
-	return "null"
-}
-
-func parseNumber(value interface{}) *float64 {
-	// NOTE: we use pointers-to-float64 because we need
-	// to represent null-json-values. they come for example
-	// when we do a group-by with fill(null)
-
-	if value == nil {
-		// this is what json-nulls become
-		return nil
-	}
-
-	number, ok := value.(json.Number)
-	if !ok {
-		// in the current inmplementation, errors become nils
-		return nil
-	}
-
-	fvalue, err := number.Float64()
-	if err != nil {
-		// in the current inmplementation, errors become nils
-		return nil
-	}
-
-	return &fvalue
-}
@@ -1,7 +1,6 @@
 package influxdb
 
 import (
-	"encoding/json"
 	"io"
 	"strings"
 	"testing"
@@ -363,6 +362,37 @@ func TestInfluxdbResponseParser(t *testing.T) {
 		}
 	})
 
+	t.Run("Influxdb response parser returns a time field per frame", func(t *testing.T) {
+		parser := &ResponseParser{}
+
+		response := `
+		{
+			"results": [
+				{
+					"series": [
+						{
+							"name": "cpu",
+							"columns": ["time","mean","max"],
+							"values": [
+								[100,50,60],
+								[101,51,61]
+							]
+						}
+					]
+				}
+			]
+		}
+		`
+
+		result := parser.Parse(prepare(response), addQueryToQueries(Query{}))
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 2)
+		require.NotSame(t, frames[0].Fields[0], frames[1].Fields[0])
+		frames[0].Fields[0].Set(0, time.Time{})
+		require.Equal(t, time.Date(1970, 1, 1, 0, 0, 0, 100000000, time.UTC), frames[1].Fields[0].At(0))
+	})
+
 	t.Run("Influxdb response parser with alias", func(t *testing.T) {
 		parser := &ResponseParser{}
 
@@ -700,34 +730,6 @@ func TestInfluxdbResponseParser(t *testing.T) {
 
 		require.EqualError(t, result.Responses["A"].Error, "error parsing query: found THING")
 	})
-
-	t.Run("Influxdb response parser parseNumber nil", func(t *testing.T) {
-		value := parseNumber(nil)
-		require.Nil(t, value)
-	})
-
-	t.Run("Influxdb response parser parseNumber valid JSON.number", func(t *testing.T) {
-		value := parseNumber(json.Number("95.4"))
-		require.Equal(t, *value, 95.4)
-	})
-
-	t.Run("Influxdb response parser parseNumber invalid type", func(t *testing.T) {
-		value := parseNumber("95.4")
-		require.Nil(t, value)
-	})
-
-	t.Run("Influxdb response parser parseTimestamp valid JSON.number", func(t *testing.T) {
-		// currently we use milliseconds-precision with influxdb, so the test works with that.
-		// if we change this to for example nanoseconds-precision, the tests will have to change.
-		timestamp, err := parseTimestamp(json.Number("1609556645000"))
-		require.NoError(t, err)
-		require.Equal(t, timestamp.Format(time.RFC3339), "2021-01-02T03:04:05Z")
-	})
-
-	t.Run("Influxdb response parser parseNumber invalid type", func(t *testing.T) {
-		_, err := parseTimestamp("hello")
-		require.Error(t, err)
-	})
 }
 
 func TestResponseParser_Parse(t *testing.T) {
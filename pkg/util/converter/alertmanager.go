@@ -0,0 +1,311 @@
+package converter
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// ReadAlertmanagerAlerts reads the alerts from the alertmanager /api/v2/alerts endpoint
+// [ { labels, annotations, fingerprint, startsAt, endsAt, updatedAt, receivers, status } ]
+func ReadAlertmanagerAlerts(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
+	return readAlertmanagerResponse(iter, opt, readAlertmanagerAlerts)
+}
+
+// ReadAlertmanagerSilences reads the silences from the alertmanager /api/v2/silences endpoint
+// [ { id, status, matchers, startsAt, endsAt, updatedAt, createdBy, comment } ]
+func ReadAlertmanagerSilences(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
+	return readAlertmanagerResponse(iter, opt, readAlertmanagerSilences)
+}
+
+// alertmanager responses are not wrapped in a status envelope like the prometheus ones
+func readAlertmanagerResponse(iter *jsoniter.Iterator, opt Options, read func(iter *jsoniter.Iterator) backend.DataResponse) backend.DataResponse {
+	attachUnknownKeys(iter, &opt)
+	rsp := read(iter)
+	if iter.Error != nil && rsp.Error == nil {
+		rsp.Error = iter.Error
+	}
+	if err := opt.unknownKeys.report(rsp.Frames, opt.Strict); err != nil && rsp.Error == nil {
+		rsp.Error = err
+	}
+	finishFrames(rsp.Frames, opt)
+	return rsp
+}
+
+// The columns match the ones of the prometheus alerts where they can
+func readAlertmanagerAlerts(iter *jsoniter.Iterator) backend.DataResponse {
+	name := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	name.Name = "name"
+	state := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	state.Name = "state"
+	fingerprint := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	fingerprint.Name = "fingerprint"
+	startsAt := data.NewFieldFromFieldType(data.FieldTypeNullableTime, 0)
+	startsAt.Name = "startsAt"
+	endsAt := data.NewFieldFromFieldType(data.FieldTypeNullableTime, 0)
+	endsAt.Name = "endsAt"
+	updatedAt := data.NewFieldFromFieldType(data.FieldTypeNullableTime, 0)
+	updatedAt.Name = "updatedAt"
+	generatorURL := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	generatorURL.Name = "generatorURL"
+	receivers := data.NewFieldFromFieldType(data.FieldTypeJSON, 0)
+	receivers.Name = "receivers"
+	silencedBy := data.NewFieldFromFieldType(data.FieldTypeJSON, 0)
+	silencedBy.Name = "silencedBy"
+	inhibitedBy := data.NewFieldFromFieldType(data.FieldTypeJSON, 0)
+	inhibitedBy.Name = "inhibitedBy"
+	labelsField := data.NewFieldFromFieldType(data.FieldTypeJSON, 0)
+	labelsField.Name = "labels"
+	annotationsField := data.NewFieldFromFieldType(data.FieldTypeJSON, 0)
+	annotationsField.Name = "annotations"
+
+	var err error
+	setErr := func(e error) {
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+
+	for iter.ReadArray() {
+		var (
+			alertName, alertState, alertFingerprint, alertURL string
+			alertStartsAt, alertEndsAt, alertUpdatedAt        *time.Time
+		)
+		labels := json.RawMessage("{}")
+		annotations := labels
+		alertReceivers := json.RawMessage("[]")
+		alertSilencedBy := alertReceivers
+		alertInhibitedBy := alertReceivers
+
+		for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+			switch l1Field {
+			case "labels":
+				l := data.Labels{}
+				iter.ReadVal(&l)
+				alertName = l["alertname"]
+				v, lerr := labelsToRawJson(l)
+				labels = v
+				setErr(lerr)
+			case "annotations":
+				v, aerr := readLabelsAsRawJson(iter)
+				annotations = v
+				setErr(aerr)
+			case "fingerprint":
+				alertFingerprint = iter.ReadString()
+			case "generatorURL":
+				alertURL = iter.ReadString()
+			case "startsAt":
+				t, terr := readRFC3339Time(iter)
+				alertStartsAt = t
+				setErr(terr)
+			case "endsAt":
+				t, terr := readRFC3339Time(iter)
+				alertEndsAt = t
+				setErr(terr)
+			case "updatedAt":
+				t, terr := readRFC3339Time(iter)
+				alertUpdatedAt = t
+				setErr(terr)
+			case "receivers":
+				names := []string{}
+				for iter.ReadArray() {
+					for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+						if k == "name" {
+							names = append(names, iter.ReadString())
+						} else {
+							iter.Skip()
+							skippedKey(iter, "receivers", k)
+						}
+					}
+				}
+				v, rerr := json.Marshal(names)
+				alertReceivers = v
+				setErr(rerr)
+			case "status":
+				for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+					switch k {
+					case "state":
+						alertState = iter.ReadString()
+					case "silencedBy":
+						v, serr := readStringsAsRawJson(iter)
+						alertSilencedBy = v
+						setErr(serr)
+					case "inhibitedBy":
+						v, ierr := readStringsAsRawJson(iter)
+						alertInhibitedBy = v
+						setErr(ierr)
+					default:
+						iter.Skip()
+						skippedKey(iter, "status", k)
+					}
+				}
+			default:
+				iter.Skip()
+				skippedKey(iter, "alerts", l1Field)
+			}
+		}
+
+		name.Append(alertName)
+		state.Append(alertState)
+		fingerprint.Append(alertFingerprint)
+		startsAt.Append(alertStartsAt)
+		endsAt.Append(alertEndsAt)
+		updatedAt.Append(alertUpdatedAt)
+		generatorURL.Append(alertURL)
+		receivers.Append(alertReceivers)
+		silencedBy.Append(alertSilencedBy)
+		inhibitedBy.Append(alertInhibitedBy)
+		labelsField.Append(labels)
+		annotationsField.Append(annotations)
+	}
+
+	frame := data.NewFrame("", name, state, fingerprint, startsAt, endsAt, updatedAt, generatorURL,
+		receivers, silencedBy, inhibitedBy, labelsField, annotationsField)
+	frame.Meta = &data.FrameMeta{
+		Custom: resultTypeToCustomMeta("alertmanagerAlerts"),
+	}
+	return backend.DataResponse{
+		Frames: data.Frames{frame},
+		Error:  err,
+	}
+}
+
+func readAlertmanagerSilences(iter *jsoniter.Iterator) backend.DataResponse {
+	id := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	id.Name = "id"
+	state := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	state.Name = "state"
+	matchersField := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	matchersField.Name = "matchers"
+	startsAt := data.NewFieldFromFieldType(data.FieldTypeNullableTime, 0)
+	startsAt.Name = "startsAt"
+	endsAt := data.NewFieldFromFieldType(data.FieldTypeNullableTime, 0)
+	endsAt.Name = "endsAt"
+	updatedAt := data.NewFieldFromFieldType(data.FieldTypeNullableTime, 0)
+	updatedAt.Name = "updatedAt"
+	createdBy := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	createdBy.Name = "createdBy"
+	comment := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	comment.Name = "comment"
+
+	var err error
+	setErr := func(e error) {
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+
+	for iter.ReadArray() {
+		var (
+			silenceID, silenceState, silenceMatchers, silenceCreatedBy, silenceComment string
+			silenceStartsAt, silenceEndsAt, silenceUpdatedAt                           *time.Time
+		)
+
+		for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+			switch l1Field {
+			case "id":
+				silenceID = iter.ReadString()
+			case "status":
+				for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+					if k == "state" {
+						silenceState = iter.ReadString()
+					} else {
+						iter.Skip()
+						skippedKey(iter, "status", k)
+					}
+				}
+			case "matchers":
+				silenceMatchers = readSilenceMatchers(iter)
+			case "startsAt":
+				t, terr := readRFC3339Time(iter)
+				silenceStartsAt = t
+				setErr(terr)
+			case "endsAt":
+				t, terr := readRFC3339Time(iter)
+				silenceEndsAt = t
+				setErr(terr)
+			case "updatedAt":
+				t, terr := readRFC3339Time(iter)
+				silenceUpdatedAt = t
+				setErr(terr)
+			case "createdBy":
+				silenceCreatedBy = iter.ReadString()
+			case "comment":
+				silenceComment = iter.ReadString()
+			default:
+				iter.Skip()
+				skippedKey(iter, "silences", l1Field)
+			}
+		}
+
+		id.Append(silenceID)
+		state.Append(silenceState)
+		matchersField.Append(silenceMatchers)
+		startsAt.Append(silenceStartsAt)
+		endsAt.Append(silenceEndsAt)
+		updatedAt.Append(silenceUpdatedAt)
+		createdBy.Append(silenceCreatedBy)
+		comment.Append(silenceComment)
+	}
+
+	frame := data.NewFrame("", id, state, matchersField, startsAt, endsAt, updatedAt, createdBy, comment)
+	frame.Meta = &data.FrameMeta{
+		Custom: resultTypeToCustomMeta("alertmanagerSilences"),
+	}
+	return backend.DataResponse{
+		Frames: data.Frames{frame},
+		Error:  err,
+	}
+}
+
+// readSilenceMatchers renders the matchers like the alertmanager does, for example
+// {alertname="HighLatency", job=~"api|web"}
+// [ { name, value, isRegex, isEqual } ], isEqual is missing before alertmanager 0.22
+func readSilenceMatchers(iter *jsoniter.Iterator) string {
+	matchers := []string{}
+	for iter.ReadArray() {
+		var name, value string
+		isRegex, isEqual := false, true
+		for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+			switch k {
+			case "name":
+				name = iter.ReadString()
+			case "value":
+				value = iter.ReadString()
+			case "isRegex":
+				isRegex = iter.ReadBool()
+			case "isEqual":
+				isEqual = iter.ReadBool()
+			default:
+				iter.Skip()
+				skippedKey(iter, "matchers", k)
+			}
+		}
+
+		op := "="
+		switch {
+		case isRegex && isEqual:
+			op = "=~"
+		case isRegex:
+			op = "!~"
+		case !isEqual:
+			op = "!="
+		}
+		matchers = append(matchers, name+op+strconv.Quote(value))
+	}
+	return "{" + strings.Join(matchers, ", ") + "}"
+}
+
+// readStringsAsRawJson reads an array of strings, null is read as an empty array
+func readStringsAsRawJson(iter *jsoniter.Iterator) (json.RawMessage, error) {
+	values := []string{}
+	for iter.ReadArray() {
+		values = append(values, iter.ReadString())
+	}
+	return json.Marshal(values)
+}
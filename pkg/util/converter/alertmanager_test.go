@@ -0,0 +1,75 @@
+package converter
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAlertmanagerAlerts(t *testing.T) {
+	f, err := os.Open(path.Join("testdata", "alertmanager-alerts.json"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	rsp := ReadAlertmanagerAlerts(jsoniter.Parse(jsoniter.ConfigDefault, f, 1024), Options{Strict: StrictError})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 1)
+
+	frame := rsp.Frames[0]
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, "alertmanagerAlerts", frame.Meta.Custom.(map[string]string)["resultType"])
+
+	row := func(name string, i int) interface{} {
+		f, _ := frame.FieldByName(name)
+		require.NotNil(t, f, name)
+		return f.At(i)
+	}
+
+	require.Equal(t, "HighRequestLatency", row("name", 0))
+	require.Equal(t, "active", row("state", 0))
+	require.Equal(t, "0c1ee3e2a1cb9b48", row("fingerprint", 0))
+	require.Equal(t, time.Date(2023, time.January, 20, 13, 55, 39, 211000000, time.UTC), *row("startsAt", 0).(*time.Time))
+	require.Equal(t, json.RawMessage(`["team-api","pager"]`), row("receivers", 0))
+	require.Equal(t, json.RawMessage(`{"alertname":"HighRequestLatency","job":"api","severity":"page"}`), row("labels", 0))
+	require.Equal(t, json.RawMessage(`{"summary":"High request latency"}`), row("annotations", 0))
+
+	require.Equal(t, "suppressed", row("state", 1))
+	require.Equal(t, json.RawMessage(`["1f7a52a1-7b2c-4d6f-9f3c-93a8c0d5d3e1"]`), row("silencedBy", 1))
+	require.Equal(t, json.RawMessage(`[]`), row("inhibitedBy", 1))
+}
+
+func TestReadAlertmanagerSilences(t *testing.T) {
+	f, err := os.Open(path.Join("testdata", "alertmanager-silences.json"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	rsp := ReadAlertmanagerSilences(jsoniter.Parse(jsoniter.ConfigDefault, f, 1024), Options{Strict: StrictError})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 1)
+
+	frame := rsp.Frames[0]
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, "alertmanagerSilences", frame.Meta.Custom.(map[string]string)["resultType"])
+
+	row := func(name string, i int) interface{} {
+		f, _ := frame.FieldByName(name)
+		require.NotNil(t, f, name)
+		return f.At(i)
+	}
+
+	require.Equal(t, "1f7a52a1-7b2c-4d6f-9f3c-93a8c0d5d3e1", row("id", 0))
+	require.Equal(t, "active", row("state", 0))
+	require.Equal(t, `{alertname="InstanceDown", job=~"web|api", env!="dev"}`, row("matchers", 0))
+	require.Equal(t, time.Date(2023, time.January, 20, 16, 0, 0, 0, time.UTC), *row("endsAt", 0).(*time.Time))
+	require.Equal(t, "ops", row("createdBy", 0))
+	require.Equal(t, "maintenance", row("comment", 0))
+
+	require.Equal(t, "expired", row("state", 1))
+	// isEqual is missing in older versions
+	require.Equal(t, `{instance=~"db-.*"}`, row("matchers", 1))
+}
@@ -0,0 +1,261 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// The matrix and vector readers are the hottest allocation site of the prometheus
+// datasource, the helpers below keep the allocations per series instead of per sample.
+
+// exact powers of ten, see parseFloat
+var float64pow10 = [...]float64{
+	1e0, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10,
+	1e11, 1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18, 1e19, 1e20, 1e21, 1e22,
+}
+
+// parseFloat parses sample values without converting them to a string. Plain decimals
+// like "12.5" with at most 15 digits are exact as mantissa / 10^n, everything else,
+// like exponents, NaN and Inf, is left to strconv.
+func parseFloat(b []byte) (float64, error) {
+	i := 0
+	neg := false
+	if len(b) > 0 && (b[0] == '-' || b[0] == '+') {
+		neg = b[0] == '-'
+		i++
+	}
+
+	var mantissa uint64
+	digits := 0
+	decimals := 0
+	dot := false
+	for ; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case c >= '0' && c <= '9':
+			mantissa = mantissa*10 + uint64(c-'0')
+			digits++
+			if dot {
+				decimals++
+			}
+		case c == '.' && !dot:
+			dot = true
+		default:
+			return strconv.ParseFloat(string(b), 64)
+		}
+	}
+	if digits == 0 || digits > 15 {
+		return strconv.ParseFloat(string(b), 64)
+	}
+
+	f := float64(mantissa) / float64pow10[decimals]
+	if neg {
+		f = -f
+	}
+	return f, nil
+}
+
+var errMalformedValuePair = errors.New(`expected a [ time, "value" ] pair`)
+
+// readTimeValuePair reads a [ time, "value" ] pair. Malformed pairs are skipped without
+// failing the iterator, so the other samples and series can still be read.
+func readTimeValuePair(iter *jsoniter.Iterator, opt Options) (time.Time, float64, error) {
+	if iter.WhatIsNext() != jsoniter.ArrayValue {
+		iter.Skip()
+		return time.Time{}, 0, errMalformedValuePair
+	}
+
+	var (
+		t         time.Time
+		fv        float64
+		err       = errMalformedValuePair
+		malformed bool
+	)
+	for i := 0; iter.ReadArray(); i++ {
+		switch {
+		case i == 0 && iter.WhatIsNext() == jsoniter.NumberValue:
+			t = readTimestamp(iter, opt)
+		case i == 1 && iter.WhatIsNext() == jsoniter.StringValue:
+			// only valid until the next read
+			v := iter.ReadStringAsSlice()
+			fv, err = parseFloat(v)
+		default:
+			// a time that is not a number or extra values, the pair is malformed even
+			// when the value parses
+			iter.Skip()
+			if i != 1 {
+				malformed = true
+			}
+		}
+	}
+	if malformed {
+		return time.Time{}, 0, errMalformedValuePair
+	}
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return t, fv, nil
+}
+
+// malformedSamples counts the samples skipped in the whole response
+type malformedSamples struct {
+	count int
+	first error
+}
+
+// add is called for every skipped sample, a nil counter ignores them
+func (m *malformedSamples) add(err error) {
+	if m == nil {
+		return
+	}
+	m.count++
+	if m.first == nil {
+		m.first = err
+	}
+}
+
+func addMalformedNotice(frames []*data.Frame, m *malformedSamples) {
+	if m == nil || m.count == 0 {
+		return
+	}
+	notice := data.Notice{
+		Severity: data.NoticeSeverityError,
+		Text:     fmt.Sprintf("Skipped %d malformed samples: %s", m.count, m.first),
+	}
+	for _, frame := range frames {
+		frame.AppendNotices(notice)
+	}
+}
+
+// stringInterner shares the label values repeated across the series of a response
+type stringInterner map[string]string
+
+func (s stringInterner) intern(v string) string {
+	if shared, ok := s[v]; ok {
+		return shared
+	}
+	s[v] = v
+	return v
+}
+
+func readLabels(iter *jsoniter.Iterator, interner stringInterner) data.Labels {
+	labels := data.Labels{}
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		iter.Skip()
+		return labels
+	}
+	for key := iter.ReadObject(); key != ""; key = iter.ReadObject() {
+		if iter.WhatIsNext() != jsoniter.StringValue {
+			iter.Skip()
+			continue
+		}
+		// values can have escapes, so they are not read as slices like the samples
+		labels[interner.intern(key)] = interner.intern(iter.ReadString())
+	}
+	return labels
+}
+
+const floatSlabSize = 1024
+
+// floatSlab hands out pointers for nullable float fields from larger blocks
+type floatSlab struct {
+	block []float64
+}
+
+func (s *floatSlab) ptr(v float64) *float64 {
+	if len(s.block) == 0 {
+		s.block = make([]float64, floatSlabSize)
+	}
+	p := &s.block[0]
+	*p = v
+	s.block = s.block[1:]
+	return p
+}
+
+// seriesBuffer collects the samples of one series of a multi frame, and is reused
+// for every series since the fields copy the values
+type seriesBuffer struct {
+	times  []time.Time
+	values []float64
+	nulls  []bool
+
+	// the time field of the previous series, and a copy of its timestamps
+	shared      *data.Field
+	sharedTimes []time.Time
+}
+
+func (b *seriesBuffer) reset() {
+	b.times = b.times[:0]
+	b.values = b.values[:0]
+	b.nulls = b.nulls[:0]
+}
+
+func (b *seriesBuffer) len() int {
+	return len(b.times)
+}
+
+// appendTimeValuePair reads a [ time, "value" ] pair, samples with invalid values are dropped
+func (b *seriesBuffer) appendTimeValuePair(iter *jsoniter.Iterator, opt Options) {
+	t, fv, err := readTimeValuePair(iter, opt)
+	if err != nil {
+		opt.malformed.add(err)
+		return
+	}
+	null, ok := nonFiniteValues(opt, fv).classify(fv)
+	if !ok || !opt.rowLimit.take() {
+		return
+	}
+	b.times = append(b.times, t)
+	b.values = append(b.values, fv)
+	b.nulls = append(b.nulls, null)
+}
+
+func (b *seriesBuffer) fields(opt Options, labels data.Labels) (*data.Field, *data.Field) {
+	timeField := b.timeField()
+
+	var valueField *data.Field
+	if multiValueFieldType(opt).Nullable() {
+		// a single block for all the pointers of the series
+		block := make([]float64, len(b.values))
+		copy(block, b.values)
+		values := make([]*float64, len(b.values))
+		for i := range block {
+			if !b.nulls[i] {
+				values[i] = &block[i]
+			}
+		}
+		valueField = data.NewField(data.TimeSeriesValueFieldName, labels, values)
+	} else {
+		valueField = data.NewField(data.TimeSeriesValueFieldName, labels, b.values)
+	}
+	return timeField, valueField
+}
+
+// timeField returns the time field of the previous series when it has the same timestamps,
+// like every series of an aligned range query. The frames then share the field, so it
+// must not be changed for a single frame.
+func (b *seriesBuffer) timeField() *data.Field {
+	if b.shared != nil && sameTimes(b.sharedTimes, b.times) {
+		return b.shared
+	}
+	b.shared = data.NewField(data.TimeSeriesTimeFieldName, nil, b.times)
+	b.sharedTimes = append(b.sharedTimes[:0], b.times...)
+	return b.shared
+}
+
+func sameTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package converter
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFloat(t *testing.T) {
+	for _, v := range []string{
+		"0", "1", "-1", "+1", "-0", "12.5", "0.1", "0.3", ".5", "5.", "123456789012345",
+		"1234567890123456789", "0.000000000000001", "3.141592653589793", "1e+06", "1.5e-3",
+		"NaN", "+Inf", "-Inf",
+	} {
+		expected, err := strconv.ParseFloat(v, 64)
+		require.NoError(t, err)
+		actual, err := parseFloat([]byte(v))
+		require.NoError(t, err, v)
+		if math.IsNaN(expected) {
+			require.True(t, math.IsNaN(actual))
+			continue
+		}
+		require.Equal(t, math.Float64bits(expected), math.Float64bits(actual), v)
+	}
+
+	for _, v := range []string{"", ".", "-", "1.2.3", "abc"} {
+		_, err := parseFloat([]byte(v))
+		require.Error(t, err, v)
+	}
+}
+
+func TestReadLabelsSharesStrings(t *testing.T) {
+	interner := stringInterner{}
+	a := readLabels(jsoniter.ParseString(jsoniter.ConfigDefault, `{"job":"grafana","i":"1"}`), interner)
+	b := readLabels(jsoniter.ParseString(jsoniter.ConfigDefault, `{"job":"grafana","i":"2","n":1}`), interner)
+	require.Equal(t, "grafana", a["job"])
+	require.Equal(t, "2", b["i"])
+	require.NotContains(t, b, "n")
+	require.Len(t, interner, 5)
+}
+
+func TestReadLabelsWithEscapes(t *testing.T) {
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"job":"say \"hi\"","path":"C:\\data\\"},"value":[1,"1"]},
+		{"metric":{"job":"node"},"value":[1,"2"]}
+	]}}`), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 2)
+	require.Equal(t, `say "hi"`, rsp.Frames[0].Fields[1].Labels["job"])
+	require.Equal(t, `C:\data\`, rsp.Frames[0].Fields[1].Labels["path"])
+	require.Equal(t, "node", rsp.Frames[1].Fields[1].Labels["job"])
+}
+
+func TestMalformedSamples(t *testing.T) {
+	const body = `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"job":"a"},"values":[[1,"1"],[2,3],[3,"3"]]},
+		{"metric":{"job":"b"},"values":[[1,"4"],"bad",[2],[3,"6","x"]]},
+		{"metric":{"job":"c"},"values":[[1,"7"],[2,"8"]]}
+	]}}`
+
+	t.Run("multi", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 3)
+		require.Equal(t, 2, rsp.Frames[0].Rows())
+		require.Equal(t, 1, rsp.Frames[1].Rows())
+		require.Equal(t, 2, rsp.Frames[2].Rows())
+		for _, frame := range rsp.Frames {
+			require.Len(t, frame.Meta.Notices, 1)
+			require.Equal(t, data.NoticeSeverityError, frame.Meta.Notices[0].Severity)
+			require.Contains(t, frame.Meta.Notices[0].Text, "Skipped 4 malformed samples")
+		}
+	})
+
+	t.Run("wide", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{MatrixWideSeries: true})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		require.Len(t, rsp.Frames[0].Fields, 4)
+		require.Equal(t, 3, rsp.Frames[0].Rows())
+		require.Len(t, rsp.Frames[0].Meta.Notices, 1)
+	})
+
+	t.Run("times that are not numbers", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"job":"a"},"values":[[1700000000,"1"],["1700000015","2"],[null,"3"]]}
+		]}}`), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		require.Equal(t, 1, rsp.Frames[0].Rows())
+		require.Equal(t, time.Unix(1700000000, 0).UTC(), rsp.Frames[0].Fields[0].At(0))
+		require.Contains(t, rsp.Frames[0].Meta.Notices[0].Text, "Skipped 2 malformed samples")
+	})
+
+	t.Run("no notice without malformed samples", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrixResponse(1)), Options{})
+		require.NoError(t, rsp.Error)
+		require.Empty(t, rsp.Frames[0].Meta.Notices)
+	})
+}
+
+func TestSharedTimeFields(t *testing.T) {
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"i":"0"},"values":[[1641889530,"1"],[1641889545,"2"]]},
+		{"metric":{"i":"1"},"values":[[1641889530,"3"],[1641889545,"4"]]},
+		{"metric":{"i":"2"},"values":[[1641889530,"5"]]},
+		{"metric":{"i":"3"},"values":[[1641889530,"6"]]}
+	]}}`), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 4)
+
+	require.Same(t, rsp.Frames[0].Fields[0], rsp.Frames[1].Fields[0])
+	require.NotSame(t, rsp.Frames[1].Fields[0], rsp.Frames[2].Fields[0])
+	require.Same(t, rsp.Frames[2].Fields[0], rsp.Frames[3].Fields[0])
+	require.Equal(t, 1, rsp.Frames[3].Rows())
+	require.Equal(t, 6.0, rsp.Frames[3].Fields[1].At(0))
+}
@@ -0,0 +1,187 @@
+package converter
+
+import (
+	"encoding/json"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/mattetti/filebuffer"
+)
+
+// ArrowResponse is a response read by ReadPrometheusStyleResultArrow. The samples of the
+// series of multi frames are read straight into arrow columns, their frames only hold
+// empty fields. The names, labels, configs and meta of the frames can still be changed,
+// they are written by MarshalArrow.
+type ArrowResponse struct {
+	backend.DataResponse
+
+	columns map[*data.Frame][]array.Interface
+}
+
+// ReadPrometheusStyleResultArrow reads a response like ReadPrometheusStyleResult, but the
+// samples of matrix and vector series are not copied into frame fields. They are appended
+// to arrow columns while parsing, so MarshalArrow writes them without building the frames
+// first, which saves one full copy of large results sent over gRPC.
+//
+// Series changed after they are read, like with DropRepeatedValues, FillGaps, numeric or loki
+// vectors, and all the other frames, are read and marshaled as usual.
+func ReadPrometheusStyleResultArrow(iter *jsoniter.Iterator, opt Options) *ArrowResponse {
+	columns := &arrowColumns{
+		pool:    memory.NewGoAllocator(),
+		columns: map[*data.Frame][]array.Interface{},
+	}
+	opt.arrow = columns
+	rsp := ReadPrometheusStyleResult(iter, opt)
+	return &ArrowResponse{
+		DataResponse: rsp,
+		columns:      columns.columns,
+	}
+}
+
+// MarshalArrow encodes every frame in the arrow IPC format of data.Frame.MarshalArrow
+func (r *ArrowResponse) MarshalArrow() ([][]byte, error) {
+	encoded := make([][]byte, 0, len(r.Frames))
+	for _, frame := range r.Frames {
+		var (
+			b   []byte
+			err error
+		)
+		if cols, ok := r.columns[frame]; ok {
+			b, err = marshalArrowColumns(frame, cols)
+		} else {
+			b, err = frame.MarshalArrow()
+		}
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, b)
+	}
+	return encoded, nil
+}
+
+// arrowColumns holds the columns of the frames read by ReadPrometheusStyleResultArrow
+type arrowColumns struct {
+	pool    memory.Allocator
+	columns map[*data.Frame][]array.Interface
+}
+
+// direct reports if the series of a result can be read into arrow columns
+func (c *arrowColumns) direct(opt Options, resultType string) bool {
+	// the values of the series are summed for the "Other" series
+	if c == nil || opt.sink != nil || opt.MaxSeries > 0 || opt.MaxLabelValues > 0 {
+		return false
+	}
+	if resultType == "matrix" {
+		return !opt.DropRepeatedValues && !opt.FillGaps
+	}
+	return !opt.NumericVector && !opt.Dataplane && !opt.LokiVector
+}
+
+// add keeps the columns of a frame, a nil receiver or nil columns are ignored
+func (c *arrowColumns) add(frame *data.Frame, cols []array.Interface) {
+	if c == nil || cols == nil {
+		return
+	}
+	c.columns[frame] = cols
+}
+
+// arrowFields returns the empty time and value fields of the series, and the arrow
+// columns holding their samples
+func (b *seriesBuffer) arrowFields(opt Options, labels data.Labels, pool memory.Allocator) (*data.Field, *data.Field, []array.Interface) {
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
+	timeField.Name = data.TimeSeriesTimeFieldName
+	valueType := multiValueFieldType(opt)
+	valueField := data.NewFieldFromFieldType(valueType, 0)
+	valueField.Name = data.TimeSeriesValueFieldName
+	valueField.Labels = labels
+
+	// the same timestamp type as the SDK, in nanoseconds whatever the unit says
+	times := array.NewTimestampBuilder(pool, &arrow.TimestampType{})
+	defer times.Release()
+	times.Reserve(len(b.times))
+	for _, t := range b.times {
+		times.UnsafeAppend(arrow.Timestamp(t.UnixNano()))
+	}
+
+	values := array.NewFloat64Builder(pool)
+	defer values.Release()
+	var valid []bool
+	if valueType.Nullable() {
+		valid = make([]bool, len(b.nulls))
+		for i, null := range b.nulls {
+			valid[i] = !null
+		}
+	}
+	values.AppendValues(b.values, valid)
+
+	return timeField, valueField, []array.Interface{times.NewArray(), values.NewArray()}
+}
+
+// marshalArrowColumns writes the schema of the frame like data.Frame.MarshalArrow,
+// with the columns read for it
+func marshalArrowColumns(frame *data.Frame, cols []array.Interface) ([]byte, error) {
+	fields := make([]arrow.Field, len(frame.Fields))
+	for i, f := range frame.Fields {
+		tstype := "number"
+		if f.Type().Time() {
+			tstype = "time"
+		}
+		meta := map[string]string{"tstype": tstype}
+		if f.Labels != nil {
+			b, err := json.Marshal(f.Labels)
+			if err != nil {
+				return nil, err
+			}
+			meta["labels"] = string(b)
+		}
+		if f.Config != nil {
+			b, err := json.Marshal(f.Config)
+			if err != nil {
+				return nil, err
+			}
+			meta["config"] = string(b)
+		}
+		fields[i] = arrow.Field{
+			Name:     f.Name,
+			Type:     cols[i].DataType(),
+			Metadata: arrow.MetadataFrom(meta),
+			Nullable: f.Nullable(),
+		}
+	}
+
+	tableMeta := map[string]string{
+		"name":  frame.Name,
+		"refId": frame.RefID,
+	}
+	if frame.Meta != nil {
+		b, err := json.Marshal(frame.Meta)
+		if err != nil {
+			return nil, err
+		}
+		tableMeta["meta"] = string(b)
+	}
+	metadata := arrow.MetadataFrom(tableMeta)
+	schema := arrow.NewSchema(fields, &metadata)
+
+	record := array.NewRecord(schema, cols, int64(cols[0].Len()))
+	defer record.Release()
+
+	// the file writer needs to seek, like in the SDK
+	fb := filebuffer.New(nil)
+	fw, err := ipc.NewFileWriter(fb, ipc.WithSchema(schema))
+	if err != nil {
+		return nil, err
+	}
+	if err := fw.Write(record); err != nil {
+		return nil, err
+	}
+	if err := fw.Close(); err != nil {
+		return nil, err
+	}
+	return fb.Buff.Bytes(), nil
+}
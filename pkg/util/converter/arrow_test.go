@@ -0,0 +1,72 @@
+package converter
+
+import (
+	"os"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPrometheusStyleResultArrow(t *testing.T) {
+	for _, name := range []string{"prom-matrix", "prom-matrix-with-nans", "prom-vector", "prom-exemplars-a", "prom-matrix-histogram-partitioned"} {
+		t.Run(name, func(t *testing.T) {
+			for _, opt := range []Options{
+				{},
+				{NonFiniteValues: NonFiniteNull},
+				{NumericVector: true, DropRepeatedValues: true},
+			} {
+				body, err := os.ReadFile("testdata/" + name + ".json")
+				require.NoError(t, err)
+
+				expected := ReadPrometheusStyleResult(jsoniter.ParseBytes(jsoniter.ConfigDefault, body), opt)
+				rsp := ReadPrometheusStyleResultArrow(jsoniter.ParseBytes(jsoniter.ConfigDefault, body), opt)
+				require.NoError(t, rsp.Error)
+				require.Len(t, rsp.Frames, len(expected.Frames))
+
+				requireSameArrowFrames(t, expected.Frames, rsp)
+			}
+		})
+	}
+
+	t.Run("frames changed after reading", func(t *testing.T) {
+		body := matrixResponse(5)
+		expected := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{})
+		rsp := ReadPrometheusStyleResultArrow(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{})
+		require.NoError(t, rsp.Error)
+
+		for _, frames := range []data.Frames{expected.Frames, rsp.Frames} {
+			for _, frame := range frames {
+				frame.RefID = "A"
+				frame.Fields[1].Config = &data.FieldConfig{DisplayNameFromDS: "series"}
+			}
+		}
+		// the series are only in the columns
+		require.Equal(t, 0, rsp.Frames[0].Rows())
+		requireSameArrowFrames(t, expected.Frames, rsp)
+	})
+}
+
+func requireSameArrowFrames(t *testing.T, expected data.Frames, rsp *ArrowResponse) {
+	t.Helper()
+
+	want, err := expected.MarshalArrow()
+	require.NoError(t, err)
+	got, err := rsp.MarshalArrow()
+	require.NoError(t, err)
+	require.Len(t, got, len(want))
+
+	for i := range want {
+		wantFrame, err := data.UnmarshalArrowFrame(want[i])
+		require.NoError(t, err)
+		gotFrame, err := data.UnmarshalArrowFrame(got[i])
+		require.NoError(t, err)
+		// NaN values are never equal, the JSON has them as entities
+		wantJSON, err := data.FrameToJSON(wantFrame, data.IncludeAll)
+		require.NoError(t, err)
+		gotJSON, err := data.FrameToJSON(gotFrame, data.IncludeAll)
+		require.NoError(t, err)
+		require.JSONEq(t, string(wantJSON), string(gotJSON))
+	}
+}
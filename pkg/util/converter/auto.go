@@ -0,0 +1,213 @@
+package converter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// ResponseFormat is a response shape that can be detected by ReadAuto
+type ResponseFormat string
+
+const (
+	FormatUnknown ResponseFormat = ""
+	// FormatPrometheus is the { "status": ..., "data": ... } envelope used by prometheus and loki queries
+	FormatPrometheus ResponseFormat = "prometheus"
+	// FormatLokiStreams is a bare { "streams": [ ... ] } object, like loki push requests and tail messages
+	FormatLokiStreams ResponseFormat = "lokiStreams"
+	// FormatRemoteRead is a snappy compressed prometheus remote read response
+	FormatRemoteRead ResponseFormat = "remoteRead"
+	// FormatNDJSON is one JSON document per line, either frames (see JSONFrameWriter) or any of the above
+	FormatNDJSON ResponseFormat = "ndjson"
+)
+
+var errUnknownFormat = errors.New("unsupported response format")
+
+type jsonShape int
+
+const (
+	shapeInvalid jsonShape = iota
+	shapeEnvelope
+	shapeStreams
+	shapeFrame
+)
+
+// DetectFormat sniffs the shape of a response body
+func DetectFormat(body []byte) ResponseFormat {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	if len(trimmed) == 0 {
+		return FormatUnknown
+	}
+	if trimmed[0] != '{' {
+		if _, err := decodeRemoteRead(body); err == nil {
+			return FormatRemoteRead
+		}
+		return FormatUnknown
+	}
+
+	shape, more := sniffJSON(trimmed)
+	switch {
+	case shape == shapeInvalid:
+		return FormatUnknown
+	case more || shape == shapeFrame:
+		return FormatNDJSON
+	case shape == shapeStreams:
+		return FormatLokiStreams
+	default:
+		return FormatPrometheus
+	}
+}
+
+// ReadAuto reads a response of any of the detected formats, for datasources proxying
+// backends that do not all answer the same way. Remote read samples are not aligned
+// to a step, so they are always returned as multi frames.
+func ReadAuto(r io.Reader, opt Options) backend.DataResponse {
+	body, err := io.ReadAll(LimitBody(r, opt.MaxBodyBytes))
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	opt.MemoryStats.addBody(len(body))
+	if opt.MaxRows > 0 && opt.rowLimit == nil {
+		opt.rowLimit = &rowLimit{max: opt.MaxRows}
+	}
+
+	switch DetectFormat(body) {
+	case FormatPrometheus:
+		return ReadPrometheusStyleResult(jsoniter.ParseBytes(jsoniter.ConfigDefault, body), opt)
+	case FormatLokiStreams:
+		return readLokiStreams(body, opt)
+	case FormatRemoteRead:
+		return readRemoteRead(body, opt)
+	case FormatNDJSON:
+		return readNDJSON(body, opt)
+	default:
+		return backend.DataResponse{Error: errUnknownFormat}
+	}
+}
+
+// sniffJSON reports the shape of the first object in b, and if another object follows it
+func sniffJSON(b []byte) (jsonShape, bool) {
+	iter := jsoniter.ParseBytes(jsoniter.ConfigDefault, b)
+	shape := shapeEnvelope
+	for key := iter.ReadObject(); key != ""; key = iter.ReadObject() {
+		switch key {
+		case "streams":
+			// the index stats have a number of streams
+			if iter.WhatIsNext() == jsoniter.ArrayValue {
+				shape = shapeStreams
+			}
+		case "schema":
+			shape = shapeFrame
+		}
+		iter.Skip()
+	}
+	if iter.Error != nil {
+		return shapeInvalid, false
+	}
+	return shape, iter.WhatIsNext() == jsoniter.ObjectValue
+}
+
+func readLokiStreams(body []byte, opt Options) backend.DataResponse {
+	rsp := backend.DataResponse{}
+	iter := jsoniter.ParseBytes(jsoniter.ConfigDefault, body)
+	attachUnknownKeys(iter, &opt)
+	for key := iter.ReadObject(); key != ""; key = iter.ReadObject() {
+		if key != "streams" {
+			iter.Skip()
+			skippedKey(iter, "streams", key)
+			continue
+		}
+		rsp = readStream(iter, opt)
+	}
+	if iter.Error != nil && rsp.Error == nil {
+		rsp.Error = iter.Error
+	}
+	if err := opt.unknownKeys.report(rsp.Frames, opt.Strict); err != nil && rsp.Error == nil {
+		rsp.Error = err
+	}
+	if opt.rowLimit != nil && opt.rowLimit.truncated {
+		addTruncatedNotice(rsp.Frames, opt.rowLimit.max)
+	}
+	finishFrames(rsp.Frames, opt)
+	return rsp
+}
+
+func readNDJSON(body []byte, opt Options) backend.DataResponse {
+	rsp := backend.DataResponse{}
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		var lineRsp backend.DataResponse
+		shape, _ := sniffJSON(line)
+		switch shape {
+		case shapeFrame:
+			frame := &data.Frame{}
+			if err := frame.UnmarshalJSON(line); err != nil {
+				lineRsp.Error = err
+			} else {
+				lineRsp.Frames = data.Frames{frame}
+			}
+		case shapeStreams:
+			lineRsp = readLokiStreams(line, opt)
+		case shapeEnvelope:
+			lineRsp = ReadPrometheusStyleResult(jsoniter.ParseBytes(jsoniter.ConfigDefault, line), opt)
+		default:
+			lineRsp.Error = errUnknownFormat
+		}
+
+		// keep the frames of the other lines
+		rsp.Frames = append(rsp.Frames, lineRsp.Frames...)
+		if lineRsp.Error != nil && rsp.Error == nil {
+			rsp.Error = lineRsp.Error
+		}
+	}
+	return rsp
+}
+
+func decodeRemoteRead(body []byte) (*prompb.ReadResponse, error) {
+	b, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, err
+	}
+	res := &prompb.ReadResponse{}
+	if err := res.Unmarshal(b); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func readRemoteRead(body []byte, opt Options) backend.DataResponse {
+	res, err := decodeRemoteRead(body)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	rsp := backend.DataResponse{}
+	for _, result := range res.Results {
+		for _, ts := range result.Timeseries {
+			frame := newRemoteReadFrame(ts.Labels, opt)
+			for _, s := range ts.Samples {
+				appendRemoteReadSample(frame, s.Timestamp, s.Value, opt)
+			}
+			rsp.Frames = append(rsp.Frames, frame)
+		}
+	}
+
+	if len(opt.MetricMetadata) > 0 {
+		attachMetricMetadata(rsp.Frames, opt.MetricMetadata)
+	}
+	if opt.rowLimit != nil && opt.rowLimit.truncated {
+		addTruncatedNotice(rsp.Frames, opt.rowLimit.max)
+	}
+	finishFrames(rsp.Frames, opt)
+	return rsp
+}
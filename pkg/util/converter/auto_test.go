@@ -0,0 +1,87 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAuto(t *testing.T) {
+	t.Run("prometheus", func(t *testing.T) {
+		body := matrixResponse(2)
+		require.Equal(t, FormatPrometheus, DetectFormat([]byte(body)))
+
+		rsp := ReadAuto(strings.NewReader(body), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 2)
+	})
+
+	t.Run("index stats are not streams", func(t *testing.T) {
+		body := `{"streams":2,"chunks":2246,"bytes":7390745,"entries":25866}`
+		require.Equal(t, FormatPrometheus, DetectFormat([]byte(body)))
+	})
+
+	t.Run("loki streams", func(t *testing.T) {
+		body := `{"streams":[{"stream":{"app":"a"},"values":[["1645030244810757120","line 1"],["1645030244810757121","line 2"]]}]}`
+		require.Equal(t, FormatLokiStreams, DetectFormat([]byte(body)))
+
+		rsp := ReadAuto(strings.NewReader(body), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		require.Equal(t, 2, rsp.Frames[0].Rows())
+	})
+
+	t.Run("remote read", func(t *testing.T) {
+		res := &prompb.ReadResponse{Results: []*prompb.QueryResult{{
+			Timeseries: []*prompb.TimeSeries{{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "grafana"}},
+				Samples: []prompb.Sample{{Timestamp: 1641889530000, Value: 1}, {Timestamp: 1641889545000, Value: 0}},
+			}},
+		}}}
+		b, err := res.Marshal()
+		require.NoError(t, err)
+		body := snappy.Encode(nil, b)
+		require.Equal(t, FormatRemoteRead, DetectFormat(body))
+
+		rsp := ReadAuto(bytes.NewReader(body), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		require.Equal(t, "grafana", rsp.Frames[0].Fields[1].Labels["job"])
+		require.Equal(t, 2, rsp.Frames[0].Rows())
+		require.Equal(t, time.Unix(1641889545, 0).UTC(), rsp.Frames[0].Fields[0].At(1))
+	})
+
+	t.Run("ndjson frames", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := StreamPrometheusStyleResult(context.Background(), strings.NewReader(matrixResponse(3)), Options{}, NewJSONFrameWriter(context.Background(), buf))
+		require.NoError(t, err)
+		require.Equal(t, FormatNDJSON, DetectFormat(buf.Bytes()))
+
+		rsp := ReadAuto(buf, Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 3)
+		require.Equal(t, "1", rsp.Frames[1].Fields[1].Labels["i"])
+	})
+
+	t.Run("ndjson responses", func(t *testing.T) {
+		body := matrixResponse(1) + "\n" + matrixResponse(2) + "\n"
+		require.Equal(t, FormatNDJSON, DetectFormat([]byte(body)))
+
+		rsp := ReadAuto(strings.NewReader(body), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 3)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		for _, body := range []string{"", "not a response", "{"} {
+			require.Equal(t, FormatUnknown, DetectFormat([]byte(body)))
+			require.Error(t, ReadAuto(strings.NewReader(body), Options{}).Error)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BodyLimitError is returned when a response is larger than Options.MaxBodyBytes. The
+// message is meant for the users, like "response exceeded 64MB limit".
+type BodyLimitError struct {
+	Limit int64
+}
+
+func (e *BodyLimitError) Error() string {
+	return fmt.Sprintf("response exceeded %s limit", formatByteLimit(e.Limit))
+}
+
+func formatByteLimit(n int64) string {
+	switch {
+	case n >= 1<<30 && n%(1<<30) == 0:
+		return fmt.Sprintf("%dGB", n>>30)
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", n>>10)
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
+
+// LimitBody returns a reader failing with a BodyLimitError as soon as more than max bytes
+// are read from r, for the iterators passed to ReadPrometheusStyleResult. The reading stops
+// there, the rest of the body is never buffered. r is returned when max is not positive.
+func LimitBody(r io.Reader, max int64) io.Reader {
+	if max <= 0 {
+		return r
+	}
+	return &limitedBody{r: r, limit: max}
+}
+
+type limitedBody struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.read > b.limit {
+		return 0, &BodyLimitError{Limit: b.limit}
+	}
+	// one byte more than the limit tells a body of the exact limit from a larger one
+	if max := b.limit - b.read + 1; int64(len(p)) > max {
+		p = p[:max]
+	}
+	n, err := b.r.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		return 0, &BodyLimitError{Limit: b.limit}
+	}
+	return n, err
+}
+
+// bodyLimitError returns the error of the iterator when it stopped at the body limit
+func bodyLimitError(err error) error {
+	var limitErr *BodyLimitError
+	if errors.As(err, &limitErr) {
+		return limitErr
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLimit(t *testing.T) {
+	body := matrixResponse(100)
+
+	t.Run("error message", func(t *testing.T) {
+		require.Equal(t, "response exceeded 64MB limit", (&BodyLimitError{Limit: 64 << 20}).Error())
+		require.Equal(t, "response exceeded 1GB limit", (&BodyLimitError{Limit: 1 << 30}).Error())
+		require.Equal(t, "response exceeded 512KB limit", (&BodyLimitError{Limit: 512 << 10}).Error())
+		require.Equal(t, "response exceeded 1000 bytes limit", (&BodyLimitError{Limit: 1000}).Error())
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		b, err := io.ReadAll(LimitBody(strings.NewReader("abcd"), 4))
+		require.NoError(t, err)
+		require.Equal(t, "abcd", string(b))
+
+		_, err = io.ReadAll(LimitBody(strings.NewReader("abcde"), 4))
+		require.Equal(t, &BodyLimitError{Limit: 4}, err)
+
+		r := strings.NewReader("abcd")
+		require.Equal(t, r, LimitBody(r, 0))
+	})
+
+	t.Run("iterator", func(t *testing.T) {
+		iter := jsoniter.Parse(jsoniter.ConfigDefault, LimitBody(strings.NewReader(body), 1024), 64)
+		rsp := ReadPrometheusStyleResult(iter, Options{})
+		require.Equal(t, &BodyLimitError{Limit: 1024}, rsp.Error)
+		require.Empty(t, rsp.Frames)
+
+		iter = jsoniter.Parse(jsoniter.ConfigDefault, LimitBody(strings.NewReader(body), int64(len(body))), 64)
+		rsp = ReadPrometheusStyleResult(iter, Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 100)
+	})
+
+	t.Run("readers of bodies", func(t *testing.T) {
+		opt := Options{MaxBodyBytes: 1024}
+		rsp := ReadAuto(strings.NewReader(body), opt)
+		require.Equal(t, &BodyLimitError{Limit: 1024}, rsp.Error)
+
+		rsp = ReadExposition(bytes.Repeat([]byte("up 1\n"), 1000), "text/plain", opt)
+		require.Equal(t, &BodyLimitError{Limit: 1024}, rsp.Error)
+
+		err := StreamPrometheusStyleResult(context.Background(), strings.NewReader(body), opt, FrameWriterFunc(func(frame *data.Frame) error {
+			return nil
+		}))
+		require.Equal(t, &BodyLimitError{Limit: 1024}, err)
+	})
+}
@@ -0,0 +1,255 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// SeriesLimitMode configures what happens to the series beyond MaxSeries or MaxLabelValues
+type SeriesLimitMode string
+
+const (
+	// SeriesLimitDrop drops the series
+	SeriesLimitDrop SeriesLimitMode = ""
+	// SeriesLimitOther sums the series into a single series displayed as "Other"
+	SeriesLimitOther SeriesLimitMode = "other"
+)
+
+const otherSeriesName = "Other"
+
+// seriesLimit decides which series are kept, in the order of the response
+type seriesLimit struct {
+	maxSeries      int
+	maxLabelValues int
+	kept           int
+	total          int
+	// the values of every label seen in the kept series
+	values map[string]map[string]bool
+}
+
+// keep reports if the series fits. A series is dropped when MaxSeries are already kept,
+// or when one of its labels has a new value while that label has MaxLabelValues already.
+func (l *seriesLimit) keep(labels data.Labels) bool {
+	l.total++
+	if l.maxSeries > 0 && l.kept >= l.maxSeries {
+		return false
+	}
+	if l.maxLabelValues > 0 {
+		for k, v := range labels {
+			if seen := l.values[k]; !seen[v] && len(seen) >= l.maxLabelValues {
+				return false
+			}
+		}
+		for k, v := range labels {
+			if l.values[k] == nil {
+				l.values[k] = map[string]bool{}
+			}
+			l.values[k][v] = true
+		}
+	}
+	l.kept++
+	return true
+}
+
+// limitSeries applies MaxSeries and MaxLabelValues to the series of matrix and vector frames.
+// The series beyond the limits are dropped or summed into an "Other" series, and a notice
+// is returned for the frames when some were.
+func limitSeries(frames data.Frames, opt Options) (data.Frames, *data.Notice) {
+	if opt.MaxSeries <= 0 && opt.MaxLabelValues <= 0 {
+		return frames, nil
+	}
+	limit := &seriesLimit{
+		maxSeries:      opt.MaxSeries,
+		maxLabelValues: opt.MaxLabelValues,
+		values:         map[string]map[string]bool{},
+	}
+
+	var other *otherSeries
+	kept := make(data.Frames, 0, len(frames))
+	for _, frame := range frames {
+		if !isLimitedFrame(frame) {
+			kept = append(kept, frame)
+			continue
+		}
+		timeIdx := timeFieldIndex(frame)
+
+		// every value field of a wide frame is a series
+		if frame.Meta.Type == data.FrameTypeTimeSeriesWide || frame.Meta.Type == data.FrameTypeNumericWide {
+			fields := make([]*data.Field, 0, len(frame.Fields))
+			var dropped []*data.Field
+			for i, f := range frame.Fields {
+				if i == timeIdx || !f.Type().Numeric() || limit.keep(f.Labels) {
+					fields = append(fields, f)
+					continue
+				}
+				dropped = append(dropped, f)
+			}
+			if len(dropped) > 0 && opt.SeriesLimit == SeriesLimitOther {
+				fields = append(fields, sumFields(dropped, frame.Rows()))
+			}
+			frame.Fields = fields
+			kept = append(kept, frame)
+			continue
+		}
+
+		valueIdx := valueFieldIndex(frame, timeIdx)
+		if valueIdx < 0 || limit.keep(frame.Fields[valueIdx].Labels) {
+			kept = append(kept, frame)
+			continue
+		}
+		if opt.SeriesLimit == SeriesLimitOther {
+			if other == nil {
+				other = newOtherSeries(frame, timeIdx >= 0)
+			}
+			other.add(frame, timeIdx, valueIdx)
+		}
+	}
+	if other != nil {
+		kept = append(kept, other.frame())
+	}
+
+	if limit.kept == limit.total {
+		return kept, nil
+	}
+	text := fmt.Sprintf("Showing %d of %d series, the others were dropped", limit.kept, limit.total)
+	if opt.SeriesLimit == SeriesLimitOther {
+		text = fmt.Sprintf("Showing %d of %d series, the others were summed into %s", limit.kept, limit.total, otherSeriesName)
+	}
+	return kept, &data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     text,
+	}
+}
+
+// isLimitedFrame reports if the frame holds series of a matrix or vector, the
+// heatmaps and exemplars of the series are left alone
+func isLimitedFrame(frame *data.Frame) bool {
+	if frame.Meta == nil {
+		return false
+	}
+	switch frameResultType(frame) {
+	case "matrix", "vector":
+	default:
+		return false
+	}
+	switch frame.Meta.Type {
+	case data.FrameTypeTimeSeriesMulti, data.FrameTypeTimeSeriesWide, data.FrameTypeNumericMulti, data.FrameTypeNumericWide:
+		return true
+	}
+	return false
+}
+
+func valueFieldIndex(frame *data.Frame, timeIdx int) int {
+	for i, f := range frame.Fields {
+		if i != timeIdx && f.Type().Numeric() {
+			return i
+		}
+	}
+	return -1
+}
+
+// sumFields sums the value fields of a wide frame row by row, rows where
+// all the values are null stay null
+func sumFields(fields []*data.Field, rows int) *data.Field {
+	sum := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, rows)
+	sum.Name = data.TimeSeriesValueFieldName
+	sum.Config = &data.FieldConfig{DisplayNameFromDS: otherSeriesName}
+	for i := 0; i < rows; i++ {
+		var total *float64
+		for _, f := range fields {
+			v, err := f.NullableFloatAt(i)
+			if err != nil || v == nil {
+				continue
+			}
+			if total == nil {
+				total = new(float64)
+			}
+			*total += *v
+		}
+		sum.Set(i, total)
+	}
+	return sum
+}
+
+// otherSeries sums the samples of the dropped multi frames by timestamp
+type otherSeries struct {
+	resultType string
+	frameType  data.FrameType
+	hasTime    bool
+	index      map[int64]int
+	times      []time.Time
+	sums       []float64
+}
+
+func newOtherSeries(frame *data.Frame, hasTime bool) *otherSeries {
+	return &otherSeries{
+		resultType: frameResultType(frame),
+		frameType:  frame.Meta.Type,
+		hasTime:    hasTime,
+		index:      map[int64]int{},
+	}
+}
+
+func (o *otherSeries) add(frame *data.Frame, timeIdx, valueIdx int) {
+	valueField := frame.Fields[valueIdx]
+	for i := 0; i < valueField.Len(); i++ {
+		v, err := valueField.NullableFloatAt(i)
+		if err != nil || v == nil {
+			continue
+		}
+		// numeric frames have a single value, summed at the zero time
+		var t time.Time
+		if timeIdx >= 0 {
+			switch tv := frame.Fields[timeIdx].At(i).(type) {
+			case time.Time:
+				t = tv
+			case *time.Time:
+				if tv == nil {
+					continue
+				}
+				t = *tv
+			}
+		}
+		ns := t.UnixNano()
+		idx, ok := o.index[ns]
+		if !ok {
+			idx = len(o.sums)
+			o.index[ns] = idx
+			o.times = append(o.times, t)
+			o.sums = append(o.sums, 0)
+		}
+		o.sums[idx] += *v
+	}
+}
+
+func (o *otherSeries) frame() *data.Frame {
+	order := make([]int, len(o.sums))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return o.times[order[i]].Before(o.times[order[j]])
+	})
+
+	times := make([]time.Time, len(order))
+	sums := make([]float64, len(order))
+	for i, idx := range order {
+		times[i] = o.times[idx]
+		sums[i] = o.sums[idx]
+	}
+
+	valueField := data.NewField(data.TimeSeriesValueFieldName, nil, sums)
+	valueField.Config = &data.FieldConfig{DisplayNameFromDS: otherSeriesName}
+	frame := data.NewFrame("", valueField)
+	if o.hasTime {
+		frame.Fields = append([]*data.Field{data.NewField(data.TimeSeriesTimeFieldName, nil, times)}, frame.Fields...)
+	}
+	frame.Meta = &data.FrameMeta{
+		Type:   o.frameType,
+		Custom: resultTypeToCustomMeta(o.resultType),
+	}
+	return frame
+}
@@ -0,0 +1,96 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+const cardinalityResponse = `{"status":"success","data":{"resultType":"matrix","result":[
+	{"metric":{"pod":"a","zone":"x"},"values":[[1,"1"],[2,"2"]]},
+	{"metric":{"pod":"b","zone":"x"},"values":[[1,"10"],[2,"20"]]},
+	{"metric":{"pod":"c","zone":"y"},"values":[[1,"100"],[3,"300"]]},
+	{"metric":{"pod":"d","zone":"z"},"values":[[2,"1000"]]}
+]}}`
+
+func TestSeriesLimit(t *testing.T) {
+	read := func(opt Options) ([]data.Labels, data.Frames) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, cardinalityResponse), opt)
+		require.NoError(t, rsp.Error)
+		labels := []data.Labels{}
+		for _, frame := range rsp.Frames {
+			for _, f := range frame.Fields[1:] {
+				labels = append(labels, f.Labels)
+			}
+		}
+		return labels, rsp.Frames
+	}
+
+	t.Run("no limits", func(t *testing.T) {
+		labels, frames := read(Options{})
+		require.Len(t, labels, 4)
+		require.Empty(t, frames[0].Meta.Notices)
+	})
+
+	t.Run("max series", func(t *testing.T) {
+		labels, frames := read(Options{MaxSeries: 2})
+		require.Equal(t, []data.Labels{{"pod": "a", "zone": "x"}, {"pod": "b", "zone": "x"}}, labels)
+		require.Equal(t, "Showing 2 of 4 series, the others were dropped", frames[0].Meta.Notices[0].Text)
+		require.Equal(t, data.NoticeSeverityWarning, frames[1].Meta.Notices[0].Severity)
+	})
+
+	t.Run("max label values", func(t *testing.T) {
+		// the zone y is the second value, z the third
+		labels, _ := read(Options{MaxLabelValues: 2})
+		require.Equal(t, []data.Labels{{"pod": "a", "zone": "x"}, {"pod": "b", "zone": "x"}}, labels)
+
+		labels, _ = read(Options{MaxLabelValues: 3})
+		require.Len(t, labels, 3)
+	})
+
+	t.Run("other series", func(t *testing.T) {
+		_, frames := read(Options{MaxSeries: 2, SeriesLimit: SeriesLimitOther})
+		require.Len(t, frames, 3)
+		other := frames[2]
+		require.Equal(t, "Other", other.Fields[1].Config.DisplayNameFromDS)
+		require.Equal(t, data.FrameTypeTimeSeriesMulti, other.Meta.Type)
+		require.Equal(t, "matrix", frameResultType(other))
+		require.Equal(t, []time.Time{time.UnixMilli(1000).UTC(), time.UnixMilli(2000).UTC(), time.UnixMilli(3000).UTC()},
+			[]time.Time{other.Fields[0].At(0).(time.Time), other.Fields[0].At(1).(time.Time), other.Fields[0].At(2).(time.Time)})
+		require.Equal(t, []float64{100, 1000, 300}, []float64{other.Fields[1].At(0).(float64), other.Fields[1].At(1).(float64), other.Fields[1].At(2).(float64)})
+		require.Equal(t, "Showing 2 of 4 series, the others were summed into Other", other.Meta.Notices[0].Text)
+	})
+
+	t.Run("wide frame", func(t *testing.T) {
+		labels, frames := read(Options{MatrixWideSeries: true, MaxSeries: 1, SeriesLimit: SeriesLimitOther})
+		require.Len(t, frames, 1)
+		require.Equal(t, data.Labels{"pod": "a", "zone": "x"}, labels[0])
+
+		other := frames[0].Fields[2]
+		require.Equal(t, "Other", other.Config.DisplayNameFromDS)
+		values := []*float64{}
+		for i := 0; i < other.Len(); i++ {
+			values = append(values, other.At(i).(*float64))
+		}
+		require.Equal(t, 110.0, *values[0])
+		require.Equal(t, 1020.0, *values[1])
+		require.Equal(t, 300.0, *values[2])
+	})
+
+	t.Run("numeric vector", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"pod":"a"},"value":[1,"1"]},
+			{"metric":{"pod":"b"},"value":[1,"2"]},
+			{"metric":{"pod":"c"},"value":[1,"3"]}
+		]}}`), Options{NumericVector: true, MaxSeries: 1, SeriesLimit: SeriesLimitOther})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 2)
+		other := rsp.Frames[1]
+		require.Equal(t, data.FrameTypeNumericMulti, other.Meta.Type)
+		require.Len(t, other.Fields, 1)
+		require.Equal(t, 5.0, other.Fields[0].At(0))
+	})
+}
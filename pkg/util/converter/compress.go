@@ -0,0 +1,112 @@
+package converter
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/golang/snappy"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/klauspost/compress/zstd"
+)
+
+const bodyIteratorBufferSize = 32 * 1024
+
+// The decoders and iterator buffers are reused across responses, so asking the server
+// for compressed payloads does not cost an allocation of the decoder state every query.
+var (
+	iteratorPool = sync.Pool{
+		New: func() interface{} {
+			return jsoniter.Parse(jsoniter.ConfigDefault, nil, bodyIteratorBufferSize)
+		},
+	}
+	gzipPool   sync.Pool
+	snappyPool = sync.Pool{
+		New: func() interface{} {
+			return snappy.NewReader(nil)
+		},
+	}
+	zstdPool sync.Pool
+)
+
+// NewBodyIterator returns an iterator reading a response body compressed with the
+// given Content-Encoding: gzip, snappy (the framed format, since the block format can
+// not be streamed), zstd or identity. The body is decoded while it is parsed, it is
+// never held in memory as a whole.
+//
+// The release function puts the decoder and iterator back in their pools, it must be
+// called once the iterator is no longer used. It does not close the body.
+func NewBodyIterator(body io.Reader, contentEncoding string) (*jsoniter.Iterator, func(), error) {
+	var (
+		r       io.Reader
+		release func()
+	)
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "", "identity":
+		r = body
+		release = func() {}
+
+	case "gzip", "x-gzip":
+		var gz *gzip.Reader
+		if pooled, ok := gzipPool.Get().(*gzip.Reader); ok {
+			gz = pooled
+			if err := gz.Reset(body); err != nil {
+				gzipPool.Put(gz)
+				return nil, nil, err
+			}
+		} else {
+			var err error
+			if gz, err = gzip.NewReader(body); err != nil {
+				return nil, nil, err
+			}
+		}
+		r = gz
+		release = func() { gzipPool.Put(gz) }
+
+	case "snappy":
+		sr := snappyPool.Get().(*snappy.Reader)
+		sr.Reset(body)
+		r = sr
+		release = func() {
+			sr.Reset(nil)
+			snappyPool.Put(sr)
+		}
+
+	case "zstd":
+		var zr *zstd.Decoder
+		if pooled, ok := zstdPool.Get().(*zstd.Decoder); ok {
+			zr = pooled
+			if err := zr.Reset(body); err != nil {
+				zstdPool.Put(zr)
+				return nil, nil, err
+			}
+		} else {
+			// a single goroutine, the iterator reads sequentially anyway
+			var err error
+			if zr, err = zstd.NewReader(body, zstd.WithDecoderConcurrency(1)); err != nil {
+				return nil, nil, err
+			}
+		}
+		r = zr
+		release = func() {
+			// drops the reference to the body
+			_ = zr.Reset(nil)
+			zstdPool.Put(zr)
+		}
+
+	default:
+		return nil, nil, fmt.Errorf("unsupported content encoding: %s", contentEncoding)
+	}
+
+	iter := iteratorPool.Get().(*jsoniter.Iterator)
+	iter.Reset(r)
+	iter.Error = nil
+	return iter, func() {
+		iter.Reset(nil)
+		iter.Attachment = nil
+		iteratorPool.Put(iter)
+		release()
+	}, nil
+}
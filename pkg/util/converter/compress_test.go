@@ -0,0 +1,63 @@
+package converter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/golang/snappy"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBodyIterator(t *testing.T) {
+	body := []byte(matrixResponse(2))
+
+	compressed := map[string][]byte{
+		"":         body,
+		"identity": body,
+	}
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	_, err := gz.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	compressed["gzip"] = buf.Bytes()
+
+	buf = &bytes.Buffer{}
+	sw := snappy.NewBufferedWriter(buf)
+	_, err = sw.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, sw.Close())
+	compressed["snappy"] = buf.Bytes()
+
+	zw, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	compressed["zstd"] = zw.EncodeAll(body, nil)
+
+	expected := ReadPrometheusStyleResult(jsoniter.ParseBytes(jsoniter.ConfigDefault, body), Options{})
+	require.NoError(t, expected.Error)
+
+	for encoding, b := range compressed {
+		// twice, the second time with the pooled decoders
+		for i := 0; i < 2; i++ {
+			iter, release, err := NewBodyIterator(bytes.NewReader(b), encoding)
+			require.NoError(t, err, encoding)
+			rsp := ReadPrometheusStyleResult(iter, Options{})
+			release()
+			require.NoError(t, rsp.Error, encoding)
+			require.Equal(t, expected.Frames, rsp.Frames, encoding)
+		}
+	}
+
+	t.Run("unsupported encoding", func(t *testing.T) {
+		_, _, err := NewBodyIterator(bytes.NewReader(body), "br")
+		require.Error(t, err)
+	})
+
+	t.Run("invalid gzip", func(t *testing.T) {
+		_, _, err := NewBodyIterator(bytes.NewReader(body), "gzip")
+		require.Error(t, err)
+	})
+}
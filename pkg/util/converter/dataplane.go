@@ -0,0 +1,60 @@
+package converter
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// The log frame type of the dataplane contract, the plugin sdk does not define it yet
+const frameTypeLogLines data.FrameType = "log-lines"
+
+// The field names of log frames in the dataplane contract
+var dataplaneLogFieldNames = map[string]string{
+	"__labels":     "labels",
+	"Time":         "timestamp",
+	"Line":         "body",
+	"TS":           "tsNs",
+	levelFieldName: "severity",
+}
+
+// applyDataplane sets the frame types and field names of the dataplane contract:
+// time series frames get a type version, instant vectors become numeric frames and
+// log frames get the log-lines type. Other frames, like heatmaps and tables, are unchanged.
+func applyDataplane(frames data.Frames, opt Options) {
+	if !opt.Dataplane {
+		return
+	}
+	for _, frame := range frames {
+		if isLogFrame(frame) {
+			for _, f := range frame.Fields {
+				if name, ok := dataplaneLogFieldNames[f.Name]; ok {
+					f.Name = name
+				}
+			}
+			if frame.Meta == nil {
+				frame.Meta = &data.FrameMeta{}
+			}
+			frame.Meta.Type = frameTypeLogLines
+			frame.Meta.TypeVersion = &data.FrameTypeVersion{0, 0}
+			continue
+		}
+		if frame.Meta == nil {
+			continue
+		}
+
+		if custom, ok := frame.Meta.Custom.(map[string]string); ok && custom["resultType"] == "vector" {
+			toNumericFrame(frame)
+		}
+		switch frame.Meta.Type {
+		case data.FrameTypeTimeSeriesMulti, data.FrameTypeTimeSeriesWide, data.FrameTypeNumericMulti, data.FrameTypeNumericWide:
+			frame.Meta.TypeVersion = &data.FrameTypeVersion{0, 1}
+		}
+	}
+}
+
+// log frames are read by readStream, they start with the labels, time and line fields
+func isLogFrame(frame *data.Frame) bool {
+	return len(frame.Fields) >= 3 &&
+		frame.Fields[0].Name == "__labels" &&
+		frame.Fields[1].Name == "Time" &&
+		frame.Fields[2].Name == "Line"
+}
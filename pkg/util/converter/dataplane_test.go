@@ -0,0 +1,59 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataplane(t *testing.T) {
+	read := func(body string, opt Options) data.Frames {
+		opt.Dataplane = true
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		require.NoError(t, rsp.Error)
+		return rsp.Frames
+	}
+	vector := `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"job":"a"},"value":[1645029699,"1"]},
+		{"metric":{"job":"b"},"value":[1645029699,"2"]}
+	]}}`
+
+	t.Run("matrix", func(t *testing.T) {
+		for _, frame := range read(matrixResponse(2), Options{}) {
+			require.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+			require.Equal(t, &data.FrameTypeVersion{0, 1}, frame.Meta.TypeVersion)
+		}
+	})
+
+	t.Run("vector", func(t *testing.T) {
+		frames := read(vector, Options{})
+		require.Len(t, frames, 2)
+		for _, frame := range frames {
+			require.Equal(t, data.FrameTypeNumericMulti, frame.Meta.Type)
+			require.Equal(t, &data.FrameTypeVersion{0, 1}, frame.Meta.TypeVersion)
+			require.Len(t, frame.Fields, 1)
+		}
+
+		frames = read(vector, Options{VectorWideSeries: true})
+		require.Len(t, frames, 1)
+		require.Equal(t, data.FrameTypeNumericWide, frames[0].Meta.Type)
+		require.Len(t, frames[0].Fields, 2)
+	})
+
+	t.Run("streams", func(t *testing.T) {
+		frames := read(`{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"a","level":"error"},"values":[["1645030244810757120","line 1"]]}
+		]}}`, Options{LevelDetection: &LevelDetection{}})
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Equal(t, data.FrameType("log-lines"), frame.Meta.Type)
+		require.Equal(t, &data.FrameTypeVersion{0, 0}, frame.Meta.TypeVersion)
+		names := []string{}
+		for _, f := range frame.Fields {
+			names = append(names, f.Name)
+		}
+		require.Equal(t, []string{"labels", "timestamp", "body", "tsNs", "severity"}, names)
+	})
+}
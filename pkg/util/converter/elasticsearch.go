@@ -0,0 +1,480 @@
+package converter
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const esDocCountName = "doc_count"
+
+// ReadElasticsearchSearch reads the response of an elasticsearch _search.
+//
+// The documents of hits.hits are returned in a "hits" table, with the _id, the _index and
+// the fields of the _source, nested objects are flattened to dotted names.
+//
+// The leaf buckets of the aggregations under a date_histogram are returned as series, the
+// date_histogram buckets, with a numeric key and a key_as_string, are the times. The keys
+// of the other bucket aggregations, like terms, are labels named after their aggregation,
+// and the doc_count and the metric aggregations of the leaf buckets are the values. The
+// values of multi-value metrics, like percentiles or stats, are named "<aggregation> <key>".
+// With MatrixWideSeries the series are joined in a single wide frame. The leaf buckets that
+// are not under a date_histogram are the rows of an "aggregations" table.
+func ReadElasticsearchSearch(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
+	attachUnknownKeys(iter, &opt)
+	rsp := readElasticsearchResponse(iter, opt)
+	if limitErr := bodyLimitError(iter.Error); limitErr != nil {
+		return backend.DataResponse{Error: limitErr}
+	}
+	if iter.Error != nil && rsp.Error == nil {
+		rsp.Error = iter.Error
+	}
+	if err := opt.unknownKeys.report(rsp.Frames, opt.Strict); err != nil && rsp.Error == nil {
+		rsp.Error = err
+	}
+	finishFrames(rsp.Frames, opt)
+	return rsp
+}
+
+// ReadElasticsearchMultiSearch reads the response of an elasticsearch _msearch, with
+// a response for every search like ReadElasticsearchSearch
+func ReadElasticsearchMultiSearch(iter *jsoniter.Iterator, opt Options) ([]backend.DataResponse, error) {
+	attachUnknownKeys(iter, &opt)
+	var responses []backend.DataResponse
+	var err error
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "responses":
+			for iter.ReadArray() {
+				responses = append(responses, readElasticsearchResponse(iter, opt))
+			}
+		case "error":
+			err = readElasticsearchError(iter)
+		case "took":
+			iter.Skip()
+		default:
+			iter.Skip()
+			skippedKey(iter, "ROOT", l1Field)
+		}
+	}
+
+	if limitErr := bodyLimitError(iter.Error); limitErr != nil {
+		return nil, limitErr
+	}
+	if iter.Error != nil {
+		return nil, iter.Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	frames := data.Frames{}
+	for _, rsp := range responses {
+		frames = append(frames, rsp.Frames...)
+	}
+	if err := opt.unknownKeys.report(frames, opt.Strict); err != nil {
+		return nil, err
+	}
+	finishFrames(frames, opt)
+	return responses, nil
+}
+
+func readElasticsearchResponse(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
+	rsp := backend.DataResponse{}
+	aggs := &esAggReader{
+		opt:    opt,
+		lookup: map[string]*esSeries{},
+	}
+	var hits *data.Frame
+	timedOut := false
+
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "hits":
+			hits = readElasticsearchHits(iter)
+		case "aggregations":
+			var metrics []esMetric
+			hasBuckets := false
+			for name := iter.ReadObject(); name != ""; name = iter.ReadObject() {
+				var b bool
+				metrics, b = aggs.readAgg(iter, name, nil, nil, metrics)
+				hasBuckets = hasBuckets || b
+			}
+			// metric aggregations of the whole index
+			if !hasBuckets && len(metrics) > 0 {
+				aggs.emit(nil, nil, metrics)
+			}
+		case "error":
+			rsp.Error = readElasticsearchError(iter)
+		case "timed_out":
+			timedOut = iter.ReadBool()
+		case "took", "status", "_shards", "_scroll_id", "terminated_early", "num_reduce_phases":
+			iter.Skip()
+		default:
+			iter.Skip()
+			skippedKey(iter, "ROOT", l1Field)
+		}
+	}
+	if rsp.Error != nil {
+		return rsp
+	}
+
+	if hits != nil {
+		rsp.Frames = append(rsp.Frames, hits)
+	}
+	frames, err := aggs.frames()
+	if err != nil {
+		rsp.Error = err
+	}
+	rsp.Frames = append(rsp.Frames, frames...)
+
+	if timedOut {
+		for _, frame := range rsp.Frames {
+			frame.AppendNotices(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     "The search timed out, the results may be incomplete",
+			})
+		}
+	}
+	return rsp
+}
+
+// readElasticsearchError reads the reason of an error, the error is an object
+// with a type and a reason, or a string for older versions
+func readElasticsearchError(iter *jsoniter.Iterator) error {
+	if iter.WhatIsNext() == jsoniter.StringValue {
+		return errors.New(iter.ReadString())
+	}
+	errorType, reason := "", ""
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "type":
+			errorType = iter.ReadString()
+		case "reason":
+			reason = iter.ReadString()
+		default:
+			iter.Skip()
+		}
+	}
+	if reason == "" {
+		reason = errorType
+	}
+	return errors.New(reason)
+}
+
+func readElasticsearchHits(iter *jsoniter.Iterator) *data.Frame {
+	table := newESTable()
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		if l1Field != "hits" {
+			// the total and the max_score
+			iter.Skip()
+			continue
+		}
+		for iter.ReadArray() {
+			for l2Field := iter.ReadObject(); l2Field != ""; l2Field = iter.ReadObject() {
+				switch l2Field {
+				case "_id", "_index":
+					v := iter.ReadString()
+					table.set(l2Field, data.FieldTypeNullableString, &v)
+				case "_source":
+					readElasticsearchSource(iter, "", table)
+				default:
+					iter.Skip()
+				}
+			}
+			table.endRow()
+		}
+	}
+
+	frame := data.NewFrame("hits", table.fields...)
+	frame.Meta = &data.FrameMeta{
+		Custom: resultTypeToCustomMeta("hits"),
+	}
+	return frame
+}
+
+// readElasticsearchSource adds the fields of a document to the row, arrays are JSON fields
+func readElasticsearchSource(iter *jsoniter.Iterator, prefix string, table *esTable) {
+	for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+		name := prefix + k
+		switch iter.WhatIsNext() {
+		case jsoniter.ObjectValue:
+			readElasticsearchSource(iter, name+".", table)
+		case jsoniter.StringValue:
+			v := iter.ReadString()
+			table.set(name, data.FieldTypeNullableString, &v)
+		case jsoniter.NumberValue:
+			v := iter.ReadFloat64()
+			table.set(name, data.FieldTypeNullableFloat64, &v)
+		case jsoniter.BoolValue:
+			v := iter.ReadBool()
+			table.set(name, data.FieldTypeNullableBool, &v)
+		case jsoniter.ArrayValue:
+			v := json.RawMessage(append([]byte(nil), iter.SkipAndReturnBytes()...))
+			table.set(name, data.FieldTypeNullableJSON, &v)
+		default:
+			iter.Skip()
+		}
+	}
+}
+
+// esTable adds rows of nullable fields, the fields missing in a row are null
+type esTable struct {
+	rows   int
+	fields []*data.Field
+	lookup map[string]*data.Field
+}
+
+func newESTable() *esTable {
+	return &esTable{lookup: map[string]*data.Field{}}
+}
+
+// set sets a value of the current row, a value of another type than the field is null
+func (t *esTable) set(name string, ft data.FieldType, v interface{}) {
+	f, ok := t.lookup[name]
+	if !ok {
+		f = data.NewFieldFromFieldType(ft, t.rows)
+		f.Name = name
+		t.lookup[name] = f
+		t.fields = append(t.fields, f)
+	}
+	if f.Type() != ft || f.Len() > t.rows {
+		return
+	}
+	f.Append(v)
+}
+
+func (t *esTable) endRow() {
+	t.rows++
+	for _, f := range t.fields {
+		if f.Len() < t.rows {
+			f.Extend(1)
+		}
+	}
+}
+
+type esMetric struct {
+	name  string
+	value *float64
+}
+
+type esSeries struct {
+	labels data.Labels
+	name   string
+	times  []time.Time
+	values []*float64
+}
+
+// esAggReader collects the leaf buckets of the aggregations
+type esAggReader struct {
+	opt    Options
+	series []*esSeries
+	lookup map[string]*esSeries
+	table  *esTable
+}
+
+// readAgg reads an aggregation, and reports if it has buckets. The values of metric
+// aggregations are added to the metrics of the bucket holding them.
+func (r *esAggReader) readAgg(iter *jsoniter.Iterator, name string, labels data.Labels, t *time.Time, metrics []esMetric) ([]esMetric, bool) {
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		iter.Skip()
+		return metrics, false
+	}
+	hasBuckets := false
+	for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+		switch k {
+		case "buckets":
+			hasBuckets = true
+			r.readBuckets(iter, name, labels, t)
+		case "value":
+			metrics = append(metrics, esMetric{name: name, value: readESNumber(iter)})
+		case "doc_count":
+			// single bucket aggregations, like filter, have a doc_count next to their aggregations
+			metrics = append(metrics, esMetric{name: name, value: readESNumber(iter)})
+		case "values":
+			// percentiles
+			if iter.WhatIsNext() != jsoniter.ObjectValue {
+				iter.Skip()
+				continue
+			}
+			for vk := iter.ReadObject(); vk != ""; vk = iter.ReadObject() {
+				metrics = append(metrics, esMetric{name: name + " " + vk, value: readESNumber(iter)})
+			}
+		case "meta", "value_as_string", "doc_count_error_upper_bound", "sum_other_doc_count", "interval":
+			iter.Skip()
+		default:
+			switch iter.WhatIsNext() {
+			case jsoniter.NumberValue, jsoniter.NilValue:
+				// stats
+				metrics = append(metrics, esMetric{name: name + " " + k, value: readESNumber(iter)})
+			case jsoniter.ObjectValue:
+				var b bool
+				metrics, b = r.readAgg(iter, k, labels, t, metrics)
+				hasBuckets = hasBuckets || b
+			default:
+				iter.Skip()
+			}
+		}
+	}
+	return metrics, hasBuckets
+}
+
+// readBuckets reads a list of buckets, or the keyed buckets of aggregations like filters
+func (r *esAggReader) readBuckets(iter *jsoniter.Iterator, name string, labels data.Labels, t *time.Time) {
+	switch iter.WhatIsNext() {
+	case jsoniter.ArrayValue:
+		for iter.ReadArray() {
+			r.readBucket(iter, name, "", labels, t)
+		}
+	case jsoniter.ObjectValue:
+		for key := iter.ReadObject(); key != ""; key = iter.ReadObject() {
+			r.readBucket(iter, name, key, labels, t)
+		}
+	default:
+		iter.Skip()
+	}
+}
+
+// readBucket reads a bucket, its key is read before its aggregations like elasticsearch writes it
+func (r *esAggReader) readBucket(iter *jsoniter.Iterator, name, key string, labels data.Labels, t *time.Time) {
+	var (
+		numKey       *float64
+		hasKeyString bool
+		resolved     bool
+		metrics      []esMetric
+		hasBuckets   bool
+	)
+	bucketLabels, bucketTime := labels, t
+	resolve := func() {
+		if resolved {
+			return
+		}
+		resolved = true
+		if numKey != nil && hasKeyString {
+			bt := time.UnixMilli(int64(*numKey)).UTC()
+			bucketTime = &bt
+			return
+		}
+		if numKey != nil {
+			key = strconv.FormatFloat(*numKey, 'f', -1, 64)
+		}
+		bucketLabels = make(data.Labels, len(labels)+1)
+		for k, v := range labels {
+			bucketLabels[k] = v
+		}
+		bucketLabels[name] = key
+	}
+
+	for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+		switch k {
+		case "key":
+			if iter.WhatIsNext() == jsoniter.NumberValue {
+				v := iter.ReadFloat64()
+				numKey = &v
+			} else {
+				key = iter.ReadString()
+			}
+		case "key_as_string":
+			iter.Skip()
+			hasKeyString = true
+		case "doc_count":
+			metrics = append(metrics, esMetric{name: esDocCountName, value: readESNumber(iter)})
+		case "from", "to", "from_as_string", "to_as_string", "doc_count_error_upper_bound", "sum_other_doc_count":
+			iter.Skip()
+		default:
+			resolve()
+			var b bool
+			metrics, b = r.readAgg(iter, k, bucketLabels, bucketTime, metrics)
+			hasBuckets = hasBuckets || b
+		}
+	}
+
+	resolve()
+	if !hasBuckets {
+		r.emit(bucketLabels, bucketTime, metrics)
+	}
+}
+
+// emit adds the metrics of a leaf bucket to the series when the bucket has a time,
+// or as a row of the table
+func (r *esAggReader) emit(labels data.Labels, t *time.Time, metrics []esMetric) {
+	if t == nil {
+		if r.table == nil {
+			r.table = newESTable()
+		}
+		keys := make([]string, 0, len(labels))
+		for k := range labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := labels[k]
+			r.table.set(k, data.FieldTypeNullableString, &v)
+		}
+		for _, m := range metrics {
+			r.table.set(m.name, data.FieldTypeNullableFloat64, m.value)
+		}
+		r.table.endRow()
+		return
+	}
+
+	for _, m := range metrics {
+		id := labels.String() + "\x00" + m.name
+		s, ok := r.lookup[id]
+		if !ok {
+			s = &esSeries{labels: labels, name: m.name}
+			r.lookup[id] = s
+			r.series = append(r.series, s)
+		}
+		s.times = append(s.times, *t)
+		s.values = append(s.values, m.value)
+	}
+}
+
+func (r *esAggReader) frames() (data.Frames, error) {
+	var frames data.Frames
+	for _, s := range r.series {
+		timeField := data.NewField(data.TimeSeriesTimeFieldName, nil, s.times)
+		valueField := data.NewField(s.name, s.labels, s.values)
+		frame := data.NewFrame(frameName(r.opt, s.labels), timeField, valueField)
+		frame.Meta = &data.FrameMeta{
+			Type:   data.FrameTypeTimeSeriesMulti,
+			Custom: resultTypeToCustomMeta("aggregations"),
+		}
+		frames = append(frames, frame)
+	}
+
+	if r.opt.MatrixWideSeries && len(frames) > 0 {
+		wide, err := JoinOnTime([]backend.DataResponse{{Frames: frames}}, JoinOptions{Mode: JoinModeOuter})
+		if err != nil {
+			return nil, err
+		}
+		wide.Meta.Custom = resultTypeToCustomMeta("aggregations")
+		frames = data.Frames{wide}
+	}
+
+	if r.table != nil {
+		table := data.NewFrame("aggregations", r.table.fields...)
+		table.Meta = &data.FrameMeta{
+			Custom: resultTypeToCustomMeta("aggregations"),
+		}
+		frames = append(frames, table)
+	}
+	return frames, nil
+}
+
+// readESNumber reads a metric value, null when it is not a number
+func readESNumber(iter *jsoniter.Iterator) *float64 {
+	if iter.WhatIsNext() != jsoniter.NumberValue {
+		iter.Skip()
+		return nil
+	}
+	v := iter.ReadFloat64()
+	return &v
+}
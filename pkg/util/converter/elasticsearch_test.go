@@ -0,0 +1,154 @@
+package converter
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+const esHistogramResponse = `{"took":3,"timed_out":false,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},
+	"hits":{"total":{"value":4,"relation":"eq"},"max_score":null,"hits":[]},
+	"aggregations":{"hosts":{"doc_count_error_upper_bound":0,"sum_other_doc_count":0,"buckets":[
+		{"key":"a","doc_count":3,"time":{"buckets":[
+			{"key_as_string":"2022-01-11T08:25:30.000Z","key":1641889530000,"doc_count":2,"avg":{"value":1.5},"p":{"values":{"50.0":1.0,"99.0":2.0}}},
+			{"key_as_string":"2022-01-11T08:25:45.000Z","key":1641889545000,"doc_count":1,"avg":{"value":null},"p":{"values":{"50.0":3.0,"99.0":3.0}}}
+		]}},
+		{"key":"b","doc_count":1,"time":{"buckets":[
+			{"key_as_string":"2022-01-11T08:25:45.000Z","key":1641889545000,"doc_count":1,"avg":{"value":4},"p":{"values":{"50.0":4.0,"99.0":4.0}}}
+		]}}
+	]}}}`
+
+func TestReadElasticsearchSearch(t *testing.T) {
+	read := func(body string, opt Options) data.Frames {
+		rsp := ReadElasticsearchSearch(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		require.NoError(t, rsp.Error)
+		return rsp.Frames
+	}
+
+	t.Run("date histogram", func(t *testing.T) {
+		frames := read(esHistogramResponse, Options{})
+		// the empty hits, then 4 series of host a and 4 of host b
+		require.Len(t, frames, 9)
+		require.Equal(t, "hits", frames[0].Name)
+		require.Equal(t, 0, frames[0].Rows())
+
+		names := []string{}
+		for _, frame := range frames[1:5] {
+			require.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+			require.Equal(t, data.Labels{"hosts": "a"}, frame.Fields[1].Labels)
+			names = append(names, frame.Fields[1].Name)
+		}
+		require.Equal(t, []string{"doc_count", "avg", "p 50.0", "p 99.0"}, names)
+
+		avg := frames[2]
+		require.Equal(t, 2, avg.Rows())
+		require.Equal(t, time.UnixMilli(1641889545000).UTC(), avg.Fields[0].At(1))
+		require.Equal(t, 1.5, *avg.Fields[1].At(0).(*float64))
+		require.Nil(t, avg.Fields[1].At(1))
+
+		require.Equal(t, data.Labels{"hosts": "b"}, frames[5].Fields[1].Labels)
+		require.Equal(t, 1, frames[5].Rows())
+	})
+
+	t.Run("wide", func(t *testing.T) {
+		frames := read(esHistogramResponse, Options{MatrixWideSeries: true})
+		require.Len(t, frames, 2)
+		wide := frames[1]
+		require.Equal(t, data.FrameTypeTimeSeriesWide, wide.Meta.Type)
+		require.Equal(t, "aggregations", frameResultType(wide))
+		require.Len(t, wide.Fields, 9)
+		require.Equal(t, 2, wide.Rows())
+		// host b has no sample at the first time
+		require.Nil(t, wide.Fields[5].At(0))
+		require.Equal(t, 1.0, *wide.Fields[5].At(1).(*float64))
+	})
+
+	t.Run("terms table", func(t *testing.T) {
+		frames := read(`{"hits":{"hits":[]},"aggregations":{
+			"status":{"buckets":[
+				{"key":200,"doc_count":10,"hosts":{"buckets":[{"key":"a","doc_count":6,"max":{"value":2}},{"key":"b","doc_count":4,"max":{"value":3}}]}},
+				{"key":500,"doc_count":1,"hosts":{"buckets":[{"key":"a","doc_count":1}]}}
+			]},
+			"errors":{"doc_count":1,"ranges":{"buckets":{"slow":{"doc_count":3},"fast":{"to":1,"doc_count":5}}}}
+		}}`, Options{})
+		require.Len(t, frames, 2)
+		table := frames[1]
+		require.Equal(t, "aggregations", table.Name)
+
+		rows := []map[string]interface{}{}
+		for i := 0; i < table.Rows(); i++ {
+			row := map[string]interface{}{}
+			for _, f := range table.Fields {
+				if v, ok := f.ConcreteAt(i); ok {
+					row[f.Name] = v
+				}
+			}
+			rows = append(rows, row)
+		}
+		require.Equal(t, []map[string]interface{}{
+			{"hosts": "a", "status": "200", "doc_count": 6.0, "max": 2.0},
+			{"hosts": "b", "status": "200", "doc_count": 4.0, "max": 3.0},
+			{"hosts": "a", "status": "500", "doc_count": 1.0},
+			{"ranges": "slow", "doc_count": 3.0},
+			{"ranges": "fast", "doc_count": 5.0},
+		}, rows)
+	})
+
+	t.Run("metrics of the whole index", func(t *testing.T) {
+		frames := read(`{"aggregations":{"avg":{"value":2.5},"stats":{"count":2,"min":1,"max":4,"avg":2.5,"sum":5}}}`, Options{})
+		require.Len(t, frames, 1)
+		require.Equal(t, 1, frames[0].Rows())
+		require.Equal(t, "stats sum", frames[0].Fields[5].Name)
+		require.Equal(t, 5.0, *frames[0].Fields[5].At(0).(*float64))
+	})
+
+	t.Run("documents", func(t *testing.T) {
+		frames := read(`{"timed_out":true,"hits":{"total":{"value":2},"hits":[
+			{"_index":"logs","_id":"1","_score":1,"_source":{"message":"hello","level":{"name":"info"},"bytes":12,"tags":["a","b"]}},
+			{"_index":"logs","_id":"2","_score":1,"_source":{"message":"bye","ok":true,"bytes":"large"}}
+		]}}`, Options{})
+		require.Len(t, frames, 1)
+		hits := frames[0]
+		require.Equal(t, 2, hits.Rows())
+
+		names := []string{}
+		for _, f := range hits.Fields {
+			names = append(names, f.Name)
+		}
+		require.Equal(t, []string{"_index", "_id", "message", "level.name", "bytes", "tags", "ok"}, names)
+		require.Equal(t, "bye", *hits.Fields[2].At(1).(*string))
+		require.Nil(t, hits.Fields[3].At(1))
+		// a value of another type than the first one is null
+		require.Nil(t, hits.Fields[4].At(1))
+		require.Equal(t, json.RawMessage(`["a","b"]`), *hits.Fields[5].At(0).(*json.RawMessage))
+		require.Nil(t, hits.Fields[6].At(0))
+		require.True(t, *hits.Fields[6].At(1).(*bool))
+		require.Equal(t, "The search timed out, the results may be incomplete", hits.Meta.Notices[0].Text)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		rsp := ReadElasticsearchSearch(jsoniter.ParseString(jsoniter.ConfigDefault, `{"error":{"root_cause":[{"type":"x","reason":"y"}],
+			"type":"search_phase_execution_exception","reason":"all shards failed"},"status":400}`), Options{})
+		require.EqualError(t, rsp.Error, "all shards failed")
+		require.Empty(t, rsp.Frames)
+	})
+}
+
+func TestReadElasticsearchMultiSearch(t *testing.T) {
+	responses, err := ReadElasticsearchMultiSearch(jsoniter.ParseString(jsoniter.ConfigDefault, `{"took":5,"responses":[
+		`+esHistogramResponse+`,
+		{"error":{"type":"index_not_found_exception","reason":"no such index [x]"},"status":404}
+	]}`), Options{})
+	require.NoError(t, err)
+	require.Len(t, responses, 2)
+	require.NoError(t, responses[0].Error)
+	require.Len(t, responses[0].Frames, 9)
+	require.EqualError(t, responses[1].Error, "no such index [x]")
+
+	_, err = ReadElasticsearchMultiSearch(jsoniter.ParseString(jsoniter.ConfigDefault, `{"responses":[`), Options{})
+	require.Error(t, err)
+}
@@ -0,0 +1,57 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorSource tells if a failed query is a problem of grafana or of the server it queried,
+// so only the first counts against the plugin in SLO metrics
+type ErrorSource string
+
+const (
+	ErrorSourcePlugin     ErrorSource = "plugin"
+	ErrorSourceDownstream ErrorSource = "downstream"
+)
+
+// Error is returned for responses with "status": "error"
+type Error struct {
+	// The errorType of the response, like bad_data or timeout
+	Type    string
+	Message string
+	Source  ErrorSource
+}
+
+func newResponseError(errorType string, message string) *Error {
+	return &Error{
+		Type:    errorType,
+		Message: message,
+		Source:  errorTypeSource(errorType),
+	}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+}
+
+// errorTypeSource maps the error types of the prometheus API. Everything is reported by the
+// server, except requests canceled because grafana stopped waiting for the response.
+func errorTypeSource(errorType string) ErrorSource {
+	switch errorType {
+	case "canceled":
+		return ErrorSourcePlugin
+	default:
+		// bad_data, execution, timeout, internal, unavailable, not_found and unknown types
+		return ErrorSourceDownstream
+	}
+}
+
+// ErrorSourceOf returns the source of an error returned by the converter, errors that
+// were not reported by the server are plugin errors
+func ErrorSourceOf(err error) ErrorSource {
+	var responseErr *Error
+	if errors.As(err, &responseErr) {
+		return responseErr.Source
+	}
+	return ErrorSourcePlugin
+}
@@ -0,0 +1,29 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseErrorSource(t *testing.T) {
+	for errorType, source := range map[string]ErrorSource{
+		"bad_data":  ErrorSourceDownstream,
+		"timeout":   ErrorSourceDownstream,
+		"execution": ErrorSourceDownstream,
+		"":          ErrorSourceDownstream,
+		"canceled":  ErrorSourcePlugin,
+	} {
+		body := fmt.Sprintf(`{"status":"error","errorType":%q,"error":"query failed"}`, errorType)
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{})
+		require.Error(t, rsp.Error)
+		require.Equal(t, errorType+": query failed", rsp.Error.Error())
+		require.Equal(t, source, ErrorSourceOf(rsp.Error), errorType)
+		require.Equal(t, source, ErrorSourceOf(fmt.Errorf("wrapped: %w", rsp.Error)), errorType)
+	}
+
+	require.Equal(t, ErrorSourcePlugin, ErrorSourceOf(errors.New("unexpected end of JSON input")))
+}
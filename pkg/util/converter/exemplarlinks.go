@@ -0,0 +1,65 @@
+package converter
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ExemplarTraceLink configures the data link of the exemplar label holding a trace ID
+type ExemplarTraceLink struct {
+	// The exemplar label holding the trace ID, like traceID
+	Name string
+
+	// The tracing datasource the trace is opened with in explore
+	DatasourceUID string
+
+	// An external URL opening the trace instead, ${__value.raw} is replaced by the trace ID
+	URL string
+
+	// The title of the link, "Query with <DatasourceUID>" by default
+	URLDisplayLabel string
+}
+
+// the placeholder the frontend replaces with the value of the field
+const traceIDPlaceholder = "${__value.raw}"
+
+func (l ExemplarTraceLink) dataLink() (data.DataLink, bool) {
+	switch {
+	case l.URL != "":
+		return data.DataLink{Title: l.URLDisplayLabel, URL: l.URL}, true
+	case l.DatasourceUID != "":
+		title := l.URLDisplayLabel
+		if title == "" {
+			title = "Query with " + l.DatasourceUID
+		}
+		return data.DataLink{Title: title, URL: exploreTraceURL(l.DatasourceUID)}, true
+	}
+	return data.DataLink{}, false
+}
+
+// exploreTraceURL returns an explore URL querying the trace ID in the datasource. The
+// placeholder is not escaped, the frontend only interpolates it when written as is.
+func exploreTraceURL(uid string) string {
+	prefix := fmt.Sprintf(`{"datasource":%q,"queries":[{"refId":"A","datasource":{"uid":%q},"query":"`, uid, uid)
+	return "/explore?left=" + url.QueryEscape(prefix) + traceIDPlaceholder + url.QueryEscape(`"}]}`)
+}
+
+// addExemplarTraceLinks adds the links to the exemplar label fields, by label name
+func addExemplarTraceLinks(fields map[string]*data.Field, links []ExemplarTraceLink) {
+	for _, l := range links {
+		f, ok := fields[l.Name]
+		if !ok {
+			continue
+		}
+		link, ok := l.dataLink()
+		if !ok {
+			continue
+		}
+		if f.Config == nil {
+			f.Config = &data.FieldConfig{}
+		}
+		f.Config.Links = append(f.Config.Links, link)
+	}
+}
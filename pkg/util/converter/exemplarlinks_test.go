@@ -0,0 +1,53 @@
+package converter
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExemplarTraceLinks(t *testing.T) {
+	const body = `{"status":"success","data":[
+		{"exemplars":[{"labels":{"traceID":"abc","span":"1"},"value":"0.15","timestamp":1641889555.123}],
+		 "seriesLabels":{"__name__":"rpc_duration_seconds","job":"api"}}
+	]}`
+	read := func(links ...ExemplarTraceLink) *data.Frame {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{ExemplarTraceLinks: links})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		return rsp.Frames[0]
+	}
+
+	t.Run("datasource", func(t *testing.T) {
+		frame := read(ExemplarTraceLink{Name: "traceID", DatasourceUID: "tempo"})
+		traceID, _ := frame.FieldByName("traceID")
+		require.NotNil(t, traceID.Config)
+		require.Len(t, traceID.Config.Links, 1)
+		link := traceID.Config.Links[0]
+		require.Equal(t, "Query with tempo", link.Title)
+		require.Contains(t, link.URL, traceIDPlaceholder)
+
+		left, err := url.QueryUnescape(link.URL[len("/explore?left="):])
+		require.NoError(t, err)
+		require.Equal(t, `{"datasource":"tempo","queries":[{"refId":"A","datasource":{"uid":"tempo"},"query":"${__value.raw}"}]}`, left)
+
+		span, _ := frame.FieldByName("span")
+		require.Nil(t, span.Config)
+	})
+
+	t.Run("url", func(t *testing.T) {
+		frame := read(ExemplarTraceLink{Name: "traceID", URL: "https://traces.example.com/trace/${__value.raw}", URLDisplayLabel: "Open trace"})
+		traceID, _ := frame.FieldByName("traceID")
+		require.Equal(t, []data.DataLink{{Title: "Open trace", URL: "https://traces.example.com/trace/${__value.raw}"}}, traceID.Config.Links)
+	})
+
+	t.Run("unknown label", func(t *testing.T) {
+		frame := read(ExemplarTraceLink{Name: "trace_id", DatasourceUID: "tempo"})
+		for _, f := range frame.Fields {
+			require.Nil(t, f.Config)
+		}
+	})
+}
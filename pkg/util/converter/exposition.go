@@ -0,0 +1,119 @@
+package converter
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/textparse"
+)
+
+// ReadExposition reads a scrape of a /metrics or /federate endpoint, in the prometheus text
+// format or in OpenMetrics when the content type is application/openmetrics-text. Every
+// series is returned like a series of an instant vector, samples without a timestamp get
+// the time of the conversion. The HELP, TYPE and UNIT lines are used as metric metadata,
+// next to the Options.MetricMetadata.
+func ReadExposition(body []byte, contentType string, opt Options) backend.DataResponse {
+	if opt.MaxRows > 0 && opt.rowLimit == nil {
+		opt.rowLimit = &rowLimit{max: opt.MaxRows}
+	}
+
+	if opt.MaxBodyBytes > 0 && int64(len(body)) > opt.MaxBodyBytes {
+		return backend.DataResponse{Error: &BodyLimitError{Limit: opt.MaxBodyBytes}}
+	}
+	opt.MemoryStats.addBody(len(body))
+
+	rsp := backend.DataResponse{}
+	metadata := map[string]MetricMetadata{}
+	scrapeTime := time.Now().UTC()
+
+	p := textparse.New(body, contentType)
+	for {
+		entry, err := p.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			rsp.Error = err
+			break
+		}
+
+		switch entry {
+		case textparse.EntryHelp:
+			name, help := p.Help()
+			md := metadata[string(name)]
+			md.Help = string(help)
+			metadata[string(name)] = md
+
+		case textparse.EntryType:
+			name, typ := p.Type()
+			md := metadata[string(name)]
+			md.Type = string(typ)
+			metadata[string(name)] = md
+
+		case textparse.EntryUnit:
+			name, unit := p.Unit()
+			md := metadata[string(name)]
+			md.Unit = string(unit)
+			metadata[string(name)] = md
+
+		case textparse.EntrySeries:
+			_, ts, v := p.Series()
+			var lset labels.Labels
+			p.Metric(&lset)
+
+			t := scrapeTime
+			if ts != nil {
+				t = time.UnixMilli(*ts).UTC()
+			}
+			if frame := newExpositionFrame(lset, t, v, opt); frame != nil {
+				rsp.Frames = append(rsp.Frames, frame)
+			}
+		}
+	}
+
+	for metric, md := range opt.MetricMetadata {
+		metadata[metric] = md
+	}
+	if len(metadata) > 0 {
+		attachMetricMetadata(rsp.Frames, metadata)
+	}
+	if opt.rowLimit != nil && opt.rowLimit.truncated {
+		addTruncatedNotice(rsp.Frames, opt.rowLimit.max)
+	}
+	finishFrames(rsp.Frames, opt)
+	return rsp
+}
+
+// newExpositionFrame returns nil when the sample is dropped
+func newExpositionFrame(lset labels.Labels, t time.Time, value float64, opt Options) *data.Frame {
+	v, ok := nonFiniteValues(opt, value).convert(value)
+	if !ok || !opt.rowLimit.take() {
+		return nil
+	}
+
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
+	timeField.Name = data.TimeSeriesTimeFieldName
+	timeField.Append(t)
+	valueField := data.NewFieldFromFieldType(multiValueFieldType(opt), 0)
+	valueField.Name = data.TimeSeriesValueFieldName
+	valueField.Labels = make(data.Labels, len(lset))
+	for _, l := range lset {
+		valueField.Labels[l.Name] = l.Value
+	}
+	appendFloat(valueField, v)
+
+	setSeriesDisplayName(opt, valueField)
+	frame := data.NewFrame(frameName(opt, valueField.Labels), timeField, valueField)
+	frame.Meta = &data.FrameMeta{
+		Type:   data.FrameTypeTimeSeriesMulti,
+		Custom: resultTypeToCustomMeta("vector"),
+	}
+	if opt.NumericVector {
+		toNumericFrame(frame)
+	}
+	return frame
+}
@@ -0,0 +1,59 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadExposition(t *testing.T) {
+	t.Run("text format", func(t *testing.T) {
+		body := `# HELP http_request_duration_seconds Request latency
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{le="0.1"} 3 1645029699000
+http_request_duration_seconds_bucket{le="+Inf"} 5 1645029699000
+http_request_duration_seconds_count 5 1645029699000
+# TYPE up gauge
+up{job="api"} 1
+`
+		rsp := ReadExposition([]byte(body), "text/plain; version=0.0.4", Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 4)
+
+		bucket := rsp.Frames[0]
+		require.Equal(t, data.Labels{"__name__": "http_request_duration_seconds_bucket", "le": "0.1"}, bucket.Fields[1].Labels)
+		require.Equal(t, time.UnixMilli(1645029699000).UTC(), bucket.Fields[0].At(0))
+		require.Equal(t, 3.0, bucket.Fields[1].At(0))
+		custom := bucket.Meta.Custom.(map[string]string)
+		require.Equal(t, "vector", custom["resultType"])
+		require.Equal(t, "histogram", custom["metricType"])
+		require.Equal(t, "Request latency", custom["metricHelp"])
+
+		// no timestamp, the time of the scrape
+		up := rsp.Frames[3]
+		require.WithinDuration(t, time.Now(), up.Fields[0].At(0).(time.Time), time.Minute)
+	})
+
+	t.Run("openmetrics", func(t *testing.T) {
+		body := `# TYPE process_cpu_seconds counter
+# UNIT process_cpu_seconds seconds
+# HELP process_cpu_seconds Total CPU time
+process_cpu_seconds_total 12.5 1645029699.5
+# EOF
+`
+		rsp := ReadExposition([]byte(body), "application/openmetrics-text; version=1.0.0; charset=utf-8", Options{NumericVector: true})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		frame := rsp.Frames[0]
+		require.Equal(t, data.FrameTypeNumericMulti, frame.Meta.Type)
+		require.Equal(t, 12.5, frame.Fields[0].At(0))
+		require.Equal(t, "seconds", frame.Meta.Custom.(map[string]string)["metricUnit"])
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		rsp := ReadExposition([]byte("up{job=\"api\" 1\n"), "text/plain", Options{})
+		require.Error(t, rsp.Error)
+	})
+}
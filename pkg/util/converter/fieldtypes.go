@@ -0,0 +1,82 @@
+package converter
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// applyFieldTypes converts the string fields named in Options.FieldTypes, like the label
+// columns of series frames or the exploded structured metadata. Values that can not be
+// parsed are null in nullable types, and the zero value otherwise.
+func applyFieldTypes(frames data.Frames, opt Options) {
+	if len(opt.FieldTypes) == 0 {
+		return
+	}
+	for _, frame := range frames {
+		for i, f := range frame.Fields {
+			typ, ok := opt.FieldTypes[f.Name]
+			if !ok || (f.Type() != data.FieldTypeString && f.Type() != data.FieldTypeNullableString) {
+				continue
+			}
+			if converted := convertStringField(f, typ); converted != nil {
+				frame.Fields[i] = converted
+			}
+		}
+	}
+}
+
+func convertStringField(f *data.Field, typ data.FieldType) *data.Field {
+	parse := stringParser(typ.NonNullableType())
+	if parse == nil {
+		return nil
+	}
+
+	converted := data.NewFieldFromFieldType(typ, f.Len())
+	converted.Name = f.Name
+	converted.Labels = f.Labels
+	converted.Config = f.Config
+	for i := 0; i < f.Len(); i++ {
+		s, ok := f.ConcreteAt(i)
+		if !ok || s.(string) == "" {
+			continue
+		}
+		if v, err := parse(s.(string)); err == nil {
+			converted.SetConcrete(i, v)
+		}
+	}
+	return converted
+}
+
+func stringParser(typ data.FieldType) func(s string) (interface{}, error) {
+	switch typ {
+	case data.FieldTypeInt64:
+		return func(s string) (interface{}, error) {
+			return strconv.ParseInt(s, 10, 64)
+		}
+	case data.FieldTypeFloat64:
+		return func(s string) (interface{}, error) {
+			return strconv.ParseFloat(s, 64)
+		}
+	case data.FieldTypeBool:
+		return func(s string) (interface{}, error) {
+			return strconv.ParseBool(s)
+		}
+	case data.FieldTypeTime:
+		return parseTimeString
+	default:
+		return nil
+	}
+}
+
+// parseTimeString reads RFC3339 times and unix timestamps in seconds, like prometheus sends them
+func parseTimeString(s string) (interface{}, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.UTC(), nil
+	}
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return nil, err
+	}
+	return timeFromDecimalString(s), nil
+}
@@ -0,0 +1,53 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldTypes(t *testing.T) {
+	t.Run("series labels", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":[
+			{"__name__":"up","code":"200","started":"2022-02-16T16:50:46Z"},
+			{"__name__":"up","code":"n/a","started":"1645030246.5"}
+		]}`), Options{FieldTypes: map[string]data.FieldType{
+			"code":     data.FieldTypeNullableInt64,
+			"started":  data.FieldTypeTime,
+			"__name__": data.FieldTypeBool, // not a bool, the values are zero
+		}})
+		require.NoError(t, rsp.Error)
+		frame := rsp.Frames[0]
+
+		code, _ := frame.FieldByName("code")
+		require.Equal(t, data.FieldTypeNullableInt64, code.Type())
+		v, ok := code.ConcreteAt(0)
+		require.True(t, ok)
+		require.Equal(t, int64(200), v)
+		_, ok = code.ConcreteAt(1)
+		require.False(t, ok)
+
+		started, _ := frame.FieldByName("started")
+		require.Equal(t, time.Date(2022, time.February, 16, 16, 50, 46, 0, time.UTC), started.At(0))
+		require.Equal(t, time.Date(2022, time.February, 16, 16, 50, 46, 500000000, time.UTC), started.At(1))
+
+		name, _ := frame.FieldByName("__name__")
+		require.Equal(t, false, name.At(0))
+	})
+
+	t.Run("exploded structured metadata", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"a"},"values":[["1645030244810757120","line 1",{"duration_ms":"12.5"}],["1645030244810757121","line 2"]]}
+		]}}`), Options{ExplodeStructuredMetadata: true, FieldTypes: map[string]data.FieldType{"duration_ms": data.FieldTypeNullableFloat64}})
+		require.NoError(t, rsp.Error)
+		duration, _ := rsp.Frames[0].FieldByName("duration_ms")
+		v, ok := duration.ConcreteAt(0)
+		require.True(t, ok)
+		require.Equal(t, 12.5, v)
+		_, ok = duration.ConcreteAt(1)
+		require.False(t, ok)
+	})
+}
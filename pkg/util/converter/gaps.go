@@ -0,0 +1,98 @@
+package converter
+
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// gapFilledRows returns the timestamps of the time field with one more row for every step
+// missing between two samples, and for every row the index of its sample, -1 for the new
+// rows. It returns nil when there is no gap, or when filling would add more rows than a
+// range query may return.
+func gapFilledRows(timeField *data.Field, step time.Duration) ([]time.Time, []int) {
+	n := timeField.Len()
+	if step <= 0 || n < 2 {
+		return nil, nil
+	}
+
+	missing := 0
+	for i := 1; i < n; i++ {
+		gap := timeField.At(i).(time.Time).Sub(timeField.At(i - 1).(time.Time))
+		if gap > step {
+			missing += int((gap - 1) / step)
+		}
+		if missing > maxTimeGridRows {
+			return nil, nil
+		}
+	}
+	if missing == 0 {
+		return nil, nil
+	}
+
+	times := make([]time.Time, 0, n+missing)
+	src := make([]int, 0, n+missing)
+	for i := 0; i < n; i++ {
+		t := timeField.At(i).(time.Time)
+		if i > 0 {
+			for gap := times[len(times)-1].Add(step); gap.Before(t); gap = gap.Add(step) {
+				times = append(times, gap)
+				src = append(src, -1)
+			}
+		}
+		times = append(times, t)
+		src = append(src, i)
+	}
+	return times, src
+}
+
+// fillSeriesGaps adds a null value for every step missing in the series of a multi frame,
+// the value field becomes nullable
+func fillSeriesGaps(timeField, valueField *data.Field, step time.Duration) (*data.Field, *data.Field) {
+	times, src := gapFilledRows(timeField, step)
+	if times == nil {
+		return timeField, valueField
+	}
+
+	newTime := data.NewField(timeField.Name, nil, times)
+	newValue := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(times))
+	newValue.Name = valueField.Name
+	newValue.Labels = valueField.Labels
+	newValue.Config = valueField.Config
+	for i, idx := range src {
+		if idx < 0 {
+			continue
+		}
+		v, err := valueField.NullableFloatAt(idx)
+		if err == nil {
+			newValue.Set(i, v)
+		}
+	}
+	return newTime, newValue
+}
+
+// fillFrameGaps adds a null row for every step missing in a sorted wide frame
+func fillFrameGaps(frame *data.Frame, step time.Duration) {
+	times, src := gapFilledRows(frame.Fields[0], step)
+	if times == nil {
+		return
+	}
+
+	for idx, f := range frame.Fields {
+		if idx == 0 {
+			frame.Fields[0] = data.NewField(f.Name, nil, times)
+			frame.Fields[0].Config = f.Config
+			continue
+		}
+		filled := data.NewFieldFromFieldType(f.Type(), len(times))
+		filled.Name = f.Name
+		filled.Labels = f.Labels
+		filled.Config = f.Config
+		for i, j := range src {
+			if j >= 0 {
+				filled.Set(i, f.At(j))
+			}
+		}
+		frame.Fields[idx] = filled
+	}
+}
@@ -0,0 +1,87 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+const gapsResponse = `{"status":"success","data":{"resultType":"matrix","result":[
+	{"metric":{"job":"a"},"values":[[10,"1"],[20,"2"],[50,"5"],[60,"6"]]},
+	{"metric":{"job":"b"},"values":[[10,"1"],[20,"2"]]}
+]}}`
+
+func fieldTimes(f *data.Field) []int64 {
+	times := make([]int64, f.Len())
+	for i := range times {
+		times[i] = f.At(i).(time.Time).Unix()
+	}
+	return times
+}
+
+func fieldValues(f *data.Field) []interface{} {
+	values := make([]interface{}, f.Len())
+	for i := range values {
+		if v, ok := f.ConcreteAt(i); ok {
+			values[i] = v
+		}
+	}
+	return values
+}
+
+func TestFillGaps(t *testing.T) {
+	t.Run("multi", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, gapsResponse), Options{FillGaps: true, Step: 10 * time.Second})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 2)
+
+		a := rsp.Frames[0]
+		require.Equal(t, []int64{10, 20, 30, 40, 50, 60}, fieldTimes(a.Fields[0]))
+		require.Equal(t, []interface{}{1.0, 2.0, nil, nil, 5.0, 6.0}, fieldValues(a.Fields[1]))
+		require.Equal(t, data.FieldTypeNullableFloat64, a.Fields[1].Type())
+		require.Equal(t, data.Labels{"job": "a"}, a.Fields[1].Labels)
+
+		// no gap, the series is left as it is
+		b := rsp.Frames[1]
+		require.Equal(t, []int64{10, 20}, fieldTimes(b.Fields[0]))
+		require.Equal(t, data.FieldTypeFloat64, b.Fields[1].Type())
+	})
+
+	t.Run("wide", func(t *testing.T) {
+		for _, opt := range []Options{
+			{FillGaps: true, Step: 10 * time.Second, MatrixWideSeries: true},
+			// the rows of the time grid without samples are kept
+			{FillGaps: true, Step: 10 * time.Second, MatrixWideSeries: true, Start: time.Unix(0, 0), End: time.Unix(70, 0)},
+		} {
+			rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, gapsResponse), opt)
+			require.NoError(t, rsp.Error)
+			require.Len(t, rsp.Frames, 1)
+
+			frame := rsp.Frames[0]
+			require.Equal(t, []int64{10, 20, 30, 40, 50, 60}, fieldTimes(frame.Fields[0]))
+			require.Equal(t, []interface{}{1.0, 2.0, nil, nil, 5.0, 6.0}, fieldValues(frame.Fields[1]))
+			require.Equal(t, []interface{}{1.0, 2.0, nil, nil, nil, nil}, fieldValues(frame.Fields[2]))
+		}
+	})
+
+	t.Run("without step", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, gapsResponse), Options{FillGaps: true})
+		require.NoError(t, rsp.Error)
+		require.Equal(t, 4, rsp.Frames[0].Rows())
+	})
+
+	t.Run("repeated values", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"job":"a"},"values":[[10,"1"],[20,"1"],[30,"1"],[60,"1"],[70,"1"]]}
+		]}}`), Options{FillGaps: true, DropRepeatedValues: true, Step: 10 * time.Second})
+		require.NoError(t, rsp.Error)
+
+		// the gap still breaks the line
+		frame := rsp.Frames[0]
+		require.Equal(t, []int64{10, 40, 60, 70}, fieldTimes(frame.Fields[0]))
+		require.Equal(t, []interface{}{1.0, nil, 1.0, 1.0}, fieldValues(frame.Fields[1]))
+	})
+}
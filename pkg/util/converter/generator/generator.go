@@ -0,0 +1,201 @@
+// Package generator builds synthetic prometheus and loki responses to benchmark and
+// profile the converter. The same config always produces the same response, so the
+// results of different runs can be compared.
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	// Number of series or streams in the response
+	Series int
+	// Samples, histograms or log lines per series
+	Samples int
+	// Labels per series, next to __name__ and the unique series label
+	Labels int
+	// Distinct values of every label, 10 when not set
+	LabelCardinality int
+	// Exemplars per series, only used by Exemplars
+	Exemplars int
+	// Buckets of every native histogram, only used by Histograms
+	Buckets int
+
+	// Time of the first sample, 2022-01-01 when not set
+	Start time.Time
+	// Time between samples, 15s when not set
+	Step time.Duration
+	// Probability of a NaN value
+	NaNRate float64
+	// Seed of the random values
+	Seed int64
+}
+
+func (c Config) withDefaults() Config {
+	if c.LabelCardinality <= 0 {
+		c.LabelCardinality = 10
+	}
+	if c.Start.IsZero() {
+		c.Start = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if c.Step <= 0 {
+		c.Step = 15 * time.Second
+	}
+	if c.Buckets <= 0 {
+		c.Buckets = 10
+	}
+	return c
+}
+
+// Matrix returns a range query response
+func Matrix(cfg Config) []byte {
+	cfg = cfg.withDefaults()
+	r := rand.New(rand.NewSource(cfg.Seed))
+
+	sb := &strings.Builder{}
+	sb.WriteString(`{"status":"success","data":{"resultType":"matrix","result":[`)
+	for s := 0; s < cfg.Series; s++ {
+		comma(sb, s)
+		sb.WriteString(`{"metric":`)
+		writeLabels(sb, cfg, s)
+		sb.WriteString(`,"values":[`)
+		for i := 0; i < cfg.Samples; i++ {
+			comma(sb, i)
+			fmt.Fprintf(sb, `[%s,"%s"]`, timestamp(cfg, i), value(cfg, r))
+		}
+		sb.WriteString("]}")
+	}
+	sb.WriteString("]}}")
+	return []byte(sb.String())
+}
+
+// Vector returns an instant query response, with a single sample for every series
+func Vector(cfg Config) []byte {
+	cfg = cfg.withDefaults()
+	r := rand.New(rand.NewSource(cfg.Seed))
+
+	sb := &strings.Builder{}
+	sb.WriteString(`{"status":"success","data":{"resultType":"vector","result":[`)
+	for s := 0; s < cfg.Series; s++ {
+		comma(sb, s)
+		sb.WriteString(`{"metric":`)
+		writeLabels(sb, cfg, s)
+		fmt.Fprintf(sb, `,"value":[%s,"%s"]}`, timestamp(cfg, 0), value(cfg, r))
+	}
+	sb.WriteString("]}}")
+	return []byte(sb.String())
+}
+
+// Histograms returns a range query response of native histograms with exponential buckets
+func Histograms(cfg Config) []byte {
+	cfg = cfg.withDefaults()
+	r := rand.New(rand.NewSource(cfg.Seed))
+
+	sb := &strings.Builder{}
+	sb.WriteString(`{"status":"success","data":{"resultType":"matrix","result":[`)
+	for s := 0; s < cfg.Series; s++ {
+		comma(sb, s)
+		sb.WriteString(`{"metric":`)
+		writeLabels(sb, cfg, s)
+		sb.WriteString(`,"histograms":[`)
+		for i := 0; i < cfg.Samples; i++ {
+			comma(sb, i)
+			count := 0.0
+			buckets := &strings.Builder{}
+			for b := 0; b < cfg.Buckets; b++ {
+				comma(buckets, b)
+				c := float64(r.Intn(100))
+				count += c
+				fmt.Fprintf(buckets, `[0,"%s","%s","%s"]`, formatFloat(math.Pow(2, float64(b))), formatFloat(math.Pow(2, float64(b+1))), formatFloat(c))
+			}
+			fmt.Fprintf(sb, `[%s,{"count":"%s","sum":"%s","buckets":[%s]}]`, timestamp(cfg, i), formatFloat(count), formatFloat(count*r.Float64()), buckets.String())
+		}
+		sb.WriteString("]}")
+	}
+	sb.WriteString("]}}")
+	return []byte(sb.String())
+}
+
+// Exemplars returns a /api/v1/query_exemplars response
+func Exemplars(cfg Config) []byte {
+	cfg = cfg.withDefaults()
+	r := rand.New(rand.NewSource(cfg.Seed))
+
+	sb := &strings.Builder{}
+	sb.WriteString(`{"status":"success","data":[`)
+	for s := 0; s < cfg.Series; s++ {
+		comma(sb, s)
+		sb.WriteString(`{"seriesLabels":`)
+		writeLabels(sb, cfg, s)
+		sb.WriteString(`,"exemplars":[`)
+		for i := 0; i < cfg.Exemplars; i++ {
+			comma(sb, i)
+			fmt.Fprintf(sb, `{"labels":{"traceID":"%016x"},"value":"%s","timestamp":%s}`, r.Uint64(), value(cfg, r), timestamp(cfg, i))
+		}
+		sb.WriteString("]}")
+	}
+	sb.WriteString("]}")
+	return []byte(sb.String())
+}
+
+// Streams returns a loki log query response
+func Streams(cfg Config) []byte {
+	cfg = cfg.withDefaults()
+	r := rand.New(rand.NewSource(cfg.Seed))
+	levels := []string{"debug", "info", "warn", "error"}
+
+	sb := &strings.Builder{}
+	sb.WriteString(`{"status":"success","data":{"resultType":"streams","result":[`)
+	for s := 0; s < cfg.Series; s++ {
+		comma(sb, s)
+		sb.WriteString(`{"stream":`)
+		writeLabels(sb, cfg, s)
+		sb.WriteString(`,"values":[`)
+		for i := 0; i < cfg.Samples; i++ {
+			comma(sb, i)
+			ns := cfg.Start.Add(time.Duration(i) * cfg.Step).UnixNano()
+			fmt.Fprintf(sb, `["%d","level=%s msg=\"request handled\" duration=%dms status=%d"]`,
+				ns, levels[r.Intn(len(levels))], r.Intn(1000), 200+r.Intn(4)*100)
+		}
+		sb.WriteString("]}")
+	}
+	sb.WriteString("]}}")
+	return []byte(sb.String())
+}
+
+func comma(sb *strings.Builder, i int) {
+	if i > 0 {
+		sb.WriteString(",")
+	}
+}
+
+// every series has a unique "series" label, the other labels repeat after LabelCardinality series
+func writeLabels(sb *strings.Builder, cfg Config, series int) {
+	fmt.Fprintf(sb, `{"__name__":"synthetic_metric","series":"%d"`, series)
+	for l := 0; l < cfg.Labels; l++ {
+		fmt.Fprintf(sb, `,"label_%d":"value_%d"`, l, (series+l)%cfg.LabelCardinality)
+	}
+	sb.WriteString("}")
+}
+
+// prometheus timestamps are seconds with millisecond precision
+func timestamp(cfg Config, i int) string {
+	ms := cfg.Start.Add(time.Duration(i) * cfg.Step).UnixMilli()
+	return strconv.FormatFloat(float64(ms)/1000, 'f', -1, 64)
+}
+
+func value(cfg Config, r *rand.Rand) string {
+	if cfg.NaNRate > 0 && r.Float64() < cfg.NaNRate {
+		return "NaN"
+	}
+	return formatFloat(r.Float64()*200 - 100)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerators(t *testing.T) {
+	cfg := Config{Series: 3, Samples: 4, Labels: 2, LabelCardinality: 2, Exemplars: 2, Buckets: 3, NaNRate: 0.5, Seed: 1}
+	for name, generate := range map[string]func(Config) []byte{
+		"matrix":     Matrix,
+		"vector":     Vector,
+		"histograms": Histograms,
+		"exemplars":  Exemplars,
+		"streams":    Streams,
+	} {
+		t.Run(name, func(t *testing.T) {
+			body := generate(cfg)
+			require.True(t, json.Valid(body), string(body))
+			require.Equal(t, body, generate(cfg), "not reproducible")
+		})
+	}
+
+	t.Run("labels", func(t *testing.T) {
+		var rsp struct {
+			Data struct {
+				Result []struct {
+					Metric map[string]string `json:"metric"`
+					Values [][]interface{}   `json:"values"`
+				} `json:"result"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(Matrix(cfg), &rsp))
+		require.Len(t, rsp.Data.Result, 3)
+		require.Len(t, rsp.Data.Result[2].Values, 4)
+		require.Equal(t, map[string]string{
+			"__name__": "synthetic_metric",
+			"series":   "2",
+			"label_0":  "value_0",
+			"label_1":  "value_1",
+		}, rsp.Data.Result[2].Metric)
+	})
+}
@@ -0,0 +1,128 @@
+package converter
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+var errGraphiteTimestamp = errors.New("failed to parse data point timestamp")
+
+// ReadGraphiteRender reads a graphite /render?format=json response
+// [ { "target": "...", "tags": { ... }, "datapoints": [ [ value, timestamp ], ... ] } ]
+// Every target is returned in a multi frame like a series of a prometheus matrix, with the
+// tags as labels and the target as display name. Null values are kept, they are gaps.
+func ReadGraphiteRender(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
+	if opt.MaxRows > 0 && opt.rowLimit == nil {
+		opt.rowLimit = &rowLimit{max: opt.MaxRows}
+	}
+	attachUnknownKeys(iter, &opt)
+
+	rsp := backend.DataResponse{}
+	for iter.ReadArray() {
+		frame, err := readGraphiteTarget(iter, opt)
+		if err != nil && rsp.Error == nil {
+			rsp.Error = err
+		}
+		rsp.Frames = append(rsp.Frames, frame)
+	}
+	if iter.Error != nil && rsp.Error == nil {
+		rsp.Error = iter.Error
+	}
+	if err := opt.unknownKeys.report(rsp.Frames, opt.Strict); err != nil && rsp.Error == nil {
+		rsp.Error = err
+	}
+	if opt.rowLimit != nil && opt.rowLimit.truncated {
+		addTruncatedNotice(rsp.Frames, opt.rowLimit.max)
+	}
+	finishFrames(rsp.Frames, opt)
+	return rsp
+}
+
+func readGraphiteTarget(iter *jsoniter.Iterator, opt Options) (*data.Frame, error) {
+	var err error
+	target := ""
+	labels := data.Labels{}
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
+	timeField.Name = data.TimeSeriesTimeFieldName
+	valueField := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, 0)
+	valueField.Name = data.TimeSeriesValueFieldName
+
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "target":
+			target = iter.ReadString()
+
+		case "tags":
+			for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+				// graphite <= 1.1.7 returns some tags as numbers
+				switch iter.WhatIsNext() {
+				case jsoniter.StringValue:
+					labels[k] = iter.ReadString()
+				case jsoniter.NumberValue:
+					labels[k] = strconv.FormatFloat(iter.ReadFloat64(), 'f', -1, 64)
+				default:
+					iter.Skip()
+				}
+			}
+
+		case "datapoints":
+			for iter.ReadArray() {
+				var (
+					value *float64
+					ts    time.Time
+					valid bool
+				)
+				for i := 0; iter.ReadArray(); i++ {
+					switch {
+					case iter.WhatIsNext() != jsoniter.NumberValue:
+						iter.Skip()
+					case i == 0:
+						v := iter.ReadFloat64()
+						value = &v
+					case i == 1:
+						ts = time.Unix(int64(iter.ReadFloat64()), 0).UTC()
+						valid = true
+					default:
+						iter.Skip()
+					}
+				}
+				if !valid {
+					if err == nil {
+						err = errGraphiteTimestamp
+					}
+					continue
+				}
+				if value != nil {
+					v, ok := nonFiniteValues(opt, *value).convert(*value)
+					if !ok {
+						continue
+					}
+					value = v
+				}
+				if !opt.rowLimit.take() {
+					continue
+				}
+				timeField.Append(ts)
+				valueField.Append(value)
+			}
+
+		default:
+			iter.Skip()
+			skippedKey(iter, "target", l1Field)
+		}
+	}
+
+	valueField.Labels = labels
+	valueField.Config = &data.FieldConfig{DisplayNameFromDS: target}
+	frame := data.NewFrame(target, timeField, valueField)
+	frame.Meta = &data.FrameMeta{
+		Type:   data.FrameTypeTimeSeriesMulti,
+		Custom: resultTypeToCustomMeta("graphite"),
+	}
+	return frame, err
+}
@@ -0,0 +1,45 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadGraphiteRender(t *testing.T) {
+	rsp := ReadGraphiteRender(jsoniter.ParseString(jsoniter.ConfigDefault, `[
+		{"target":"apps.web.requests","tags":{"name":"apps.web.requests","dc":"west","shard":1},
+		 "datapoints":[[1.5,1672531200],[null,1672531260],[3,1672531320]]},
+		{"target":"apps.api.requests","datapoints":[[2,1672531200]]}
+	]`), Options{Strict: StrictError})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 2)
+
+	frame := rsp.Frames[0]
+	require.Equal(t, "apps.web.requests", frame.Name)
+	require.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+	require.Equal(t, 3, frame.Rows())
+	require.Equal(t, time.Unix(1672531260, 0).UTC(), frame.Fields[0].At(1))
+	require.Equal(t, 1.5, *frame.Fields[1].At(0).(*float64))
+	require.Nil(t, frame.Fields[1].At(1))
+	require.Equal(t, data.Labels{"name": "apps.web.requests", "dc": "west", "shard": "1"}, frame.Fields[1].Labels)
+	require.Equal(t, "apps.web.requests", frame.Fields[1].Config.DisplayNameFromDS)
+
+	require.Equal(t, data.Labels{}, rsp.Frames[1].Fields[1].Labels)
+
+	t.Run("missing timestamp", func(t *testing.T) {
+		rsp := ReadGraphiteRender(jsoniter.ParseString(jsoniter.ConfigDefault, `[{"target":"a","datapoints":[[1,null],[2,1672531200]]}]`), Options{})
+		require.EqualError(t, rsp.Error, "failed to parse data point timestamp")
+		require.Equal(t, 1, rsp.Frames[0].Rows())
+	})
+
+	t.Run("max rows", func(t *testing.T) {
+		rsp := ReadGraphiteRender(jsoniter.ParseString(jsoniter.ConfigDefault, `[{"target":"a","datapoints":[[1,1672531200],[2,1672531260]]}]`), Options{MaxRows: 1})
+		require.NoError(t, rsp.Error)
+		require.Equal(t, 1, rsp.Frames[0].Rows())
+		require.Len(t, rsp.Frames[0].Meta.Notices, 1)
+	})
+}
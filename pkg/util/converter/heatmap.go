@@ -0,0 +1,46 @@
+package converter
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// mergedHistograms collects the native histogram buckets of every series of the
+// response into a single heatmap-cells-sparse frame. The series field holds the
+// labels of every bucket, so the heatmap panel can facet by it.
+type mergedHistograms struct {
+	hist   *histogramInfo
+	series *data.Field
+}
+
+func newMergedHistograms(opt Options) *mergedHistograms {
+	m := &mergedHistograms{
+		hist:   newHistogramInfo(opt),
+		series: data.NewFieldFromFieldType(data.FieldTypeString, 0),
+	}
+	m.series.Name = "series"
+	return m
+}
+
+func (m *mergedHistograms) add(labels data.Labels, histogram *histogramInfo) {
+	name := labels.String()
+	for i := 0; i < histogram.time.Len(); i++ {
+		m.hist.time.Append(histogram.time.At(i))
+		m.hist.yMin.Append(histogram.yMin.At(i))
+		m.hist.yMax.Append(histogram.yMax.At(i))
+		m.hist.count.Append(histogram.count.At(i))
+		m.hist.yLayout.Append(histogram.yLayout.At(i))
+		m.series.Append(name)
+	}
+}
+
+func (m *mergedHistograms) len() int {
+	return m.series.Len()
+}
+
+func (m *mergedHistograms) frame() *data.Frame {
+	frame := data.NewFrame("", m.hist.time, m.hist.yMin, m.hist.yMax, m.hist.count, m.hist.yLayout, m.series)
+	frame.Meta = &data.FrameMeta{
+		Type: "heatmap-cells-sparse",
+	}
+	return frame
+}
@@ -0,0 +1,44 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeHistograms(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"job":"a"},
+		 "histograms":[[1641889530,{"count":"3","sum":"0.6","buckets":[[0,"0.1","0.2","1"],[0,"0.2","0.4","2"]]}]]},
+		{"metric":{"job":"b"},
+		 "values":[[1641889530,"1"]],
+		 "histograms":[[1641889530,{"count":"5","sum":"1","buckets":[[0,"0.1","0.2","5"]]}]]}
+	]}}`
+
+	for _, wide := range []bool{false, true} {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{MergeHistograms: true, MatrixWideSeries: wide})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 2)
+
+		frame := rsp.Frames[1]
+		require.Equal(t, data.FrameType("heatmap-cells-sparse"), frame.Meta.Type)
+		require.Equal(t, 3, frame.Rows())
+		series, _ := frame.FieldByName("series")
+		require.NotNil(t, series)
+		require.Equal(t, "job=a", series.At(0))
+		require.Equal(t, "job=a", series.At(1))
+		require.Equal(t, "job=b", series.At(2))
+		count, _ := frame.FieldByName("count")
+		require.Equal(t, 5.0, count.At(2))
+	}
+
+	t.Run("no frame without histograms", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrixResponse(1)), Options{MergeHistograms: true})
+		require.NoError(t, rsp.Error)
+		for _, frame := range rsp.Frames {
+			require.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+		}
+	})
+}
@@ -0,0 +1,339 @@
+package converter
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// ReadInfluxQLResult reads an InfluxQL /query response, with the times in milliseconds (epoch=ms)
+// or as RFC3339 strings:
+//
+//	{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"host":"a"},"columns":["time","mean"],"values":[[1641889530000,0.5]]}]}]}
+//
+// It returns a response for every statement, in order, with the error of the statement if any.
+// Every column of a series is returned in a multi frame named after the measurement, with the
+// column as the name of the value field and the tags as labels, the frames of a series share its
+// time field. With MatrixWideSeries, every series is a wide frame holding all its columns. The type
+// of a column is the one of its first value that is not null, numbers are nullable float64 and the
+// values of another type are null or empty.
+// Rows with an invalid time are skipped. Series without a time column, like the ones of the SHOW
+// queries, are returned as tables, with unnamed fields when the series has no columns. The error
+// of the whole response is returned on its own.
+func ReadInfluxQLResult(iter *jsoniter.Iterator, opt Options) ([]backend.DataResponse, error) {
+	if opt.MaxRows > 0 && opt.rowLimit == nil {
+		opt.rowLimit = &rowLimit{max: opt.MaxRows}
+	}
+	attachUnknownKeys(iter, &opt)
+
+	var responses []backend.DataResponse
+	errMsg := ""
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "results":
+			for iter.ReadArray() {
+				responses = append(responses, readInfluxQLStatement(iter, opt))
+			}
+		case "error":
+			errMsg = iter.ReadString()
+		default:
+			iter.Skip()
+			skippedKey(iter, "ROOT", l1Field)
+		}
+	}
+
+	if limitErr := bodyLimitError(iter.Error); limitErr != nil {
+		return nil, limitErr
+	}
+	if iter.Error != nil {
+		return nil, iter.Error
+	}
+	if errMsg != "" {
+		return nil, errors.New(errMsg)
+	}
+
+	frames := data.Frames{}
+	for _, rsp := range responses {
+		frames = append(frames, rsp.Frames...)
+	}
+	if err := opt.unknownKeys.report(frames, opt.Strict); err != nil {
+		return nil, err
+	}
+	if opt.rowLimit != nil && opt.rowLimit.truncated {
+		addTruncatedNotice(frames, opt.rowLimit.max)
+	}
+	finishFrames(frames, opt)
+	return responses, nil
+}
+
+func readInfluxQLStatement(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
+	rsp := backend.DataResponse{}
+	var notices []data.Notice
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "series":
+			for iter.ReadArray() {
+				rsp.Frames = append(rsp.Frames, readInfluxQLSeries(iter, opt)...)
+			}
+		case "messages":
+			notices = readInfluxQLMessages(iter)
+		case "error":
+			rsp.Error = errors.New(iter.ReadString())
+		case "statement_id", "partial":
+			iter.Skip()
+		default:
+			iter.Skip()
+			skippedKey(iter, "results", l1Field)
+		}
+	}
+	for _, frame := range rsp.Frames {
+		frame.AppendNotices(notices...)
+	}
+	return rsp
+}
+
+// readInfluxQLMessages reads the messages of a statement, like the deprecation
+// warnings, their level is mapped like the type of the prometheus warnings
+func readInfluxQLMessages(iter *jsoniter.Iterator) []data.Notice {
+	var notices []data.Notice
+	for iter.ReadArray() {
+		level, text := "", ""
+		for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+			switch k {
+			case "level":
+				level = iter.ReadString()
+			case "text":
+				text = iter.ReadString()
+			default:
+				iter.Skip()
+				skippedKey(iter, "messages", k)
+			}
+		}
+		if text != "" {
+			notices = append(notices, data.Notice{
+				Severity: warningSeverity(level),
+				Text:     text,
+			})
+		}
+	}
+	return notices
+}
+
+// influxValue is a value of a row, a row is read before its values are added
+// to the columns so the rows with an invalid time can be skipped
+type influxValue struct {
+	kind jsoniter.ValueType
+	num  string
+	str  string
+	b    bool
+}
+
+// influxColumn is created once the type of the column is known
+type influxColumn struct {
+	name  string
+	field *data.Field
+	// the rows added before the field is created
+	rows int
+}
+
+func (c *influxColumn) append(v influxValue) {
+	if c.field == nil {
+		var ft data.FieldType
+		switch v.kind {
+		case jsoniter.NumberValue:
+			ft = data.FieldTypeNullableFloat64
+		case jsoniter.StringValue:
+			ft = data.FieldTypeString
+		case jsoniter.BoolValue:
+			ft = data.FieldTypeBool
+		default:
+			c.rows++
+			return
+		}
+		c.field = data.NewFieldFromFieldType(ft, c.rows)
+		c.field.Name = c.name
+	}
+
+	switch c.field.Type() {
+	case data.FieldTypeNullableFloat64:
+		var fv *float64
+		if v.kind == jsoniter.NumberValue {
+			if f, err := strconv.ParseFloat(v.num, 64); err == nil {
+				fv = &f
+			}
+		}
+		c.field.Append(fv)
+	case data.FieldTypeString:
+		c.field.Append(v.str)
+	case data.FieldTypeBool:
+		c.field.Append(v.b)
+	}
+}
+
+// columnField returns the field of the column, a column without values is a nullable float64
+func (c *influxColumn) columnField() *data.Field {
+	if c.field == nil {
+		c.field = data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, c.rows)
+		c.field.Name = c.name
+	}
+	return c.field
+}
+
+func readInfluxQLSeries(iter *jsoniter.Iterator, opt Options) []*data.Frame {
+	name := ""
+	var labels data.Labels
+	var columns []*influxColumn
+	hasColumns := false
+	rows := 0
+	timeIdx := -1
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
+	timeField.Name = data.TimeSeriesTimeFieldName
+
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "name":
+			name = iter.ReadString()
+
+		case "tags":
+			labels = data.Labels{}
+			for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+				labels[k] = iter.ReadString()
+			}
+
+		case "columns":
+			// influxdb writes the columns before the values
+			hasColumns = true
+			for iter.ReadArray() {
+				column := iter.ReadString()
+				if timeIdx < 0 && strings.EqualFold(column, "time") {
+					timeIdx = len(columns)
+				}
+				columns = append(columns, &influxColumn{name: column})
+			}
+
+		case "values":
+			row := make([]influxValue, len(columns))
+			for iter.ReadArray() {
+				for i := range row {
+					row[i] = influxValue{kind: jsoniter.NilValue}
+				}
+				for i := 0; iter.ReadArray(); i++ {
+					if i >= len(row) {
+						if hasColumns {
+							iter.Skip()
+							continue
+						}
+						// the values of the series without columns are a table
+						row = append(row, influxValue{kind: jsoniter.NilValue})
+						columns = append(columns, &influxColumn{rows: rows})
+					}
+					row[i] = readInfluxValue(iter)
+				}
+
+				if timeIdx >= 0 {
+					t, ok := influxTime(row[timeIdx])
+					if !ok || !opt.rowLimit.take() {
+						continue
+					}
+					timeField.Append(t)
+				} else if !opt.rowLimit.take() {
+					continue
+				}
+				for i, c := range columns {
+					if i != timeIdx {
+						c.append(row[i])
+					}
+				}
+				rows++
+			}
+
+		case "partial":
+			iter.Skip()
+
+		default:
+			iter.Skip()
+			skippedKey(iter, "series", l1Field)
+		}
+	}
+
+	if timeIdx < 0 {
+		fields := make([]*data.Field, 0, len(columns))
+		for _, c := range columns {
+			fields = append(fields, c.columnField())
+		}
+		frame := data.NewFrame(name, fields...)
+		frame.Meta = &data.FrameMeta{
+			Custom: resultTypeToCustomMeta("influxql"),
+		}
+		return []*data.Frame{frame}
+	}
+
+	valueFields := make([]*data.Field, 0, len(columns))
+	for i, c := range columns {
+		if i == timeIdx {
+			continue
+		}
+		f := c.columnField()
+		f.Labels = labels
+		valueFields = append(valueFields, f)
+	}
+
+	if opt.MatrixWideSeries {
+		frame := data.NewFrame(name, append([]*data.Field{timeField}, valueFields...)...)
+		frame.Meta = &data.FrameMeta{
+			Type:   data.FrameTypeTimeSeriesWide,
+			Custom: resultTypeToCustomMeta("influxql"),
+		}
+		return []*data.Frame{frame}
+	}
+
+	// the frames of the columns share the time field
+	frames := make([]*data.Frame, 0, len(valueFields))
+	for _, f := range valueFields {
+		frame := data.NewFrame(name, timeField, f)
+		frame.Meta = &data.FrameMeta{
+			Type:   data.FrameTypeTimeSeriesMulti,
+			Custom: resultTypeToCustomMeta("influxql"),
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func readInfluxValue(iter *jsoniter.Iterator) influxValue {
+	switch iter.WhatIsNext() {
+	case jsoniter.NumberValue:
+		return influxValue{kind: jsoniter.NumberValue, num: string(iter.ReadNumber())}
+	case jsoniter.StringValue:
+		return influxValue{kind: jsoniter.StringValue, str: iter.ReadString()}
+	case jsoniter.BoolValue:
+		return influxValue{kind: jsoniter.BoolValue, b: iter.ReadBool()}
+	default:
+		iter.Skip()
+		return influxValue{kind: jsoniter.NilValue}
+	}
+}
+
+// influxTime reads the time of a row, in milliseconds or as a RFC3339 string
+func influxTime(v influxValue) (time.Time, bool) {
+	switch v.kind {
+	case jsoniter.NumberValue:
+		ms, err := strconv.ParseInt(v.num, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.UnixMilli(ms).UTC(), true
+	case jsoniter.StringValue:
+		t, err := time.Parse(time.RFC3339Nano, v.str)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t.UTC(), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,144 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+const influxQLResponse = `{"results":[
+	{"statement_id":0,"series":[
+		{"name":"cpu","tags":{"host":"a"},"columns":["time","mean","path","active"],"values":[
+			[1000,null,"/usr",true],
+			[2000,2.5,"/usr",false],
+			["bad",3,"/usr",true],
+			[3000,"x",null,null]
+		]},
+		{"name":"cpu","tags":{"host":"b"},"columns":["time","mean"],"values":[[1000,null]]}
+	],"messages":[{"level":"warning","text":"deprecated"}]},
+	{"statement_id":1,"error":"query-timeout limit exceeded"},
+	{"statement_id":2,"series":[{"name":"measurements","columns":["name"],"values":[["cpu"],["mem"]]}]}
+]}`
+
+func TestReadInfluxQLResult(t *testing.T) {
+	read := func(body string, opt Options) []*data.Frame {
+		responses, err := ReadInfluxQLResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		require.NoError(t, err)
+		require.Len(t, responses, 3)
+		require.EqualError(t, responses[1].Error, "query-timeout limit exceeded")
+		require.Len(t, responses[2].Frames, 1)
+		return responses[0].Frames
+	}
+
+	t.Run("multi", func(t *testing.T) {
+		frames := read(influxQLResponse, Options{})
+		require.Len(t, frames, 4)
+
+		times := []time.Time{time.UnixMilli(1000).UTC(), time.UnixMilli(2000).UTC(), time.UnixMilli(3000).UTC()}
+		mean := frames[0]
+		require.Equal(t, "cpu", mean.Name)
+		require.Equal(t, data.FrameTypeTimeSeriesMulti, mean.Meta.Type)
+		require.Equal(t, "influxql", frameResultType(mean))
+		require.Equal(t, []data.Notice{{Severity: data.NoticeSeverityWarning, Text: "deprecated"}}, mean.Meta.Notices)
+		require.Equal(t, "mean", mean.Fields[1].Name)
+		require.Equal(t, data.Labels{"host": "a"}, mean.Fields[1].Labels)
+		require.Equal(t, times, []time.Time{mean.Fields[0].At(0).(time.Time), mean.Fields[0].At(1).(time.Time), mean.Fields[0].At(2).(time.Time)})
+		require.Equal(t, data.FieldTypeNullableFloat64, mean.Fields[1].Type())
+		require.Nil(t, mean.Fields[1].At(0))
+		require.Equal(t, 2.5, *mean.Fields[1].At(1).(*float64))
+		require.Nil(t, mean.Fields[1].At(2))
+
+		// the type of a column is the one of its first value
+		path := frames[1].Fields[1]
+		require.Equal(t, data.FieldTypeString, path.Type())
+		require.Equal(t, []interface{}{"/usr", "/usr", ""}, []interface{}{path.At(0), path.At(1), path.At(2)})
+		active := frames[2].Fields[1]
+		require.Equal(t, data.FieldTypeBool, active.Type())
+		require.Equal(t, []interface{}{true, false, false}, []interface{}{active.At(0), active.At(1), active.At(2)})
+		require.Same(t, frames[0].Fields[0], frames[2].Fields[0])
+
+		// a column without values
+		require.Equal(t, data.FieldTypeNullableFloat64, frames[3].Fields[1].Type())
+		require.Equal(t, 1, frames[3].Rows())
+	})
+
+	t.Run("wide", func(t *testing.T) {
+		frames := read(influxQLResponse, Options{MatrixWideSeries: true})
+		require.Len(t, frames, 2)
+		require.Equal(t, data.FrameTypeTimeSeriesWide, frames[0].Meta.Type)
+		require.Len(t, frames[0].Fields, 4)
+		require.Equal(t, 3, frames[0].Rows())
+	})
+
+	t.Run("table", func(t *testing.T) {
+		responses, err := ReadInfluxQLResult(jsoniter.ParseString(jsoniter.ConfigDefault, influxQLResponse), Options{})
+		require.NoError(t, err)
+		table := responses[2].Frames[0]
+		require.Equal(t, "measurements", table.Name)
+		require.Len(t, table.Fields, 1)
+		require.Equal(t, "name", table.Fields[0].Name)
+		require.Equal(t, "mem", table.Fields[0].At(1))
+
+		responses, err = ReadInfluxQLResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"results":[{"series":[
+			{"name":"cpu","values":[["values","cpu0"],["values"],["values","cpu1"]]}
+		]}]}`), Options{})
+		require.NoError(t, err)
+		table = responses[0].Frames[0]
+		require.Len(t, table.Fields, 2)
+		require.Equal(t, []interface{}{"cpu0", "", "cpu1"}, []interface{}{table.Fields[1].At(0), table.Fields[1].At(1), table.Fields[1].At(2)})
+	})
+
+	t.Run("rfc3339 times and max rows", func(t *testing.T) {
+		responses, err := ReadInfluxQLResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"results":[{"series":[
+			{"name":"cpu","columns":["time","mean"],"values":[["2022-01-11T08:25:30Z",1],["2022-01-11T08:25:45.5Z",2],["2022-01-11T08:26:00Z",3]]}
+		]}]}`), Options{MaxRows: 2})
+		require.NoError(t, err)
+		frame := responses[0].Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, time.Date(2022, 1, 11, 8, 25, 45, 500000000, time.UTC), frame.Fields[0].At(1))
+		require.Equal(t, "Results were truncated to 2 rows", frame.Meta.Notices[0].Text)
+	})
+
+	t.Run("null first values and invalid times", func(t *testing.T) {
+		responses, err := ReadInfluxQLResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"results":[{"series":[
+			{"name":"cpu","columns":["time","mean","host"],"values":[
+				[1609556645000,null,null],
+				["hello",51,"a"],
+				[1609556645000.5,52,"b"],
+				[null,53,"c"],
+				[1609556646000,54,"d"],
+				[1609556647000,"95.4","e"]
+			]}
+		]}]}`), Options{})
+		require.NoError(t, err)
+		frames := responses[0].Frames
+		require.Len(t, frames, 2)
+
+		// the rows with an invalid time are skipped
+		mean := frames[0]
+		require.Equal(t, 3, mean.Rows())
+		require.Equal(t, time.UnixMilli(1609556645000).UTC(), mean.Fields[0].At(0))
+		require.Equal(t, time.UnixMilli(1609556646000).UTC(), mean.Fields[0].At(1))
+
+		// a null first value does not decide the type, and strings are null in a number column
+		require.Equal(t, data.FieldTypeNullableFloat64, mean.Fields[1].Type())
+		require.Nil(t, mean.Fields[1].At(0))
+		require.Equal(t, 54.0, *mean.Fields[1].At(1).(*float64))
+		require.Nil(t, mean.Fields[1].At(2))
+
+		host := frames[1].Fields[1]
+		require.Equal(t, data.FieldTypeString, host.Type())
+		require.Equal(t, []interface{}{"", "d", "e"}, []interface{}{host.At(0), host.At(1), host.At(2)})
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := ReadInfluxQLResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"error":"error parsing query: found THING"}`), Options{})
+		require.EqualError(t, err, "error parsing query: found THING")
+
+		_, err = ReadInfluxQLResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{ invalid }`), Options{})
+		require.Error(t, err)
+	})
+}
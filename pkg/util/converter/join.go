@@ -0,0 +1,171 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type JoinMode string
+
+const (
+	// JoinModeOuter keeps every timestamp, values missing from a series are null
+	JoinModeOuter JoinMode = "outer"
+	// JoinModeInner only keeps timestamps where every series has a value
+	JoinModeInner JoinMode = "inner"
+)
+
+type JoinOptions struct {
+	Mode JoinMode
+
+	// Timestamps closer than the tolerance to the first timestamp of a row are
+	// joined into that row. This helps joining series scraped at slightly different times.
+	Tolerance time.Duration
+}
+
+type joinSeries struct {
+	time  *data.Field
+	value *data.Field
+}
+
+// JoinOnTime joins all the time series in the converted responses into a single wide frame.
+// Every frame with a time field contributes all its other fields as columns. Frames without
+// a time field (labels, metadata, ...) are ignored.
+func JoinOnTime(responses []backend.DataResponse, opt JoinOptions) (*data.Frame, error) {
+	series := []joinSeries{}
+	for _, rsp := range responses {
+		if rsp.Error != nil {
+			return nil, rsp.Error
+		}
+		for _, frame := range rsp.Frames {
+			timeIdx := -1
+			for i, f := range frame.Fields {
+				if f.Type() == data.FieldTypeTime || f.Type() == data.FieldTypeNullableTime {
+					timeIdx = i
+					break
+				}
+			}
+			if timeIdx < 0 {
+				continue
+			}
+			for i, f := range frame.Fields {
+				if i == timeIdx || f.Type().Time() {
+					continue
+				}
+				series = append(series, joinSeries{time: frame.Fields[timeIdx], value: f})
+			}
+		}
+	}
+
+	times := joinedTimes(series, opt.Tolerance)
+
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, len(times))
+	timeField.Name = data.TimeSeriesTimeFieldName
+	for i, t := range times {
+		timeField.Set(i, time.Unix(0, t).UTC())
+	}
+	frame := data.NewFrame("", timeField)
+	frame.Meta = &data.FrameMeta{
+		Type: data.FrameTypeTimeSeriesWide,
+	}
+
+	// count the values per row to support inner joins
+	counts := make([]int, len(times))
+	for _, s := range series {
+		field := data.NewFieldFromFieldType(s.value.Type().NullableType(), len(times))
+		field.Name = s.value.Name
+		field.Labels = s.value.Labels
+		field.Config = s.value.Config
+
+		seen := make([]bool, len(times))
+		for i := 0; i < s.time.Len(); i++ {
+			t, ok := joinTimeAt(s.time, i)
+			if !ok {
+				continue
+			}
+			row := rowForTime(times, t, opt.Tolerance)
+			if row < 0 {
+				return nil, fmt.Errorf("unable to find row for time %d", t)
+			}
+			v, ok := s.value.ConcreteAt(i)
+			if !ok {
+				continue
+			}
+			field.SetConcrete(row, v)
+			if !seen[row] {
+				seen[row] = true
+				counts[row]++
+			}
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+
+	if opt.Mode == JoinModeInner {
+		for row := len(times) - 1; row >= 0; row-- {
+			if counts[row] < len(series) {
+				frame.DeleteRow(row)
+			}
+		}
+	}
+
+	return frame, nil
+}
+
+// joinedTimes returns the sorted row times in nanoseconds. When a tolerance
+// is set, a row starts at the first time not within tolerance of the previous row.
+func joinedTimes(series []joinSeries, tolerance time.Duration) []int64 {
+	unique := map[int64]struct{}{}
+	for _, s := range series {
+		for i := 0; i < s.time.Len(); i++ {
+			if t, ok := joinTimeAt(s.time, i); ok {
+				unique[t] = struct{}{}
+			}
+		}
+	}
+
+	all := make([]int64, 0, len(unique))
+	for t := range unique {
+		all = append(all, t)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+
+	if tolerance <= 0 || len(all) == 0 {
+		return all
+	}
+
+	times := make([]int64, 0, len(all))
+	times = append(times, all[0])
+	for _, t := range all[1:] {
+		if t-times[len(times)-1] > int64(tolerance) {
+			times = append(times, t)
+		}
+	}
+	return times
+}
+
+// rowForTime finds the last row starting at or before t
+func rowForTime(times []int64, t int64, tolerance time.Duration) int {
+	idx := sort.Search(len(times), func(i int) bool { return times[i] > t }) - 1
+	if idx < 0 {
+		return -1
+	}
+	if times[idx] != t && t-times[idx] > int64(tolerance) {
+		return -1
+	}
+	return idx
+}
+
+func joinTimeAt(f *data.Field, idx int) (int64, bool) {
+	v, ok := f.ConcreteAt(idx)
+	if !ok {
+		return 0, false
+	}
+	t, ok := v.(time.Time)
+	if !ok {
+		return 0, false
+	}
+	return t.UnixNano(), true
+}
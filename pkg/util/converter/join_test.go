@@ -0,0 +1,46 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinOnTime(t *testing.T) {
+	read := func(body string) backend.DataResponse {
+		return ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{})
+	}
+	a := read(`{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"__name__":"a"},"values":[[10,"1"],[20,"2"],[30,"3"]]}
+	]}}`)
+	b := read(`{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"__name__":"b"},"values":[[20.5,"20"],[40,"40"]]}
+	]}}`)
+
+	t.Run("outer join", func(t *testing.T) {
+		frame, err := JoinOnTime([]backend.DataResponse{a, b}, JoinOptions{Mode: JoinModeOuter})
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 5, frame.Rows())
+		require.Nil(t, frame.Fields[2].At(0))
+	})
+
+	t.Run("outer join with tolerance", func(t *testing.T) {
+		frame, err := JoinOnTime([]backend.DataResponse{a, b}, JoinOptions{Mode: JoinModeOuter, Tolerance: time.Second})
+		require.NoError(t, err)
+		require.Equal(t, 4, frame.Rows())
+		require.Equal(t, time.Unix(20, 0).UTC(), frame.Fields[0].At(1))
+		require.Equal(t, 20.0, *frame.Fields[2].At(1).(*float64))
+	})
+
+	t.Run("inner join with tolerance", func(t *testing.T) {
+		frame, err := JoinOnTime([]backend.DataResponse{a, b}, JoinOptions{Mode: JoinModeInner, Tolerance: time.Second})
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, 2.0, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, 20.0, *frame.Fields[2].At(0).(*float64))
+	})
+}
@@ -0,0 +1,194 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// LevelSource identifies where a log level can be detected from
+type LevelSource string
+
+const (
+	LevelSourceLabels LevelSource = "labels"
+	LevelSourceJSON   LevelSource = "json"
+	LevelSourceLine   LevelSource = "line"
+)
+
+const levelFieldName = "level"
+
+// Level values understood by the logs panel and the logs volume histogram
+const (
+	LogLevelCritical = "critical"
+	LogLevelError    = "error"
+	LogLevelWarning  = "warning"
+	LogLevelInfo     = "info"
+	LogLevelDebug    = "debug"
+	LogLevelTrace    = "trace"
+	LogLevelUnknown  = "unknown"
+)
+
+// LevelDetection configures how a log level is detected for every stream entry
+type LevelDetection struct {
+	// Sources are checked in order, the first one that finds a level wins.
+	// When empty, labels are checked first, then JSON fields and then the line prefix.
+	Sources []LevelSource
+
+	// Keys to look for in stream labels and JSON log lines.
+	// When empty, the defaultLevelKeys are used.
+	Keys []string
+}
+
+var defaultLevelSources = []LevelSource{LevelSourceLabels, LevelSourceJSON, LevelSourceLine}
+
+var defaultLevelKeys = []string{"level", "lvl", "loglevel", "severity"}
+
+// levelTranslation maps the (lowercase) values found in the wild to the
+// level values known by the frontend
+var levelTranslation = map[string]string{
+	"emerg":         LogLevelCritical,
+	"emergency":     LogLevelCritical,
+	"alert":         LogLevelCritical,
+	"crit":          LogLevelCritical,
+	"critical":      LogLevelCritical,
+	"fatal":         LogLevelCritical,
+	"panic":         LogLevelCritical,
+	"err":           LogLevelError,
+	"eror":          LogLevelError,
+	"error":         LogLevelError,
+	"warn":          LogLevelWarning,
+	"warning":       LogLevelWarning,
+	"wrn":           LogLevelWarning,
+	"info":          LogLevelInfo,
+	"inf":           LogLevelInfo,
+	"information":   LogLevelInfo,
+	"informational": LogLevelInfo,
+	"notice":        LogLevelInfo,
+	"debug":         LogLevelDebug,
+	"dbug":          LogLevelDebug,
+	"dbg":           LogLevelDebug,
+	"trace":         LogLevelTrace,
+	"trc":           LogLevelTrace,
+}
+
+// TranslateLevel returns the frontend level for a raw level value, or an empty string when unknown
+func TranslateLevel(v string) string {
+	return levelTranslation[strings.ToLower(strings.TrimSpace(v))]
+}
+
+type levelDetector struct {
+	sources []LevelSource
+	keys    []string
+}
+
+func newLevelDetector(opt *LevelDetection) *levelDetector {
+	if opt == nil {
+		return nil
+	}
+	d := &levelDetector{
+		sources: opt.Sources,
+		keys:    defaultLevelKeys,
+	}
+	if len(d.sources) == 0 {
+		d.sources = defaultLevelSources
+	}
+	if len(opt.Keys) > 0 {
+		d.keys = make([]string, len(opt.Keys))
+		for i, k := range opt.Keys {
+			d.keys[i] = strings.ToLower(k)
+		}
+	}
+	return d
+}
+
+func newLevelField() *data.Field {
+	f := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	f.Name = levelFieldName
+	return f
+}
+
+// detect returns the level for a single log line. The level found in the
+// stream labels is passed in since it is the same for all lines in a stream.
+func (d *levelDetector) detect(labelLevel string, line string) string {
+	for _, src := range d.sources {
+		lvl := ""
+		switch src {
+		case LevelSourceLabels:
+			lvl = labelLevel
+		case LevelSourceJSON:
+			lvl = d.fromJSON(line)
+		case LevelSourceLine:
+			lvl = levelFromLinePrefix(line)
+		}
+		if lvl != "" {
+			return lvl
+		}
+	}
+	return LogLevelUnknown
+}
+
+func (d *levelDetector) fromLabels(labels data.Labels) string {
+	for _, k := range d.keys {
+		if v, ok := labels[k]; ok {
+			if lvl := TranslateLevel(v); lvl != "" {
+				return lvl
+			}
+		}
+	}
+	return ""
+}
+
+func (d *levelDetector) fromJSON(line string) string {
+	s := strings.TrimSpace(line)
+	if len(s) < 2 || s[0] != '{' {
+		return ""
+	}
+
+	iter := jsoniter.ParseString(jsoniter.ConfigDefault, s)
+	found := map[string]string{}
+	for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+		if iter.WhatIsNext() == jsoniter.StringValue && d.isKey(k) {
+			found[strings.ToLower(k)] = iter.ReadString()
+			continue
+		}
+		iter.Skip()
+	}
+	if iter.Error != nil {
+		return ""
+	}
+
+	// respect the configured key order, not the order in the line
+	for _, k := range d.keys {
+		if v, ok := found[k]; ok {
+			if lvl := TranslateLevel(v); lvl != "" {
+				return lvl
+			}
+		}
+	}
+	return ""
+}
+
+func (d *levelDetector) isKey(k string) bool {
+	for _, key := range d.keys {
+		if strings.EqualFold(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// levelFromLinePrefix finds levels in lines like "ERROR something", "[warn] something" or "info: something"
+func levelFromLinePrefix(line string) string {
+	s := strings.TrimLeft(line, " \t[(<")
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	})
+	if end < 0 {
+		end = len(s)
+	}
+	if end == 0 {
+		return ""
+	}
+	return TranslateLevel(s[:end])
+}
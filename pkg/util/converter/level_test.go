@@ -0,0 +1,54 @@
+package converter
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelDetection(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"level":"WARN","app":"a"},"values":[["1645030244810757120","anything"]]},
+		{"stream":{"app":"b"},"values":[
+			["1645030244810757121","{\"msg\":\"hello\",\"lvl\":\"err\"}"],
+			["1645030244810757122","[DEBUG] something"],
+			["1645030244810757123","no level here"]
+		]}
+	]}}`
+
+	t.Run("no level field by default", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		_, idx := rsp.Frames[0].FieldByName(levelFieldName)
+		require.Equal(t, -1, idx)
+	})
+
+	t.Run("default sources", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{
+			LevelDetection: &LevelDetection{},
+		})
+		require.NoError(t, rsp.Error)
+		f, idx := rsp.Frames[0].FieldByName(levelFieldName)
+		require.NotEqual(t, -1, idx)
+		require.Equal(t, []string{LogLevelWarning, LogLevelError, LogLevelDebug, LogLevelUnknown}, fieldStrings(f.Len(), f.At))
+	})
+
+	t.Run("configured sources", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{
+			LevelDetection: &LevelDetection{Sources: []LevelSource{LevelSourceLine}},
+		})
+		require.NoError(t, rsp.Error)
+		f, _ := rsp.Frames[0].FieldByName(levelFieldName)
+		require.Equal(t, []string{LogLevelUnknown, LogLevelUnknown, LogLevelDebug, LogLevelUnknown}, fieldStrings(f.Len(), f.At))
+	})
+}
+
+func fieldStrings(n int, at func(int) interface{}) []string {
+	out := make([]string, n)
+	for i := 0; i < n; i++ {
+		out[i] = at(i).(string)
+	}
+	return out
+}
@@ -0,0 +1,40 @@
+package converter
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// rowLimit counts the rows of the whole response, it is shared by all the frames
+type rowLimit struct {
+	max       int
+	count     int
+	truncated bool
+	// the rows beyond max
+	dropped int
+}
+
+// take reports if one more row fits, a nil limit always fits
+func (l *rowLimit) take() bool {
+	if l == nil {
+		return true
+	}
+	if l.count >= l.max {
+		l.truncated = true
+		l.dropped++
+		return false
+	}
+	l.count++
+	return true
+}
+
+func addTruncatedNotice(frames data.Frames, max int) {
+	notice := data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     fmt.Sprintf("Results were truncated to %d rows", max),
+	}
+	for _, frame := range frames {
+		frame.AppendNotices(notice)
+	}
+}
@@ -0,0 +1,47 @@
+package converter
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxRows(t *testing.T) {
+	t.Run("matrix", func(t *testing.T) {
+		for _, wide := range []bool{false, true} {
+			rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrixResponse(3)), Options{MaxRows: 3, MatrixWideSeries: wide})
+			require.NoError(t, rsp.Error)
+
+			values := 0
+			for _, frame := range rsp.Frames {
+				for _, f := range frame.Fields[1:] {
+					for i := 0; i < f.Len(); i++ {
+						if _, ok := f.ConcreteAt(i); ok {
+							values++
+						}
+					}
+				}
+				require.Len(t, frame.Meta.Notices, 1)
+				require.Equal(t, "Results were truncated to 3 rows", frame.Meta.Notices[0].Text)
+			}
+			require.Equal(t, 3, values)
+		}
+	})
+
+	t.Run("streams", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"a"},"values":[["1645030244810757120","1"],["1645030244810757121","2"]]},
+			{"stream":{"app":"b"},"values":[["1645030244810757122","3"]]}
+		]}}`), Options{MaxRows: 2})
+		require.NoError(t, rsp.Error)
+		require.Equal(t, 2, rsp.Frames[0].Rows())
+		require.Len(t, rsp.Frames[0].Meta.Notices, 1)
+	})
+
+	t.Run("no notice below the limit", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrixResponse(1)), Options{MaxRows: 2})
+		require.NoError(t, rsp.Error)
+		require.Empty(t, rsp.Frames[0].Meta.Notices)
+	})
+}
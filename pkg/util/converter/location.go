@@ -0,0 +1,32 @@
+package converter
+
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// applyLocation converts the time fields of the frames to the location of the options,
+// the converter creates all the times in UTC
+func applyLocation(frames data.Frames, opt Options) {
+	if opt.Location == nil || opt.Location == time.UTC {
+		return
+	}
+	for _, frame := range frames {
+		for _, f := range frame.Fields {
+			switch f.Type() {
+			case data.FieldTypeTime:
+				for i := 0; i < f.Len(); i++ {
+					f.Set(i, f.At(i).(time.Time).In(opt.Location))
+				}
+			case data.FieldTypeNullableTime:
+				for i := 0; i < f.Len(); i++ {
+					if t, ok := f.At(i).(*time.Time); ok && t != nil {
+						local := t.In(opt.Location)
+						f.Set(i, &local)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+
+	t.Run("matrix", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrixResponse(1)), Options{Location: loc})
+		require.NoError(t, rsp.Error)
+		ts := rsp.Frames[0].Fields[0].At(0).(time.Time)
+		require.Equal(t, loc, ts.Location())
+	})
+
+	t.Run("streams", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"a"},"values":[["1645030244810757120","line 1"]]}
+		]}}`), Options{Location: loc})
+		require.NoError(t, rsp.Error)
+		ts := rsp.Frames[0].Fields[1].At(0).(time.Time)
+		require.Equal(t, loc, ts.Location())
+		require.Equal(t, "2022-02-16T18:50:44.81075712+02:00", ts.Format(time.RFC3339Nano))
+	})
+
+	t.Run("utc by default", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrixResponse(1)), Options{})
+		require.NoError(t, rsp.Error)
+		require.Equal(t, time.UTC, rsp.Frames[0].Fields[0].At(0).(time.Time).Location())
+	})
+}
@@ -0,0 +1,426 @@
+package converter
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	structuredMetadataFieldName = "structuredMetadata"
+	parsedLabelsFieldName       = "parsedLabels"
+)
+
+// structuredMetadataBuilder collects the structured metadata that newer Loki versions
+// send as a third element of every entry: [ "<ts>", "<line>", { "traceID": "..." } ]
+// It also collects the parsed labels of the categorize-labels encoding.
+type structuredMetadataBuilder struct {
+	name    string
+	explode bool
+	rows    []data.Labels
+	found   bool
+}
+
+func newStructuredMetadataBuilder(opt Options) *structuredMetadataBuilder {
+	return &structuredMetadataBuilder{
+		name:    structuredMetadataFieldName,
+		explode: opt.ExplodeStructuredMetadata,
+	}
+}
+
+// the parsed labels are always a single field, so they can not clash with the exploded metadata
+func newParsedLabelsBuilder() *structuredMetadataBuilder {
+	return &structuredMetadataBuilder{
+		name: parsedLabelsFieldName,
+	}
+}
+
+// readStructuredMetadata consumes the rest of a stream entry after the line, and must be called for every entry.
+// With the categorize-labels encoding flag, the labels are grouped by where they come from:
+// [ "<ts>", "<line>", { "structuredMetadata": { ... }, "parsed": { ... } } ]
+// The stream labels are still in the "stream" of the result.
+func readStructuredMetadata(iter *jsoniter.Iterator) (md data.Labels, parsed data.Labels) {
+	if iter.ReadArray() {
+		if iter.WhatIsNext() == jsoniter.ObjectValue {
+			for key := iter.ReadObject(); key != ""; key = iter.ReadObject() {
+				switch iter.WhatIsNext() {
+				case jsoniter.ObjectValue:
+					labels := data.Labels{}
+					iter.ReadVal(&labels)
+					switch key {
+					case "structuredMetadata":
+						md = mergeLabels(md, labels)
+					case "parsed":
+						parsed = mergeLabels(parsed, labels)
+					}
+				case jsoniter.StringValue:
+					if md == nil {
+						md = data.Labels{}
+					}
+					md[key] = iter.ReadString()
+				default:
+					iter.Skip()
+				}
+			}
+		} else {
+			iter.Skip()
+		}
+		// anything after the metadata is not supported yet
+		for iter.ReadArray() {
+			iter.Skip()
+		}
+	}
+	return md, parsed
+}
+
+func mergeLabels(dst data.Labels, src data.Labels) data.Labels {
+	if dst == nil {
+		return src
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+// add must be called for every row of the frame
+func (b *structuredMetadataBuilder) add(md data.Labels) {
+	if len(md) > 0 {
+		b.found = true
+	}
+	b.rows = append(b.rows, md)
+}
+
+// fields returns nothing when no entry has structured metadata, so responses
+// from older Loki versions keep the same shape
+func (b *structuredMetadataBuilder) fields() ([]*data.Field, error) {
+	if !b.found {
+		return nil, nil
+	}
+
+	if !b.explode {
+		field := data.NewFieldFromFieldType(data.FieldTypeJSON, len(b.rows))
+		field.Name = b.name
+		for i, md := range b.rows {
+			if md == nil {
+				md = data.Labels{}
+			}
+			v, err := labelsToRawJson(md)
+			if err != nil {
+				return nil, err
+			}
+			field.Set(i, v)
+		}
+		return []*data.Field{field}, nil
+	}
+
+	keys := map[string]*data.Field{}
+	for i, md := range b.rows {
+		for k, v := range md {
+			f, ok := keys[k]
+			if !ok {
+				f = data.NewFieldFromFieldType(data.FieldTypeString, len(b.rows))
+				f.Name = k
+				keys[k] = f
+			}
+			f.Set(i, v)
+		}
+	}
+
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	fields := make([]*data.Field, 0, len(names))
+	for _, k := range names {
+		fields = append(fields, keys[k])
+	}
+	return fields, nil
+}
+
+const defaultMaxJSONLineFields = 50
+
+// jsonLineFieldsBuilder adds a typed field for every top level key of JSON log lines.
+// The field type is picked by the first value of a key, values of another type are null.
+type jsonLineFieldsBuilder struct {
+	maxFields int
+	rows      int
+	keys      []string
+	values    map[string][]interface{}
+}
+
+func newJSONLineFieldsBuilder(opt Options) *jsonLineFieldsBuilder {
+	if !opt.ParseJSONLines {
+		return nil
+	}
+	maxFields := opt.MaxJSONLineFields
+	if maxFields <= 0 {
+		maxFields = defaultMaxJSONLineFields
+	}
+	return &jsonLineFieldsBuilder{
+		maxFields: maxFields,
+		values:    map[string][]interface{}{},
+	}
+}
+
+// add must be called for every entry, lines that are not a JSON object only add nulls
+func (b *jsonLineFieldsBuilder) add(line string) {
+	row := b.rows
+	b.rows++
+
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") {
+		return
+	}
+
+	type kv struct {
+		key   string
+		value interface{}
+	}
+	pairs := []kv{}
+	iter := jsoniter.ParseString(jsoniter.ConfigDefault, trimmed)
+	for key := iter.ReadObject(); key != ""; key = iter.ReadObject() {
+		var v interface{}
+		switch iter.WhatIsNext() {
+		case jsoniter.StringValue:
+			v = iter.ReadString()
+		case jsoniter.NumberValue:
+			v = iter.ReadFloat64()
+		case jsoniter.BoolValue:
+			v = iter.ReadBool()
+		case jsoniter.NilValue:
+			iter.Skip()
+		default:
+			raw := iter.SkipAndReturnBytes()
+			v = json.RawMessage(append([]byte{}, raw...))
+		}
+		pairs = append(pairs, kv{key: key, value: v})
+	}
+	if iter.Error != nil {
+		// not a JSON line after all
+		return
+	}
+
+	for _, p := range pairs {
+		if p.value == nil {
+			continue
+		}
+		values, ok := b.values[p.key]
+		if !ok {
+			if len(b.keys) >= b.maxFields {
+				continue
+			}
+			b.keys = append(b.keys, p.key)
+		}
+		if len(values) > row {
+			// duplicate key, the first value wins
+			continue
+		}
+		for len(values) < row {
+			values = append(values, nil)
+		}
+		b.values[p.key] = append(values, p.value)
+	}
+}
+
+// fields returns the parsed fields in the order the keys were first seen. Keys with the
+// name of an existing field get an "_extracted" suffix, like in the LogQL json parser.
+func (b *jsonLineFieldsBuilder) fields(existing []*data.Field) []*data.Field {
+	names := map[string]bool{}
+	for _, f := range existing {
+		names[f.Name] = true
+	}
+
+	fields := make([]*data.Field, 0, len(b.keys))
+	for _, key := range b.keys {
+		values := b.values[key]
+
+		var field *data.Field
+		for _, v := range values {
+			if v != nil {
+				field = data.NewFieldFromFieldType(jsonLineFieldType(v), b.rows)
+				break
+			}
+		}
+		for i, v := range values {
+			if v == nil || jsonLineFieldType(v) != field.Type() {
+				continue
+			}
+			field.SetConcrete(i, v)
+		}
+
+		field.Name = key
+		if names[key] {
+			field.Name = key + "_extracted"
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func jsonLineFieldType(v interface{}) data.FieldType {
+	switch v.(type) {
+	case float64:
+		return data.FieldTypeNullableFloat64
+	case bool:
+		return data.FieldTypeNullableBool
+	case json.RawMessage:
+		return data.FieldTypeNullableJSON
+	default:
+		return data.FieldTypeNullableString
+	}
+}
+
+// DedupStrategy mirrors the dedup modes of the logs panel
+type DedupStrategy string
+
+const (
+	DedupNone DedupStrategy = ""
+	// DedupExact drops lines that are equal to a line seen before
+	DedupExact DedupStrategy = "exact"
+	// DedupNumbers drops lines that only differ in their digits, like timestamps and durations
+	DedupNumbers DedupStrategy = "numbers"
+	// DedupSignature drops lines that only differ in letters and digits, keeping whitespace and punctuation
+	DedupSignature DedupStrategy = "signature"
+)
+
+var (
+	dedupNumbersRegexp   = regexp.MustCompile(`\d`)
+	dedupSignatureRegexp = regexp.MustCompile(`\w`)
+)
+
+// lineDeduper remembers the lines of all the streams in the response
+type lineDeduper struct {
+	strategy DedupStrategy
+	seen     map[string]struct{}
+}
+
+func newLineDeduper(strategy DedupStrategy) *lineDeduper {
+	if strategy == DedupNone {
+		return nil
+	}
+	return &lineDeduper{
+		strategy: strategy,
+		seen:     map[string]struct{}{},
+	}
+}
+
+func (d *lineDeduper) isDuplicate(line string) bool {
+	key := line
+	switch d.strategy {
+	case DedupNumbers:
+		key = dedupNumbersRegexp.ReplaceAllString(line, "")
+	case DedupSignature:
+		key = dedupSignatureRegexp.ReplaceAllString(line, "")
+	}
+	if _, ok := d.seen[key]; ok {
+		return true
+	}
+	d.seen[key] = struct{}{}
+	return false
+}
+
+// The index stats are returned as a flat object:
+// { "streams": 2, "chunks": 2246, "bytes": 7390745, "entries": 25866 }
+// Every stat is added as a single value field
+func newIndexStatsFrame() *data.Frame {
+	frame := data.NewFrame("indexStats")
+	frame.Meta = &data.FrameMeta{
+		Custom: resultTypeToCustomMeta("indexStats"),
+	}
+	return frame
+}
+
+func readIndexStat(iter *jsoniter.Iterator, name string, frame *data.Frame) {
+	field := data.NewField(name, nil, []int64{iter.ReadInt64()})
+	if name == "bytes" {
+		field.Config = &data.FieldConfig{Unit: "bytes"}
+	}
+	frame.Fields = append(frame.Fields, field)
+}
+
+// setVolumeUnit marks the values of index volume responses as bytes
+func setVolumeUnit(frames data.Frames) {
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			if field.Config == nil {
+				field.Config = &data.FieldConfig{}
+			}
+			field.Config.Unit = "bytes"
+		}
+	}
+}
+
+// readPatternSamples reads the samples of a /loki/api/v1/patterns entry
+// { "pattern": "<_> level=info <_>", "samples": [ [ 1711839260, 1 ], [ 1711839270, 2 ] ] }
+func readPatternSamples(iter *jsoniter.Iterator) *data.Frame {
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
+	timeField.Name = data.TimeSeriesTimeFieldName
+	valueField := data.NewFieldFromFieldType(data.FieldTypeInt64, 0)
+	valueField.Name = data.TimeSeriesValueFieldName
+
+	for iter.ReadArray() {
+		iter.ReadArray()
+		t := iter.ReadFloat64()
+		iter.ReadArray()
+		v := iter.ReadInt64()
+		for iter.ReadArray() {
+			iter.Skip()
+		}
+		timeField.Append(timeFromFloat(t))
+		valueField.Append(v)
+	}
+
+	frame := data.NewFrame("", timeField, valueField)
+	frame.Meta = &data.FrameMeta{
+		Type:   data.FrameTypeTimeSeriesMulti,
+		Custom: resultTypeToCustomMeta("patterns"),
+	}
+	return frame
+}
+
+func setPatternName(frame *data.Frame, pattern string) {
+	frame.Name = pattern
+	valueField := frame.Fields[1]
+	valueField.Labels = data.Labels{"pattern": pattern}
+	valueField.Config = &data.FieldConfig{DisplayNameFromDS: pattern}
+}
+
+// readLokiVectorLabels reads the labels of a loki vector series, and keeps their JSON as sent
+func readLokiVectorLabels(iter *jsoniter.Iterator, interner stringInterner) (data.Labels, json.RawMessage) {
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		iter.Skip()
+		return data.Labels{}, json.RawMessage("{}")
+	}
+	// the bytes are only valid until the next read
+	raw := json.RawMessage(append([]byte(nil), iter.SkipAndReturnBytes()...))
+	labelsIter := jsoniter.ConfigDefault.BorrowIterator(raw)
+	defer jsoniter.ConfigDefault.ReturnIterator(labelsIter)
+	return readLabels(labelsIter, interner), raw
+}
+
+// addLokiVectorLabels adds the labels JSON of every row after the value field,
+// and flags the frame as a loki one
+func addLokiVectorLabels(frame *data.Frame, labelsJSON json.RawMessage) {
+	if labelsJSON == nil {
+		labelsJSON = json.RawMessage("{}")
+	}
+	labelsField := data.NewFieldFromFieldType(data.FieldTypeJSON, frame.Rows())
+	labelsField.Name = "__labels" // avoid automatically spreading this by labels
+	for i := 0; i < labelsField.Len(); i++ {
+		labelsField.Set(i, labelsJSON)
+	}
+	frame.Fields = append(frame.Fields, labelsField)
+	if custom, ok := frame.Meta.Custom.(map[string]string); ok {
+		custom["origin"] = "loki"
+	}
+}
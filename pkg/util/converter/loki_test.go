@@ -0,0 +1,281 @@
+package converter
+
+import (
+	"encoding/json"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredMetadata(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"app":"a"},"values":[
+			["1645030244810757120","line 1",{"traceID":"abc","pod":"p1"}],
+			["1645030244810757121","line 2"],
+			["1645030244810757122","line 3",{"traceID":"def"}]
+		]}
+	]}}`
+
+	t.Run("json field", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{})
+		require.NoError(t, rsp.Error)
+		f, idx := rsp.Frames[0].FieldByName(structuredMetadataFieldName)
+		require.NotEqual(t, -1, idx)
+		require.Equal(t, json.RawMessage(`{"pod":"p1","traceID":"abc"}`), f.At(0))
+		require.Equal(t, json.RawMessage(`{}`), f.At(1))
+		require.Equal(t, "line 3", rsp.Frames[0].Fields[2].At(2))
+	})
+
+	t.Run("exploded fields", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{ExplodeStructuredMetadata: true})
+		require.NoError(t, rsp.Error)
+		frame := rsp.Frames[0]
+		pod, _ := frame.FieldByName("pod")
+		traceID, _ := frame.FieldByName("traceID")
+		require.Equal(t, []string{"p1", "", ""}, fieldStrings(pod.Len(), pod.At))
+		require.Equal(t, []string{"abc", "", "def"}, fieldStrings(traceID.Len(), traceID.At))
+	})
+
+	t.Run("no field without metadata", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"a"},"values":[["1645030244810757120","line 1"]]}
+		]}}`), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames[0].Fields, 4)
+	})
+}
+
+func TestCategorizedLabels(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"streams","encodingFlags":["categorize-labels"],"result":[
+		{"stream":{"app":"a"},"values":[
+			["1645030244810757120","line 1",{"structuredMetadata":{"traceID":"abc"},"parsed":{"method":"GET"}}],
+			["1645030244810757121","line 2",{"parsed":{"method":"POST"}}],
+			["1645030244810757122","line 3",{}]
+		]}
+	]}}`
+
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{Strict: StrictError})
+	require.NoError(t, rsp.Error)
+	frame := rsp.Frames[0]
+
+	md, _ := frame.FieldByName(structuredMetadataFieldName)
+	require.NotNil(t, md)
+	require.Equal(t, json.RawMessage(`{"traceID":"abc"}`), md.At(0))
+	require.Equal(t, json.RawMessage(`{}`), md.At(1))
+
+	parsed, _ := frame.FieldByName(parsedLabelsFieldName)
+	require.NotNil(t, parsed)
+	require.Equal(t, json.RawMessage(`{"method":"GET"}`), parsed.At(0))
+	require.Equal(t, json.RawMessage(`{"method":"POST"}`), parsed.At(1))
+	require.Equal(t, json.RawMessage(`{}`), parsed.At(2))
+
+	require.Equal(t, json.RawMessage(`{"app":"a"}`), frame.Fields[0].At(0))
+}
+
+func TestInt64LogTimestamps(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"app":"a"},"values":[["1645030244810757120","line 1"],["1645030244810757121","line 2"]]}
+	]}}`
+
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{Int64LogTimestamps: true})
+	require.NoError(t, rsp.Error)
+	ts, _ := rsp.Frames[0].FieldByName("TS")
+	require.Equal(t, int64(1645030244810757120), ts.At(0))
+	require.Equal(t, int64(1645030244810757121), ts.At(1))
+
+	rsp = ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{})
+	require.NoError(t, rsp.Error)
+	ts, _ = rsp.Frames[0].FieldByName("TS")
+	require.Equal(t, "1645030244810757120", ts.At(0))
+}
+
+func TestParseJSONLines(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"app":"a"},"values":[
+			["1645030244810757120","{\"msg\":\"hello\",\"duration\":1.5,\"ok\":true,\"Line\":\"x\"}"],
+			["1645030244810757121","plain text"],
+			["1645030244810757122","{\"msg\":\"bye\",\"duration\":\"slow\",\"ctx\":{\"a\":1}}"],
+			["1645030244810757123","{\"msg\": broken"]
+		]}
+	]}}`
+
+	t.Run("typed fields", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{ParseJSONLines: true})
+		require.NoError(t, rsp.Error)
+		frame := rsp.Frames[0]
+		require.Equal(t, 4, frame.Rows())
+
+		names := []string{}
+		for _, f := range frame.Fields {
+			names = append(names, f.Name)
+		}
+		require.Equal(t, []string{"__labels", "Time", "Line", "TS", "msg", "duration", "ok", "Line_extracted", "ctx"}, names)
+
+		msg, _ := frame.FieldByName("msg")
+		require.Equal(t, "hello", *(msg.At(0).(*string)))
+		require.Nil(t, msg.At(1))
+		require.Equal(t, "bye", *(msg.At(2).(*string)))
+		require.Nil(t, msg.At(3))
+
+		duration, _ := frame.FieldByName("duration")
+		require.Equal(t, 1.5, *(duration.At(0).(*float64)))
+		require.Nil(t, duration.At(2)) // not a number
+
+		ctx, _ := frame.FieldByName("ctx")
+		require.Equal(t, json.RawMessage(`{"a":1}`), *(ctx.At(2).(*json.RawMessage)))
+	})
+
+	t.Run("field limit", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{ParseJSONLines: true, MaxJSONLineFields: 2})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames[0].Fields, 6)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames[0].Fields, 4)
+	})
+}
+
+func TestDedupStreams(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"pod":"a"},"values":[
+			["1645030244810757120","GET /api 200 12ms"],
+			["1645030244810757121","GET /api 200 12ms"],
+			["1645030244810757122","GET /api 500 3ms"]
+		]},
+		{"stream":{"pod":"b"},"values":[
+			["1645030244810757123","GET /api 200 12ms"],
+			["1645030244810757124","POST /login 401 1ms"]
+		]}
+	]}}`
+
+	lines := func(strategy DedupStrategy) []string {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{Dedup: strategy})
+		require.NoError(t, rsp.Error)
+		f, _ := rsp.Frames[0].FieldByName("Line")
+		return fieldStrings(f.Len(), f.At)
+	}
+
+	require.Len(t, lines(DedupNone), 5)
+	require.Equal(t, []string{"GET /api 200 12ms", "GET /api 500 3ms", "POST /login 401 1ms"}, lines(DedupExact))
+	require.Equal(t, []string{"GET /api 200 12ms", "POST /login 401 1ms"}, lines(DedupNumbers))
+	require.Equal(t, []string{"GET /api 200 12ms"}, lines(DedupSignature))
+}
+
+func TestIndexStatsAndVolume(t *testing.T) {
+	t.Run("stats", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault,
+			`{"streams":2,"chunks":2246,"bytes":7390745,"entries":25866}`), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		frame := rsp.Frames[0]
+		require.Equal(t, "indexStats", frame.Name)
+		require.Equal(t, 1, frame.Rows())
+		bytes, _ := frame.FieldByName("bytes")
+		require.Equal(t, int64(7390745), bytes.At(0))
+		require.Equal(t, "bytes", bytes.Config.Unit)
+		entries, _ := frame.FieldByName("entries")
+		require.Equal(t, int64(25866), entries.At(0))
+	})
+
+	t.Run("volume", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"service_name":"api"},"value":[1645029699,"4096"]},
+			{"metric":{"service_name":"db"},"value":[1645029699,"1024"]}
+		]}}`), Options{IndexVolume: true})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 2)
+		for _, frame := range rsp.Frames {
+			require.Equal(t, "bytes", frame.Fields[1].Config.Unit)
+		}
+	})
+}
+
+func TestPatterns(t *testing.T) {
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":[
+		{"pattern":"<_> level=info <_>","samples":[[1711839260,1],[1711839270,2]]},
+		{"samples":[[1711839260,5]],"pattern":"<_> level=error <_>"}
+	]}`), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 2)
+
+	frame := rsp.Frames[0]
+	require.Equal(t, "<_> level=info <_>", frame.Name)
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, int64(2), frame.Fields[1].At(1))
+	require.Equal(t, "<_> level=info <_>", frame.Fields[1].Config.DisplayNameFromDS)
+	require.Equal(t, "patterns", frame.Meta.Custom.(map[string]string)["resultType"])
+
+	require.Equal(t, "<_> level=error <_>", rsp.Frames[1].Name)
+	require.Equal(t, int64(5), rsp.Frames[1].Fields[1].At(0))
+}
+
+func TestSeriesWithPatternLabel(t *testing.T) {
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":[
+		{"__name__":"up","pattern":"a"}
+	]}`), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 1)
+	f, _ := rsp.Frames[0].FieldByName("pattern")
+	require.Equal(t, "a", f.At(0))
+}
+
+func TestSeriesWithSamplesLabel(t *testing.T) {
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":[
+		{"__name__":"x","samples":"10"},
+		{"__name__":"y"}
+	]}`), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 1)
+
+	frame := rsp.Frames[0]
+	require.Equal(t, 2, frame.Rows())
+	name, _ := frame.FieldByName("__name__")
+	require.Equal(t, "y", name.At(1))
+	samples, _ := frame.FieldByName("samples")
+	require.Equal(t, "10", samples.At(0))
+}
+
+func TestLokiVector(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"level":"error","app":"a\"b","__error__":"JSONParserErr"},"value":[1645029699,"3"]},
+		{"metric":{},"value":[1645029699,"1"]}
+	]}}`
+
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{LokiVector: true})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 2)
+
+	frame := rsp.Frames[0]
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, "a\"b", frame.Fields[1].Labels["app"])
+	require.Equal(t, "__labels", frame.Fields[2].Name)
+	// the labels keep the order and the escapes of the response
+	require.Equal(t, `{"level":"error","app":"a\"b","__error__":"JSONParserErr"}`, string(frame.Fields[2].At(0).(json.RawMessage)))
+	require.Equal(t, map[string]string{"resultType": "vector", "origin": "loki"}, frame.Meta.Custom)
+	require.Equal(t, `{}`, string(rsp.Frames[1].Fields[2].At(0).(json.RawMessage)))
+
+	t.Run("numeric", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{LokiVector: true, Dataplane: true})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames[0].Fields, 2)
+		require.Equal(t, "__labels", rsp.Frames[0].Fields[1].Name)
+	})
+
+	t.Run("matrix is unchanged", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrixResponse(1)), Options{LokiVector: true})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames[0].Fields, 2)
+		require.Equal(t, "matrix", frameResultType(rsp.Frames[0]))
+	})
+
+	t.Run("arrow", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResultArrow(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{LokiVector: true})
+		require.NoError(t, rsp.Error)
+		_, err := rsp.MarshalArrow()
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,164 @@
+package converter
+
+import (
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// longSeries collects the samples of all the series of a matrix, with a column for every label
+type longSeries struct {
+	times   []time.Time
+	values  []float64
+	nulls   []bool
+	columns map[string][]string
+	// the labels in the order they are first seen
+	names []string
+}
+
+func (l *longSeries) add(labels data.Labels, samples *seriesBuffer) {
+	rows := len(l.times)
+	for _, name := range l.names {
+		l.columns[name] = append(l.columns[name], make([]string, samples.len())...)
+	}
+	// the new labels of a series are added sorted, the map has no order
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := labels[name]
+		column, ok := l.columns[name]
+		if !ok {
+			// the rows of the previous series do not have the label
+			column = make([]string, rows+samples.len())
+			l.names = append(l.names, name)
+		}
+		for i := rows; i < len(column); i++ {
+			column[i] = value
+		}
+		l.columns[name] = column
+	}
+	l.times = append(l.times, samples.times...)
+	l.values = append(l.values, samples.values...)
+	l.nulls = append(l.nulls, samples.nulls...)
+}
+
+// frame returns the rows sorted by time, the series stay in the order of the response for the same time
+func (l *longSeries) frame(opt Options) *data.Frame {
+	order := make([]int, len(l.times))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return l.times[order[i]].Before(l.times[order[j]])
+	})
+
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, len(order))
+	timeField.Name = data.TimeSeriesTimeFieldName
+	valueField := data.NewFieldFromFieldType(multiValueFieldType(opt), len(order))
+	valueField.Name = data.TimeSeriesValueFieldName
+	nullable := valueField.Nullable()
+	for row, idx := range order {
+		timeField.Set(row, l.times[idx])
+		switch {
+		case !nullable:
+			valueField.Set(row, l.values[idx])
+		case !l.nulls[idx]:
+			v := l.values[idx]
+			valueField.Set(row, &v)
+		}
+	}
+
+	labelFields := data.NewFrame("")
+	for _, name := range l.names {
+		column := l.columns[name]
+		f := data.NewFieldFromFieldType(data.FieldTypeString, len(order))
+		f.Name = name
+		for row, idx := range order {
+			f.Set(row, column[idx])
+		}
+		labelFields.Fields = append(labelFields.Fields, f)
+	}
+	if opt.SortLabelColumns {
+		sortLabelColumns(labelFields)
+	}
+
+	frame := data.NewFrame("", append([]*data.Field{timeField, valueField}, labelFields.Fields...)...)
+	frame.Meta = &data.FrameMeta{
+		Type:   data.FrameTypeTimeSeriesLong,
+		Custom: resultTypeToCustomMeta("matrix"),
+	}
+	return frame
+}
+
+// readMatrixLong reads a matrix into a single long frame: a time and a value field, and a
+// string field for every label, empty for the series without it. Native histograms and
+// exemplars get their own frames like with multi frames.
+func readMatrixLong(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
+	rsp := backend.DataResponse{}
+	state := newMultiSeriesState(opt)
+	long := &longSeries{columns: map[string][]string{}}
+
+	for iter.ReadArray() {
+		state.samples.reset()
+		labels := data.Labels{}
+		var histogram *histogramInfo
+		var exemplars *data.Frame
+
+		for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+			switch l1Field {
+			case "metric":
+				labels = readLabels(iter, state.interner)
+
+			case "values":
+				for iter.ReadArray() {
+					state.samples.appendTimeValuePair(iter, opt)
+				}
+
+			case "histograms":
+				if histogram == nil {
+					histogram = newHistogramInfo(opt)
+				}
+				for iter.ReadArray() {
+					if err := readHistogram(iter, histogram); err != nil {
+						rsp.Error = err
+					}
+				}
+
+			case "exemplars":
+				exemplars = readExemplars(iter, nil, opt)
+
+			default:
+				iter.Skip()
+				skippedKey(iter, "result", l1Field)
+			}
+		}
+
+		opt.timestamps.check(labels, state.samples.times)
+		long.add(labels, state.samples)
+		if histogram != nil && state.merged != nil {
+			state.merged.add(labels, histogram)
+		} else if histogram != nil {
+			appendFrame(iter, &rsp, newHistogramFrame(opt, labels, histogram), opt)
+		}
+		if exemplars != nil {
+			exemplars.Fields[1].Labels = labels
+			appendFrame(iter, &rsp, exemplars, opt)
+		}
+	}
+
+	// the long frame is first, like the value frames of the series
+	frames := rsp.Frames
+	rsp.Frames = nil
+	appendFrame(iter, &rsp, long.frame(opt), opt)
+	rsp.Frames = append(rsp.Frames, frames...)
+	if state.merged != nil && state.merged.len() > 0 {
+		appendFrame(iter, &rsp, state.merged.frame(), opt)
+	}
+	return rsp
+}
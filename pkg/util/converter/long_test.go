@@ -0,0 +1,51 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrixLongSeries(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"__name__":"up","job":"b"},"values":[[1,"1"],[3,"0"]]},
+		{"metric":{"__name__":"up","job":"a","instance":"x"},"values":[[2,"1"],[3,"NaN"]]}
+	]}}`
+	read := func(opt Options) *data.Frame {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		return rsp.Frames[0]
+	}
+
+	frame := read(Options{MatrixLongSeries: true})
+	require.Equal(t, data.FrameTypeTimeSeriesLong, frame.Meta.Type)
+	require.Equal(t, "matrix", frameResultType(frame))
+
+	names := []string{}
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{"Time", "Value", "__name__", "job", "instance"}, names)
+	require.Equal(t, 4, frame.Rows())
+
+	// sorted by time, in the order of the series for the same time
+	rows := [][]interface{}{}
+	for i := 0; i < frame.Rows(); i++ {
+		rows = append(rows, []interface{}{frame.Fields[0].At(i).(time.Time).Unix(), frame.Fields[3].At(i), frame.Fields[4].At(i)})
+	}
+	require.Equal(t, [][]interface{}{
+		{int64(1), "b", ""},
+		{int64(2), "a", "x"},
+		{int64(3), "b", ""},
+		{int64(3), "a", "x"},
+	}, rows)
+	require.Equal(t, 0.0, frame.Fields[1].At(2))
+
+	frame = read(Options{MatrixLongSeries: true, NonFiniteValues: NonFiniteNull})
+	require.Nil(t, frame.Fields[1].At(3))
+	require.Equal(t, 1.0, *frame.Fields[1].At(1).(*float64))
+}
@@ -0,0 +1,140 @@
+package converter
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// approximate sizes of the values held by fields, on 64 bit platforms
+const (
+	pointerSize      = 8
+	stringHeaderSize = 16
+	sliceHeaderSize  = 24
+	timeSize         = 24
+)
+
+// MemoryStats is the approximate memory used by the conversion of a response, so
+// callers can enforce memory budgets. It is filled when set in the Options.
+type MemoryStats struct {
+	// Bytes of the response body read. The body is counted by ReadAuto, ReadExposition,
+	// the remote read and streaming readers, and when read through Reader.
+	BodyBytes int64
+
+	// Estimated bytes held by the returned or written frames
+	FrameBytes int64
+}
+
+// Reader counts the bytes read from r in BodyBytes, for the iterators passed to
+// ReadPrometheusStyleResult
+func (s *MemoryStats) Reader(r io.Reader) io.Reader {
+	if s == nil {
+		return r
+	}
+	return &countingReader{r: r, stats: s}
+}
+
+func (s *MemoryStats) addBody(n int) {
+	if s != nil {
+		s.BodyBytes += int64(n)
+	}
+}
+
+type countingReader struct {
+	r     io.Reader
+	stats *MemoryStats
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.stats.BodyBytes += int64(n)
+	return n, err
+}
+
+// accountFrames adds the size of the frames to the stats, every frame also gets its
+// own size as "bytes" in its custom meta
+func accountFrames(frames data.Frames, opt Options) {
+	if opt.MemoryStats == nil {
+		return
+	}
+	for _, frame := range frames {
+		size := frameSize(frame)
+		opt.MemoryStats.FrameBytes += size
+
+		if frame.Meta == nil {
+			frame.Meta = &data.FrameMeta{}
+		}
+		switch custom := frame.Meta.Custom.(type) {
+		case nil:
+			frame.Meta.Custom = map[string]string{"bytes": strconv.FormatInt(size, 10)}
+		case map[string]string:
+			custom["bytes"] = strconv.FormatInt(size, 10)
+		case map[string]interface{}:
+			custom["bytes"] = size
+		}
+	}
+}
+
+func frameSize(frame *data.Frame) int64 {
+	size := int64(len(frame.Name) + len(frame.RefID))
+	for _, f := range frame.Fields {
+		size += fieldSize(f)
+	}
+	return size
+}
+
+func fieldSize(f *data.Field) int64 {
+	size := int64(len(f.Name))
+	for k, v := range f.Labels {
+		size += int64(len(k) + len(v))
+	}
+
+	n := f.Len()
+	switch f.Type() {
+	case data.FieldTypeString:
+		for i := 0; i < n; i++ {
+			size += stringHeaderSize + int64(len(f.At(i).(string)))
+		}
+	case data.FieldTypeNullableString:
+		for i := 0; i < n; i++ {
+			size += pointerSize
+			if s, ok := f.At(i).(*string); ok && s != nil {
+				size += stringHeaderSize + int64(len(*s))
+			}
+		}
+	case data.FieldTypeJSON, data.FieldTypeNullableJSON:
+		for i := 0; i < n; i++ {
+			size += sliceHeaderSize
+			if f.Type().Nullable() {
+				size += pointerSize
+			}
+			if raw, ok := f.ConcreteAt(i); ok {
+				size += int64(len(raw.(json.RawMessage)))
+			}
+		}
+	default:
+		elem := valueSize(f.Type().NonNullableType())
+		if f.Type().Nullable() {
+			elem += pointerSize
+		}
+		size += int64(n) * elem
+	}
+	return size
+}
+
+func valueSize(t data.FieldType) int64 {
+	switch t {
+	case data.FieldTypeInt8, data.FieldTypeUint8, data.FieldTypeBool:
+		return 1
+	case data.FieldTypeInt16, data.FieldTypeUint16, data.FieldTypeEnum:
+		return 2
+	case data.FieldTypeInt32, data.FieldTypeUint32, data.FieldTypeFloat32:
+		return 4
+	case data.FieldTypeTime:
+		return timeSize
+	default:
+		return 8
+	}
+}
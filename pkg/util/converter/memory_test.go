@@ -0,0 +1,72 @@
+package converter
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStats(t *testing.T) {
+	t.Run("matrix", func(t *testing.T) {
+		body := matrixResponse(10)
+		stats := &MemoryStats{}
+		iter := jsoniter.Parse(jsoniter.ConfigDefault, stats.Reader(strings.NewReader(body)), 16)
+		rsp := ReadPrometheusStyleResult(iter, Options{MemoryStats: stats})
+		require.NoError(t, rsp.Error)
+		require.Equal(t, int64(len(body)), stats.BodyBytes)
+
+		var total int64
+		for _, frame := range rsp.Frames {
+			size := frameSize(frame)
+			require.Equal(t, frame.Meta.Custom.(map[string]string)["bytes"], strconv.FormatInt(size, 10))
+			total += size
+		}
+		require.Equal(t, total, stats.FrameBytes)
+		// 10 times and 10 float64 values
+		require.GreaterOrEqual(t, stats.FrameBytes, int64(10*timeSize+10*8))
+	})
+
+	t.Run("streams", func(t *testing.T) {
+		stats := &MemoryStats{}
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"a"},"values":[["1645030244810757120","line 1"],["1645030245810757120","line 2"]]}
+		]}}`), Options{MemoryStats: stats})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		// the lines are counted
+		require.Greater(t, stats.FrameBytes, int64(len("line 1")+len("line 2")))
+	})
+
+	t.Run("streamed frames", func(t *testing.T) {
+		body := matrixResponse(3)
+		stats := &MemoryStats{}
+		var written int64
+		err := StreamPrometheusStyleResult(context.Background(), strings.NewReader(body), Options{MemoryStats: stats}, FrameWriterFunc(func(frame *data.Frame) error {
+			written += frameSize(frame)
+			return nil
+		}))
+		require.NoError(t, err)
+		require.Equal(t, int64(len(body)), stats.BodyBytes)
+		require.Equal(t, written, stats.FrameBytes)
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrixResponse(1)), Options{})
+		require.NoError(t, rsp.Error)
+		require.NotContains(t, rsp.Frames[0].Meta.Custom, "bytes")
+	})
+}
+
+func TestFieldSize(t *testing.T) {
+	s := "abc"
+	require.Equal(t, int64(len("v")+2*8), fieldSize(data.NewField("v", nil, []float64{1, 2})))
+	require.Equal(t, int64(2*(8+8)), fieldSize(data.NewField("", nil, []*int64{nil, nil})))
+	require.Equal(t, int64(stringHeaderSize+3), fieldSize(data.NewField("", nil, []string{s})))
+	require.Equal(t, int64(2*pointerSize+stringHeaderSize+3), fieldSize(data.NewField("", nil, []*string{&s, nil})))
+	require.Equal(t, int64(len("job")+len("api")), fieldSize(data.NewField("", data.Labels{"job": "api"}, []float64{})))
+}
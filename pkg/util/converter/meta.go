@@ -0,0 +1,63 @@
+package converter
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// finishFrames applies the options that change the frames once they are read
+func finishFrames(frames data.Frames, opt Options) {
+	applyFieldTypes(frames, opt)
+	applyFrameMeta(frames, opt)
+	applyLocation(frames, opt)
+	applyDataplane(frames, opt)
+	accountFrames(frames, opt)
+}
+
+// applyFrameMeta writes the query details of the options into the meta of every frame.
+// Custom values set by the converter, like the resultType, are never replaced.
+func applyFrameMeta(frames data.Frames, opt Options) {
+	if opt.ExecutedQueryString == "" && opt.Step <= 0 && len(opt.CustomMeta) == 0 {
+		return
+	}
+
+	custom := make(map[string]string, len(opt.CustomMeta)+1)
+	for k, v := range opt.CustomMeta {
+		custom[k] = v
+	}
+	if opt.Step > 0 {
+		custom["step"] = opt.Step.String()
+	}
+
+	for _, frame := range frames {
+		if frame.Meta == nil {
+			frame.Meta = &data.FrameMeta{}
+		}
+		if opt.ExecutedQueryString != "" {
+			frame.Meta.ExecutedQueryString = opt.ExecutedQueryString
+		}
+		if len(custom) == 0 {
+			continue
+		}
+
+		switch existing := frame.Meta.Custom.(type) {
+		case nil:
+			m := make(map[string]string, len(custom))
+			for k, v := range custom {
+				m[k] = v
+			}
+			frame.Meta.Custom = m
+		case map[string]string:
+			for k, v := range custom {
+				if _, ok := existing[k]; !ok {
+					existing[k] = v
+				}
+			}
+		case map[string]interface{}:
+			for k, v := range custom {
+				if _, ok := existing[k]; !ok {
+					existing[k] = v
+				}
+			}
+		}
+	}
+}
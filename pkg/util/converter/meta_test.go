@@ -0,0 +1,57 @@
+package converter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameMeta(t *testing.T) {
+	opt := Options{
+		ExecutedQueryString: "Expr: up\nStep: 15s",
+		Step:                15 * time.Second,
+		CustomMeta:          map[string]string{"refId": "A", "resultType": "ignored"},
+	}
+
+	check := func(t *testing.T, frames data.Frames) {
+		require.NotEmpty(t, frames)
+		for _, frame := range frames {
+			require.Equal(t, "Expr: up\nStep: 15s", frame.Meta.ExecutedQueryString)
+			require.Equal(t, map[string]string{"resultType": "matrix", "refId": "A", "step": "15s"}, frame.Meta.Custom)
+		}
+	}
+
+	t.Run("read", func(t *testing.T) {
+		for _, wide := range []bool{false, true} {
+			o := opt
+			o.MatrixWideSeries = wide
+			// the time range is not set, so the step does not change the rows
+			rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrixResponse(2)), o)
+			require.NoError(t, rsp.Error)
+			check(t, rsp.Frames)
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		frames := data.Frames{}
+		err := StreamPrometheusStyleResult(context.Background(), strings.NewReader(matrixResponse(2)), opt,
+			FrameWriterFunc(func(frame *data.Frame) error {
+				frames = append(frames, frame)
+				return nil
+			}))
+		require.NoError(t, err)
+		check(t, frames)
+	})
+
+	t.Run("nothing to add", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrixResponse(1)), Options{})
+		require.NoError(t, rsp.Error)
+		require.Equal(t, map[string]string{"resultType": "matrix"}, rsp.Frames[0].Meta.Custom)
+		require.Empty(t, rsp.Frames[0].Meta.ExecutedQueryString)
+	})
+}
@@ -0,0 +1,161 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// MetricMetadata is the metadata prometheus reports for a single metric in /api/v1/metadata
+type MetricMetadata struct {
+	Type string `json:"type"`
+	Help string `json:"help"`
+	Unit string `json:"unit"`
+}
+
+// prometheus units (and metric name suffixes) to grafana units
+var promUnitToGrafanaUnit = map[string]string{
+	"seconds":      "s",
+	"milliseconds": "ms",
+	"microseconds": "µs",
+	"nanoseconds":  "ns",
+	"bytes":        "bytes",
+	"ratio":        "percentunit",
+	"celsius":      "celsius",
+	"volts":        "volt",
+	"amperes":      "amp",
+	"joules":       "joule",
+	"hertz":        "hertz",
+}
+
+// The metadata response is an object keyed by metric name:
+// { "metric_name": [ { type, help, unit }, ... ], ... }
+// This is called for every key in "data" that holds an array
+func readMetadataEntry(iter *jsoniter.Iterator, metric string, frame *data.Frame) {
+	for iter.ReadArray() {
+		md := MetricMetadata{}
+		for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+			switch l1Field {
+			case "type":
+				md.Type = iter.ReadString()
+			case "help":
+				md.Help = iter.ReadString()
+			case "unit":
+				md.Unit = iter.ReadString()
+			default:
+				iter.Skip()
+				skippedKey(iter, "metadata", l1Field)
+			}
+		}
+		frame.AppendRow(metric, md.Type, md.Help, md.Unit)
+	}
+}
+
+func newMetadataFrame() *data.Frame {
+	frame := data.NewFrame("",
+		data.NewField("metric", nil, []string{}),
+		data.NewField("type", nil, []string{}),
+		data.NewField("help", nil, []string{}),
+		data.NewField("unit", nil, []string{}),
+	)
+	frame.Meta = &data.FrameMeta{
+		Custom: resultTypeToCustomMeta("metadata"),
+	}
+	return frame
+}
+
+// MetricMetadataFromFrame converts a frame read from /api/v1/metadata into a lookup
+// that can be passed to Options.MetricMetadata when converting query results.
+// When a metric reports multiple metadata entries, the first one is used.
+func MetricMetadataFromFrame(frame *data.Frame) map[string]MetricMetadata {
+	lookup := make(map[string]MetricMetadata)
+	if frame == nil || len(frame.Fields) != 4 {
+		return lookup
+	}
+	for i := 0; i < frame.Rows(); i++ {
+		metric, _ := frame.Fields[0].At(i).(string)
+		if _, ok := lookup[metric]; ok {
+			continue
+		}
+		md := MetricMetadata{}
+		md.Type, _ = frame.Fields[1].At(i).(string)
+		md.Help, _ = frame.Fields[2].At(i).(string)
+		md.Unit, _ = frame.Fields[3].At(i).(string)
+		lookup[metric] = md
+	}
+	return lookup
+}
+
+// attachMetricMetadata sets the unit of value fields for known metrics, and adds the
+// metadata to the custom meta of frames holding a single metric
+func attachMetricMetadata(frames []*data.Frame, lookup map[string]MetricMetadata) {
+	for _, frame := range frames {
+		var frameMetadata *MetricMetadata
+		matched := 0
+		for _, field := range frame.Fields {
+			name, ok := field.Labels["__name__"]
+			if !ok {
+				continue
+			}
+			md, ok := lookupMetricMetadata(lookup, name)
+			if !ok {
+				continue
+			}
+			if unit := grafanaUnit(name, md); unit != "" {
+				if field.Config == nil {
+					field.Config = &data.FieldConfig{}
+				}
+				if field.Config.Unit == "" {
+					field.Config.Unit = unit
+				}
+			}
+			frameMetadata = &md
+			matched++
+		}
+
+		if matched != 1 || frame.Meta == nil {
+			continue
+		}
+		if custom, ok := frame.Meta.Custom.(map[string]string); ok {
+			custom["metricType"] = frameMetadata.Type
+			custom["metricHelp"] = frameMetadata.Help
+			custom["metricUnit"] = frameMetadata.Unit
+		}
+	}
+}
+
+// histogram and summary series are reported under the base metric name
+func lookupMetricMetadata(lookup map[string]MetricMetadata, name string) (MetricMetadata, bool) {
+	if md, ok := lookup[name]; ok {
+		return md, true
+	}
+	for _, suffix := range []string{"_bucket", "_sum", "_count", "_total"} {
+		if strings.HasSuffix(name, suffix) {
+			if md, ok := lookup[strings.TrimSuffix(name, suffix)]; ok {
+				return md, true
+			}
+		}
+	}
+	return MetricMetadata{}, false
+}
+
+func grafanaUnit(name string, md MetricMetadata) string {
+	if (strings.HasSuffix(name, "_count") || strings.HasSuffix(name, "_bucket")) && (md.Type == "histogram" || md.Type == "summary") {
+		return "" // observation counts do not share the unit of the metric
+	}
+	if md.Unit != "" {
+		return promUnitToGrafanaUnit[md.Unit]
+	}
+
+	// the unit is often only encoded in the metric name
+	base := name
+	for _, suffix := range []string{"_bucket", "_sum", "_count", "_total"} {
+		base = strings.TrimSuffix(base, suffix)
+	}
+	idx := strings.LastIndex(base, "_")
+	if idx < 0 {
+		return ""
+	}
+	return promUnitToGrafanaUnit[base[idx+1:]]
+}
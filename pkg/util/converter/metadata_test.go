@@ -0,0 +1,40 @@
+package converter
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricMetadata(t *testing.T) {
+	metadata := `{"status":"success","data":{
+		"http_request_duration_seconds":[{"type":"histogram","help":"Request latency","unit":""}],
+		"process_resident_memory_bytes":[{"type":"gauge","help":"Resident memory","unit":"bytes"}]
+	}}`
+
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, metadata), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 1)
+	require.Equal(t, 2, rsp.Frames[0].Rows())
+
+	lookup := MetricMetadataFromFrame(rsp.Frames[0])
+	require.Equal(t, MetricMetadata{Type: "gauge", Help: "Resident memory", Unit: "bytes"}, lookup["process_resident_memory_bytes"])
+
+	matrix := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"__name__":"process_resident_memory_bytes"},"values":[[1,"10"]]},
+		{"metric":{"__name__":"http_request_duration_seconds_sum"},"values":[[1,"10"]]},
+		{"metric":{"__name__":"http_request_duration_seconds_count"},"values":[[1,"10"]]},
+		{"metric":{"__name__":"unknown_metric_seconds"},"values":[[1,"10"]]}
+	]}}`
+
+	rsp = ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrix), Options{MetricMetadata: lookup})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 4)
+
+	require.Equal(t, "bytes", rsp.Frames[0].Fields[1].Config.Unit)
+	require.Equal(t, "gauge", rsp.Frames[0].Meta.Custom.(map[string]string)["metricType"])
+	require.Equal(t, "s", rsp.Frames[1].Fields[1].Config.Unit)
+	require.Nil(t, rsp.Frames[2].Fields[1].Config)
+	require.Nil(t, rsp.Frames[3].Fields[1].Config)
+}
@@ -0,0 +1,108 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// FrameNaming configures the names of the frames holding a single series
+type FrameNaming string
+
+const (
+	// FrameNameEmpty leaves the name empty, the frontend picks a display name
+	FrameNameEmpty FrameNaming = ""
+	// FrameNameMetric uses the __name__ label
+	FrameNameMetric FrameNaming = "metric"
+	// FrameNameSignature uses the series signature, like up{instance="localhost:9090", job="prometheus"}
+	FrameNameSignature FrameNaming = "signature"
+	// FrameNameTemplate uses Options.FrameNameTemplate, where {{label}} is replaced by the label value
+	FrameNameTemplate FrameNaming = "template"
+	// FrameNameLegacy uses the series name of the frontend conversion, like
+	// up{instance="localhost:9090", job="prometheus"}, for the series with a __name__ label.
+	// The value fields get the name as display name too, so the legends do not depend on
+	// where the response was converted.
+	FrameNameLegacy FrameNaming = "legacy"
+)
+
+var frameNameTemplateRegexp = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
+
+func frameName(opt Options, labels data.Labels) string {
+	switch opt.FrameNaming {
+	case FrameNameMetric:
+		return labels["__name__"]
+	case FrameNameSignature:
+		return seriesSignature(labels)
+	case FrameNameTemplate:
+		return frameNameTemplateRegexp.ReplaceAllStringFunc(opt.FrameNameTemplate, func(in string) string {
+			name := frameNameTemplateRegexp.FindStringSubmatch(in)[1]
+			return labels[name]
+		})
+	case FrameNameLegacy:
+		if _, ok := labels["__name__"]; !ok {
+			return ""
+		}
+		return legacySeriesName(labels)
+	default:
+		return ""
+	}
+}
+
+// setSeriesDisplayName sets the legacy series name as display name of the value field
+func setSeriesDisplayName(opt Options, field *data.Field) {
+	if opt.FrameNaming != FrameNameLegacy {
+		return
+	}
+	name := frameName(opt, field.Labels)
+	if name == "" {
+		return
+	}
+	if field.Config == nil {
+		field.Config = &data.FieldConfig{}
+	}
+	field.Config.DisplayNameFromDS = name
+}
+
+// legacySeriesName formats the labels like model.Metric.String of prometheus, the
+// metric name alone when there are no other labels
+func legacySeriesName(labels data.Labels) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		if k != "__name__" {
+			pairs = append(pairs, fmt.Sprintf("%s=%q", k, v))
+		}
+	}
+	if len(pairs) == 0 {
+		return labels["__name__"]
+	}
+	sort.Strings(pairs)
+	return labels["__name__"] + "{" + strings.Join(pairs, ", ") + "}"
+}
+
+func seriesSignature(labels data.Labels) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		if k != "__name__" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	sb := strings.Builder{}
+	sb.WriteString(labels["__name__"])
+	sb.WriteString("{")
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(k)
+		sb.WriteString(`="`)
+		sb.WriteString(labels[k])
+		sb.WriteString(`"`)
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
@@ -0,0 +1,67 @@
+package converter
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameNaming(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"__name__":"up","job":"prometheus","instance":"localhost:9090"},"value":[1641889530,"1"]},
+		{"metric":{"job":"node"},"value":[1641889530,"0"]}
+	]}}`
+
+	for naming, expected := range map[FrameNaming][]string{
+		FrameNameEmpty:     {"", ""},
+		FrameNameMetric:    {"up", ""},
+		FrameNameSignature: {`up{instance="localhost:9090", job="prometheus"}`, `{job="node"}`},
+		FrameNameTemplate:  {"prometheus - localhost:9090", "node - "},
+		FrameNameLegacy:    {`up{instance="localhost:9090", job="prometheus"}`, ""},
+	} {
+		opt := Options{FrameNaming: naming, FrameNameTemplate: "{{job}} - {{ instance }}"}
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 2)
+		for i, frame := range rsp.Frames {
+			require.Equal(t, expected[i], frame.Name, naming)
+		}
+	}
+}
+
+func TestLegacyDisplayName(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"__name__":"up","job":"say \"hi\""},"values":[[1641889530,"1"]]},
+		{"metric":{"__name__":"up"},"values":[[1641889530,"1"]]},
+		{"metric":{"job":"node"},"values":[[1641889530,"0"]]}
+	]}}`
+	expected := []string{`up{job="say \"hi\""}`, "up", ""}
+
+	t.Run("multi", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{FrameNaming: FrameNameLegacy})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 3)
+		for i, frame := range rsp.Frames {
+			require.Equal(t, expected[i], frame.Name)
+			if expected[i] == "" {
+				require.Nil(t, frame.Fields[1].Config)
+				continue
+			}
+			require.Equal(t, expected[i], frame.Fields[1].Config.DisplayNameFromDS)
+		}
+	})
+
+	t.Run("wide", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{FrameNaming: FrameNameLegacy, MatrixWideSeries: true})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		names := map[string]bool{}
+		for _, f := range rsp.Frames[0].Fields[1:] {
+			if f.Config != nil {
+				names[f.Config.DisplayNameFromDS] = true
+			}
+		}
+		require.Equal(t, map[string]bool{expected[0]: true, expected[1]: true}, names)
+	})
+}
@@ -0,0 +1,26 @@
+package converter
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// toNumericFrame drops the time field of a vector frame, following the numeric formats
+// of the dataplane contract. Wide frames with samples at different times can not be
+// represented as a single row, so they stay time series.
+func toNumericFrame(frame *data.Frame) {
+	if len(frame.Fields) == 0 || frame.Fields[0].Type() != data.FieldTypeTime {
+		return
+	}
+	switch frame.Meta.Type {
+	case data.FrameTypeTimeSeriesMulti:
+		frame.Meta.Type = data.FrameTypeNumericMulti
+	case data.FrameTypeTimeSeriesWide:
+		if frame.Rows() > 1 {
+			return
+		}
+		frame.Meta.Type = data.FrameTypeNumericWide
+	default:
+		return
+	}
+	frame.Fields = frame.Fields[1:]
+}
@@ -0,0 +1,57 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumericVector(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"job":"a"},"value":[1645029699,"1"]},
+		{"metric":{"job":"b"},"value":[1645029699,"2"]}
+	]}}`
+	read := func(opt Options) data.Frames {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		require.NoError(t, rsp.Error)
+		return rsp.Frames
+	}
+
+	t.Run("multi", func(t *testing.T) {
+		frames := read(Options{NumericVector: true})
+		require.Len(t, frames, 2)
+		for i, frame := range frames {
+			require.Equal(t, data.FrameTypeNumericMulti, frame.Meta.Type)
+			require.Len(t, frame.Fields, 1)
+			require.Equal(t, 1, frame.Rows())
+			require.Equal(t, data.Labels{"job": []string{"a", "b"}[i]}, frame.Fields[0].Labels)
+		}
+		v, _ := frames[1].Fields[0].ConcreteAt(0)
+		require.Equal(t, 2.0, v)
+	})
+
+	t.Run("wide", func(t *testing.T) {
+		frames := read(Options{NumericVector: true, VectorWideSeries: true})
+		require.Len(t, frames, 1)
+		require.Equal(t, data.FrameTypeNumericWide, frames[0].Meta.Type)
+		require.Len(t, frames[0].Fields, 2)
+		require.Equal(t, 1, frames[0].Rows())
+	})
+
+	t.Run("wide with different times", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"job":"a"},"value":[1645029699,"1"]},
+			{"metric":{"job":"b"},"value":[1645029700,"2"]}
+		]}}`), Options{NumericVector: true, VectorWideSeries: true})
+		require.NoError(t, rsp.Error)
+		require.Equal(t, data.FrameTypeTimeSeriesWide, rsp.Frames[0].Meta.Type)
+	})
+
+	t.Run("matrix is unchanged", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrixResponse(1)), Options{NumericVector: true})
+		require.NoError(t, rsp.Error)
+		require.Equal(t, data.FrameTypeTimeSeriesMulti, rsp.Frames[0].Meta.Type)
+	})
+}
@@ -0,0 +1,106 @@
+package converter
+
+import (
+	"sync"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// the series handed to a worker at once
+const parallelBatchSize = 32
+
+// parallelSeries reports if the series of multi frames are parsed by ParseWorkers goroutines.
+// The options that count across series, or write the frames as they are read, need the
+// order of the response and keep the single reader.
+func parallelSeries(opt Options) bool {
+	return opt.ParseWorkers > 1 && opt.rowLimit == nil && opt.sink == nil && opt.arrow == nil && !opt.MergeHistograms
+}
+
+// seriesBatch holds the raw JSON of some series of the result array, and what was read from it
+type seriesBatch struct {
+	series     [][]byte
+	rsp        backend.DataResponse
+	malformed  *malformedSamples
+	timestamps *timestampIssues
+	unknown    *unknownKeys
+}
+
+// readMatrixOrVectorParallel splits the result array into the raw series, which is much cheaper
+// than reading them, and reads batches of series concurrently. The frames, errors and notices
+// are merged in the order of the response, so they match the ones of the single reader.
+func readMatrixOrVectorParallel(iter *jsoniter.Iterator, resultType string, opt Options) backend.DataResponse {
+	jobs := make(chan *seriesBatch)
+	wg := sync.WaitGroup{}
+	for i := 0; i < opt.ParseWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range jobs {
+				readSeriesBatch(batch, resultType, opt)
+			}
+		}()
+	}
+
+	batches := []*seriesBatch{}
+	batch := &seriesBatch{}
+	for iter.ReadArray() {
+		batch.series = append(batch.series, iter.SkipAndReturnBytes())
+		if len(batch.series) == parallelBatchSize {
+			batches = append(batches, batch)
+			jobs <- batch
+			batch = &seriesBatch{}
+		}
+	}
+	if len(batch.series) > 0 {
+		batches = append(batches, batch)
+		jobs <- batch
+	}
+	close(jobs)
+	wg.Wait()
+
+	rsp := backend.DataResponse{}
+	for _, b := range batches {
+		rsp.Frames = append(rsp.Frames, b.rsp.Frames...)
+		if b.rsp.Error != nil && rsp.Error == nil {
+			rsp.Error = b.rsp.Error
+		}
+		if opt.malformed != nil && b.malformed.count > 0 {
+			if opt.malformed.first == nil {
+				opt.malformed.first = b.malformed.first
+			}
+			opt.malformed.count += b.malformed.count
+		}
+		opt.timestamps.merge(b.timestamps)
+		if opt.unknownKeys != nil {
+			for _, key := range b.unknown.keys {
+				opt.unknownKeys.add(key)
+			}
+		}
+	}
+	return rsp
+}
+
+// readSeriesBatch reads the series of a batch with their own counters, merged
+// into the ones of the options once all the batches are read
+func readSeriesBatch(batch *seriesBatch, resultType string, opt Options) {
+	batch.malformed = &malformedSamples{}
+	batch.unknown = &unknownKeys{}
+	opt.malformed = batch.malformed
+	if opt.timestamps != nil {
+		batch.timestamps = &timestampIssues{}
+		opt.timestamps = batch.timestamps
+	}
+	opt.unknownKeys = batch.unknown
+	state := newMultiSeriesState(opt)
+
+	for _, raw := range batch.series {
+		iter := jsoniter.ConfigDefault.BorrowIterator(raw)
+		iter.Attachment = batch.unknown
+		readMultiSeries(iter, resultType, opt, state, &batch.rsp)
+		if iter.Error != nil && batch.rsp.Error == nil {
+			batch.rsp.Error = iter.Error
+		}
+		jsoniter.ConfigDefault.ReturnIterator(iter)
+	}
+}
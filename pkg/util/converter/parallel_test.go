@@ -0,0 +1,84 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelSeries(t *testing.T) {
+	requireSameFrames := func(t *testing.T, body string, opt Options) {
+		t.Helper()
+		expected := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		opt.ParseWorkers = 4
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		require.Equal(t, expected.Error, rsp.Error)
+		require.Len(t, rsp.Frames, len(expected.Frames))
+		for i := range expected.Frames {
+			want, err := data.FrameToJSON(expected.Frames[i], data.IncludeAll)
+			require.NoError(t, err)
+			got, err := data.FrameToJSON(rsp.Frames[i], data.IncludeAll)
+			require.NoError(t, err)
+			require.JSONEq(t, string(want), string(got))
+		}
+	}
+
+	t.Run("many series", func(t *testing.T) {
+		requireSameFrames(t, matrixResponse(1000), Options{})
+	})
+
+	t.Run("histograms and exemplars", func(t *testing.T) {
+		for _, name := range []string{"prom-exemplars-a", "prom-matrix-histogram-partitioned", "prom-vector"} {
+			body, err := os.ReadFile("testdata/" + name + ".json")
+			require.NoError(t, err)
+			requireSameFrames(t, string(body), Options{})
+		}
+	})
+
+	t.Run("malformed samples and unknown keys", func(t *testing.T) {
+		body := `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"i":"0"},"values":[[1,"1"],[2,"x"]]},
+			{"metric":{"i":"1"},"values":[[1,"1"]],"extra":1},
+			{"metric":{"i":"2"},"values":[[1,"y"],[2,"2"]],"other":1}
+		]}}`
+		requireSameFrames(t, body, Options{Strict: StrictNotice})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		body := `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"i":"0"},"histograms":[[1,{"count":"x"}]]}
+		]}}`
+		requireSameFrames(t, body, Options{})
+	})
+}
+
+// the workers only pay off with more than one core
+func BenchmarkParallelSeries(b *testing.B) {
+	result := make([]string, 0, 500)
+	for i := 0; i < 500; i++ {
+		values := make([]string, 0, 200)
+		for j := 0; j < 200; j++ {
+			values = append(values, fmt.Sprintf(`[%d,"%d.5"]`, 1641889530+j*15, j))
+		}
+		result = append(result, fmt.Sprintf(`{"metric":{"i":"%d"},"values":[%s]}`, i, strings.Join(values, ",")))
+	}
+	body := []byte(`{"status":"success","data":{"resultType":"matrix","result":[` + strings.Join(result, ",") + `]}}`)
+
+	for _, workers := range []int{1, runtime.NumCPU()} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				rsp := ReadPrometheusStyleResult(jsoniter.ParseBytes(jsoniter.ConfigDefault, body), Options{ParseWorkers: workers})
+				if rsp.Error != nil {
+					b.Fatal(rsp.Error)
+				}
+			}
+		})
+	}
+}
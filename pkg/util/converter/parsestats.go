@@ -0,0 +1,93 @@
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// parseStats is what the conversion of a response did, written into the frame meta with ParseStats
+type parseStats struct {
+	duration time.Duration
+	series   int
+	samples  int
+	// the samples that were malformed or beyond MaxRows
+	dropped int
+}
+
+// readParseStats counts the series and the samples of the matrix and vector frames
+func readParseStats(frames data.Frames, start time.Time, opt Options) parseStats {
+	stats := parseStats{}
+	for _, frame := range frames {
+		if !isLimitedFrame(frame) {
+			continue
+		}
+		// the samples of the frames read by ReadPrometheusStyleResultArrow are in their columns
+		if opt.arrow != nil {
+			if cols, ok := opt.arrow.columns[frame]; ok {
+				stats.series++
+				stats.samples += cols[1].Len() - cols[1].NullN()
+				continue
+			}
+		}
+		timeIdx := timeFieldIndex(frame)
+		for i, f := range frame.Fields {
+			if i == timeIdx || !f.Type().Numeric() {
+				continue
+			}
+			stats.series++
+			for row := 0; row < f.Len(); row++ {
+				if _, ok := f.ConcreteAt(row); ok {
+					stats.samples++
+				}
+			}
+		}
+	}
+	if opt.malformed != nil {
+		stats.dropped += opt.malformed.count
+	}
+	if opt.rowLimit != nil {
+		stats.dropped += opt.rowLimit.dropped
+	}
+	stats.duration = time.Since(start)
+	return stats
+}
+
+// addParseStats writes the stats into the custom meta of every frame, and adds a notice
+// with the number of samples dropped when there are some
+func addParseStats(frames data.Frames, stats parseStats) {
+	for _, frame := range frames {
+		if frame.Meta == nil {
+			frame.Meta = &data.FrameMeta{}
+		}
+		switch custom := frame.Meta.Custom.(type) {
+		case nil:
+			frame.Meta.Custom = map[string]string{
+				"parseDuration":  stats.duration.String(),
+				"series":         strconv.Itoa(stats.series),
+				"samples":        strconv.Itoa(stats.samples),
+				"droppedSamples": strconv.Itoa(stats.dropped),
+			}
+		case map[string]string:
+			custom["parseDuration"] = stats.duration.String()
+			custom["series"] = strconv.Itoa(stats.series)
+			custom["samples"] = strconv.Itoa(stats.samples)
+			custom["droppedSamples"] = strconv.Itoa(stats.dropped)
+		case map[string]interface{}:
+			custom["parseDuration"] = stats.duration.String()
+			custom["series"] = stats.series
+			custom["samples"] = stats.samples
+			custom["droppedSamples"] = stats.dropped
+		}
+
+		if stats.dropped > 0 {
+			frame.AppendNotices(data.Notice{
+				Severity: data.NoticeSeverityInfo,
+				Text:     fmt.Sprintf("Dropped %d of %d samples while parsing", stats.dropped, stats.samples+stats.dropped),
+				Inspect:  data.InspectTypeMeta,
+			})
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStats(t *testing.T) {
+	const body = `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"job":"a"},"values":[[1,"1"],[2,3],[3,"3"]]},
+		{"metric":{"job":"b"},"values":[[1,"4"],[2,"5"]]}
+	]}}`
+	read := func(opt Options) data.Frames {
+		opt.ParseStats = true
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		require.NoError(t, rsp.Error)
+		return rsp.Frames
+	}
+
+	t.Run("multi", func(t *testing.T) {
+		frames := read(Options{})
+		require.Len(t, frames, 2)
+		for _, frame := range frames {
+			custom := frame.Meta.Custom.(map[string]string)
+			require.Equal(t, "matrix", custom["resultType"])
+			require.Equal(t, "2", custom["series"])
+			require.Equal(t, "4", custom["samples"])
+			require.Equal(t, "1", custom["droppedSamples"])
+			_, err := time.ParseDuration(custom["parseDuration"])
+			require.NoError(t, err)
+
+			notice := frame.Meta.Notices[len(frame.Meta.Notices)-1]
+			require.Equal(t, "Dropped 1 of 5 samples while parsing", notice.Text)
+			require.Equal(t, data.NoticeSeverityInfo, notice.Severity)
+		}
+	})
+
+	t.Run("wide", func(t *testing.T) {
+		// the missing samples of a series are null rows, they are not counted
+		frames := read(Options{MatrixWideSeries: true})
+		require.Len(t, frames, 1)
+		custom := frames[0].Meta.Custom.(map[string]string)
+		require.Equal(t, "2", custom["series"])
+		require.Equal(t, "4", custom["samples"])
+	})
+
+	t.Run("max rows", func(t *testing.T) {
+		frames := read(Options{MaxRows: 2})
+		custom := frames[0].Meta.Custom.(map[string]string)
+		require.Equal(t, "2", custom["samples"])
+		require.Equal(t, "3", custom["droppedSamples"])
+	})
+
+	t.Run("arrow", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResultArrow(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{ParseStats: true})
+		require.NoError(t, rsp.Error)
+		custom := rsp.Frames[0].Meta.Custom.(map[string]string)
+		require.Equal(t, "2", custom["series"])
+		require.Equal(t, "4", custom["samples"])
+	})
+
+	t.Run("no notice without dropped samples", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrixResponse(3)), Options{ParseStats: true})
+		require.NoError(t, rsp.Error)
+		require.Empty(t, rsp.Frames[0].Meta.Notices)
+		require.Equal(t, "0", rsp.Frames[0].Meta.Custom.(map[string]string)["droppedSamples"])
+	})
+}
@@ -0,0 +1,238 @@
+package converter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// A bar of a flamebearer level is 4 numbers: the offset to the end of the previous bar,
+// the total value, the self value and the index in the names
+const flamebearerBarSize = 4
+
+// the units of pyroscope and the ones of the panels
+var profileUnits = map[string]string{
+	"samples":          "short",
+	"objects":          "short",
+	"goroutines":       "short",
+	"lock_samples":     "short",
+	"bytes":            "bytes",
+	"lock_nanoseconds": "ns",
+}
+
+type profileBar struct {
+	start int64
+	total int64
+	self  int64
+	name  int64
+}
+
+type profileResponse struct {
+	names    []string
+	levels   [][]int64
+	format   string
+	units    string
+	timeline *data.Frame
+}
+
+// ReadPyroscopeProfile reads a profile from the pyroscope /render endpoint in JSON
+// { "flamebearer": { names, levels, numTicks }, "metadata": { format, units }, "timeline": { ... } }
+// The flamegraph is returned in the nested set frame of the flame graph panel, where the bars are
+// sorted depth first and have a level. The timeline is returned as a second, time series frame.
+func ReadPyroscopeProfile(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
+	attachUnknownKeys(iter, &opt)
+	p := &profileResponse{format: "single"}
+
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "flamebearer":
+			p.readFlamebearer(iter)
+		case "metadata":
+			for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+				switch k {
+				case "format":
+					p.format = iter.ReadString()
+				case "units":
+					p.units = iter.ReadString()
+				case "spyName", "sampleRate", "name":
+					iter.Skip()
+				default:
+					iter.Skip()
+					skippedKey(iter, "metadata", k)
+				}
+			}
+		case "timeline":
+			p.timeline = readProfileTimeline(iter)
+		default:
+			iter.Skip()
+			skippedKey(iter, "profile", l1Field)
+		}
+	}
+
+	rsp := backend.DataResponse{}
+	if iter.Error != nil {
+		rsp.Error = iter.Error
+		return rsp
+	}
+	if p.format != "single" {
+		rsp.Error = fmt.Errorf("unsupported flamebearer format: %s", p.format)
+		return rsp
+	}
+
+	frame, err := p.nestedSetFrame()
+	if err != nil {
+		rsp.Error = err
+		return rsp
+	}
+	rsp.Frames = append(rsp.Frames, frame)
+	if p.timeline != nil {
+		rsp.Frames = append(rsp.Frames, p.timeline)
+	}
+
+	// the value and self fields, and the timeline values
+	if unit, ok := profileUnits[p.units]; ok {
+		frame.Fields[1].Config = &data.FieldConfig{Unit: unit}
+		frame.Fields[2].Config = &data.FieldConfig{Unit: unit}
+		if p.timeline != nil {
+			p.timeline.Fields[1].Config = &data.FieldConfig{Unit: unit}
+		}
+	}
+
+	if err := opt.unknownKeys.report(rsp.Frames, opt.Strict); err != nil {
+		rsp.Error = err
+	}
+	finishFrames(rsp.Frames, opt)
+	return rsp
+}
+
+func (p *profileResponse) readFlamebearer(iter *jsoniter.Iterator) {
+	for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+		switch k {
+		case "names":
+			for iter.ReadArray() {
+				p.names = append(p.names, iter.ReadString())
+			}
+		case "levels":
+			for iter.ReadArray() {
+				level := []int64{}
+				for iter.ReadArray() {
+					level = append(level, iter.ReadInt64())
+				}
+				p.levels = append(p.levels, level)
+			}
+		// can be computed from the levels
+		case "numTicks", "maxSelf":
+			iter.Skip()
+		default:
+			iter.Skip()
+			skippedKey(iter, "flamebearer", k)
+		}
+	}
+}
+
+// readProfileTimeline reads the values of the profile over time
+// { "startTime": 1672531200, "samples": [ 10, 12, ... ], "durationDelta": 10 }
+func readProfileTimeline(iter *jsoniter.Iterator) *data.Frame {
+	var (
+		start, step int64
+		samples     []float64
+	)
+	for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+		switch k {
+		case "startTime":
+			start = iter.ReadInt64()
+		case "durationDelta":
+			step = iter.ReadInt64()
+		case "samples":
+			for iter.ReadArray() {
+				samples = append(samples, iter.ReadFloat64())
+			}
+		default:
+			iter.Skip()
+			skippedKey(iter, "timeline", k)
+		}
+	}
+
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, len(samples))
+	timeField.Name = data.TimeSeriesTimeFieldName
+	valueField := data.NewField(data.TimeSeriesValueFieldName, nil, samples)
+	for i := range samples {
+		timeField.Set(i, time.Unix(start+int64(i)*step, 0).UTC())
+	}
+
+	frame := data.NewFrame("", timeField, valueField)
+	frame.Meta = &data.FrameMeta{
+		Type:   data.FrameTypeTimeSeriesMulti,
+		Custom: resultTypeToCustomMeta("timeline"),
+	}
+	return frame
+}
+
+// nestedSetFrame walks the bars depth first. The bars of a level are sorted by their start,
+// so the children of a bar are the next bars of the level below that start before it ends.
+func (p *profileResponse) nestedSetFrame() (*data.Frame, error) {
+	levels := make([][]profileBar, len(p.levels))
+	for i, values := range p.levels {
+		if len(values)%flamebearerBarSize != 0 {
+			return nil, fmt.Errorf("invalid flamebearer level %d, %d values are not a list of bars", i, len(values))
+		}
+		bars := make([]profileBar, 0, len(values)/flamebearerBarSize)
+		end := int64(0)
+		for j := 0; j < len(values); j += flamebearerBarSize {
+			bar := profileBar{
+				start: end + values[j],
+				total: values[j+1],
+				self:  values[j+2],
+				name:  values[j+3],
+			}
+			if bar.name < 0 || bar.name >= int64(len(p.names)) {
+				return nil, fmt.Errorf("invalid flamebearer name index: %d", bar.name)
+			}
+			end = bar.start + bar.total
+			bars = append(bars, bar)
+		}
+		levels[i] = bars
+	}
+
+	levelField := data.NewField("level", nil, []int64{})
+	valueField := data.NewField("value", nil, []int64{})
+	selfField := data.NewField("self", nil, []int64{})
+	labelField := data.NewField("label", nil, []string{})
+
+	next := make([]int, len(levels))
+	var walk func(level int, bar profileBar)
+	walk = func(level int, bar profileBar) {
+		levelField.Append(int64(level))
+		valueField.Append(bar.total)
+		selfField.Append(bar.self)
+		labelField.Append(p.names[bar.name])
+
+		child := level + 1
+		if child >= len(levels) {
+			return
+		}
+		for next[child] < len(levels[child]) {
+			c := levels[child][next[child]]
+			if c.start >= bar.start+bar.total {
+				break
+			}
+			next[child]++
+			walk(child, c)
+		}
+	}
+	if len(levels) > 0 {
+		for _, bar := range levels[0] {
+			walk(0, bar)
+		}
+	}
+
+	frame := data.NewFrame("", levelField, valueField, selfField, labelField)
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: "flamegraph",
+		Custom:                 resultTypeToCustomMeta("profile"),
+	}
+	return frame, nil
+}
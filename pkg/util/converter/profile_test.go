@@ -0,0 +1,85 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPyroscopeProfile(t *testing.T) {
+	// total
+	// ├── main (60)
+	// │   ├── work (40)
+	// │   └── sleep (10)
+	// └── gc (30)
+	//     └── work (30)
+	const body = `{
+		"flamebearer":{
+			"names":["total","main","gc","work","sleep"],
+			"levels":[
+				[0,100,10,0],
+				[0,60,10,1, 0,30,0,2],
+				[0,40,40,3, 0,10,10,4, 10,30,30,3]
+			],
+			"numTicks":100,"maxSelf":40
+		},
+		"metadata":{"format":"single","sampleRate":100,"spyName":"gospy","units":"samples"},
+		"timeline":{"startTime":1672531200,"samples":[40,60],"durationDelta":10}
+	}`
+
+	rsp := ReadPyroscopeProfile(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{Strict: StrictError})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 2)
+
+	frame := rsp.Frames[0]
+	require.Equal(t, "flamegraph", string(frame.Meta.PreferredVisualization))
+	require.Equal(t, 6, frame.Rows())
+
+	type bar struct {
+		level int64
+		value int64
+		self  int64
+		label string
+	}
+	bars := make([]bar, frame.Rows())
+	for i := range bars {
+		bars[i] = bar{
+			level: frame.Fields[0].At(i).(int64),
+			value: frame.Fields[1].At(i).(int64),
+			self:  frame.Fields[2].At(i).(int64),
+			label: frame.Fields[3].At(i).(string),
+		}
+	}
+	require.Equal(t, []bar{
+		{0, 100, 10, "total"},
+		{1, 60, 10, "main"},
+		{2, 40, 40, "work"},
+		{2, 10, 10, "sleep"},
+		{1, 30, 0, "gc"},
+		{2, 30, 30, "work"},
+	}, bars)
+	require.Equal(t, "short", frame.Fields[1].Config.Unit)
+
+	timeline := rsp.Frames[1]
+	require.Equal(t, 2, timeline.Rows())
+	require.Equal(t, time.Unix(1672531210, 0).UTC(), timeline.Fields[0].At(1))
+	require.Equal(t, 60.0, timeline.Fields[1].At(1))
+
+	t.Run("diff profiles are not supported", func(t *testing.T) {
+		rsp := ReadPyroscopeProfile(jsoniter.ParseString(jsoniter.ConfigDefault, `{"flamebearer":{"names":[],"levels":[]},"metadata":{"format":"double"}}`), Options{})
+		require.EqualError(t, rsp.Error, "unsupported flamebearer format: double")
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		rsp := ReadPyroscopeProfile(jsoniter.ParseString(jsoniter.ConfigDefault, `{"flamebearer":{"names":["total"],"levels":[[0,1,1,3]]}}`), Options{})
+		require.EqualError(t, rsp.Error, "invalid flamebearer name index: 3")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		rsp := ReadPyroscopeProfile(jsoniter.ParseString(jsoniter.ConfigDefault, `{"flamebearer":{"names":[],"levels":[]}}`), Options{})
+		require.NoError(t, rsp.Error)
+		require.Equal(t, 0, rsp.Frames[0].Rows())
+	})
+}
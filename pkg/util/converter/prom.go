@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/apache/arrow/go/arrow/array"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/experimental"
@@ -21,15 +23,198 @@ func logf(format string, a ...interface{}) {
 type Options struct {
 	MatrixWideSeries bool
 	VectorWideSeries bool
+
+	// When set, matrix results are returned as a single long frame sorted by time, with a
+	// string field for every label, for the SQL like transformations. Not used with
+	// MatrixWideSeries, and MaxSeries does not apply to it.
+	MatrixLongSeries bool
+
+	// When set, log frames get a "level" field detected from labels, JSON fields or line prefixes
+	LevelDetection *LevelDetection
+
+	// When set, value fields of known metrics get a unit and single metric frames
+	// get the metric metadata in their custom meta. See MetricMetadataFromFrame.
+	MetricMetadata map[string]MetricMetadata
+
+	// When set, sample timestamps keep all the decimals sent by the server instead of being
+	// rounded to milliseconds. Log line timestamps are always kept in nanoseconds.
+	NanosecondTimestamps bool
+
+	// When set, adjacent native histogram buckets are merged until every timestamp
+	// has at most this many buckets. Counts are preserved.
+	MaxHistogramBuckets int
+
+	// When set, the native histograms of all the series are returned as a single
+	// heatmap-cells-sparse frame with a "series" field, instead of a frame per series
+	MergeHistograms bool
+
+	// Structured metadata of log entries is added as a single JSON field by default,
+	// when set every key gets its own string field instead
+	ExplodeStructuredMetadata bool
+
+	// When set, log frames get a typed field for every top level key of JSON log lines,
+	// next to the raw Line field. At most MaxJSONLineFields keys are added, 50 by default.
+	ParseJSONLines    bool
+	MaxJSONLineFields int
+
+	// When set, the TS field of log frames holds the nanoseconds as int64 instead of the
+	// string sent by loki, which takes half the memory and sorts numerically
+	Int64LogTimestamps bool
+
+	// Drops duplicated log lines across all the streams of the response
+	Dedup DedupStrategy
+
+	// When set, the columns of label frames (/api/v1/series) are sorted by name, with
+	// __name__, job and instance first, instead of the order they are first seen in
+	SortLabelColumns bool
+
+	// When set, vector results are returned as numeric frames, without a time field,
+	// for the stat and gauge panels and server side expressions
+	NumericVector bool
+
+	// When set, the vector or matrix is read as a /loki/api/v1/index/volume(_range) response
+	// and the value fields get the bytes unit
+	IndexVolume bool
+
+	// When set, values and log lines beyond this many rows are dropped and the
+	// frames get a notice that the result was truncated
+	MaxRows  int
+	rowLimit *rowLimit
+
+	// How NaN and ±Inf sample values are converted, they are kept by default. NonFiniteMapping
+	// overrides it for some of the values, for example to keep ±Inf and turn the stale markers
+	// of remote read into nulls that break the lines.
+	NonFiniteValues  NonFiniteValues
+	NonFiniteMapping map[NonFiniteValue]NonFiniteValues
+
+	// When set, the series of matrix and vector results beyond MaxSeries, or with a new value of a
+	// label that has MaxLabelValues already, are dropped or summed into an "Other" series, and the
+	// frames get a notice. The series are taken in the order of the response. Streamed frames are
+	// written before the series are counted, so the limits do not apply to them.
+	MaxSeries      int
+	MaxLabelValues int
+	SeriesLimit    SeriesLimitMode
+
+	// When above 1, the series of matrix and vector results read into multi frames are parsed
+	// by this many goroutines, for responses large enough that a single core is the bottleneck.
+	// The frames are in the order of the response. Not used with MaxRows, MergeHistograms or
+	// when the frames are streamed.
+	ParseWorkers int
+
+	// When set, samples of matrix results repeating the previous value of the series are
+	// dropped (null in wide frames). The last sample of every series is kept, so state
+	// timelines still end at the right time.
+	DropRepeatedValues bool
+
+	// When set with Step, matrix results get a null row for every step missing between two
+	// samples, so staleness gaps break the lines without the panels guessing the interval.
+	// The value fields of multi frames with gaps become nullable.
+	FillGaps bool
+
+	// When set, the value fields of multi frames are nullable like in wide frames
+	NullableMultiValues bool
+
+	// The step and time range of the query. When all are set, wide frames of matrix
+	// results preallocate a row for every step instead of growing for every sample.
+	Step  time.Duration
+	Start time.Time
+	End   time.Time
+	grid  *timeGrid
+
+	// How frames holding a single series are named, they have no name by default
+	FrameNaming       FrameNaming
+	FrameNameTemplate string
+
+	// The name of the frames of scalar and string results and the labels of their value
+	// field, so a constant like the 1 of a health query gets a legend instead of "Value"
+	ScalarFrameName string
+	ScalarLabels    data.Labels
+
+	// Written into the meta of every frame, so the query inspector shows the query that ran.
+	// Step is added to the custom meta as "step" when set, next to the CustomMeta values.
+	ExecutedQueryString string
+	CustomMeta          map[string]string
+
+	// Types of the string fields with these names, like label columns, for example
+	// data.FieldTypeNullableInt64 to read a "status_code" label as a number.
+	// Int64, float64, bool and time types are supported.
+	FieldTypes map[string]data.FieldType
+
+	// The location of the time fields, UTC when not set
+	Location *time.Location
+
+	// Data links added to the exemplar label fields holding trace IDs, so an
+	// exemplar opens its trace without enrichment in the frontend
+	ExemplarTraceLinks []ExemplarTraceLink
+
+	// When set, the exemplars of the series of a response are attached to the value fields of
+	// the series instead of returned in exemplar frames. They are in the "exemplars" custom config
+	// of the fields, with their time in milliseconds, value, labels and the ExemplarTraceLinks
+	// for their trace ID. Exemplars of series that are not in the response keep their frames.
+	ExemplarsOnSeries bool
+
+	// When set, the vectors read into multi frames are loki instant metric queries. Every series
+	// gets a "__labels" JSON field after its value, holding its labels as sent by loki like the
+	// streams do, so the stream selectors and the labels added by parsers, like __error__, are
+	// kept as they are. The frames have "origin": "loki" in their custom meta, which is checked
+	// by the logs volume histogram.
+	LokiVector bool
+
+	// When set, filled with the approximate size of the body read and of the frames
+	MemoryStats *MemoryStats
+
+	// When set, the timestamps of every matrix and vector series are checked to be increasing,
+	// and the frames get a notice counting the duplicated and out of order ones. The samples
+	// are kept as they are.
+	ValidateTimestamps bool
+	timestamps         *timestampIssues
+
+	// When set, the custom meta of every frame has the parse duration, the number of series and
+	// samples read, and the samples dropped because they were malformed or beyond MaxRows, as
+	// "parseDuration", "series", "samples" and "droppedSamples". A notice tells how many were dropped.
+	ParseStats bool
+
+	// When set, reading a larger body fails with a BodyLimitError instead of converting it.
+	// ReadAuto, ReadExposition, the remote read and streaming readers enforce it, the iterators
+	// passed to ReadPrometheusStyleResult must read the body through LimitBody.
+	MaxBodyBytes int64
+
+	// set by StreamPrometheusStyleResult
+	sink *frameSink
+	// set by ReadPrometheusStyleResultArrow
+	arrow *arrowColumns
+	// samples skipped in the whole response
+	malformed *malformedSamples
+
+	// When set, the frames follow the dataplane contract: instant vectors are numeric frames,
+	// log frames have the log-lines type and field names, and the frame types are versioned
+	Dataplane bool
+
+	// How the keys of the response that are not read are reported, they are skipped silently by default
+	Strict      StrictMode
+	unknownKeys *unknownKeys
 }
 
 // ReadPrometheusStyleResult will read results from a prometheus or loki server and return data frames
 func ReadPrometheusStyleResult(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
+	start := time.Now()
 	var rsp backend.DataResponse
 	status := "unknown"
 	errorType := ""
 	err := ""
 	warnings := []data.Notice{}
+	partialResponse := false
+	var indexStats *data.Frame
+	if opt.MaxRows > 0 && opt.rowLimit == nil {
+		opt.rowLimit = &rowLimit{max: opt.MaxRows}
+	}
+	if opt.malformed == nil {
+		opt.malformed = &malformedSamples{}
+	}
+	if opt.ValidateTimestamps && opt.timestamps == nil {
+		opt.timestamps = &timestampIssues{}
+	}
+	attachUnknownKeys(iter, &opt)
 
 	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
 		switch l1Field {
@@ -46,20 +231,52 @@ func ReadPrometheusStyleResult(iter *jsoniter.Iterator, opt Options) backend.Dat
 			errorType = iter.ReadString()
 
 		case "warnings":
-			warnings = readWarnings(iter)
+			warnings, partialResponse = readWarnings(iter)
+
+		case "streams", "chunks", "bytes", "entries":
+			// /loki/api/v1/index/stats is not wrapped in data
+			if indexStats == nil {
+				indexStats = newIndexStatsFrame()
+			}
+			readIndexStat(iter, l1Field, indexStats)
 
 		default:
-			v := iter.Read()
-			logf("[ROOT] TODO, support key: %s / %v\n", l1Field, v)
+			iter.Skip()
+			skippedKey(iter, "ROOT", l1Field)
 		}
 	}
 
+	// the frames read until the limit are dropped, like the body beyond it
+	if limitErr := bodyLimitError(iter.Error); limitErr != nil {
+		return backend.DataResponse{Error: limitErr}
+	}
+
 	if status == "error" {
 		return backend.DataResponse{
-			Error: fmt.Errorf("%s: %s", errorType, err),
+			Error: newResponseError(errorType, err),
 		}
 	}
 
+	if indexStats != nil {
+		rsp.Frames = append(rsp.Frames, indexStats)
+	}
+
+	var seriesNotice *data.Notice
+	if opt.sink == nil {
+		rsp.Frames, seriesNotice = limitSeries(rsp.Frames, opt)
+		if opt.ExemplarsOnSeries {
+			rsp.Frames = attachSeriesExemplars(rsp.Frames)
+		}
+	}
+
+	if opt.IndexVolume {
+		setVolumeUnit(rsp.Frames)
+	}
+
+	if len(opt.MetricMetadata) > 0 {
+		attachMetricMetadata(rsp.Frames, opt.MetricMetadata)
+	}
+
 	if len(warnings) > 0 {
 		for _, frame := range rsp.Frames {
 			if frame.Meta == nil {
@@ -69,32 +286,99 @@ func ReadPrometheusStyleResult(iter *jsoniter.Iterator, opt Options) backend.Dat
 		}
 	}
 
+	if partialResponse {
+		markPartialResponse(rsp.Frames)
+	}
+
+	finishFrames(rsp.Frames, opt)
+
+	if opt.rowLimit != nil && opt.rowLimit.truncated {
+		addTruncatedNotice(rsp.Frames, opt.rowLimit.max)
+	}
+	if seriesNotice != nil {
+		for _, frame := range rsp.Frames {
+			frame.AppendNotices(*seriesNotice)
+		}
+	}
+	addMalformedNotice(rsp.Frames, opt.malformed)
+	addTimestampNotice(rsp.Frames, opt.timestamps)
+	if opt.ParseStats {
+		addParseStats(rsp.Frames, readParseStats(rsp.Frames, start, opt))
+	}
+	if err := opt.unknownKeys.report(rsp.Frames, opt.Strict); err != nil && rsp.Error == nil {
+		rsp.Error = err
+	}
+
 	return rsp
 }
 
-func readWarnings(iter *jsoniter.Iterator) []data.Notice {
+// readWarnings reads the warnings as strings, or as objects with a type and a message
+// like some compatible backends return them
+func readWarnings(iter *jsoniter.Iterator) ([]data.Notice, bool) {
 	warnings := []data.Notice{}
+	partialResponse := false
 	if iter.WhatIsNext() != jsoniter.ArrayValue {
-		return warnings
+		iter.Skip()
+		return warnings, false
 	}
 
 	for iter.ReadArray() {
-		if iter.WhatIsNext() == jsoniter.StringValue {
-			notice := data.Notice{
-				Severity: data.NoticeSeverityWarning,
-				Text:     iter.ReadString(),
+		switch iter.WhatIsNext() {
+		case jsoniter.StringValue:
+			notice, partial := readWarningNotice(iter.ReadString())
+			partialResponse = partialResponse || partial
+			warnings = append(warnings, notice)
+		case jsoniter.ObjectValue:
+			warningType, text := readWarningObject(iter)
+			if text == "" {
+				continue
+			}
+			notice, partial := readWarningNotice(text)
+			if !partial {
+				notice.Severity = warningSeverity(warningType)
 			}
+			partialResponse = partialResponse || partial
 			warnings = append(warnings, notice)
+		default:
+			iter.Skip()
 		}
 	}
 
-	return warnings
+	return warnings, partialResponse
+}
+
+func readWarningObject(iter *jsoniter.Iterator) (string, string) {
+	warningType, text := "", ""
+	for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+		switch k {
+		case "type":
+			warningType = iter.ReadString()
+		case "message":
+			text = iter.ReadString()
+		default:
+			iter.Skip()
+			skippedKey(iter, "warnings", k)
+		}
+	}
+	return warningType, text
+}
+
+// warningSeverity maps the type of a warning object to the severity of its notice,
+// unknown types are warnings
+func warningSeverity(warningType string) data.NoticeSeverity {
+	switch strings.ToLower(warningType) {
+	case "error", "err":
+		return data.NoticeSeverityError
+	case "info", "information":
+		return data.NoticeSeverityInfo
+	}
+	return data.NoticeSeverityWarning
 }
 
 func readPrometheusData(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
 	t := iter.WhatIsNext()
 	if t == jsoniter.ArrayValue {
-		return readArrayData(iter)
+		return readArrayData(iter, opt)
 	}
 
 	if t != jsoniter.ObjectValue {
@@ -105,6 +389,8 @@ func readPrometheusData(iter *jsoniter.Iterator, opt Options) backend.DataRespon
 
 	resultType := ""
 	var rsp backend.DataResponse
+	var metadataFrame *data.Frame
+	var statusFrame *data.Frame
 
 	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
 		switch l1Field {
@@ -115,29 +401,55 @@ func readPrometheusData(iter *jsoniter.Iterator, opt Options) backend.DataRespon
 			switch resultType {
 			case "matrix":
 				if opt.MatrixWideSeries {
-					rsp = readMatrixOrVectorWide(iter, resultType)
+					rsp = readMatrixOrVectorWide(iter, resultType, opt)
+				} else if opt.MatrixLongSeries {
+					rsp = readMatrixLong(iter, opt)
 				} else {
-					rsp = readMatrixOrVectorMulti(iter, resultType)
+					rsp = readMatrixOrVectorMulti(iter, resultType, opt)
 				}
 			case "vector":
 				if opt.VectorWideSeries {
-					rsp = readMatrixOrVectorWide(iter, resultType)
+					rsp = readMatrixOrVectorWide(iter, resultType, opt)
 				} else {
-					rsp = readMatrixOrVectorMulti(iter, resultType)
+					rsp = readMatrixOrVectorMulti(iter, resultType, opt)
 				}
 			case "streams":
-				rsp = readStream(iter)
+				rsp = readStream(iter, opt)
 			case "string":
-				rsp = readString(iter)
+				rsp = readString(iter, opt)
 			case "scalar":
-				rsp = readScalar(iter)
+				rsp = readScalar(iter, opt)
 			default:
-				iter.Skip()
-				rsp = backend.DataResponse{
-					Error: fmt.Errorf("unknown result type: %s", resultType),
+				if fn, ok := getResultTypeReader(resultType); ok {
+					rsp = fn(iter, opt)
+				} else {
+					iter.Skip()
+					rsp = backend.DataResponse{
+						Error: fmt.Errorf("unknown result type: %s", resultType),
+					}
 				}
 			}
 
+		case "groups":
+			rsp = readRules(iter)
+
+		case "alerts":
+			rsp = readAlerts(iter)
+
+		case "activeTargets":
+			targets := readActiveTargets(iter)
+			rsp.Frames = append(rsp.Frames, targets.Frames...)
+			if targets.Error != nil {
+				rsp.Error = targets.Error
+			}
+
+		case "droppedTargets":
+			frame, err := readDroppedTargets(iter)
+			rsp.Frames = append(rsp.Frames, frame)
+			if err != nil {
+				rsp.Error = err
+			}
+
 		case "stats":
 			v := iter.Read()
 			if len(rsp.Frames) > 0 {
@@ -149,11 +461,48 @@ func readPrometheusData(iter *jsoniter.Iterator, opt Options) backend.DataRespon
 				meta.Custom = map[string]interface{}{
 					"stats": v,
 				}
+				meta.Stats = append(meta.Stats, readQueryStats(v)...)
+			}
+			if frame := readSamplesPerStep(v); frame != nil {
+				rsp.Frames = append(rsp.Frames, frame)
 			}
 
+		case "headStats":
+			rsp.Frames = append(rsp.Frames, readTSDBHeadStats(iter))
+
+		// the categorize-labels encoding is detected from the entries, see readStructuredMetadata
+		case "encodingFlags":
+			iter.Skip()
+
 		default:
-			v := iter.Read()
-			logf("[data] TODO, support key: %s / %v\n", l1Field, v)
+			if resultType == "" {
+				next := iter.WhatIsNext()
+				switch {
+				case tsdbStatusLists[l1Field]:
+					rsp.Frames = append(rsp.Frames, readTSDBStatusList(iter, l1Field))
+					continue
+
+				// metric metadata is keyed by metric name
+				case next == jsoniter.ArrayValue:
+					if metadataFrame == nil {
+						metadataFrame = newMetadataFrame()
+						rsp.Frames = append(rsp.Frames, metadataFrame)
+					}
+					readMetadataEntry(iter, l1Field, metadataFrame)
+					continue
+
+				// buildinfo and runtimeinfo
+				case next != jsoniter.ObjectValue:
+					if statusFrame == nil {
+						statusFrame = newStatusInfoFrame()
+						rsp.Frames = append(rsp.Frames, statusFrame)
+					}
+					readStatusInfoValue(iter, l1Field, statusFrame)
+					continue
+				}
+			}
+			iter.Skip()
+			skippedKey(iter, "data", l1Field)
 		}
 	}
 
@@ -161,7 +510,7 @@ func readPrometheusData(iter *jsoniter.Iterator, opt Options) backend.DataRespon
 }
 
 // will return strings or exemplars
-func readArrayData(iter *jsoniter.Iterator) backend.DataResponse {
+func readArrayData(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
 	lookup := make(map[string]*data.Field)
 
 	var labelFrame *data.Frame
@@ -175,7 +524,7 @@ func readArrayData(iter *jsoniter.Iterator) backend.DataResponse {
 
 		// Either label or exemplars
 		case jsoniter.ObjectValue:
-			exemplar, labelPairs := readLabelsOrExemplars(iter)
+			exemplar, labelPairs := readLabelsOrExemplars(iter, opt)
 			if exemplar != nil {
 				rsp.Frames = append(rsp.Frames, exemplar)
 			} else if labelPairs != nil {
@@ -219,6 +568,10 @@ func readArrayData(iter *jsoniter.Iterator) backend.DataResponse {
 		}
 	}
 
+	if labelFrame != nil && opt.SortLabelColumns {
+		sortLabelColumns(labelFrame)
+	}
+
 	if stringField.Len() > 0 {
 		rsp.Frames = append(rsp.Frames, data.NewFrame("", stringField))
 	}
@@ -226,6 +579,94 @@ func readArrayData(iter *jsoniter.Iterator) backend.DataResponse {
 	return rsp
 }
 
+// Well known labels are shown first when sorting the label columns
+var pinnedLabelColumns = map[string]int{
+	"__name__": 0,
+	"job":      1,
+	"instance": 2,
+}
+
+func sortLabelColumns(frame *data.Frame) {
+	sort.SliceStable(frame.Fields, func(i, j int) bool {
+		a, b := frame.Fields[i].Name, frame.Fields[j].Name
+		pa, aPinned := pinnedLabelColumns[a]
+		pb, bPinned := pinnedLabelColumns[b]
+		switch {
+		case aPinned && bPinned:
+			return pa < pb
+		case aPinned != bPinned:
+			return aPinned
+		}
+		return a < b
+	})
+}
+
+// readExemplars reads the exemplars of a series, from /api/v1/query_exemplars or next to
+// the values or histograms of a matrix series. The labels map can still be filled after.
+func readExemplars(iter *jsoniter.Iterator, labels data.Labels, opt Options) *data.Frame {
+	pairs := make([][2]string, 0, 10)
+	lookup := make(map[string]*data.Field)
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
+	timeField.Name = data.TimeSeriesTimeFieldName
+	// exemplar values are never dropped, the rows of the other fields are already added
+	valueField := data.NewFieldFromFieldType(exemplarValueFieldType(opt), 0)
+	valueField.Name = data.TimeSeriesValueFieldName
+	valueField.Labels = labels
+	frame := data.NewFrame("", timeField, valueField)
+	frame.Meta = &data.FrameMeta{
+		Custom: resultTypeToCustomMeta("exemplar"),
+	}
+	for row := 0; iter.ReadArray(); row++ {
+		for l2Field := iter.ReadObject(); l2Field != ""; l2Field = iter.ReadObject() {
+			switch l2Field {
+			// nolint:goconst
+			case "value":
+				v, _ := strconv.ParseFloat(iter.ReadString(), 64)
+				fv, ok := nonFiniteValues(opt, v).convert(v)
+				if !ok {
+					fv = nil
+				}
+				appendFloat(valueField, fv)
+
+			case "timestamp":
+				ts := readTimestamp(iter, opt)
+				timeField.Append(ts)
+
+			case "labels":
+				for _, pair := range readLabelsAsPairs(iter, pairs) {
+					k := pair[0]
+					v := pair[1]
+					f, ok := lookup[k]
+					if !ok {
+						// the previous exemplars did not have the label
+						f = data.NewFieldFromFieldType(data.FieldTypeString, row)
+						f.Name = k
+						lookup[k] = f
+						frame.Fields = append(frame.Fields, f)
+					}
+					f.Append(v)
+				}
+
+			default:
+				iter.Skip()
+				frame.AppendNotices(data.Notice{
+					Severity: data.NoticeSeverityError,
+					Text:     fmt.Sprintf("unable to parse key: %s in response body", l2Field),
+				})
+			}
+		}
+
+		// Make sure all fields have equal length, the labels missing in this exemplar are empty
+		for _, f := range lookup {
+			if diff := row + 1 - f.Len(); diff > 0 {
+				f.Extend(diff)
+			}
+		}
+	}
+	addExemplarTraceLinks(lookup, opt.ExemplarTraceLinks)
+	return frame
+}
+
 // For consistent ordering read values to an array not a map
 func readLabelsAsPairs(iter *jsoniter.Iterator, pairs [][2]string) [][2]string {
 	pairs = pairs[:0]
@@ -235,101 +676,82 @@ func readLabelsAsPairs(iter *jsoniter.Iterator, pairs [][2]string) [][2]string {
 	return pairs
 }
 
-func readLabelsOrExemplars(iter *jsoniter.Iterator) (*data.Frame, [][2]string) {
+func readLabelsOrExemplars(iter *jsoniter.Iterator, opt Options) (*data.Frame, [][2]string) {
 	pairs := make([][2]string, 0, 10)
 	labels := data.Labels{}
 	var frame *data.Frame
+	pattern := ""
+	hasPattern := false
 
 	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
 		switch l1Field {
 		case "seriesLabels":
 			iter.ReadVal(&labels)
 		case "exemplars":
-			lookup := make(map[string]*data.Field)
-			timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
-			timeField.Name = data.TimeSeriesTimeFieldName
-			valueField := data.NewFieldFromFieldType(data.FieldTypeFloat64, 0)
-			valueField.Name = data.TimeSeriesValueFieldName
-			valueField.Labels = labels
-			frame = data.NewFrame("", timeField, valueField)
-			frame.Meta = &data.FrameMeta{
-				Custom: resultTypeToCustomMeta("exemplar"),
-			}
-			for iter.ReadArray() {
-				for l2Field := iter.ReadObject(); l2Field != ""; l2Field = iter.ReadObject() {
-					switch l2Field {
-					// nolint:goconst
-					case "value":
-						v, _ := strconv.ParseFloat(iter.ReadString(), 64)
-						valueField.Append(v)
-
-					case "timestamp":
-						ts := timeFromFloat(iter.ReadFloat64())
-						timeField.Append(ts)
-
-					case "labels":
-						max := 0
-						for _, pair := range readLabelsAsPairs(iter, pairs) {
-							k := pair[0]
-							v := pair[1]
-							f, ok := lookup[k]
-							if !ok {
-								f = data.NewFieldFromFieldType(data.FieldTypeString, 0)
-								f.Name = k
-								lookup[k] = f
-								frame.Fields = append(frame.Fields, f)
-							}
-							f.Append(v)
-							if f.Len() > max {
-								max = f.Len()
-							}
-						}
-
-						// Make sure all fields have equal length
-						for _, f := range lookup {
-							diff := max - f.Len()
-							if diff > 0 {
-								f.Extend(diff)
-							}
-						}
-
-					default:
-						iter.Skip()
-						frame.AppendNotices(data.Notice{
-							Severity: data.NoticeSeverityError,
-							Text:     fmt.Sprintf("unable to parse key: %s in response body", l2Field),
-						})
-					}
+			frame = readExemplars(iter, labels, opt)
+		// targets/metadata: { target: { instance, job }, metric, type, help, unit }
+		case "target":
+			if iter.WhatIsNext() == jsoniter.ObjectValue {
+				for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+					pairs = append(pairs, [2]string{k, iter.ReadString()})
 				}
+				continue
+			}
+			// a series with a "target" label
+			v := fmt.Sprintf("%v", iter.Read())
+			pairs = append(pairs, [2]string{l1Field, v})
+		// loki patterns: { pattern, samples: [ [ ts, count ], ... ] }
+		case "samples":
+			if iter.WhatIsNext() == jsoniter.ArrayValue {
+				frame = readPatternSamples(iter)
+				continue
 			}
+			// a series with a "samples" label
+			v := fmt.Sprintf("%v", iter.Read())
+			pairs = append(pairs, [2]string{l1Field, v})
+		case "pattern":
+			if iter.WhatIsNext() == jsoniter.StringValue {
+				pattern = iter.ReadString()
+				hasPattern = true
+				continue
+			}
+			v := fmt.Sprintf("%v", iter.Read())
+			pairs = append(pairs, [2]string{l1Field, v})
 		default:
 			v := fmt.Sprintf("%v", iter.Read())
-			pairs = pairs[:0]
 			pairs = append(pairs, [2]string{l1Field, v})
 		}
 	}
 
+	if hasPattern {
+		if frame != nil {
+			setPatternName(frame, pattern)
+		} else {
+			// a series with a "pattern" label
+			pairs = append(pairs, [2]string{"pattern", pattern})
+		}
+	}
+
 	return frame, pairs
 }
 
-func readString(iter *jsoniter.Iterator) backend.DataResponse {
+func readString(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
 	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
 	timeField.Name = data.TimeSeriesTimeFieldName
 	valueField := data.NewFieldFromFieldType(data.FieldTypeString, 0)
 	valueField.Name = data.TimeSeriesValueFieldName
-	valueField.Labels = data.Labels{}
+	valueField.Labels = scalarLabels(opt)
 
 	iter.ReadArray()
-	t := iter.ReadFloat64()
+	t := readTimestamp(iter, opt)
 	iter.ReadArray()
 	v := iter.ReadString()
 	iter.ReadArray()
 
-	tt := timeFromFloat(t)
-	timeField.Append(tt)
+	timeField.Append(t)
 	valueField.Append(v)
 
-	frame := data.NewFrame("", timeField, valueField)
+	frame := data.NewFrame(opt.ScalarFrameName, timeField, valueField)
 	frame.Meta = &data.FrameMeta{
 		Type:   data.FrameTypeTimeSeriesMulti,
 		Custom: resultTypeToCustomMeta("string"),
@@ -340,20 +762,25 @@ func readString(iter *jsoniter.Iterator) backend.DataResponse {
 	}
 }
 
-func readScalar(iter *jsoniter.Iterator) backend.DataResponse {
+// scalarLabels returns a copy of the ScalarLabels, the frames must not share the map of the options
+func scalarLabels(opt Options) data.Labels {
+	labels := data.Labels{}
+	for k, v := range opt.ScalarLabels {
+		labels[k] = v
+	}
+	return labels
+}
+
+func readScalar(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
 	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
 	timeField.Name = data.TimeSeriesTimeFieldName
-	valueField := data.NewFieldFromFieldType(data.FieldTypeFloat64, 0)
+	valueField := data.NewFieldFromFieldType(multiValueFieldType(opt), 0)
 	valueField.Name = data.TimeSeriesValueFieldName
-	valueField.Labels = data.Labels{}
+	valueField.Labels = scalarLabels(opt)
 
-	t, v, err := readTimeValuePair(iter)
-	if err == nil {
-		timeField.Append(t)
-		valueField.Append(v)
-	}
+	appendTimeValuePair(iter, timeField, valueField, opt)
 
-	frame := data.NewFrame("", timeField, valueField)
+	frame := data.NewFrame(opt.ScalarFrameName, timeField, valueField)
 	frame.Meta = &data.FrameMeta{
 		Type:   data.FrameTypeTimeSeriesMulti,
 		Custom: resultTypeToCustomMeta("scalar"),
@@ -364,10 +791,15 @@ func readScalar(iter *jsoniter.Iterator) backend.DataResponse {
 	}
 }
 
-func readMatrixOrVectorWide(iter *jsoniter.Iterator, resultType string) backend.DataResponse {
+func readMatrixOrVectorWide(iter *jsoniter.Iterator, resultType string, opt Options) backend.DataResponse {
 	rowIdx := 0
 	timeMap := map[int64]int{}
 	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
+	opt.grid = newTimeGrid(opt, resultType)
+	if opt.grid != nil {
+		timeField = opt.grid.timeField()
+		rowIdx = opt.grid.rows
+	}
 	timeField.Name = data.TimeSeriesTimeFieldName
 	frame := data.NewFrame("", timeField)
 	frame.Meta = &data.FrameMeta{
@@ -377,32 +809,39 @@ func readMatrixOrVectorWide(iter *jsoniter.Iterator, resultType string) backend.
 	rsp := backend.DataResponse{
 		Frames: []*data.Frame{},
 	}
+	interner := stringInterner{}
+	slab := &floatSlab{}
+	var merged *mergedHistograms
+	if opt.MergeHistograms {
+		merged = newMergedHistograms(opt)
+	}
+
+	samples := &seriesBuffer{}
 
 	for iter.ReadArray() {
-		valueField := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, frame.Rows())
-		valueField.Name = data.TimeSeriesValueFieldName
-		valueField.Labels = data.Labels{}
-		frame.Fields = append(frame.Fields, valueField)
+		samples.reset()
+		labels := data.Labels{}
 
 		var histogram *histogramInfo
+		var exemplars *data.Frame
 
 		for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
 			switch l1Field {
 			case "metric":
-				iter.ReadVal(&valueField.Labels)
+				labels = readLabels(iter, interner)
 
 			case "value":
-				timeMap, rowIdx = addValuePairToFrame(frame, timeMap, rowIdx, iter)
+				samples.appendTimeValuePair(iter, opt)
 
 			// nolint:goconst
 			case "values":
 				for iter.ReadArray() {
-					timeMap, rowIdx = addValuePairToFrame(frame, timeMap, rowIdx, iter)
+					samples.appendTimeValuePair(iter, opt)
 				}
 
 			case "histogram":
 				if histogram == nil {
-					histogram = newHistogramInfo()
+					histogram = newHistogramInfo(opt)
 				}
 				err := readHistogram(iter, histogram)
 				if err != nil {
@@ -411,7 +850,7 @@ func readMatrixOrVectorWide(iter *jsoniter.Iterator, resultType string) backend.
 
 			case "histograms":
 				if histogram == nil {
-					histogram = newHistogramInfo()
+					histogram = newHistogramInfo(opt)
 				}
 				for iter.ReadArray() {
 					err := readHistogram(iter, histogram)
@@ -420,151 +859,258 @@ func readMatrixOrVectorWide(iter *jsoniter.Iterator, resultType string) backend.
 					}
 				}
 
+			// exemplars of the series, like the ones of native histograms
+			case "exemplars":
+				exemplars = readExemplars(iter, nil, opt)
+
 			default:
 				iter.Skip()
-				logf("readMatrixOrVector: %s\n", l1Field)
+				skippedKey(iter, "result", l1Field)
 			}
 		}
 
+		opt.timestamps.check(labels, samples.times)
+		// series with only histograms or exemplars do not get a value column, but series
+		// mixing float values and histograms (like during a migration) keep both
+		if (histogram == nil && exemplars == nil) || samples.len() > 0 {
+			valueField := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, frame.Rows())
+			valueField.Name = data.TimeSeriesValueFieldName
+			valueField.Labels = labels
+			setSeriesDisplayName(opt, valueField)
+			frame.Fields = append(frame.Fields, valueField)
+			rowIdx = addSamplesToFrame(frame, timeMap, rowIdx, samples, slab, opt)
+		}
 		if histogram != nil {
-			histogram.yMin.Labels = valueField.Labels
-			frame := data.NewFrame(valueField.Name, histogram.time, histogram.yMin, histogram.yMax, histogram.count, histogram.yLayout)
-			frame.Meta = &data.FrameMeta{
-				Type: "heatmap-cells",
-			}
-			if frame.Name == data.TimeSeriesValueFieldName {
-				frame.Name = "" // only set the name if useful
+			if merged != nil {
+				merged.add(labels, histogram)
+			} else {
+				rsp.Frames = append(rsp.Frames, newHistogramFrame(opt, labels, histogram))
 			}
-			rsp.Frames = append(rsp.Frames, frame)
+		}
+		if exemplars != nil {
+			exemplars.Fields[1].Labels = labels
+			rsp.Frames = append(rsp.Frames, exemplars)
 		}
 	}
+	if merged != nil && merged.len() > 0 {
+		rsp.Frames = append(rsp.Frames, merged.frame())
+	}
 
-	if len(rsp.Frames) == 0 {
+	if len(rsp.Frames) == 0 || len(frame.Fields) > 1 {
+		if opt.grid != nil {
+			opt.grid.compact(frame)
+		}
 		sorter := experimental.NewFrameSorter(frame, frame.Fields[0])
 		sort.Sort(sorter)
-		rsp.Frames = append(rsp.Frames, frame)
+		sortValueFields(frame)
+		if opt.FillGaps && resultType == "matrix" {
+			fillFrameGaps(frame, opt.Step)
+		}
+		if opt.DropRepeatedValues && resultType == "matrix" {
+			for _, f := range frame.Fields[1:] {
+				nullRepeatedValues(f)
+			}
+		}
+		if opt.NumericVector && resultType == "vector" {
+			toNumericFrame(frame)
+		}
+		rsp.Frames = append([]*data.Frame{frame}, rsp.Frames...)
 	}
 
 	return rsp
 }
 
-func addValuePairToFrame(frame *data.Frame, timeMap map[int64]int, rowIdx int, iter *jsoniter.Iterator) (map[int64]int, int) {
+// sortValueFields orders the value fields by their labels, so the same query always
+// returns the same frame even when the series are returned in another order
+func sortValueFields(frame *data.Frame) {
+	fields := frame.Fields[1:]
+	keys := make(map[*data.Field]string, len(fields))
+	for _, f := range fields {
+		keys[f] = f.Labels.String()
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return keys[fields[i]] < keys[fields[j]]
+	})
+}
+
+// addSamplesToFrame sets the samples of a series in the last field of a wide frame,
+// adding the rows of the timestamps no series had before
+func addSamplesToFrame(frame *data.Frame, timeMap map[int64]int, rowIdx int, samples *seriesBuffer, slab *floatSlab, opt Options) int {
 	timeField := frame.Fields[0]
 	valueField := frame.Fields[len(frame.Fields)-1]
 
-	t, v, err := readTimeValuePair(iter)
-	if err != nil {
-		return timeMap, rowIdx
+	for j, t := range samples.times {
+		var v *float64
+		if !samples.nulls[j] {
+			v = slab.ptr(samples.values[j])
+		}
+
+		ns := t.UnixNano()
+		i, ok := opt.grid.index(ns)
+		if ok {
+			valueField.Set(i, v)
+			continue
+		}
+		i, ok = timeMap[ns]
+		if !ok {
+			timeMap[ns] = rowIdx
+			i = rowIdx
+			expandFrame(frame, i)
+			timeField.Set(i, t)
+			rowIdx++
+		}
+		valueField.Set(i, v)
 	}
+	return rowIdx
+}
 
-	ns := t.UnixNano()
-	i, ok := timeMap[ns]
-	if !ok {
-		timeMap[ns] = rowIdx
-		i = rowIdx
-		expandFrame(frame, i)
-		rowIdx++
+func readMatrixOrVectorMulti(iter *jsoniter.Iterator, resultType string, opt Options) backend.DataResponse {
+	if parallelSeries(opt) {
+		return readMatrixOrVectorParallel(iter, resultType, opt)
 	}
 
-	timeField.Set(i, t)
-	valueField.Set(i, &v)
+	rsp := backend.DataResponse{}
+	state := newMultiSeriesState(opt)
+	for iter.ReadArray() {
+		readMultiSeries(iter, resultType, opt, state, &rsp)
+	}
+	if state.merged != nil && state.merged.len() > 0 {
+		appendFrame(iter, &rsp, state.merged.frame(), opt)
+	}
 
-	return timeMap, rowIdx
+	return rsp
 }
 
-func readMatrixOrVectorMulti(iter *jsoniter.Iterator, resultType string) backend.DataResponse {
-	rsp := backend.DataResponse{}
+// multiSeriesState is shared by the series of a result read into multi frames
+type multiSeriesState struct {
+	interner stringInterner
+	samples  *seriesBuffer
+	merged   *mergedHistograms
+}
 
-	for iter.ReadArray() {
-		timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
-		timeField.Name = data.TimeSeriesTimeFieldName
-		valueField := data.NewFieldFromFieldType(data.FieldTypeFloat64, 0)
-		valueField.Name = data.TimeSeriesValueFieldName
-		valueField.Labels = data.Labels{}
+func newMultiSeriesState(opt Options) *multiSeriesState {
+	state := &multiSeriesState{
+		interner: stringInterner{},
+		samples:  &seriesBuffer{},
+	}
+	if opt.MergeHistograms {
+		state.merged = newMergedHistograms(opt)
+	}
+	return state
+}
 
-		var histogram *histogramInfo
+// readMultiSeries reads a series of the result array into its frames
+func readMultiSeries(iter *jsoniter.Iterator, resultType string, opt Options, state *multiSeriesState, rsp *backend.DataResponse) {
+	state.samples.reset()
+	labels := data.Labels{}
 
-		for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
-			switch l1Field {
-			case "metric":
-				iter.ReadVal(&valueField.Labels)
+	var histogram *histogramInfo
+	var exemplars *data.Frame
+	var labelsJSON json.RawMessage
 
-			case "value":
-				t, v, err := readTimeValuePair(iter)
-				if err == nil {
-					timeField.Append(t)
-					valueField.Append(v)
-				}
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "metric":
+			if opt.LokiVector && resultType == "vector" {
+				labels, labelsJSON = readLokiVectorLabels(iter, state.interner)
+				continue
+			}
+			labels = readLabels(iter, state.interner)
 
-			// nolint:goconst
-			case "values":
-				for iter.ReadArray() {
-					t, v, err := readTimeValuePair(iter)
-					if err == nil {
-						timeField.Append(t)
-						valueField.Append(v)
-					}
-				}
+		case "value":
+			state.samples.appendTimeValuePair(iter, opt)
 
-			case "histogram":
-				if histogram == nil {
-					histogram = newHistogramInfo()
-				}
+		// nolint:goconst
+		case "values":
+			for iter.ReadArray() {
+				state.samples.appendTimeValuePair(iter, opt)
+			}
+
+		case "histogram":
+			if histogram == nil {
+				histogram = newHistogramInfo(opt)
+			}
+			err := readHistogram(iter, histogram)
+			if err != nil {
+				rsp.Error = err
+			}
+
+		case "histograms":
+			if histogram == nil {
+				histogram = newHistogramInfo(opt)
+			}
+			for iter.ReadArray() {
 				err := readHistogram(iter, histogram)
 				if err != nil {
 					rsp.Error = err
 				}
+			}
 
-			case "histograms":
-				if histogram == nil {
-					histogram = newHistogramInfo()
-				}
-				for iter.ReadArray() {
-					err := readHistogram(iter, histogram)
-					if err != nil {
-						rsp.Error = err
-					}
-				}
+		// exemplars of the series, like the ones of native histograms
+		case "exemplars":
+			exemplars = readExemplars(iter, nil, opt)
 
-			default:
-				iter.Skip()
-				logf("readMatrixOrVector: %s\n", l1Field)
-			}
+		default:
+			iter.Skip()
+			skippedKey(iter, "result", l1Field)
 		}
+	}
 
-		if histogram != nil {
-			histogram.yMin.Labels = valueField.Labels
-			frame := data.NewFrame(valueField.Name, histogram.time, histogram.yMin, histogram.yMax, histogram.count, histogram.yLayout)
-			frame.Meta = &data.FrameMeta{
-				Type: "heatmap-cells",
-			}
-			if frame.Name == data.TimeSeriesValueFieldName {
-				frame.Name = "" // only set the name if useful
-			}
-			rsp.Frames = append(rsp.Frames, frame)
-		} else {
-			frame := data.NewFrame("", timeField, valueField)
-			frame.Meta = &data.FrameMeta{
-				Type:   data.FrameTypeTimeSeriesMulti,
-				Custom: resultTypeToCustomMeta(resultType),
-			}
-			rsp.Frames = append(rsp.Frames, frame)
-		}
+	opt.timestamps.check(labels, state.samples.times)
+	var (
+		timeField, valueField *data.Field
+		columns               []array.Interface
+	)
+	if opt.arrow.direct(opt, resultType) {
+		timeField, valueField, columns = state.samples.arrowFields(opt, labels, opt.arrow.pool)
+	} else {
+		timeField, valueField = state.samples.fields(opt, labels)
 	}
 
-	return rsp
+	// series mixing float values and histograms (like during a migration)
+	// get a value frame followed by a heatmap frame with the same labels
+	if (histogram == nil && exemplars == nil) || state.samples.len() > 0 {
+		// the gaps are filled first, the samples dropped for repeating are not gaps
+		if opt.FillGaps && resultType == "matrix" {
+			timeField, valueField = fillSeriesGaps(timeField, valueField, opt.Step)
+		}
+		if opt.DropRepeatedValues && resultType == "matrix" {
+			timeField, valueField = dropRepeatedValues(timeField, valueField)
+		}
+		setSeriesDisplayName(opt, valueField)
+		frame := data.NewFrame(frameName(opt, labels), timeField, valueField)
+		frame.Meta = &data.FrameMeta{
+			Type:   data.FrameTypeTimeSeriesMulti,
+			Custom: resultTypeToCustomMeta(resultType),
+		}
+		if opt.LokiVector && resultType == "vector" {
+			addLokiVectorLabels(frame, labelsJSON)
+		}
+		if opt.NumericVector && resultType == "vector" {
+			toNumericFrame(frame)
+		}
+		opt.arrow.add(frame, columns)
+		appendFrame(iter, rsp, frame, opt)
+	}
+	if histogram != nil && state.merged != nil {
+		state.merged.add(labels, histogram)
+	} else if histogram != nil {
+		appendFrame(iter, rsp, newHistogramFrame(opt, labels, histogram), opt)
+	}
+	if exemplars != nil {
+		exemplars.Fields[1].Labels = labels
+		appendFrame(iter, rsp, exemplars, opt)
+	}
 }
 
-func readTimeValuePair(iter *jsoniter.Iterator) (time.Time, float64, error) {
-	iter.ReadArray()
-	t := iter.ReadFloat64()
-	iter.ReadArray()
-	v := iter.ReadString()
-	iter.ReadArray()
-
-	tt := timeFromFloat(t)
-	fv, err := strconv.ParseFloat(v, 64)
-	return tt, fv, err
+// newHistogramFrame returns the heatmap of a series, named like the frames of the float series
+func newHistogramFrame(opt Options, labels data.Labels, histogram *histogramInfo) *data.Frame {
+	histogram.yMin.Labels = labels
+	frame := data.NewFrame(frameName(opt, labels), histogram.time, histogram.yMin, histogram.yMax, histogram.count, histogram.yLayout)
+	frame.Meta = &data.FrameMeta{
+		Type: "heatmap-cells",
+	}
+	return frame
 }
 
 func expandFrame(frame *data.Frame, idx int) {
@@ -582,15 +1128,21 @@ type histogramInfo struct {
 	yMax    *data.Field
 	count   *data.Field
 	yLayout *data.Field
+
+	// bucket budget per timestamp, 0 means unlimited
+	maxBuckets int
+	opt        Options
 }
 
-func newHistogramInfo() *histogramInfo {
+func newHistogramInfo(opt Options) *histogramInfo {
 	hist := &histogramInfo{
-		time:    data.NewFieldFromFieldType(data.FieldTypeTime, 0),
-		yMin:    data.NewFieldFromFieldType(data.FieldTypeFloat64, 0),
-		yMax:    data.NewFieldFromFieldType(data.FieldTypeFloat64, 0),
-		count:   data.NewFieldFromFieldType(data.FieldTypeFloat64, 0),
-		yLayout: data.NewFieldFromFieldType(data.FieldTypeInt8, 0),
+		time:       data.NewFieldFromFieldType(data.FieldTypeTime, 0),
+		yMin:       data.NewFieldFromFieldType(data.FieldTypeFloat64, 0),
+		yMax:       data.NewFieldFromFieldType(data.FieldTypeFloat64, 0),
+		count:      data.NewFieldFromFieldType(data.FieldTypeFloat64, 0),
+		yLayout:    data.NewFieldFromFieldType(data.FieldTypeInt8, 0),
+		maxBuckets: opt.MaxHistogramBuckets,
+		opt:        opt,
 	}
 	hist.time.Name = "xMax"
 	hist.yMin.Name = "yMin"
@@ -600,14 +1152,22 @@ func newHistogramInfo() *histogramInfo {
 	return hist
 }
 
+type histogramBucket struct {
+	layout int8
+	min    float64
+	max    float64
+	count  float64
+}
+
 // This will read a single sparse histogram
 // [ time, { count, sum, buckets: [...] }]
 func readHistogram(iter *jsoniter.Iterator, hist *histogramInfo) error {
 	// first element
 	iter.ReadArray()
-	t := timeFromFloat(iter.ReadFloat64())
+	t := readTimestamp(iter, hist.opt)
 
 	var err error
+	buckets := []histogramBucket{}
 
 	// next object element
 	iter.ReadArray()
@@ -620,25 +1180,25 @@ func readHistogram(iter *jsoniter.Iterator, hist *histogramInfo) error {
 
 		case "buckets":
 			for iter.ReadArray() {
-				hist.time.Append(t)
+				b := histogramBucket{}
 
 				iter.ReadArray()
-				hist.yLayout.Append(iter.ReadInt8())
+				b.layout = iter.ReadInt8()
 
 				iter.ReadArray()
-				err = appendValueFromString(iter, hist.yMin)
+				b.min, err = readFloatFromString(iter)
 				if err != nil {
 					return err
 				}
 
 				iter.ReadArray()
-				err = appendValueFromString(iter, hist.yMax)
+				b.max, err = readFloatFromString(iter)
 				if err != nil {
 					return err
 				}
 
 				iter.ReadArray()
-				err = appendValueFromString(iter, hist.count)
+				b.count, err = readFloatFromString(iter)
 				if err != nil {
 					return err
 				}
@@ -646,11 +1206,12 @@ func readHistogram(iter *jsoniter.Iterator, hist *histogramInfo) error {
 				if iter.ReadArray() {
 					return fmt.Errorf("expected close array")
 				}
+				buckets = append(buckets, b)
 			}
 
 		default:
 			iter.Skip()
-			logf("[SKIP]readHistogram: %s\n", l1Field)
+			skippedKey(iter, "histogram", l1Field)
 		}
 	}
 
@@ -658,20 +1219,73 @@ func readHistogram(iter *jsoniter.Iterator, hist *histogramInfo) error {
 		return fmt.Errorf("expected to be done")
 	}
 
+	if hist.maxBuckets > 0 && len(buckets) > hist.maxBuckets {
+		buckets = mergeHistogramBuckets(buckets, hist.maxBuckets)
+	}
+
+	for _, b := range buckets {
+		hist.time.Append(t)
+		hist.yLayout.Append(b.layout)
+		hist.yMin.Append(b.min)
+		hist.yMax.Append(b.max)
+		hist.count.Append(b.count)
+	}
+
 	return nil
 }
 
-func appendValueFromString(iter *jsoniter.Iterator, field *data.Field) error {
-	v, err := strconv.ParseFloat(iter.ReadString(), 64)
-	if err != nil {
-		return err
+// mergeHistogramBuckets merges runs of adjacent buckets so no more than max buckets remain.
+// The merged bucket spans from the lower bound of the first to the upper bound of the last
+// bucket in the run, keeping their boundary inclusiveness.
+func mergeHistogramBuckets(buckets []histogramBucket, max int) []histogramBucket {
+	size := (len(buckets) + max - 1) / max
+	merged := make([]histogramBucket, 0, max)
+	for i := 0; i < len(buckets); i += size {
+		end := i + size
+		if end > len(buckets) {
+			end = len(buckets)
+		}
+		first := buckets[i]
+		last := buckets[end-1]
+		b := histogramBucket{
+			layout: mergedBucketLayout(first.layout, last.layout),
+			min:    first.min,
+			max:    last.max,
+		}
+		for _, v := range buckets[i:end] {
+			b.count += v.count
+		}
+		merged = append(merged, b)
 	}
-	field.Append(v)
-	return nil
+	return merged
 }
 
-func readStream(iter *jsoniter.Iterator) backend.DataResponse {
+// Bucket boundaries as reported by prometheus:
+// 0: open left, closed right
+// 1: closed left, open right
+// 2: open left, open right
+// 3: closed left, closed right
+func mergedBucketLayout(first, last int8) int8 {
+	closedLeft := first == 1 || first == 3
+	closedRight := last == 0 || last == 3
+	switch {
+	case closedLeft && closedRight:
+		return 3
+	case closedLeft:
+		return 1
+	case closedRight:
+		return 0
+	}
+	return 2
+}
+
+func readFloatFromString(iter *jsoniter.Iterator) (float64, error) {
+	return strconv.ParseFloat(iter.ReadString(), 64)
+}
+
+func readStream(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
 	rsp := backend.DataResponse{}
+	levels := newLevelDetector(opt.LevelDetection)
 
 	labelsField := data.NewFieldFromFieldType(data.FieldTypeJSON, 0)
 	labelsField.Name = "__labels" // avoid automatically spreading this by labels
@@ -684,13 +1298,26 @@ func readStream(iter *jsoniter.Iterator) backend.DataResponse {
 
 	// Nanoseconds time field
 	tsField := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	if opt.Int64LogTimestamps {
+		tsField = data.NewFieldFromFieldType(data.FieldTypeInt64, 0)
+	}
 	tsField.Name = "TS"
 
+	var levelField *data.Field
+	if levels != nil {
+		levelField = newLevelField()
+	}
+
 	labels := data.Labels{}
 	labelJson, err := labelsToRawJson(labels)
 	if err != nil {
 		return backend.DataResponse{Error: err}
 	}
+	labelLevel := ""
+	structuredMetadata := newStructuredMetadataBuilder(opt)
+	parsedLabels := newParsedLabelsBuilder()
+	jsonLines := newJSONLineFieldsBuilder(opt)
+	deduper := newLineDeduper(opt.Dedup)
 
 	for iter.ReadArray() {
 		for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
@@ -704,6 +1331,9 @@ func readStream(iter *jsoniter.Iterator) backend.DataResponse {
 				if err != nil {
 					return backend.DataResponse{Error: err}
 				}
+				if levels != nil {
+					labelLevel = levels.fromLabels(labels)
+				}
 
 			case "values":
 				for iter.ReadArray() {
@@ -711,20 +1341,54 @@ func readStream(iter *jsoniter.Iterator) backend.DataResponse {
 					ts := iter.ReadString()
 					iter.ReadArray()
 					line := iter.ReadString()
-					iter.ReadArray()
+					md, parsed := readStructuredMetadata(iter)
+					if deduper != nil && deduper.isDuplicate(line) {
+						continue
+					}
+					if !opt.rowLimit.take() {
+						continue
+					}
+					structuredMetadata.add(md)
+					parsedLabels.add(parsed)
 
 					t := timeFromLokiString(ts)
 
 					labelsField.Append(labelJson)
 					timeField.Append(t)
 					lineField.Append(line)
-					tsField.Append(ts)
+					if opt.Int64LogTimestamps {
+						tsField.Append(t.UnixNano())
+					} else {
+						tsField.Append(ts)
+					}
+					if levelField != nil {
+						levelField.Append(levels.detect(labelLevel, line))
+					}
+					if jsonLines != nil {
+						jsonLines.add(line)
+					}
 				}
 			}
 		}
 	}
 
 	frame := data.NewFrame("", labelsField, timeField, lineField, tsField)
+	if levelField != nil {
+		frame.Fields = append(frame.Fields, levelField)
+	}
+	metadataFields, err := structuredMetadata.fields()
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	frame.Fields = append(frame.Fields, metadataFields...)
+	parsedFields, err := parsedLabels.fields()
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	frame.Fields = append(frame.Fields, parsedFields...)
+	if jsonLines != nil {
+		frame.Fields = append(frame.Fields, jsonLines.fields(frame.Fields)...)
+	}
 	frame.Meta = &data.FrameMeta{}
 	rsp.Frames = append(rsp.Frames, frame)
 
@@ -739,6 +1403,37 @@ func timeFromFloat(fv float64) time.Time {
 	return time.UnixMilli(int64(fv * 1000.0)).UTC()
 }
 
+// readTimestamp reads a unix timestamp in seconds, like 1645030246.277587968
+func readTimestamp(iter *jsoniter.Iterator, opt Options) time.Time {
+	if !opt.NanosecondTimestamps {
+		return timeFromFloat(iter.ReadFloat64())
+	}
+	// a float64 can not hold the nanoseconds of current times, so the digits are parsed
+	return timeFromDecimalString(string(iter.ReadNumber()))
+}
+
+func timeFromDecimalString(str string) time.Time {
+	sec, frac, _ := strings.Cut(str, ".")
+	ss, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil || strings.ContainsAny(frac, "eE+-") {
+		// exponents are not sent by prometheus or loki
+		fv, _ := strconv.ParseFloat(str, 64)
+		return timeFromFloat(fv)
+	}
+
+	var ns int64
+	for i := 0; i < 9; i++ {
+		ns *= 10
+		if i < len(frac) {
+			ns += int64(frac[i] - '0')
+		}
+	}
+	if strings.HasPrefix(sec, "-") {
+		ns = -ns
+	}
+	return time.Unix(ss, ns).UTC()
+}
+
 func timeFromLokiString(str string) time.Time {
 	// normal time values look like: 1645030246277587968
 	// and are less than: math.MaxInt65=9223372036854775807
@@ -765,3 +1460,9 @@ func labelsToRawJson(labels data.Labels) (json.RawMessage, error) {
 
 	return json.RawMessage(bytes), nil
 }
+
+func readLabelsAsRawJson(iter *jsoniter.Iterator) (json.RawMessage, error) {
+	labels := data.Labels{}
+	iter.ReadVal(&labels)
+	return labelsToRawJson(labels)
+}
@@ -0,0 +1,62 @@
+package converter
+
+import (
+	"fmt"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util/converter/generator"
+)
+
+// when profiling these benchmarks, these commands are recommended:
+// - go test -benchmem -run=^$ -bench ^BenchmarkReadMatrix$ -memprofile memprofile.out -cpuprofile cpuprofile.out github.com/grafana/grafana/pkg/util/converter
+// - go tool pprof -http=localhost:6061 memprofile.out
+// compare runs across changes with benchstat
+
+func benchmarkRead(b *testing.B, body []byte, opt Options) {
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		iter := jsoniter.ParseBytes(jsoniter.ConfigDefault, body)
+		rsp := ReadPrometheusStyleResult(iter, opt)
+		require.NoError(b, rsp.Error)
+	}
+}
+
+// the shape of a busy dashboard panel: many series sharing most of their labels,
+// with a sample for every step
+func BenchmarkReadMatrix(b *testing.B) {
+	body := generator.Matrix(generator.Config{Series: 400, Samples: 300, Labels: 4, NaNRate: 0.002})
+	for _, wide := range []bool{false, true} {
+		b.Run(fmt.Sprintf("wide=%v", wide), func(b *testing.B) {
+			benchmarkRead(b, body, Options{MatrixWideSeries: wide})
+		})
+	}
+}
+
+func BenchmarkReadVector(b *testing.B) {
+	body := generator.Vector(generator.Config{Series: 10000, Labels: 8, LabelCardinality: 100})
+	for _, wide := range []bool{false, true} {
+		b.Run(fmt.Sprintf("wide=%v", wide), func(b *testing.B) {
+			benchmarkRead(b, body, Options{VectorWideSeries: wide})
+		})
+	}
+}
+
+func BenchmarkReadHistograms(b *testing.B) {
+	body := generator.Histograms(generator.Config{Series: 20, Samples: 100, Buckets: 40})
+	benchmarkRead(b, body, Options{})
+}
+
+func BenchmarkReadExemplars(b *testing.B) {
+	body := generator.Exemplars(generator.Config{Series: 100, Exemplars: 100, Labels: 4})
+	benchmarkRead(b, body, Options{})
+}
+
+func BenchmarkReadStreams(b *testing.B) {
+	body := generator.Streams(generator.Config{Series: 50, Samples: 1000, Labels: 3})
+	benchmarkRead(b, body, Options{})
+}
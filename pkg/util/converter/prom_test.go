@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/experimental"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
@@ -72,6 +73,25 @@ func runScenario(name string, opts Options) func(t *testing.T) {
 	}
 }
 
+func TestReadSeriesLabelColumns(t *testing.T) {
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":[
+		{"__name__":"up","job":"prometheus","instance":"localhost:9090"},
+		{"__name__":"up","job":"node","instance":"localhost:9100"}
+	]}`), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 1)
+
+	frame := rsp.Frames[0]
+	require.Equal(t, 2, frame.Rows())
+	names := []string{}
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{"__name__", "job", "instance"}, names)
+	require.Equal(t, "node", frame.Fields[1].At(1))
+	require.Equal(t, "localhost:9100", frame.Fields[2].At(1))
+}
+
 func TestTimeConversions(t *testing.T) {
 	// include millisecond precision
 	assert.Equal(t,
@@ -86,4 +106,287 @@ func TestTimeConversions(t *testing.T) {
 	assert.Equal(t,
 		time.Date(2033, time.May, 18, 3, 33, 20, 0, time.UTC),
 		timeFromLokiString("2000000000000000000"))
+
+	// decimal seconds
+	assert.Equal(t,
+		time.Date(2022, time.February, 16, 16, 50, 46, 277587968, time.UTC),
+		timeFromDecimalString("1645030246.277587968"))
+	assert.Equal(t,
+		time.Date(2022, time.February, 16, 16, 50, 46, 500000000, time.UTC),
+		timeFromDecimalString("1645030246.5"))
+	assert.Equal(t,
+		time.Date(2022, time.February, 16, 16, 50, 46, 0, time.UTC),
+		timeFromDecimalString("1645030246"))
+	assert.Equal(t,
+		time.Date(2020, time.September, 14, 15, 22, 25, 479000000, time.UTC),
+		timeFromDecimalString("1.600096945479e9"))
+}
+
+func TestNanosecondTimestamps(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"job":"a"},"values":[[1645030246.277587968,"1"],[1645030246.277587969,"2"]]}
+	]}}`
+	for _, wide := range []bool{false, true} {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{NanosecondTimestamps: true, MatrixWideSeries: wide})
+		require.NoError(t, rsp.Error)
+		require.Equal(t, 2, rsp.Frames[0].Rows())
+		require.Equal(t, time.Date(2022, time.February, 16, 16, 50, 46, 277587968, time.UTC), rsp.Frames[0].Fields[0].At(0))
+		require.Equal(t, time.Date(2022, time.February, 16, 16, 50, 46, 277587969, time.UTC), rsp.Frames[0].Fields[0].At(1))
+	}
+
+	// both samples are in the same millisecond by default
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{MatrixWideSeries: true})
+	require.NoError(t, rsp.Error)
+	require.Equal(t, 1, rsp.Frames[0].Rows())
+}
+
+func TestHistogramBucketBudget(t *testing.T) {
+	read := func(opt Options) *data.Frame {
+		f, err := os.Open(path.Join("testdata", "prom-matrix-histogram-no-labels.json"))
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+		rsp := ReadPrometheusStyleResult(jsoniter.Parse(jsoniter.ConfigDefault, f, 1024), opt)
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		return rsp.Frames[0]
+	}
+
+	sumCounts := func(frame *data.Frame) float64 {
+		total := 0.0
+		for i := 0; i < frame.Rows(); i++ {
+			total += frame.Fields[3].At(i).(float64)
+		}
+		return total
+	}
+
+	full := read(Options{})
+	merged := read(Options{MaxHistogramBuckets: 4})
+
+	perTime := map[time.Time]int{}
+	for i := 0; i < merged.Rows(); i++ {
+		perTime[merged.Fields[0].At(i).(time.Time)]++
+	}
+	for _, n := range perTime {
+		require.LessOrEqual(t, n, 4)
+	}
+	require.Less(t, merged.Rows(), full.Rows())
+	require.InDelta(t, sumCounts(full), sumCounts(merged), 1e-9)
+}
+
+func TestMergedBucketLayout(t *testing.T) {
+	buckets := []histogramBucket{
+		{layout: 3, min: 0, max: 1, count: 1},
+		{layout: 0, min: 1, max: 2, count: 2},
+		{layout: 0, min: 2, max: 4, count: 3},
+	}
+	merged := mergeHistogramBuckets(buckets, 2)
+	require.Equal(t, []histogramBucket{
+		{layout: 3, min: 0, max: 2, count: 3},
+		{layout: 0, min: 2, max: 4, count: 3},
+	}, merged)
+}
+
+func TestSortLabelColumns(t *testing.T) {
+	body := `{"status":"success","data":[
+		{"zone":"a","instance":"localhost:9090","__name__":"up","job":"prometheus"},
+		{"__name__":"up","env":"prod","job":"node","instance":"localhost:9100"}
+	]}`
+
+	names := func(opt Options) []string {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		names := []string{}
+		for _, f := range rsp.Frames[0].Fields {
+			names = append(names, f.Name)
+		}
+		return names
+	}
+
+	require.Equal(t, []string{"zone", "instance", "__name__", "job", "env"}, names(Options{}))
+	require.Equal(t, []string{"__name__", "job", "instance", "env", "zone"}, names(Options{SortLabelColumns: true}))
+}
+
+func TestMixedFloatAndHistogramSeries(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"__name__":"rpc_duration_seconds"},
+		 "values":[[1641889530,"1"],[1641889545,"2"]],
+		 "histograms":[[1641889560,{"count":"3","sum":"0.6","buckets":[[0,"0.1","0.2","1"],[0,"0.2","0.4","2"]]}]]}
+	]}}`
+
+	t.Run("multi", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 2)
+		require.Equal(t, data.FrameTypeTimeSeriesMulti, rsp.Frames[0].Meta.Type)
+		require.Equal(t, 2, rsp.Frames[0].Rows())
+		require.Equal(t, data.FrameType("heatmap-cells"), rsp.Frames[1].Meta.Type)
+		require.Equal(t, 2, rsp.Frames[1].Rows())
+		require.Equal(t, rsp.Frames[0].Fields[1].Labels, rsp.Frames[1].Fields[1].Labels)
+	})
+
+	t.Run("wide", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{MatrixWideSeries: true})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 2)
+		require.Equal(t, data.FrameTypeTimeSeriesWide, rsp.Frames[0].Meta.Type)
+		require.Len(t, rsp.Frames[0].Fields, 2)
+		require.Equal(t, 2, rsp.Frames[0].Rows())
+		require.Equal(t, data.FrameType("heatmap-cells"), rsp.Frames[1].Meta.Type)
+	})
+}
+
+func TestWideHistogramSeries(t *testing.T) {
+	// the metric of the second series comes after its histograms
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"__name__":"up","job":"a"},"values":[[1641889530,"1"],[1641889545,"2"]]},
+		{"histograms":[[1641889530,{"count":"3","sum":"0.6","buckets":[[0,"0.1","0.2","1"],[0,"0.2","0.4","2"]]}]],
+		 "metric":{"__name__":"rpc_duration_seconds","job":"b"}},
+		{"metric":{"__name__":"rpc_duration_seconds","job":"c"},
+		 "histograms":[[1641889545,{"count":"1","sum":"0.1","buckets":[[0,"0.1","0.2","1"]]}]]},
+		{"metric":{"__name__":"up","job":"d"},"values":[[1641889560,"NaN"]],
+		 "histograms":[[1641889560,{"count":"1","sum":"0.1","buckets":[[0,"0.1","0.2","1"]]}]]}
+	]}}`
+	opt := Options{MatrixWideSeries: true, NonFiniteValues: NonFiniteNull, FrameNaming: FrameNameMetric}
+
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 4)
+
+	// only the series with float samples are columns, a null sample is still a sample
+	wide := rsp.Frames[0]
+	require.Equal(t, data.FrameTypeTimeSeriesWide, wide.Meta.Type)
+	require.Len(t, wide.Fields, 3)
+	require.Equal(t, 3, wide.Rows())
+	require.Equal(t, "a", wide.Fields[1].Labels["job"])
+	require.Equal(t, "d", wide.Fields[2].Labels["job"])
+	for _, f := range wide.Fields {
+		require.Equal(t, wide.Rows(), f.Len())
+	}
+
+	// the heatmaps match the ones of multi frames
+	multi := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{NonFiniteValues: NonFiniteNull, FrameNaming: FrameNameMetric})
+	require.NoError(t, multi.Error)
+	heatmaps := data.Frames{}
+	for _, frame := range multi.Frames {
+		if frame.Meta.Type == "heatmap-cells" {
+			heatmaps = append(heatmaps, frame)
+		}
+	}
+	require.Len(t, heatmaps, 3)
+	for i, frame := range rsp.Frames[1:] {
+		require.Equal(t, data.FrameType("heatmap-cells"), frame.Meta.Type)
+		require.Equal(t, heatmaps[i].Name, frame.Name)
+		require.Equal(t, heatmaps[i].Fields[1].Labels, frame.Fields[1].Labels)
+		require.Equal(t, heatmaps[i].Rows(), frame.Rows())
+	}
+	require.Equal(t, "rpc_duration_seconds", rsp.Frames[1].Name)
+	require.Equal(t, "b", rsp.Frames[1].Fields[1].Labels["job"])
+}
+
+func TestNativeHistogramExemplars(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"__name__":"rpc_duration_seconds","job":"api"},
+		 "histograms":[[1641889560,{"count":"3","sum":"0.6","buckets":[[0,"0.1","0.2","1"],[0,"0.2","0.4","2"]]}]],
+		 "exemplars":[{"labels":{"traceID":"abc"},"value":"0.15","timestamp":1641889555.123}]}
+	]}}`
+
+	for _, wide := range []bool{false, true} {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{MatrixWideSeries: wide})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 2)
+		require.Equal(t, data.FrameType("heatmap-cells"), rsp.Frames[0].Meta.Type)
+
+		exemplars := rsp.Frames[1]
+		require.Equal(t, map[string]string{"resultType": "exemplar"}, exemplars.Meta.Custom)
+		require.Equal(t, 1, exemplars.Rows())
+		require.Equal(t, data.Labels{"__name__": "rpc_duration_seconds", "job": "api"}, exemplars.Fields[1].Labels)
+		traceID, _ := exemplars.FieldByName("traceID")
+		require.Equal(t, "abc", traceID.At(0))
+	}
+
+	t.Run("query exemplars", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":[
+			{"exemplars":[{"labels":{"traceID":"abc"},"value":"0.15","timestamp":1641889555.123}],
+			 "seriesLabels":{"__name__":"rpc_duration_seconds","job":"api"}}
+		]}`), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		require.Equal(t, data.Labels{"__name__": "rpc_duration_seconds", "job": "api"}, rsp.Frames[0].Fields[1].Labels)
+	})
+}
+
+func TestWideFieldOrder(t *testing.T) {
+	a := `{"metric":{"job":"a"},"values":[[1641889530,"1"]]}`
+	b := `{"metric":{"job":"b","instance":"x"},"values":[[1641889545,"2"]]}`
+	c := `{"metric":{"job":"b"},"values":[[1641889530,"3"]]}`
+	read := func(series ...string) *data.Frame {
+		body := `{"status":"success","data":{"resultType":"matrix","result":[` + strings.Join(series, ",") + `]}}`
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{MatrixWideSeries: true})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		return rsp.Frames[0]
+	}
+
+	expected := read(a, b, c)
+	require.Equal(t, `instance=x, job=b`, expected.Fields[1].Labels.String())
+	require.Equal(t, `job=a`, expected.Fields[2].Labels.String())
+	require.Equal(t, `job=b`, expected.Fields[3].Labels.String())
+
+	for _, order := range [][]string{{c, b, a}, {b, a, c}} {
+		actual := read(order...)
+		require.Equal(t, expected.Rows(), actual.Rows())
+		for i, f := range actual.Fields {
+			require.Equal(t, expected.Fields[i].Labels, f.Labels)
+			for row := 0; row < f.Len(); row++ {
+				require.Equal(t, expected.Fields[i].At(row), f.At(row))
+			}
+		}
+	}
+}
+
+func TestStructuredWarnings(t *testing.T) {
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{
+		"status":"success",
+		"warnings":[
+			{"type":"info","message":"PromQL info: metric might not be a counter"},
+			{"type":"error","message":"query was slow","code":7},
+			{"message":"no type"},
+			"plain",
+			{"type":"warning","message":"fetch series for {replica=\"a\"} Addr: store-0:10901"},
+			{"type":"info"},
+			42
+		],
+		"data":{"resultType":"vector","result":[{"metric":{"__name__":"up"},"value":[1645029699,"1"]}]}
+	}`), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 1)
+
+	notices := rsp.Frames[0].Meta.Notices
+	require.Len(t, notices, 5)
+	require.Equal(t, data.Notice{Severity: data.NoticeSeverityInfo, Text: "PromQL info: metric might not be a counter"}, notices[0])
+	require.Equal(t, data.Notice{Severity: data.NoticeSeverityError, Text: "query was slow"}, notices[1])
+	require.Equal(t, data.Notice{Severity: data.NoticeSeverityWarning, Text: "no type"}, notices[2])
+	require.Equal(t, data.Notice{Severity: data.NoticeSeverityWarning, Text: "plain"}, notices[3])
+	require.Equal(t, "Partial response: fetch series for {replica=\"a\"} Addr: store-0:10901", notices[4].Text)
+	require.Equal(t, "true", rsp.Frames[0].Meta.Custom.(map[string]string)["partialResponse"])
+}
+
+func TestScalarNameAndLabels(t *testing.T) {
+	opt := Options{ScalarFrameName: "health", ScalarLabels: data.Labels{"check": "api"}}
+	for _, body := range []string{
+		`{"status":"success","data":{"resultType":"scalar","result":[1645029699,"1"]}}`,
+		`{"status":"success","data":{"resultType":"string","result":[1645029699,"ok"]}}`,
+	} {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		require.Equal(t, "health", rsp.Frames[0].Name)
+		require.Equal(t, data.Labels{"check": "api"}, rsp.Frames[0].Fields[1].Labels)
+	}
+
+	// the frames get their own labels
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"scalar","result":[1645029699,"1"]}}`), opt)
+	rsp.Frames[0].Fields[1].Labels["check"] = "db"
+	require.Equal(t, "api", opt.ScalarLabels["check"])
 }
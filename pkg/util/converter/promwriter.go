@@ -0,0 +1,239 @@
+package converter
+
+import (
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// WritePrometheusStyleResult writes the time series of the frames in the JSON of the prometheus
+// query API, the reverse of ReadPrometheusStyleResult. Every value field of multi and wide frames
+// is a series, its labels are the metric. The result is a vector when all the frames were read
+// from a vector, and a matrix otherwise. Null values are left out, and the warning notices of
+// the frames are written as warnings.
+//
+// Frames that are not series, like exemplars, labels, heatmaps or numeric frames, are left out.
+func WritePrometheusStyleResult(w io.Writer, frames data.Frames) error {
+	resultType := "matrix"
+	for _, frame := range frames {
+		if !isSeriesFrame(frame) {
+			continue
+		}
+		if frameResultType(frame) != "vector" {
+			resultType = "matrix"
+			break
+		}
+		resultType = "vector"
+	}
+
+	stream := jsoniter.NewStream(jsoniter.ConfigDefault, w, 4096)
+	stream.WriteObjectStart()
+	stream.WriteObjectField("status")
+	stream.WriteString("success")
+	stream.WriteMore()
+	stream.WriteObjectField("data")
+	stream.WriteObjectStart()
+	stream.WriteObjectField("resultType")
+	stream.WriteString(resultType)
+	stream.WriteMore()
+	stream.WriteObjectField("result")
+	stream.WriteArrayStart()
+
+	first := true
+	for _, frame := range frames {
+		if !isSeriesFrame(frame) {
+			continue
+		}
+		timeField := frame.Fields[timeFieldIndex(frame)]
+		for _, f := range frame.Fields {
+			if f == timeField || !f.Type().Numeric() {
+				continue
+			}
+			// a series of a vector always has a value
+			if resultType == "vector" && lastSample(timeField, f) < 0 {
+				continue
+			}
+			if !first {
+				stream.WriteMore()
+			}
+			first = false
+			writeSeries(stream, timeField, f, resultType)
+		}
+		if stream.Error != nil {
+			return stream.Error
+		}
+	}
+
+	stream.WriteArrayEnd()
+	stream.WriteObjectEnd()
+
+	if warnings := frameWarnings(frames); len(warnings) > 0 {
+		stream.WriteMore()
+		stream.WriteObjectField("warnings")
+		stream.WriteArrayStart()
+		for i, text := range warnings {
+			if i > 0 {
+				stream.WriteMore()
+			}
+			stream.WriteString(text)
+		}
+		stream.WriteArrayEnd()
+	}
+	stream.WriteObjectEnd()
+	return stream.Flush()
+}
+
+// isSeriesFrame reports if the frame holds series, exemplars and the samples
+// per step of the query stats have a time field too
+func isSeriesFrame(frame *data.Frame) bool {
+	switch frameResultType(frame) {
+	case "exemplar", "samplesPerStep":
+		return false
+	}
+	if timeFieldIndex(frame) < 0 {
+		return false
+	}
+	if frame.Meta == nil || frame.Meta.Type == "" {
+		return true
+	}
+	return frame.Meta.Type == data.FrameTypeTimeSeriesMulti || frame.Meta.Type == data.FrameTypeTimeSeriesWide
+}
+
+func frameResultType(frame *data.Frame) string {
+	if frame.Meta != nil {
+		if custom, ok := frame.Meta.Custom.(map[string]string); ok {
+			return custom["resultType"]
+		}
+	}
+	return ""
+}
+
+func timeFieldIndex(frame *data.Frame) int {
+	for i, f := range frame.Fields {
+		if f.Type() == data.FieldTypeTime || f.Type() == data.FieldTypeNullableTime {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeSeries writes { "metric": {...}, "values": [[t, "v"], ...] }, or the last
+// sample as "value" for a vector
+func writeSeries(stream *jsoniter.Stream, timeField, valueField *data.Field, resultType string) {
+	stream.WriteObjectStart()
+	stream.WriteObjectField("metric")
+	writeMetric(stream, valueField.Labels)
+	stream.WriteMore()
+
+	if resultType == "vector" {
+		stream.WriteObjectField("value")
+		t, v, _ := samplePair(timeField, valueField, lastSample(timeField, valueField))
+		writeSamplePair(stream, t, v)
+		stream.WriteObjectEnd()
+		return
+	}
+
+	stream.WriteObjectField("values")
+	stream.WriteArrayStart()
+	first := true
+	for i := 0; i < valueField.Len(); i++ {
+		t, v, ok := samplePair(timeField, valueField, i)
+		if !ok {
+			continue
+		}
+		if !first {
+			stream.WriteMore()
+		}
+		first = false
+		writeSamplePair(stream, t, v)
+	}
+	stream.WriteArrayEnd()
+	stream.WriteObjectEnd()
+}
+
+func writeMetric(stream *jsoniter.Stream, labels data.Labels) {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	stream.WriteObjectStart()
+	for i, k := range keys {
+		if i > 0 {
+			stream.WriteMore()
+		}
+		stream.WriteObjectField(k)
+		stream.WriteString(labels[k])
+	}
+	stream.WriteObjectEnd()
+}
+
+// lastSample returns the index of the last sample that is not null, or -1
+func lastSample(timeField, valueField *data.Field) int {
+	for i := valueField.Len() - 1; i >= 0; i-- {
+		if _, _, ok := samplePair(timeField, valueField, i); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+func samplePair(timeField, valueField *data.Field, i int) (time.Time, float64, bool) {
+	v, err := valueField.NullableFloatAt(i)
+	if err != nil || v == nil {
+		return time.Time{}, 0, false
+	}
+	switch t := timeField.At(i).(type) {
+	case time.Time:
+		return t, *v, true
+	case *time.Time:
+		if t != nil {
+			return *t, *v, true
+		}
+	}
+	return time.Time{}, 0, false
+}
+
+// writeSamplePair writes [ 1641889530.123, "1.5" ] like prometheus does
+func writeSamplePair(stream *jsoniter.Stream, t time.Time, v float64) {
+	stream.WriteArrayStart()
+	stream.WriteRaw(strconv.FormatFloat(float64(t.UnixMilli())/1000, 'f', -1, 64))
+	stream.WriteMore()
+	stream.WriteString(formatSampleValue(v))
+	stream.WriteArrayEnd()
+}
+
+func formatSampleValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// frameWarnings returns the texts of the warning notices, once
+func frameWarnings(frames data.Frames) []string {
+	var warnings []string
+	seen := map[string]bool{}
+	for _, frame := range frames {
+		if frame.Meta == nil {
+			continue
+		}
+		for _, n := range frame.Meta.Notices {
+			if n.Severity != data.NoticeSeverityWarning || seen[n.Text] {
+				continue
+			}
+			seen[n.Text] = true
+			warnings = append(warnings, n.Text)
+		}
+	}
+	return warnings
+}
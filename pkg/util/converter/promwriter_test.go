@@ -0,0 +1,60 @@
+package converter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePrometheusStyleResult(t *testing.T) {
+	roundTrip := func(t *testing.T, body string, opt Options) (string, backend.DataResponse) {
+		t.Helper()
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		require.NoError(t, rsp.Error)
+		buf := &bytes.Buffer{}
+		require.NoError(t, WritePrometheusStyleResult(buf, rsp.Frames))
+		return buf.String(), rsp
+	}
+
+	t.Run("matrix", func(t *testing.T) {
+		body := `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"__name__":"up","job":"a"},"values":[[1641889530.123,"1"],[1641889545,"NaN"]]},
+			{"metric":{"__name__":"up","job":"b"},"values":[[1641889530.123,"+Inf"]]}
+		]}}`
+		out, _ := roundTrip(t, body, Options{})
+		require.JSONEq(t, body, out)
+
+		wide, _ := roundTrip(t, body, Options{MatrixWideSeries: true})
+		// the wide frame has a null for b at the second timestamp
+		require.JSONEq(t, body, wide)
+	})
+
+	t.Run("vector", func(t *testing.T) {
+		body := `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"job":"a"},"value":[1641889530,"0.5"]},
+			{"metric":{"job":"b"},"value":[1641889530,"-Inf"]}
+		]}}`
+		out, _ := roundTrip(t, body, Options{})
+		require.JSONEq(t, body, out)
+	})
+
+	t.Run("warnings and exemplars", func(t *testing.T) {
+		out, rsp := roundTrip(t, `{"status":"success","warnings":["some store failed"],"data":{"resultType":"matrix","result":[
+			{"metric":{"job":"a"},"values":[[1641889530,"1"]],
+			 "exemplars":[{"labels":{"traceID":"abc"},"value":"0.15","timestamp":1641889530}]}
+		]}}`, Options{})
+		require.Len(t, rsp.Frames, 2)
+		require.JSONEq(t, `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"job":"a"},"values":[[1641889530,"1"]]}
+		]},"warnings":["some store failed"]}`, out)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, WritePrometheusStyleResult(buf, nil))
+		require.JSONEq(t, `{"status":"success","data":{"resultType":"matrix","result":[]}}`, buf.String())
+	})
+}
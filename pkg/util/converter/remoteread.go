@@ -0,0 +1,221 @@
+package converter
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+)
+
+// the default limit of the prometheus remote read client
+const maxChunkedMessageSize = 50 * 1024 * 1024
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+var errChunkedChecksum = errors.New("corrupted remote read message, checksum mismatch")
+
+// ReadRemoteReadChunks reads a STREAMED_XOR_CHUNKS remote read response, the body sent with
+// the application/x-streamed-protobuf; proto=prometheus.ChunkedReadResponse content type.
+// The messages are decoded one at a time, only the frames are held in memory.
+func ReadRemoteReadChunks(r io.Reader, opt Options) backend.DataResponse {
+	if opt.MaxRows > 0 && opt.rowLimit == nil {
+		opt.rowLimit = &rowLimit{max: opt.MaxRows}
+	}
+
+	rsp := backend.DataResponse{}
+	rsp.Error = readRemoteReadChunks(LimitBody(opt.MemoryStats.Reader(r), opt.MaxBodyBytes), opt, func(frame *data.Frame) error {
+		rsp.Frames = append(rsp.Frames, frame)
+		return nil
+	})
+
+	if len(opt.MetricMetadata) > 0 {
+		attachMetricMetadata(rsp.Frames, opt.MetricMetadata)
+	}
+	if opt.rowLimit != nil && opt.rowLimit.truncated {
+		addTruncatedNotice(rsp.Frames, opt.rowLimit.max)
+	}
+	finishFrames(rsp.Frames, opt)
+	return rsp
+}
+
+// StreamRemoteReadChunks converts a STREAMED_XOR_CHUNKS remote read response like
+// ReadRemoteReadChunks, but writes the frame of every series to w as soon as all its
+// chunks are read. The conversion is aborted as soon as the context is done or a write fails.
+func StreamRemoteReadChunks(ctx context.Context, r io.Reader, opt Options, w FrameWriter) error {
+	if opt.MaxRows > 0 && opt.rowLimit == nil {
+		opt.rowLimit = &rowLimit{max: opt.MaxRows}
+	}
+
+	return readRemoteReadChunks(LimitBody(opt.MemoryStats.Reader(r), opt.MaxBodyBytes), opt, func(frame *data.Frame) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		frames := data.Frames{frame}
+		if len(opt.MetricMetadata) > 0 {
+			attachMetricMetadata(frames, opt.MetricMetadata)
+		}
+		finishFrames(frames, opt)
+		return w.WriteFrame(frame)
+	})
+}
+
+// readRemoteReadChunks calls emit with the frame of every series. The chunks of a series
+// may be split across consecutive messages, so a frame is only complete once another
+// series starts.
+func readRemoteReadChunks(r io.Reader, opt Options, emit func(frame *data.Frame) error) error {
+	br := bufio.NewReader(r)
+	var (
+		buf      []byte
+		frame    *data.Frame
+		key      string
+		lastTime int64
+		seen     bool
+	)
+
+	for {
+		msg, err := readChunkedMessage(br, buf)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		buf = msg
+
+		res := &prompb.ChunkedReadResponse{}
+		if err := res.Unmarshal(msg); err != nil {
+			return err
+		}
+
+		for _, series := range res.ChunkedSeries {
+			if k := chunkedSeriesKey(res.QueryIndex, series.Labels); frame == nil || k != key {
+				if frame != nil {
+					if err := emit(frame); err != nil {
+						return err
+					}
+				}
+				frame = newRemoteReadFrame(series.Labels, opt)
+				key = k
+				seen = false
+			}
+
+			for _, chk := range series.Chunks {
+				if chk.Type != prompb.Chunk_XOR {
+					return fmt.Errorf("unsupported remote read chunk encoding: %s", chk.Type)
+				}
+				c, err := chunkenc.FromData(chunkenc.EncXOR, chk.Data)
+				if err != nil {
+					return err
+				}
+				it := c.Iterator(nil)
+				for it.Next() {
+					t, v := it.At()
+					// chunks are in time order, but may overlap
+					if seen && t <= lastTime {
+						continue
+					}
+					lastTime, seen = t, true
+					appendRemoteReadSample(frame, t, v, opt)
+				}
+				if err := it.Err(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if frame != nil {
+		return emit(frame)
+	}
+	return nil
+}
+
+// readChunkedMessage reads a message of the stream, framed as a uvarint size, a big endian
+// CRC-32 (Castagnoli) checksum and the protobuf bytes. buf is reused when large enough.
+func readChunkedMessage(r *bufio.Reader, buf []byte) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxChunkedMessageSize {
+		return nil, fmt.Errorf("remote read message of %d bytes exceeds the limit of %d bytes", size, maxChunkedMessageSize)
+	}
+
+	var checksum uint32
+	if err := binary.Read(r, binary.BigEndian, &checksum); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	if uint64(cap(buf)) < size {
+		buf = make([]byte, size)
+	}
+	buf = buf[:size]
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if crc32.Checksum(buf, castagnoliTable) != checksum {
+		return nil, errChunkedChecksum
+	}
+	return buf, nil
+}
+
+// a message cut after its size is not the end of the stream
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func chunkedSeriesKey(queryIndex int64, labels []prompb.Label) string {
+	sb := strings.Builder{}
+	sb.WriteString(strconv.FormatInt(queryIndex, 10))
+	for _, l := range labels {
+		sb.WriteByte('\xff')
+		sb.WriteString(l.Name)
+		sb.WriteByte('\xff')
+		sb.WriteString(l.Value)
+	}
+	return sb.String()
+}
+
+// newRemoteReadFrame returns the multi frame of a remote read series. Remote read samples
+// are not aligned to a step, so they are never joined in a wide frame.
+func newRemoteReadFrame(labels []prompb.Label, opt Options) *data.Frame {
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
+	timeField.Name = data.TimeSeriesTimeFieldName
+	valueField := data.NewFieldFromFieldType(multiValueFieldType(opt), 0)
+	valueField.Name = data.TimeSeriesValueFieldName
+	valueField.Labels = make(data.Labels, len(labels))
+	for _, l := range labels {
+		valueField.Labels[l.Name] = l.Value
+	}
+
+	setSeriesDisplayName(opt, valueField)
+	frame := data.NewFrame(frameName(opt, valueField.Labels), timeField, valueField)
+	frame.Meta = &data.FrameMeta{
+		Type:   data.FrameTypeTimeSeriesMulti,
+		Custom: resultTypeToCustomMeta("matrix"),
+	}
+	return frame
+}
+
+func appendRemoteReadSample(frame *data.Frame, ms int64, value float64, opt Options) {
+	v, ok := nonFiniteValues(opt, value).convert(value)
+	if !ok || !opt.rowLimit.take() {
+		return
+	}
+	frame.Fields[0].Append(time.UnixMilli(ms).UTC())
+	appendFloat(frame.Fields[1], v)
+}
@@ -0,0 +1,103 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/stretchr/testify/require"
+)
+
+func xorChunk(t *testing.T, samples ...[2]int64) prompb.Chunk {
+	t.Helper()
+	c := chunkenc.NewXORChunk()
+	app, err := c.Appender()
+	require.NoError(t, err)
+	for _, s := range samples {
+		app.Append(s[0], float64(s[1]))
+	}
+	return prompb.Chunk{
+		MinTimeMs: samples[0][0],
+		MaxTimeMs: samples[len(samples)-1][0],
+		Type:      prompb.Chunk_XOR,
+		Data:      c.Bytes(),
+	}
+}
+
+func writeChunkedMessages(t *testing.T, messages ...*prompb.ChunkedReadResponse) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	for _, msg := range messages {
+		b, err := msg.Marshal()
+		require.NoError(t, err)
+		buf.Write(binary.AppendUvarint(nil, uint64(len(b))))
+		require.NoError(t, binary.Write(buf, binary.BigEndian, crc32.Checksum(b, castagnoliTable)))
+		buf.Write(b)
+	}
+	return buf.Bytes()
+}
+
+func TestReadRemoteReadChunks(t *testing.T) {
+	a := []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "a"}}
+	b := []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "b"}}
+	body := writeChunkedMessages(t,
+		&prompb.ChunkedReadResponse{ChunkedSeries: []*prompb.ChunkedSeries{
+			{Labels: a, Chunks: []prompb.Chunk{xorChunk(t, [2]int64{1000, 1}, [2]int64{2000, 2})}},
+		}},
+		// the same series continues in the next message, with an overlapping chunk
+		&prompb.ChunkedReadResponse{ChunkedSeries: []*prompb.ChunkedSeries{
+			{Labels: a, Chunks: []prompb.Chunk{xorChunk(t, [2]int64{2000, 2}, [2]int64{3000, 3})}},
+			{Labels: b, Chunks: []prompb.Chunk{xorChunk(t, [2]int64{1000, 10})}},
+		}},
+	)
+
+	t.Run("read", func(t *testing.T) {
+		rsp := ReadRemoteReadChunks(bytes.NewReader(body), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 2)
+		require.Equal(t, 3, rsp.Frames[0].Rows())
+		require.Equal(t, data.Labels{"__name__": "up", "job": "a"}, rsp.Frames[0].Fields[1].Labels)
+		require.Equal(t, time.UnixMilli(3000).UTC(), rsp.Frames[0].Fields[0].At(2))
+		require.Equal(t, 3.0, rsp.Frames[0].Fields[1].At(2))
+		require.Equal(t, 1, rsp.Frames[1].Rows())
+		require.Equal(t, data.FrameTypeTimeSeriesMulti, rsp.Frames[1].Meta.Type)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		var frames []*data.Frame
+		err := StreamRemoteReadChunks(context.Background(), bytes.NewReader(body), Options{}, FrameWriterFunc(func(frame *data.Frame) error {
+			frames = append(frames, frame)
+			return nil
+		}))
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+		require.Equal(t, 3, frames[0].Rows())
+	})
+
+	t.Run("corrupted message", func(t *testing.T) {
+		corrupted := append([]byte{}, body...)
+		corrupted[len(corrupted)-1] ^= 0xff
+		rsp := ReadRemoteReadChunks(bytes.NewReader(corrupted), Options{})
+		require.ErrorIs(t, rsp.Error, errChunkedChecksum)
+		// the series of the first message continues in the corrupted one
+		require.Empty(t, rsp.Frames)
+	})
+
+	t.Run("truncated message", func(t *testing.T) {
+		rsp := ReadRemoteReadChunks(bytes.NewReader(body[:len(body)-3]), Options{})
+		require.ErrorIs(t, rsp.Error, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("body limit", func(t *testing.T) {
+		rsp := ReadRemoteReadChunks(bytes.NewReader(body), Options{MaxBodyBytes: int64(len(body) - 1)})
+		var limitErr *BodyLimitError
+		require.ErrorAs(t, rsp.Error, &limitErr)
+	})
+}
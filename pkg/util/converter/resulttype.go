@@ -0,0 +1,54 @@
+package converter
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// ResultTypeReader reads the "result" of a response with a custom resultType. The
+// iterator is positioned at the value of "result", which must be read completely.
+type ResultTypeReader func(iter *jsoniter.Iterator, opt Options) backend.DataResponse
+
+var builtinResultTypes = map[string]bool{
+	"matrix":  true,
+	"vector":  true,
+	"streams": true,
+	"string":  true,
+	"scalar":  true,
+}
+
+var resultTypes = struct {
+	sync.RWMutex
+	readers map[string]ResultTypeReader
+}{
+	readers: map[string]ResultTypeReader{},
+}
+
+// RegisterResultType adds a reader for a resultType the converter does not know, like
+// the ones added by prometheus compatible servers. It is meant to be called from init,
+// and panics if the result type is built in or already registered.
+func RegisterResultType(resultType string, fn ResultTypeReader) {
+	if fn == nil {
+		panic("converter: RegisterResultType reader is nil")
+	}
+	if builtinResultTypes[resultType] {
+		panic(fmt.Sprintf("converter: result type %q is built in", resultType))
+	}
+
+	resultTypes.Lock()
+	defer resultTypes.Unlock()
+	if _, ok := resultTypes.readers[resultType]; ok {
+		panic(fmt.Sprintf("converter: result type %q is already registered", resultType))
+	}
+	resultTypes.readers[resultType] = fn
+}
+
+func getResultTypeReader(resultType string) (ResultTypeReader, bool) {
+	resultTypes.RLock()
+	defer resultTypes.RUnlock()
+	fn, ok := resultTypes.readers[resultType]
+	return fn, ok
+}
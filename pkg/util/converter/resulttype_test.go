@@ -0,0 +1,47 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterResultType(t *testing.T) {
+	RegisterResultType("test_counts", func(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
+		field := data.NewFieldFromFieldType(data.FieldTypeInt64, 0)
+		field.Name = "count"
+		for iter.ReadArray() {
+			field.Append(iter.ReadInt64())
+		}
+		return backend.DataResponse{Frames: data.Frames{data.NewFrame("", field)}}
+	})
+	t.Cleanup(func() {
+		resultTypes.Lock()
+		delete(resultTypes.readers, "test_counts")
+		resultTypes.Unlock()
+	})
+
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault,
+		`{"status":"success","data":{"resultType":"test_counts","result":[1,2,3]},"warnings":["slow"]}`), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 1)
+	require.Equal(t, 3, rsp.Frames[0].Rows())
+	require.Equal(t, int64(3), rsp.Frames[0].Fields[0].At(2))
+	require.Len(t, rsp.Frames[0].Meta.Notices, 1)
+
+	t.Run("unknown result type", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault,
+			`{"status":"success","data":{"resultType":"other","result":[1]}}`), Options{})
+		require.EqualError(t, rsp.Error, "unknown result type: other")
+	})
+
+	t.Run("duplicate and built in result types", func(t *testing.T) {
+		fn := func(iter *jsoniter.Iterator, opt Options) backend.DataResponse { return backend.DataResponse{} }
+		require.Panics(t, func() { RegisterResultType("test_counts", fn) })
+		require.Panics(t, func() { RegisterResultType("matrix", fn) })
+		require.Panics(t, func() { RegisterResultType("test_nil", nil) })
+	})
+}
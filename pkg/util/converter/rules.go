@@ -0,0 +1,273 @@
+package converter
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+type rulesFrameBuilder struct {
+	group          *data.Field
+	file           *data.Field
+	name           *data.Field
+	ruleType       *data.Field
+	query          *data.Field
+	state          *data.Field
+	health         *data.Field
+	lastError      *data.Field
+	lastEvaluation *data.Field
+	evaluationTime *data.Field
+	duration       *data.Field
+	labels         *data.Field
+	annotations    *data.Field
+}
+
+func newRulesFrameBuilder() *rulesFrameBuilder {
+	b := &rulesFrameBuilder{
+		group:          data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		file:           data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		name:           data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		ruleType:       data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		query:          data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		state:          data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		health:         data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		lastError:      data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		lastEvaluation: data.NewFieldFromFieldType(data.FieldTypeNullableTime, 0),
+		evaluationTime: data.NewFieldFromFieldType(data.FieldTypeFloat64, 0),
+		duration:       data.NewFieldFromFieldType(data.FieldTypeFloat64, 0),
+		labels:         data.NewFieldFromFieldType(data.FieldTypeJSON, 0),
+		annotations:    data.NewFieldFromFieldType(data.FieldTypeJSON, 0),
+	}
+	b.group.Name = "group"
+	b.file.Name = "file"
+	b.name.Name = "name"
+	b.ruleType.Name = "type"
+	b.query.Name = "query"
+	b.state.Name = "state"
+	b.health.Name = "health"
+	b.lastError.Name = "lastError"
+	b.lastEvaluation.Name = "lastEvaluation"
+	b.evaluationTime.Name = "evaluationTime"
+	b.evaluationTime.Config = &data.FieldConfig{Unit: "s"}
+	b.duration.Name = "duration"
+	b.duration.Config = &data.FieldConfig{Unit: "s"}
+	b.labels.Name = "labels"
+	b.annotations.Name = "annotations"
+	return b
+}
+
+func (b *rulesFrameBuilder) frame() *data.Frame {
+	frame := data.NewFrame("", b.group, b.file, b.name, b.ruleType, b.query, b.state, b.health,
+		b.lastError, b.lastEvaluation, b.evaluationTime, b.duration, b.labels, b.annotations)
+	frame.Meta = &data.FrameMeta{
+		Custom: resultTypeToCustomMeta("rules"),
+	}
+	return frame
+}
+
+// readRules reads the groups from /api/v1/rules
+// { "groups": [ { name, file, interval, rules: [...] } ] }
+func readRules(iter *jsoniter.Iterator) backend.DataResponse {
+	b := newRulesFrameBuilder()
+	var err error
+
+	for iter.ReadArray() {
+		group := ""
+		file := ""
+		for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+			switch l1Field {
+			case "name":
+				group = iter.ReadString()
+			case "file":
+				file = iter.ReadString()
+			case "rules":
+				for iter.ReadArray() {
+					if rerr := b.readRule(iter, group, file); rerr != nil && err == nil {
+						err = rerr
+					}
+				}
+			default:
+				iter.Skip()
+				skippedKey(iter, "rules", l1Field)
+			}
+		}
+	}
+
+	return backend.DataResponse{
+		Frames: data.Frames{b.frame()},
+		Error:  err,
+	}
+}
+
+func (b *rulesFrameBuilder) readRule(iter *jsoniter.Iterator, group string, file string) error {
+	var (
+		name, ruleType, query, state, health, lastError string
+		lastEvaluation                                  *time.Time
+		evaluationTime, duration                        float64
+		err                                             error
+	)
+	labels := json.RawMessage("{}")
+	annotations := labels
+
+	// keep reading on errors so the fields stay aligned
+	setErr := func(e error) {
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "name":
+			name = iter.ReadString()
+		case "type":
+			ruleType = iter.ReadString()
+		case "query":
+			query = iter.ReadString()
+		case "state":
+			state = iter.ReadString()
+		case "health":
+			health = iter.ReadString()
+		case "lastError":
+			lastError = iter.ReadString()
+		case "lastEvaluation":
+			t, terr := readRFC3339Time(iter)
+			lastEvaluation = t
+			setErr(terr)
+		case "evaluationTime":
+			evaluationTime = iter.ReadFloat64()
+		case "duration":
+			duration = iter.ReadFloat64()
+		case "labels":
+			v, lerr := readLabelsAsRawJson(iter)
+			labels = v
+			setErr(lerr)
+		case "annotations":
+			v, aerr := readLabelsAsRawJson(iter)
+			annotations = v
+			setErr(aerr)
+		default:
+			// "alerts" are only included for alerting rules, see /api/v1/alerts for a flat list
+			iter.Skip()
+			skippedKey(iter, "rule", l1Field)
+		}
+	}
+
+	b.group.Append(group)
+	b.file.Append(file)
+	b.name.Append(name)
+	b.ruleType.Append(ruleType)
+	b.query.Append(query)
+	b.state.Append(state)
+	b.health.Append(health)
+	b.lastError.Append(lastError)
+	b.lastEvaluation.Append(lastEvaluation)
+	b.evaluationTime.Append(evaluationTime)
+	b.duration.Append(duration)
+	b.labels.Append(labels)
+	b.annotations.Append(annotations)
+	return err
+}
+
+// Prometheus reports "0001-01-01T00:00:00Z" for things that have not happened yet
+func readRFC3339Time(iter *jsoniter.Iterator) (*time.Time, error) {
+	if iter.WhatIsNext() == jsoniter.NilValue {
+		iter.Skip()
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, iter.ReadString())
+	if err != nil {
+		return nil, err
+	}
+	if t.IsZero() {
+		return nil, nil
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// readAlerts reads the active alerts from /api/v1/alerts
+// { "alerts": [ { labels, annotations, state, activeAt, value } ] }
+// The columns match the ones used for rules so both can be shown together
+func readAlerts(iter *jsoniter.Iterator) backend.DataResponse {
+	name := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	name.Name = "name"
+	state := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	state.Name = "state"
+	activeAt := data.NewFieldFromFieldType(data.FieldTypeNullableTime, 0)
+	activeAt.Name = "activeAt"
+	value := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, 0)
+	value.Name = data.TimeSeriesValueFieldName
+	labelsField := data.NewFieldFromFieldType(data.FieldTypeJSON, 0)
+	labelsField.Name = "labels"
+	annotationsField := data.NewFieldFromFieldType(data.FieldTypeJSON, 0)
+	annotationsField.Name = "annotations"
+
+	var err error
+	setErr := func(e error) {
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+
+	for iter.ReadArray() {
+		var (
+			alertName, alertState string
+			alertActiveAt         *time.Time
+			alertValue            *float64
+		)
+		labels := json.RawMessage("{}")
+		annotations := labels
+
+		for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+			switch l1Field {
+			case "labels":
+				l := data.Labels{}
+				iter.ReadVal(&l)
+				alertName = l["alertname"]
+				v, lerr := labelsToRawJson(l)
+				labels = v
+				setErr(lerr)
+			case "annotations":
+				v, aerr := readLabelsAsRawJson(iter)
+				annotations = v
+				setErr(aerr)
+			case "state":
+				alertState = iter.ReadString()
+			case "activeAt":
+				t, terr := readRFC3339Time(iter)
+				alertActiveAt = t
+				setErr(terr)
+			case "value":
+				// the value is a string like "1e+00"
+				v, verr := strconv.ParseFloat(iter.ReadString(), 64)
+				if verr == nil {
+					alertValue = &v
+				}
+			default:
+				iter.Skip()
+				skippedKey(iter, "alerts", l1Field)
+			}
+		}
+
+		name.Append(alertName)
+		state.Append(alertState)
+		activeAt.Append(alertActiveAt)
+		value.Append(alertValue)
+		labelsField.Append(labels)
+		annotationsField.Append(annotations)
+	}
+
+	frame := data.NewFrame("", name, state, activeAt, value, labelsField, annotationsField)
+	frame.Meta = &data.FrameMeta{
+		Custom: resultTypeToCustomMeta("alerts"),
+	}
+	return backend.DataResponse{
+		Frames: data.Frames{frame},
+		Error:  err,
+	}
+}
@@ -0,0 +1,71 @@
+package converter
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRules(t *testing.T) {
+	f, err := os.Open(path.Join("testdata", "prom-rules.json"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	rsp := ReadPrometheusStyleResult(jsoniter.Parse(jsoniter.ConfigDefault, f, 1024), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 1)
+
+	frame := rsp.Frames[0]
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, "rules", frame.Meta.Custom.(map[string]string)["resultType"])
+
+	row := func(name string, i int) interface{} {
+		f, _ := frame.FieldByName(name)
+		require.NotNil(t, f, name)
+		return f.At(i)
+	}
+
+	require.Equal(t, "example", row("group", 0))
+	require.Equal(t, "recording", row("type", 0))
+	require.Equal(t, time.Date(2023, time.January, 20, 14, 15, 39, 211371432, time.UTC), *row("lastEvaluation", 0).(*time.Time))
+
+	require.Equal(t, "HighRequestLatency", row("name", 1))
+	require.Equal(t, "firing", row("state", 1))
+	require.Equal(t, "err", row("health", 1))
+	require.Equal(t, "query timed out", row("lastError", 1))
+	require.Equal(t, 600.0, row("duration", 1))
+	require.Nil(t, row("lastEvaluation", 1))
+	require.Equal(t, json.RawMessage(`{"severity":"page"}`), row("labels", 1))
+}
+
+func TestReadAlerts(t *testing.T) {
+	f, err := os.Open(path.Join("testdata", "prom-alerts.json"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	rsp := ReadPrometheusStyleResult(jsoniter.Parse(jsoniter.ConfigDefault, f, 1024), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 1)
+
+	frame := rsp.Frames[0]
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, "alerts", frame.Meta.Custom.(map[string]string)["resultType"])
+
+	row := func(name string, i int) interface{} {
+		f, _ := frame.FieldByName(name)
+		require.NotNil(t, f, name)
+		return f.At(i)
+	}
+
+	require.Equal(t, "HighRequestLatency", row("name", 0))
+	require.Equal(t, "firing", row("state", 0))
+	require.Equal(t, time.Date(2023, time.January, 20, 14, 5, 39, 204000000, time.UTC), *row("activeAt", 0).(*time.Time))
+	require.Equal(t, 1.0, *row("Value", 0).(*float64))
+	require.Equal(t, json.RawMessage(`{"alertname":"InstanceDown","instance":"localhost:9100"}`), row("labels", 1))
+	require.Equal(t, json.RawMessage(`{}`), row("annotations", 1))
+}
@@ -0,0 +1,95 @@
+package converter
+
+import (
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// seriesExemplar is an exemplar in the "exemplars" custom config of the value field of its series
+type seriesExemplar struct {
+	// milliseconds, like the times of the frames in JSON
+	Time   int64             `json:"time"`
+	Value  *float64          `json:"value"`
+	Labels map[string]string `json:"labels,omitempty"`
+	// the trace links of the exemplar labels, for its trace ID
+	Links []data.DataLink `json:"links,omitempty"`
+}
+
+// attachSeriesExemplars moves the exemplar frames of the series in the frames to the value
+// fields of the series. Exemplar frames without their series are returned as they are.
+func attachSeriesExemplars(frames data.Frames) data.Frames {
+	fields := map[string]*data.Field{}
+	for _, frame := range frames {
+		if !isSeriesFrame(frame) {
+			continue
+		}
+		timeIdx := timeFieldIndex(frame)
+		for i, f := range frame.Fields {
+			if i != timeIdx && f.Type().Numeric() {
+				fields[f.Labels.String()] = f
+			}
+		}
+	}
+
+	kept := make(data.Frames, 0, len(frames))
+	for _, frame := range frames {
+		if frameResultType(frame) != "exemplar" || len(frame.Fields) < 2 {
+			kept = append(kept, frame)
+			continue
+		}
+		field, ok := fields[frame.Fields[1].Labels.String()]
+		if !ok {
+			kept = append(kept, frame)
+			continue
+		}
+
+		if field.Config == nil {
+			field.Config = &data.FieldConfig{}
+		}
+		if field.Config.Custom == nil {
+			field.Config.Custom = map[string]interface{}{}
+		}
+		exemplars, _ := field.Config.Custom["exemplars"].([]seriesExemplar)
+		for i := 0; i < frame.Rows(); i++ {
+			exemplars = append(exemplars, newSeriesExemplar(frame, i))
+		}
+		field.Config.Custom["exemplars"] = exemplars
+	}
+	return kept
+}
+
+func newSeriesExemplar(frame *data.Frame, row int) seriesExemplar {
+	e := seriesExemplar{}
+	if t, ok := frame.Fields[0].At(row).(time.Time); ok {
+		e.Time = t.UnixMilli()
+	}
+	// the config is written as JSON, which has no NaN or Inf
+	if v, err := frame.Fields[1].NullableFloatAt(row); err == nil && v != nil && !math.IsNaN(*v) && !math.IsInf(*v, 0) {
+		e.Value = v
+	}
+
+	for _, f := range frame.Fields[2:] {
+		v, ok := f.At(row).(string)
+		// the labels missing in an exemplar are empty
+		if !ok || v == "" {
+			continue
+		}
+		if e.Labels == nil {
+			e.Labels = map[string]string{}
+		}
+		e.Labels[f.Name] = v
+
+		if f.Config == nil {
+			continue
+		}
+		for _, link := range f.Config.Links {
+			link.URL = strings.ReplaceAll(link.URL, traceIDPlaceholder, url.QueryEscape(v))
+			e.Links = append(e.Links, link)
+		}
+	}
+	return e
+}
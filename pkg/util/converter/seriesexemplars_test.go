@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExemplarsOnSeries(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"__name__":"rpc_duration_seconds","job":"api"},
+		 "values":[[1641889530,"1"],[1641889545,"2"]],
+		 "exemplars":[
+			{"labels":{"traceID":"abc"},"value":"0.15","timestamp":1641889531.123},
+			{"labels":{"span":"x"},"value":"NaN","timestamp":1641889546}
+		 ]},
+		{"metric":{"__name__":"rpc_duration_seconds","job":"web"},"values":[[1641889530,"3"]]}
+	]}}`
+	opt := Options{
+		ExemplarsOnSeries:  true,
+		ExemplarTraceLinks: []ExemplarTraceLink{{Name: "traceID", URL: "https://tracing/trace/${__value.raw}", URLDisplayLabel: "Trace"}},
+	}
+
+	for _, wide := range []bool{false, true} {
+		opt.MatrixWideSeries = wide
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		require.NoError(t, rsp.Error)
+
+		var field *data.Field
+		for _, frame := range rsp.Frames {
+			require.NotEqual(t, "exemplar", frameResultType(frame))
+			for _, f := range frame.Fields {
+				if f.Labels["job"] == "api" {
+					field = f
+				}
+			}
+		}
+		require.NotNil(t, field)
+
+		v := 0.15
+		require.Equal(t, []seriesExemplar{
+			{
+				Time:   1641889531123,
+				Value:  &v,
+				Labels: map[string]string{"traceID": "abc"},
+				Links:  []data.DataLink{{Title: "Trace", URL: "https://tracing/trace/abc"}},
+			},
+			{Time: 1641889546000, Labels: map[string]string{"span": "x"}},
+		}, field.Config.Custom["exemplars"])
+
+		// the frames can still be sent
+		_, err := json.Marshal(rsp.Frames)
+		require.NoError(t, err)
+	}
+
+	t.Run("exemplars without their series", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":[
+			{"exemplars":[{"labels":{"traceID":"abc"},"value":"0.15","timestamp":1641889555.123}],
+			 "seriesLabels":{"__name__":"rpc_duration_seconds","job":"api"}}
+		]}`), Options{ExemplarsOnSeries: true})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		require.Equal(t, "exemplar", frameResultType(rsp.Frames[0]))
+	})
+}
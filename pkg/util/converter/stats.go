@@ -0,0 +1,107 @@
+package converter
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// queryStatFields are the numeric query stats returned with stats=all by prometheus,
+// and the extended stats of mimir. The stats are grouped in objects, mimir also
+// returns some of them at the top level, so both are looked up.
+var queryStatFields = []struct {
+	group string
+	key   string
+	name  string
+	unit  string
+}{
+	{"samples", "totalQueryableSamples", "Samples: total queryable samples", ""},
+	{"samples", "peakSamples", "Samples: peak samples", ""},
+	{"timings", "evalTotalTime", "Timings: eval total time", "s"},
+	{"timings", "resultSortTime", "Timings: result sort time", "s"},
+	{"timings", "queryPreparationTime", "Timings: query preparation time", "s"},
+	{"timings", "innerEvalTime", "Timings: inner eval time", "s"},
+	{"timings", "execQueueTime", "Timings: exec queue time", "s"},
+	{"timings", "execTotalTime", "Timings: exec total time", "s"},
+	{"", "peakSamples", "Samples: peak samples", ""},
+	{"", "totalQueriedSeries", "Mimir: total queried series", ""},
+	{"", "fetchedSeriesCount", "Mimir: fetched series", ""},
+	{"", "fetchedChunksCount", "Mimir: fetched chunks", ""},
+	{"", "fetchedChunkBytes", "Mimir: fetched chunk bytes", "decbytes"},
+	{"", "fetchedIndexBytes", "Mimir: fetched index bytes", "decbytes"},
+	{"", "shardedQueries", "Mimir: sharded queries", ""},
+	{"", "splitQueries", "Mimir: split queries", ""},
+	{"", "estimatedSeriesCount", "Mimir: estimated series", ""},
+}
+
+// readQueryStats converts the "stats" object of a query response. Stats that are not
+// returned by the server are left out, and a stat is only added once.
+func readQueryStats(v interface{}) []data.QueryStat {
+	raw, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var stats []data.QueryStat
+	seen := make(map[string]bool, len(queryStatFields))
+	for _, f := range queryStatFields {
+		group := raw
+		if f.group != "" {
+			group, _ = raw[f.group].(map[string]interface{})
+		}
+		value, ok := group[f.key].(float64)
+		if !ok || seen[f.name] {
+			continue
+		}
+		seen[f.name] = true
+		stats = append(stats, data.QueryStat{
+			FieldConfig: data.FieldConfig{
+				DisplayName: f.name,
+				Unit:        f.unit,
+			},
+			Value: value,
+		})
+	}
+	return stats
+}
+
+// readSamplesPerStep returns a frame of the samples read at every step, returned by
+// prometheus with stats=all, or nil when the stats have no steps
+func readSamplesPerStep(v interface{}) *data.Frame {
+	raw, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	samples, _ := raw["samples"].(map[string]interface{})
+	steps, _ := samples["totalQueryableSamplesPerStep"].([]interface{})
+	if len(steps) == 0 {
+		return nil
+	}
+
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
+	timeField.Name = data.TimeSeriesTimeFieldName
+	valueField := data.NewFieldFromFieldType(data.FieldTypeInt64, 0)
+	valueField.Name = data.TimeSeriesValueFieldName
+	valueField.Config = &data.FieldConfig{DisplayNameFromDS: "Samples per step"}
+	for _, step := range steps {
+		pair, ok := step.([]interface{})
+		if !ok || len(pair) != 2 {
+			continue
+		}
+		ts, ok := pair[0].(float64)
+		if !ok {
+			continue
+		}
+		count, ok := pair[1].(float64)
+		if !ok {
+			continue
+		}
+		timeField.Append(timeFromFloat(ts))
+		valueField.Append(int64(count))
+	}
+
+	frame := data.NewFrame("", timeField, valueField)
+	frame.Meta = &data.FrameMeta{
+		Type:   data.FrameTypeTimeSeriesMulti,
+		Custom: resultTypeToCustomMeta("samplesPerStep"),
+	}
+	return frame
+}
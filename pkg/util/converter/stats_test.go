@@ -0,0 +1,76 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryStats(t *testing.T) {
+	t.Run("mimir", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"job":"a"},"value":[1645029699,"1"]},
+			{"metric":{"job":"b"},"value":[1645029699,"2"]}
+		],"stats":{
+			"timings":{"evalTotalTime":0.25},
+			"samples":{"totalQueryableSamples":1200,"peakSamples":300},
+			"totalQueriedSeries":2,
+			"fetchedChunkBytes":4096,
+			"peakSamples":300
+		}}}`), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 2)
+
+		stats := map[string]data.QueryStat{}
+		for _, s := range rsp.Frames[0].Meta.Stats {
+			stats[s.DisplayName] = s
+		}
+		require.Len(t, stats, 5)
+		require.Equal(t, 300.0, stats["Samples: peak samples"].Value)
+		require.Equal(t, 1200.0, stats["Samples: total queryable samples"].Value)
+		require.Equal(t, 0.25, stats["Timings: eval total time"].Value)
+		require.Equal(t, "s", stats["Timings: eval total time"].Unit)
+		require.Equal(t, 2.0, stats["Mimir: total queried series"].Value)
+		require.Equal(t, 4096.0, stats["Mimir: fetched chunk bytes"].Value)
+		require.Equal(t, "decbytes", stats["Mimir: fetched chunk bytes"].Unit)
+
+		// the raw stats are still available
+		custom, ok := rsp.Frames[0].Meta.Custom.(map[string]interface{})
+		require.True(t, ok)
+		require.NotNil(t, custom["stats"])
+	})
+
+	t.Run("unknown stats", func(t *testing.T) {
+		require.Empty(t, readQueryStats(map[string]interface{}{"summary": map[string]interface{}{"execTime": 1.0}}))
+		require.Empty(t, readQueryStats("stats"))
+	})
+}
+
+func TestSamplesPerStep(t *testing.T) {
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"job":"a"},"values":[[1645029699,"1"],[1645029714,"2"]]}
+	],"stats":{
+		"timings":{"evalTotalTime":0.25},
+		"samples":{"totalQueryableSamplesPerStep":[[1645029699,10],[1645029714,12]],"totalQueryableSamples":22,"peakSamples":12}
+	}}}`), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 2)
+
+	frame := rsp.Frames[1]
+	require.Equal(t, map[string]string{"resultType": "samplesPerStep"}, frame.Meta.Custom)
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, time.Unix(1645029714, 0).UTC(), frame.Fields[0].At(1))
+	require.Equal(t, int64(12), frame.Fields[1].At(1))
+	require.Equal(t, "Samples per step", frame.Fields[1].Config.DisplayNameFromDS)
+
+	t.Run("without steps", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"job":"a"},"values":[[1645029699,"1"]]}
+		],"stats":{"samples":{"totalQueryableSamples":1}}}}`), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+	})
+}
@@ -0,0 +1,99 @@
+package converter
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// The tsdb status lists are sorted by the server, largest first
+var tsdbStatusLists = map[string]bool{
+	"seriesCountByMetricName":     true,
+	"labelValueCountByLabelName":  true,
+	"memoryInBytesByLabelName":    true,
+	"seriesCountByLabelValuePair": true,
+}
+
+// The buildinfo and runtimeinfo endpoints return a flat object:
+// { "version": "2.13.1", "goroutineCount": 48, ... }
+// Every value is added to a name/value frame as a string
+func newStatusInfoFrame() *data.Frame {
+	frame := data.NewFrame("",
+		data.NewField("name", nil, []string{}),
+		data.NewField(data.TimeSeriesValueFieldName, nil, []string{}),
+	)
+	frame.Meta = &data.FrameMeta{
+		Custom: resultTypeToCustomMeta("status"),
+	}
+	return frame
+}
+
+func readStatusInfoValue(iter *jsoniter.Iterator, name string, frame *data.Frame) {
+	switch iter.WhatIsNext() {
+	case jsoniter.StringValue:
+		frame.AppendRow(name, iter.ReadString())
+	case jsoniter.NilValue:
+		iter.Skip()
+		frame.AppendRow(name, "")
+	default:
+		frame.AppendRow(name, fmt.Sprintf("%v", iter.Read()))
+	}
+}
+
+// readTSDBHeadStats reads the "headStats" of /api/v1/status/tsdb
+// { "numSeries": 508, "chunkCount": 937, "minTime": 1591516800000, "maxTime": 1598896800143 }
+func readTSDBHeadStats(iter *jsoniter.Iterator) *data.Frame {
+	frame := data.NewFrame("headStats",
+		data.NewField("name", nil, []string{}),
+		data.NewField(data.TimeSeriesValueFieldName, nil, []float64{}),
+	)
+	frame.Meta = &data.FrameMeta{
+		Custom: resultTypeToCustomMeta("tsdb"),
+	}
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		if iter.WhatIsNext() != jsoniter.NumberValue {
+			iter.Skip()
+			skippedKey(iter, "headStats", l1Field)
+			continue
+		}
+		frame.AppendRow(l1Field, iter.ReadFloat64())
+	}
+	return frame
+}
+
+// readTSDBStatusList reads one of the top cardinality lists of /api/v1/status/tsdb
+// [ { "name": "http_request_duration_seconds_bucket", "value": 4200 }, ... ]
+func readTSDBStatusList(iter *jsoniter.Iterator, name string) *data.Frame {
+	nameField := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	nameField.Name = "name"
+	valueField := data.NewFieldFromFieldType(data.FieldTypeFloat64, 0)
+	valueField.Name = data.TimeSeriesValueFieldName
+	if name == "memoryInBytesByLabelName" {
+		valueField.Config = &data.FieldConfig{Unit: "bytes"}
+	}
+
+	for iter.ReadArray() {
+		n := ""
+		v := 0.0
+		for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+			switch l1Field {
+			case "name":
+				n = iter.ReadString()
+			case "value":
+				v = iter.ReadFloat64()
+			default:
+				iter.Skip()
+				skippedKey(iter, name, l1Field)
+			}
+		}
+		nameField.Append(n)
+		valueField.Append(v)
+	}
+
+	frame := data.NewFrame(name, nameField, valueField)
+	frame.Meta = &data.FrameMeta{
+		Custom: resultTypeToCustomMeta("tsdb"),
+	}
+	return frame
+}
@@ -0,0 +1,48 @@
+package converter
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadStatus(t *testing.T) {
+	t.Run("buildinfo", func(t *testing.T) {
+		body := `{"status":"success","data":{"version":"2.13.1","revision":"cb7cbad5f9a2823a622aaa668833ca04f50a0ea7","branch":"master","buildUser":"julius@desktop","buildDate":"20191102-16:19:59","goVersion":"go1.13.1"}}`
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		require.Equal(t, 6, rsp.Frames[0].Rows())
+		require.Equal(t, "version", rsp.Frames[0].Fields[0].At(0))
+		require.Equal(t, "2.13.1", rsp.Frames[0].Fields[1].At(0))
+	})
+
+	t.Run("runtimeinfo", func(t *testing.T) {
+		body := `{"status":"success","data":{"startTime":"2019-11-02T17:23:59.301361365+01:00","reloadConfigSuccess":true,"goroutineCount":48,"GOGC":""}}`
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		require.Equal(t, 4, rsp.Frames[0].Rows())
+		require.Equal(t, "true", rsp.Frames[0].Fields[1].At(1))
+		require.Equal(t, "48", rsp.Frames[0].Fields[1].At(2))
+	})
+
+	t.Run("tsdb", func(t *testing.T) {
+		body := `{"status":"success","data":{
+			"headStats":{"numSeries":508,"chunkCount":937,"minTime":1591516800000,"maxTime":1598896800143},
+			"seriesCountByMetricName":[{"name":"net_conntrack_dialer_conn_failed_total","value":20},{"name":"prometheus_http_request_duration_seconds_bucket","value":20}],
+			"labelValueCountByLabelName":[{"name":"__name__","value":211}],
+			"memoryInBytesByLabelName":[{"name":"__name__","value":8266}],
+			"seriesCountByLabelValuePair":[{"name":"job=prometheus","value":425}]
+		}}`
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 5)
+		require.Equal(t, "headStats", rsp.Frames[0].Name)
+		require.Equal(t, 508.0, rsp.Frames[0].Fields[1].At(0))
+		require.Equal(t, "seriesCountByMetricName", rsp.Frames[1].Name)
+		require.Equal(t, 2, rsp.Frames[1].Rows())
+		require.Equal(t, "bytes", rsp.Frames[3].Fields[1].Config.Unit)
+	})
+}
@@ -0,0 +1,81 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// StrictMode configures how the keys of a response that the converter does not read are reported
+type StrictMode string
+
+const (
+	// StrictOff skips unknown keys silently
+	StrictOff StrictMode = ""
+	// StrictNotice adds a warning to every frame listing the unknown keys
+	StrictNotice StrictMode = "notice"
+	// StrictError fails the response when it has unknown keys, meant for tests and CI
+	StrictError StrictMode = "error"
+)
+
+// unknownKeys collects the keys skipped in the whole response. It is the attachment of
+// the iterator, so the readers that are not passed the options can report keys too.
+type unknownKeys struct {
+	keys []string
+	seen map[string]bool
+}
+
+func (u *unknownKeys) add(key string) {
+	if u.seen == nil {
+		u.seen = map[string]bool{}
+	}
+	if u.seen[key] {
+		return
+	}
+	u.seen[key] = true
+	u.keys = append(u.keys, key)
+}
+
+// report adds the notice or returns the error of the strict mode, a nil collector reports nothing
+func (u *unknownKeys) report(frames data.Frames, mode StrictMode) error {
+	if u == nil || len(u.keys) == 0 {
+		return nil
+	}
+	keys := strings.Join(u.keys, ", ")
+	switch mode {
+	case StrictError:
+		return fmt.Errorf("unknown response keys: %s", keys)
+	case StrictNotice:
+		notice := data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     "Unknown response keys were skipped: " + keys,
+		}
+		for _, frame := range frames {
+			frame.AppendNotices(notice)
+		}
+	}
+	return nil
+}
+
+// attachUnknownKeys shares the collector of the options with the iterator
+func attachUnknownKeys(iter *jsoniter.Iterator, opt *Options) {
+	if opt.Strict == StrictOff {
+		return
+	}
+	if opt.unknownKeys == nil {
+		opt.unknownKeys = &unknownKeys{}
+	}
+	if iter.Attachment == nil {
+		iter.Attachment = opt.unknownKeys
+	}
+}
+
+// skippedKey is called for every key a reader skips, where is the object the key is in
+func skippedKey(iter *jsoniter.Iterator, where string, key string) {
+	logf("[%s] TODO, support key: %s\n", where, key)
+	if u, ok := iter.Attachment.(*unknownKeys); ok {
+		u.add(where + "." + key)
+	}
+}
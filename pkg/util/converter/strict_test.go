@@ -0,0 +1,47 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrictMode(t *testing.T) {
+	body := `{"status":"success","infos":["x"],"data":{"resultType":"matrix","result":[
+		{"metric":{"job":"a"},"values":[[1,"1"]],"shard":1},
+		{"metric":{"job":"b"},"values":[[1,"2"]],"shard":2}
+	]}}`
+	read := func(mode StrictMode) (*data.Frame, error) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{Strict: mode})
+		if len(rsp.Frames) == 0 {
+			return nil, rsp.Error
+		}
+		return rsp.Frames[0], rsp.Error
+	}
+
+	t.Run("off", func(t *testing.T) {
+		frame, err := read(StrictOff)
+		require.NoError(t, err)
+		require.Empty(t, frame.Meta.Notices)
+	})
+
+	t.Run("notice", func(t *testing.T) {
+		frame, err := read(StrictNotice)
+		require.NoError(t, err)
+		require.Len(t, frame.Meta.Notices, 1)
+		require.Equal(t, "Unknown response keys were skipped: ROOT.infos, result.shard", frame.Meta.Notices[0].Text)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := read(StrictError)
+		require.EqualError(t, err, "unknown response keys: ROOT.infos, result.shard")
+	})
+
+	t.Run("loki streams", func(t *testing.T) {
+		rsp := ReadAuto(strings.NewReader(`{"streams":[],"dropped":[]}`), Options{Strict: StrictError})
+		require.EqualError(t, rsp.Error, "unknown response keys: streams.dropped")
+	})
+}
@@ -0,0 +1,127 @@
+package converter
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// readActiveTargets reads the scrape target health from /api/v1/targets
+// { "activeTargets": [ { labels, scrapePool, scrapeUrl, health, lastScrape, lastError, ... } ] }
+func readActiveTargets(iter *jsoniter.Iterator) backend.DataResponse {
+	job := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	job.Name = "job"
+	instance := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	instance.Name = "instance"
+	scrapePool := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	scrapePool.Name = "scrapePool"
+	scrapeURL := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	scrapeURL.Name = "scrapeUrl"
+	health := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	health.Name = "health"
+	lastScrape := data.NewFieldFromFieldType(data.FieldTypeNullableTime, 0)
+	lastScrape.Name = "lastScrape"
+	lastScrapeDuration := data.NewFieldFromFieldType(data.FieldTypeFloat64, 0)
+	lastScrapeDuration.Name = "lastScrapeDuration"
+	lastScrapeDuration.Config = &data.FieldConfig{Unit: "s"}
+	lastError := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	lastError.Name = "lastError"
+	labelsField := data.NewFieldFromFieldType(data.FieldTypeJSON, 0)
+	labelsField.Name = "labels"
+
+	var err error
+	for iter.ReadArray() {
+		targetLabels := data.Labels{}
+		var (
+			pool, url, h, lastErr string
+			scrapeTime            *time.Time
+			duration              float64
+		)
+		for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+			switch l1Field {
+			case "labels":
+				iter.ReadVal(&targetLabels)
+			case "scrapePool":
+				pool = iter.ReadString()
+			case "scrapeUrl":
+				url = iter.ReadString()
+			case "health":
+				h = iter.ReadString()
+			case "lastError":
+				lastErr = iter.ReadString()
+			case "lastScrape":
+				t, terr := readRFC3339Time(iter)
+				scrapeTime = t
+				if terr != nil && err == nil {
+					err = terr
+				}
+			case "lastScrapeDuration":
+				duration = iter.ReadFloat64()
+			default:
+				// discoveredLabels, globalUrl, scrapeInterval, scrapeTimeout
+				iter.Skip()
+				skippedKey(iter, "targets", l1Field)
+			}
+		}
+
+		labelJson, lerr := labelsToRawJson(targetLabels)
+		if lerr != nil && err == nil {
+			err = lerr
+		}
+
+		job.Append(targetLabels["job"])
+		instance.Append(targetLabels["instance"])
+		scrapePool.Append(pool)
+		scrapeURL.Append(url)
+		health.Append(h)
+		lastScrape.Append(scrapeTime)
+		lastScrapeDuration.Append(duration)
+		lastError.Append(lastErr)
+		labelsField.Append(labelJson)
+	}
+
+	frame := data.NewFrame("activeTargets", job, instance, scrapePool, scrapeURL, health,
+		lastScrape, lastScrapeDuration, lastError, labelsField)
+	frame.Meta = &data.FrameMeta{
+		Custom: resultTypeToCustomMeta("targets"),
+	}
+	return backend.DataResponse{
+		Frames: data.Frames{frame},
+		Error:  err,
+	}
+}
+
+// readDroppedTargets reads the targets dropped by relabeling from /api/v1/targets
+// { "droppedTargets": [ { discoveredLabels } ] }
+func readDroppedTargets(iter *jsoniter.Iterator) (*data.Frame, error) {
+	discovered := data.NewFieldFromFieldType(data.FieldTypeJSON, 0)
+	discovered.Name = "discoveredLabels"
+
+	var err error
+	for iter.ReadArray() {
+		labelJson := json.RawMessage("{}")
+		for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+			switch l1Field {
+			case "discoveredLabels":
+				v, lerr := readLabelsAsRawJson(iter)
+				labelJson = v
+				if lerr != nil && err == nil {
+					err = lerr
+				}
+			default:
+				iter.Skip()
+				skippedKey(iter, "droppedTargets", l1Field)
+			}
+		}
+		discovered.Append(labelJson)
+	}
+
+	frame := data.NewFrame("droppedTargets", discovered)
+	frame.Meta = &data.FrameMeta{
+		Custom: resultTypeToCustomMeta("targets"),
+	}
+	return frame, err
+}
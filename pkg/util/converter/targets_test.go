@@ -0,0 +1,73 @@
+package converter
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTargets(t *testing.T) {
+	f, err := os.Open(path.Join("testdata", "prom-targets.json"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	rsp := ReadPrometheusStyleResult(jsoniter.Parse(jsoniter.ConfigDefault, f, 1024), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 2)
+
+	active := rsp.Frames[0]
+	require.Equal(t, "activeTargets", active.Name)
+	require.Equal(t, 1, active.Rows())
+	row := func(name string) interface{} {
+		f, _ := active.FieldByName(name)
+		require.NotNil(t, f, name)
+		return f.At(0)
+	}
+	require.Equal(t, "prometheus", row("job"))
+	require.Equal(t, "127.0.0.1:9090", row("instance"))
+	require.Equal(t, "up", row("health"))
+	require.Equal(t, time.Date(2017, time.January, 17, 14, 7, 44, 723715405, time.UTC), *row("lastScrape").(*time.Time))
+
+	dropped := rsp.Frames[1]
+	require.Equal(t, "droppedTargets", dropped.Name)
+	require.Equal(t, 1, dropped.Rows())
+}
+
+func TestReadTargetsMetadata(t *testing.T) {
+	f, err := os.Open(path.Join("testdata", "prom-targets-metadata.json"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	rsp := ReadPrometheusStyleResult(jsoniter.Parse(jsoniter.ConfigDefault, f, 1024), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 1)
+
+	frame := rsp.Frames[0]
+	require.Equal(t, 2, frame.Rows())
+	names := []string{}
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{"instance", "job", "metric", "type", "help", "unit"}, names)
+	require.Equal(t, "prometheus_tsdb_reloads_total", frame.Fields[2].At(1))
+}
+
+func TestSeriesWithTargetLabel(t *testing.T) {
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{"status":"success","data":[
+		{"__name__":"x","target":"a"},
+		{"__name__":"y"}
+	]}`), Options{})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 1)
+
+	frame := rsp.Frames[0]
+	require.Equal(t, 2, frame.Rows())
+	name, _ := frame.FieldByName("__name__")
+	require.Equal(t, "y", name.At(1))
+	target, _ := frame.FieldByName("target")
+	require.Equal(t, "a", target.At(0))
+}
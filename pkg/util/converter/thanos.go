@@ -0,0 +1,70 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const partialResponseNoticePrefix = "Partial response: "
+
+// Thanos returns data with warnings when some of the stores could not be queried
+// and partial responses are enabled. The warnings are plain error strings, like:
+//
+//	"No StoreAPIs matched for this query"
+//	"fetch series for {replica=\"a\"} Addr: store-0:10901: rpc error: code = Unavailable ..."
+//	"receive series from Addr: store-1:10901 LabelSets: ...: context deadline exceeded"
+var thanosPartialResponseWarnings = []string{
+	"partial response",
+	"no storeapis matched",
+	"fetch series for",
+	"receive series from",
+	"failed to receive any data",
+	"store failure",
+}
+
+func isThanosPartialResponseWarning(text string) bool {
+	lower := strings.ToLower(text)
+	for _, w := range thanosPartialResponseWarnings {
+		if strings.Contains(lower, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// readWarningNotice maps the known Thanos warnings to a distinct notice, and
+// reports if the data may be incomplete
+func readWarningNotice(text string) (data.Notice, bool) {
+	if !isThanosPartialResponseWarning(text) {
+		return data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     text,
+		}, false
+	}
+	if !strings.HasPrefix(strings.ToLower(text), "partial response") {
+		text = partialResponseNoticePrefix + text
+	}
+	return data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     text,
+		Inspect:  data.InspectTypeData,
+	}, true
+}
+
+// markPartialResponse flags the frames so dashboards can show that the data may be incomplete
+func markPartialResponse(frames data.Frames) {
+	for _, frame := range frames {
+		if frame.Meta == nil {
+			frame.Meta = &data.FrameMeta{}
+		}
+		switch custom := frame.Meta.Custom.(type) {
+		case nil:
+			frame.Meta.Custom = map[string]string{"partialResponse": "true"}
+		case map[string]string:
+			custom["partialResponse"] = "true"
+		case map[string]interface{}:
+			custom["partialResponse"] = true
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThanosPartialResponse(t *testing.T) {
+	read := func(warnings string) *data.Frame {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, `{
+			"status":"success",
+			"data":{"resultType":"vector","result":[{"metric":{"__name__":"up"},"value":[1645029699,"1"]}]},
+			"warnings":`+warnings+`
+		}`), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		return rsp.Frames[0]
+	}
+
+	t.Run("store failure", func(t *testing.T) {
+		frame := read(`["fetch series for {replica=\"a\"} Addr: store-0:10901: rpc error: code = Unavailable", "something else"]`)
+		require.Equal(t, "true", frame.Meta.Custom.(map[string]string)["partialResponse"])
+		require.Equal(t, "vector", frame.Meta.Custom.(map[string]string)["resultType"])
+		require.Len(t, frame.Meta.Notices, 2)
+		require.Equal(t, `Partial response: fetch series for {replica="a"} Addr: store-0:10901: rpc error: code = Unavailable`, frame.Meta.Notices[0].Text)
+		require.Equal(t, data.InspectTypeData, frame.Meta.Notices[0].Inspect)
+		require.Equal(t, "something else", frame.Meta.Notices[1].Text)
+	})
+
+	t.Run("other warnings", func(t *testing.T) {
+		frame := read(`["PromQL info: metric might not be a counter"]`)
+		_, ok := frame.Meta.Custom.(map[string]string)["partialResponse"]
+		require.False(t, ok)
+		require.Equal(t, data.InspectTypeNone, frame.Meta.Notices[0].Inspect)
+	})
+}
@@ -0,0 +1,83 @@
+package converter
+
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// prometheus rejects range queries resolving to more points per series
+const maxTimeGridRows = 11000
+
+// timeGrid holds the rows of a wide frame for the timestamps a range query is
+// evaluated at (start, start+step, ... end), so samples can be set by index
+// instead of growing the frame for every new timestamp
+type timeGrid struct {
+	start int64
+	step  int64
+	rows  int
+	used  []bool
+}
+
+func newTimeGrid(opt Options, resultType string) *timeGrid {
+	if resultType != "matrix" || opt.Step <= 0 || opt.Start.IsZero() || opt.End.Before(opt.Start) {
+		return nil
+	}
+	rows := int(opt.End.Sub(opt.Start)/opt.Step) + 1
+	if rows > maxTimeGridRows {
+		return nil
+	}
+	return &timeGrid{
+		start: opt.Start.UnixNano(),
+		step:  int64(opt.Step),
+		rows:  rows,
+		used:  make([]bool, rows),
+	}
+}
+
+func (g *timeGrid) timeField() *data.Field {
+	field := data.NewFieldFromFieldType(data.FieldTypeTime, g.rows)
+	for i := 0; i < g.rows; i++ {
+		field.Set(i, time.Unix(0, g.start+int64(i)*g.step).UTC())
+	}
+	return field
+}
+
+// index returns the preallocated row of a timestamp, samples off the grid
+// (like from subqueries or a different step) are added after the grid rows
+func (g *timeGrid) index(ns int64) (int, bool) {
+	if g == nil || ns < g.start || (ns-g.start)%g.step != 0 {
+		return 0, false
+	}
+	i := int((ns - g.start) / g.step)
+	if i >= g.rows {
+		return 0, false
+	}
+	g.used[i] = true
+	return i, true
+}
+
+// compact removes the grid rows no series had a sample for, so the frame
+// matches the one built without a grid
+func (g *timeGrid) compact(frame *data.Frame) {
+	keep := make([]int, 0, frame.Rows())
+	for i := 0; i < frame.Rows(); i++ {
+		if i >= g.rows || g.used[i] {
+			keep = append(keep, i)
+		}
+	}
+	if len(keep) == frame.Rows() {
+		return
+	}
+
+	for idx, f := range frame.Fields {
+		compacted := data.NewFieldFromFieldType(f.Type(), len(keep))
+		compacted.Name = f.Name
+		compacted.Labels = f.Labels
+		compacted.Config = f.Config
+		for j, i := range keep {
+			compacted.Set(j, f.At(i))
+		}
+		frame.Fields[idx] = compacted
+	}
+}
@@ -0,0 +1,50 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeGrid(t *testing.T) {
+	// no series has a sample at 1641889560, and 1641889551 is off the grid
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"i":"0"},"values":[[1641889530,"1"],[1641889545,"2"],[1641889575,"4"]]},
+		{"metric":{"i":"1"},"values":[[1641889545,"5"],[1641889551,"6"]]}
+	]}}`
+
+	read := func() *jsoniter.Iterator {
+		return jsoniter.ParseString(jsoniter.ConfigDefault, body)
+	}
+
+	expected := ReadPrometheusStyleResult(read(), Options{MatrixWideSeries: true})
+	require.NoError(t, expected.Error)
+
+	opt := Options{
+		MatrixWideSeries: true,
+		Step:             15 * time.Second,
+		Start:            time.Unix(1641889530, 0),
+		End:              time.Unix(1641889575, 0),
+	}
+	rsp := ReadPrometheusStyleResult(read(), opt)
+	require.NoError(t, rsp.Error)
+
+	require.Len(t, rsp.Frames, 1)
+	require.Equal(t, 4, rsp.Frames[0].Rows())
+	for i, f := range rsp.Frames[0].Fields {
+		require.Equal(t, expected.Frames[0].Fields[i].Labels, f.Labels)
+		for row := 0; row < f.Len(); row++ {
+			require.Equal(t, expected.Frames[0].Fields[i].At(row), f.At(row))
+		}
+	}
+
+	t.Run("ignores ranges with too many steps", func(t *testing.T) {
+		opt := Options{Step: time.Millisecond, Start: time.Unix(0, 0), End: time.Unix(3600, 0)}
+		require.Nil(t, newTimeGrid(opt, "matrix"))
+		opt.End = time.Unix(1, 0)
+		require.NotNil(t, newTimeGrid(opt, "matrix"))
+		require.Nil(t, newTimeGrid(opt, "vector"))
+	})
+}
@@ -0,0 +1,69 @@
+package converter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// timestampIssues counts the samples of matrix and vector series that do not come after
+// the previous sample of their series, like the ones of misbehaving federation proxies
+type timestampIssues struct {
+	duplicates  int
+	regressions int
+	series      int
+	// the labels of the first series with issues
+	first data.Labels
+}
+
+// check is called with the timestamps of every series, a nil counter ignores them
+func (t *timestampIssues) check(labels data.Labels, times []time.Time) {
+	if t == nil {
+		return
+	}
+	duplicates, regressions := 0, 0
+	for i := 1; i < len(times); i++ {
+		switch {
+		case times[i].Equal(times[i-1]):
+			duplicates++
+		case times[i].Before(times[i-1]):
+			regressions++
+		}
+	}
+	if duplicates == 0 && regressions == 0 {
+		return
+	}
+	t.duplicates += duplicates
+	t.regressions += regressions
+	t.series++
+	if t.first == nil {
+		t.first = labels
+	}
+}
+
+func (t *timestampIssues) merge(other *timestampIssues) {
+	if t == nil || other == nil || other.series == 0 {
+		return
+	}
+	t.duplicates += other.duplicates
+	t.regressions += other.regressions
+	t.series += other.series
+	if t.first == nil {
+		t.first = other.first
+	}
+}
+
+func addTimestampNotice(frames []*data.Frame, t *timestampIssues) {
+	if t == nil || t.series == 0 {
+		return
+	}
+	notice := data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text: fmt.Sprintf("Found %d duplicated and %d out of order timestamps in %d series, the first one is {%s}",
+			t.duplicates, t.regressions, t.series, t.first),
+	}
+	for _, frame := range frames {
+		frame.AppendNotices(notice)
+	}
+}
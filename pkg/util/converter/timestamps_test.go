@@ -0,0 +1,43 @@
+package converter
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTimestamps(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"job":"a"},"values":[[1,"1"],[2,"2"],[3,"3"]]},
+		{"metric":{"job":"b"},"values":[[1,"1"],[3,"2"],[2,"3"],[2,"4"]]},
+		{"metric":{"job":"c"},"values":[[2,"1"],[1,"2"]]}
+	]}}`
+	const text = `Found 1 duplicated and 2 out of order timestamps in 2 series, the first one is {job=b}`
+
+	for name, opt := range map[string]Options{
+		"multi":    {ValidateTimestamps: true},
+		"wide":     {ValidateTimestamps: true, MatrixWideSeries: true},
+		"long":     {ValidateTimestamps: true, MatrixLongSeries: true},
+		"parallel": {ValidateTimestamps: true, ParseWorkers: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+			require.NoError(t, rsp.Error)
+			require.NotEmpty(t, rsp.Frames)
+			for _, frame := range rsp.Frames {
+				require.Len(t, frame.Meta.Notices, 1)
+				require.Equal(t, text, frame.Meta.Notices[0].Text)
+			}
+		})
+	}
+
+	t.Run("not validated", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{})
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 3)
+		require.Empty(t, rsp.Frames[1].Meta.Notices)
+		// the samples are kept in the order of the response
+		require.Equal(t, 4, rsp.Frames[1].Rows())
+	})
+}
@@ -0,0 +1,484 @@
+package converter
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"go.opentelemetry.io/collector/translator/conventions"
+	tracetranslator "go.opentelemetry.io/collector/translator/trace"
+)
+
+// The JSON of the key/value lists of the trace frame, the same as the tempo datasource
+type traceKeyValue struct {
+	Value interface{} `json:"value"`
+	Key   string      `json:"key"`
+}
+
+type traceLog struct {
+	// Millisecond epoch time
+	Timestamp float64          `json:"timestamp"`
+	Fields    []*traceKeyValue `json:"fields"`
+}
+
+type traceReference struct {
+	SpanID  string           `json:"spanID"`
+	TraceID string           `json:"traceID"`
+	Tags    []*traceKeyValue `json:"tags"`
+}
+
+type traceSpan struct {
+	traceID       string
+	spanID        string
+	parentSpanID  string
+	name          string
+	kind          string
+	traceState    string
+	statusCode    int64
+	statusMessage string
+	start         uint64
+	end           uint64
+	attributes    []*traceKeyValue
+	logs          []*traceLog
+	references    []*traceReference
+}
+
+// OTLP status codes
+const (
+	traceStatusUnset int64 = iota
+	traceStatusOk
+	traceStatusError
+)
+
+var traceStatusCodes = map[string]int64{
+	"STATUS_CODE_UNSET": traceStatusUnset,
+	"STATUS_CODE_OK":    traceStatusOk,
+	"STATUS_CODE_ERROR": traceStatusError,
+}
+
+var traceSpanKinds = map[string]tracetranslator.OpenTracingSpanKind{
+	"SPAN_KIND_INTERNAL": tracetranslator.OpenTracingSpanKindInternal,
+	"SPAN_KIND_SERVER":   tracetranslator.OpenTracingSpanKindServer,
+	"SPAN_KIND_CLIENT":   tracetranslator.OpenTracingSpanKindClient,
+	"SPAN_KIND_PRODUCER": tracetranslator.OpenTracingSpanKindProducer,
+	"SPAN_KIND_CONSUMER": tracetranslator.OpenTracingSpanKindConsumer,
+	// the numbers of the enum
+	"1": tracetranslator.OpenTracingSpanKindInternal,
+	"2": tracetranslator.OpenTracingSpanKindServer,
+	"3": tracetranslator.OpenTracingSpanKindClient,
+	"4": tracetranslator.OpenTracingSpanKindProducer,
+	"5": tracetranslator.OpenTracingSpanKindConsumer,
+}
+
+type traceFrameBuilder struct {
+	frame *data.Frame
+	err   error
+}
+
+func newTraceFrameBuilder() *traceFrameBuilder {
+	return &traceFrameBuilder{
+		frame: &data.Frame{
+			Name: "Trace",
+			Fields: []*data.Field{
+				data.NewField("traceID", nil, []string{}),
+				data.NewField("spanID", nil, []string{}),
+				data.NewField("parentSpanID", nil, []string{}),
+				data.NewField("operationName", nil, []string{}),
+				data.NewField("serviceName", nil, []string{}),
+				data.NewField("serviceTags", nil, []json.RawMessage{}),
+				data.NewField("startTime", nil, []float64{}),
+				data.NewField("duration", nil, []float64{}),
+				data.NewField("logs", nil, []json.RawMessage{}),
+				data.NewField("references", nil, []json.RawMessage{}),
+				data.NewField("tags", nil, []json.RawMessage{}),
+			},
+			Meta: &data.FrameMeta{
+				PreferredVisualization: "trace",
+			},
+		},
+	}
+}
+
+func (b *traceFrameBuilder) marshal(v interface{}) json.RawMessage {
+	raw, err := json.Marshal(v)
+	if err != nil && b.err == nil {
+		b.err = err
+	}
+	return raw
+}
+
+// ReadTempoTrace reads a trace from the tempo /api/traces/<traceID> endpoint in JSON, which
+// is OTLP: { "batches": [ { resource, instrumentationLibrarySpans: [ { spans } ] } ] }. The newer
+// resourceSpans and scopeSpans names are read too. The frame has the columns of the trace view.
+func ReadTempoTrace(iter *jsoniter.Iterator, opt Options) backend.DataResponse {
+	attachUnknownKeys(iter, &opt)
+	b := newTraceFrameBuilder()
+
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "batches", "resourceSpans":
+			for iter.ReadArray() {
+				b.readResourceSpans(iter)
+			}
+		default:
+			iter.Skip()
+			skippedKey(iter, "trace", l1Field)
+		}
+	}
+
+	rsp := backend.DataResponse{
+		Frames: data.Frames{b.frame},
+		Error:  b.err,
+	}
+	if iter.Error != nil && rsp.Error == nil {
+		rsp.Error = iter.Error
+	}
+	if err := opt.unknownKeys.report(rsp.Frames, opt.Strict); err != nil && rsp.Error == nil {
+		rsp.Error = err
+	}
+	finishFrames(rsp.Frames, opt)
+	return rsp
+}
+
+// readResourceSpans appends the spans of a resource. The resource may come after its
+// spans, so the service columns are set once the whole object is read.
+func (b *traceFrameBuilder) readResourceSpans(iter *jsoniter.Iterator) {
+	first := b.frame.Rows()
+	serviceName := tracetranslator.ResourceNoServiceName
+	var serviceTags []*traceKeyValue
+
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "resource":
+			for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+				if k == "attributes" {
+					serviceTags = readTraceAttributes(iter)
+				} else {
+					iter.Skip()
+					skippedKey(iter, "resource", k)
+				}
+			}
+		case "instrumentationLibrarySpans", "scopeSpans":
+			for iter.ReadArray() {
+				b.readScopeSpans(iter)
+			}
+		default:
+			iter.Skip()
+			skippedKey(iter, "resourceSpans", l1Field)
+		}
+	}
+
+	for _, kv := range serviceTags {
+		if name, ok := kv.Value.(string); ok && kv.Key == conventions.AttributeServiceName {
+			serviceName = name
+		}
+	}
+	tags := b.marshal(serviceTags)
+	for i := first; i < b.frame.Rows(); i++ {
+		b.frame.Fields[4].Set(i, serviceName)
+		b.frame.Fields[5].Set(i, tags)
+	}
+}
+
+// readScopeSpans appends the spans created by an instrumentation library
+func (b *traceFrameBuilder) readScopeSpans(iter *jsoniter.Iterator) {
+	var (
+		libraryTags []*traceKeyValue
+		spans       []*traceSpan
+	)
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "instrumentationLibrary", "scope":
+			for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+				switch k {
+				case "name":
+					libraryTags = append(libraryTags, &traceKeyValue{Key: conventions.InstrumentationLibraryName, Value: iter.ReadString()})
+				case "version":
+					libraryTags = append(libraryTags, &traceKeyValue{Key: conventions.InstrumentationLibraryVersion, Value: iter.ReadString()})
+				default:
+					iter.Skip()
+					skippedKey(iter, "scope", k)
+				}
+			}
+		case "spans":
+			for iter.ReadArray() {
+				spans = append(spans, readTraceSpan(iter))
+			}
+		default:
+			iter.Skip()
+			skippedKey(iter, "scopeSpans", l1Field)
+		}
+	}
+
+	for _, span := range spans {
+		b.appendSpan(span, libraryTags)
+	}
+}
+
+func (b *traceFrameBuilder) appendSpan(span *traceSpan, libraryTags []*traceKeyValue) {
+	var tags []*traceKeyValue
+	for _, kv := range libraryTags {
+		if s, _ := kv.Value.(string); s != "" {
+			tags = append(tags, kv)
+		}
+	}
+	tags = append(tags, span.attributes...)
+	if span.kind != "" {
+		tags = append(tags, &traceKeyValue{Key: tracetranslator.TagSpanKind, Value: span.kind})
+	}
+	tags = append(tags, &traceKeyValue{Key: tracetranslator.TagStatusCode, Value: span.statusCode})
+	if span.statusCode == traceStatusError {
+		tags = append(tags, &traceKeyValue{Key: tracetranslator.TagError, Value: true})
+	}
+	if span.statusMessage != "" {
+		tags = append(tags, &traceKeyValue{Key: tracetranslator.TagStatusMsg, Value: span.statusMessage})
+	}
+	if span.traceState != "" {
+		tags = append(tags, &traceKeyValue{Key: tracetranslator.TagW3CTraceState, Value: span.traceState})
+	}
+
+	var logs, references json.RawMessage = json.RawMessage("null"), json.RawMessage("null")
+	if len(span.logs) > 0 {
+		logs = b.marshal(span.logs)
+	}
+	if len(span.references) > 0 {
+		references = b.marshal(span.references)
+	}
+
+	// the service columns are set with the resource
+	b.frame.AppendRow(
+		strings.TrimPrefix(span.traceID, strings.Repeat("0", 16)),
+		span.spanID,
+		span.parentSpanID,
+		span.name,
+		"",
+		json.RawMessage("null"),
+		float64(span.start)/1_000_000,
+		float64(int64(span.end-span.start))/1_000_000,
+		logs,
+		references,
+		b.marshal(tags),
+	)
+}
+
+func readTraceSpan(iter *jsoniter.Iterator) *traceSpan {
+	span := &traceSpan{}
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "traceId":
+			span.traceID = traceIDToHex(iter.ReadString())
+		case "spanId":
+			span.spanID = traceIDToHex(iter.ReadString())
+		case "parentSpanId":
+			span.parentSpanID = traceIDToHex(iter.ReadString())
+		case "name":
+			span.name = iter.ReadString()
+		case "kind":
+			span.kind = string(traceSpanKinds[readTraceEnum(iter)])
+		case "traceState":
+			span.traceState = iter.ReadString()
+		case "startTimeUnixNano":
+			span.start = readTraceUint64(iter)
+		case "endTimeUnixNano":
+			span.end = readTraceUint64(iter)
+		case "attributes":
+			span.attributes = readTraceAttributes(iter)
+		case "status":
+			for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+				switch k {
+				case "code":
+					code := readTraceEnum(iter)
+					if c, ok := traceStatusCodes[code]; ok {
+						span.statusCode = c
+					} else {
+						span.statusCode, _ = strconv.ParseInt(code, 10, 64)
+					}
+				case "message":
+					span.statusMessage = iter.ReadString()
+				default:
+					iter.Skip()
+					skippedKey(iter, "status", k)
+				}
+			}
+		case "events":
+			for iter.ReadArray() {
+				span.logs = append(span.logs, readTraceEvent(iter))
+			}
+		case "links":
+			for iter.ReadArray() {
+				span.references = append(span.references, readTraceLink(iter))
+			}
+		default:
+			// like the dropped*Count values
+			iter.Skip()
+			skippedKey(iter, "span", l1Field)
+		}
+	}
+	return span
+}
+
+// readTraceEvent reads a span event as a log, its name is the message field
+func readTraceEvent(iter *jsoniter.Iterator) *traceLog {
+	log := &traceLog{}
+	var name string
+	var attributes []*traceKeyValue
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "timeUnixNano":
+			log.Timestamp = float64(readTraceUint64(iter)) / 1_000_000
+		case "name":
+			name = iter.ReadString()
+		case "attributes":
+			attributes = readTraceAttributes(iter)
+		default:
+			iter.Skip()
+			skippedKey(iter, "event", l1Field)
+		}
+	}
+	log.Fields = make([]*traceKeyValue, 0, len(attributes)+1)
+	if name != "" {
+		log.Fields = append(log.Fields, &traceKeyValue{Key: tracetranslator.TagMessage, Value: name})
+	}
+	log.Fields = append(log.Fields, attributes...)
+	return log
+}
+
+func readTraceLink(iter *jsoniter.Iterator) *traceReference {
+	ref := &traceReference{Tags: []*traceKeyValue{}}
+	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
+		switch l1Field {
+		case "traceId":
+			ref.TraceID = strings.TrimLeft(traceIDToHex(iter.ReadString()), "0")
+		case "spanId":
+			ref.SpanID = traceIDToHex(iter.ReadString())
+		case "attributes":
+			if tags := readTraceAttributes(iter); tags != nil {
+				ref.Tags = tags
+			}
+		default:
+			iter.Skip()
+			skippedKey(iter, "link", l1Field)
+		}
+	}
+	return ref
+}
+
+// readTraceAttributes reads [ { key, value: { stringValue | intValue | ... } } ]. Arrays and
+// maps are written as JSON strings.
+func readTraceAttributes(iter *jsoniter.Iterator) []*traceKeyValue {
+	var attributes []*traceKeyValue
+	for iter.ReadArray() {
+		kv := readTraceKeyValue(iter)
+		switch kv.Value.(type) {
+		case []interface{}, map[string]interface{}:
+			b, _ := json.Marshal(kv.Value)
+			kv.Value = string(b)
+		}
+		attributes = append(attributes, kv)
+	}
+	return attributes
+}
+
+func readTraceKeyValue(iter *jsoniter.Iterator) *traceKeyValue {
+	kv := &traceKeyValue{}
+	for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+		switch k {
+		case "key":
+			kv.Key = iter.ReadString()
+		case "value":
+			kv.Value = readTraceAnyValue(iter)
+		default:
+			iter.Skip()
+			skippedKey(iter, "attribute", k)
+		}
+	}
+	return kv
+}
+
+func readTraceAnyValue(iter *jsoniter.Iterator) interface{} {
+	var v interface{}
+	for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+		switch k {
+		case "stringValue", "bytesValue":
+			v = iter.ReadString()
+		case "boolValue":
+			v = iter.ReadBool()
+		case "intValue":
+			// int64 values are strings in the JSON of protobuf
+			if iter.WhatIsNext() == jsoniter.StringValue {
+				i, _ := strconv.ParseInt(iter.ReadString(), 10, 64)
+				v = i
+			} else {
+				v = iter.ReadInt64()
+			}
+		case "doubleValue":
+			v = iter.ReadFloat64()
+		case "arrayValue":
+			values := []interface{}{}
+			for ak := iter.ReadObject(); ak != ""; ak = iter.ReadObject() {
+				if ak != "values" {
+					iter.Skip()
+					continue
+				}
+				for iter.ReadArray() {
+					values = append(values, readTraceAnyValue(iter))
+				}
+			}
+			v = values
+		case "kvlistValue":
+			values := map[string]interface{}{}
+			for ak := iter.ReadObject(); ak != ""; ak = iter.ReadObject() {
+				if ak != "values" {
+					iter.Skip()
+					continue
+				}
+				for iter.ReadArray() {
+					kv := readTraceKeyValue(iter)
+					values[kv.Key] = kv.Value
+				}
+			}
+			v = values
+		default:
+			iter.Skip()
+			skippedKey(iter, "value", k)
+		}
+	}
+	return v
+}
+
+// readTraceEnum reads an enum written by name or by number
+func readTraceEnum(iter *jsoniter.Iterator) string {
+	if iter.WhatIsNext() == jsoniter.NumberValue {
+		return strconv.FormatInt(iter.ReadInt64(), 10)
+	}
+	return iter.ReadString()
+}
+
+// readTraceUint64 reads a uint64, written as a string in the JSON of protobuf
+func readTraceUint64(iter *jsoniter.Iterator) uint64 {
+	if iter.WhatIsNext() == jsoniter.StringValue {
+		v, _ := strconv.ParseUint(iter.ReadString(), 10, 64)
+		return v
+	}
+	return iter.ReadUint64()
+}
+
+// traceIDToHex returns the hex string of a trace or span ID. Tempo writes the IDs in base64
+// like the JSON of protobuf does for bytes, the OTLP JSON encoding uses hex.
+func traceIDToHex(id string) string {
+	if len(id) == 32 || len(id) == 16 {
+		if _, err := hex.DecodeString(id); err == nil {
+			return strings.ToLower(id)
+		}
+	}
+	b, err := base64.StdEncoding.DecodeString(id)
+	if err != nil {
+		return id
+	}
+	return hex.EncodeToString(b)
+}
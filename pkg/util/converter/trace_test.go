@@ -0,0 +1,75 @@
+package converter
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTempoTrace(t *testing.T) {
+	f, err := os.Open(path.Join("testdata", "tempo-trace.json"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	rsp := ReadTempoTrace(jsoniter.Parse(jsoniter.ConfigDefault, f, 1024), Options{Strict: StrictError})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 1)
+
+	frame := rsp.Frames[0]
+	require.Equal(t, "Trace", frame.Name)
+	require.Equal(t, "trace", string(frame.Meta.PreferredVisualization))
+	require.Equal(t, 1, frame.Rows())
+
+	row := func(name string) interface{} {
+		f, _ := frame.FieldByName(name)
+		require.NotNil(t, f, name)
+		return f.At(0)
+	}
+
+	require.Equal(t, "60ba2abb44f13eae", row("traceID"))
+	require.Equal(t, "60ba2abb44f13eae", row("spanID"))
+	require.Equal(t, "", row("parentSpanID"))
+	require.Equal(t, "GET /api/users", row("operationName"))
+	require.Equal(t, "api", row("serviceName"))
+	require.JSONEq(t, `[{"key":"service.name","value":"api"},{"key":"k8s.pod.ip","value":"10.0.0.1"}]`, string(row("serviceTags").(json.RawMessage)))
+	require.Equal(t, 1622813371144.0, row("startTime"))
+	require.Equal(t, 15.5, row("duration"))
+	require.JSONEq(t, `[
+		{"key":"otel.library.name","value":"http"},
+		{"key":"otel.library.version","value":"1.0"},
+		{"key":"http.status_code","value":500},
+		{"key":"http.retries","value":"[1,\"b\"]"},
+		{"key":"span.kind","value":"server"},
+		{"key":"status.code","value":2},
+		{"key":"error","value":true},
+		{"key":"status.message","value":"internal error"}
+	]`, string(row("tags").(json.RawMessage)))
+	require.JSONEq(t, `[{"timestamp":1622813371000,"fields":[{"key":"message","value":"exception"},{"key":"exception.type","value":"timeout"}]}]`, string(row("logs").(json.RawMessage)))
+	require.JSONEq(t, `[{"traceID":"1","spanID":"0000000000000002","tags":[{"key":"cause","value":true}]}]`, string(row("references").(json.RawMessage)))
+}
+
+func TestReadTempoTraceScopeSpans(t *testing.T) {
+	// the names of newer OTLP versions, with hex IDs and numeric enums
+	rsp := ReadTempoTrace(jsoniter.ParseString(jsoniter.ConfigDefault, `{"resourceSpans":[{
+		"scopeSpans":[{"scope":{"name":"grpc"},"spans":[{
+			"traceId":"5b8efff798038103d269b633813fc60c","spanId":"EEE19B7EC3C1B174",
+			"name":"op","kind":3,"startTimeUnixNano":1000000,"endTimeUnixNano":3000000,"status":{"code":1}
+		}]}],
+		"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"web"}}]}
+	}]}`), Options{Strict: StrictError})
+	require.NoError(t, rsp.Error)
+
+	frame := rsp.Frames[0]
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, "5b8efff798038103d269b633813fc60c", frame.Fields[0].At(0))
+	require.Equal(t, "eee19b7ec3c1b174", frame.Fields[1].At(0))
+	// the resource is read after the spans
+	require.Equal(t, "web", frame.Fields[4].At(0))
+	require.Equal(t, 2.0, frame.Fields[7].At(0))
+	require.JSONEq(t, `[{"key":"otel.library.name","value":"grpc"},{"key":"span.kind","value":"client"},{"key":"status.code","value":1}]`,
+		string(frame.Fields[10].At(0).(json.RawMessage)))
+}
@@ -0,0 +1,204 @@
+package converter
+
+import (
+	"math"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// NonFiniteValues configures how NaN and ±Inf sample values are converted
+type NonFiniteValues string
+
+const (
+	// NonFiniteKeep keeps the values as they are
+	NonFiniteKeep NonFiniteValues = ""
+	// NonFiniteNull converts the values to null, the value fields become nullable
+	NonFiniteNull NonFiniteValues = "null"
+	// NonFiniteDrop drops the samples
+	NonFiniteDrop NonFiniteValues = "drop"
+)
+
+// NonFiniteValue is a non finite sample value converted by NonFiniteMapping
+type NonFiniteValue string
+
+const (
+	NonFiniteNaN    NonFiniteValue = "NaN"
+	NonFinitePosInf NonFiniteValue = "+Inf"
+	NonFiniteNegInf NonFiniteValue = "-Inf"
+	// NonFiniteStale is the NaN written by prometheus when a series goes stale. The query API
+	// leaves the stale samples out, they are only found in remote read responses.
+	NonFiniteStale NonFiniteValue = "stale"
+)
+
+// the bits of the stale marker, like value.StaleNaN of prometheus
+const staleNaN uint64 = 0x7ff0000000000002
+
+// nonFiniteValues returns how the value is converted. NonFiniteMapping is checked first,
+// stale markers without a mapping are converted like NaN.
+func nonFiniteValues(opt Options, v float64) NonFiniteValues {
+	if len(opt.NonFiniteMapping) == 0 {
+		return opt.NonFiniteValues
+	}
+	var kind NonFiniteValue
+	switch {
+	case math.IsInf(v, 1):
+		kind = NonFinitePosInf
+	case math.IsInf(v, -1):
+		kind = NonFiniteNegInf
+	case math.IsNaN(v):
+		if m, ok := opt.NonFiniteMapping[NonFiniteStale]; ok && math.Float64bits(v) == staleNaN {
+			return m
+		}
+		kind = NonFiniteNaN
+	default:
+		return opt.NonFiniteValues
+	}
+	if m, ok := opt.NonFiniteMapping[kind]; ok {
+		return m
+	}
+	return opt.NonFiniteValues
+}
+
+// nonFiniteConverts reports if some non finite values are converted to m
+func nonFiniteConverts(opt Options, m NonFiniteValues) bool {
+	if opt.NonFiniteValues == m {
+		return true
+	}
+	for _, mapped := range opt.NonFiniteMapping {
+		if mapped == m {
+			return true
+		}
+	}
+	return false
+}
+
+// multiValueFieldType is the type of the value fields in multi frames, wide frames are always nullable
+func multiValueFieldType(opt Options) data.FieldType {
+	if opt.NullableMultiValues || nonFiniteConverts(opt, NonFiniteNull) {
+		return data.FieldTypeNullableFloat64
+	}
+	return data.FieldTypeFloat64
+}
+
+func exemplarValueFieldType(opt Options) data.FieldType {
+	if nonFiniteConverts(opt, NonFiniteNull) || nonFiniteConverts(opt, NonFiniteDrop) {
+		return data.FieldTypeNullableFloat64
+	}
+	return data.FieldTypeFloat64
+}
+
+// convert returns the value to add, nil for null, and false when the sample is dropped
+func (m NonFiniteValues) convert(v float64) (*float64, bool) {
+	null, ok := m.classify(v)
+	if null || !ok {
+		return nil, ok
+	}
+	return &v, true
+}
+
+// classify is convert without taking the address of the value, for the matrix readers
+func (m NonFiniteValues) classify(v float64) (null bool, ok bool) {
+	if m == NonFiniteKeep || !(math.IsNaN(v) || math.IsInf(v, 0)) {
+		return false, true
+	}
+	if m == NonFiniteDrop {
+		return false, false
+	}
+	return true, true
+}
+
+func appendFloat(field *data.Field, v *float64) {
+	if field.Nullable() {
+		field.Append(v)
+		return
+	}
+	field.Append(*v)
+}
+
+// appendTimeValuePair reads a [ time, "value" ] pair, samples with invalid values are dropped
+func appendTimeValuePair(iter *jsoniter.Iterator, timeField, valueField *data.Field, opt Options) {
+	t, fv, err := readTimeValuePair(iter, opt)
+	if err != nil {
+		opt.malformed.add(err)
+		return
+	}
+	v, ok := nonFiniteValues(opt, fv).convert(fv)
+	if !ok || !opt.rowLimit.take() {
+		return
+	}
+	timeField.Append(t)
+	appendFloat(valueField, v)
+}
+
+func sameValue(a, b interface{}) bool {
+	af, aok := a.(float64)
+	bf, bok := b.(float64)
+	if aok && bok {
+		// NaN repeats too
+		return math.Float64bits(af) == math.Float64bits(bf)
+	}
+	return a == b
+}
+
+// dropRepeatedValues returns the fields without the samples that repeat the previous value,
+// the first and last samples are always kept
+func dropRepeatedValues(timeField, valueField *data.Field) (*data.Field, *data.Field) {
+	n := valueField.Len()
+	if n < 3 {
+		return timeField, valueField
+	}
+
+	keep := make([]int, 0, n)
+	prev, _ := valueField.ConcreteAt(0)
+	keep = append(keep, 0)
+	for i := 1; i < n; i++ {
+		v, _ := valueField.ConcreteAt(i)
+		if i == n-1 || !sameValue(v, prev) {
+			keep = append(keep, i)
+		}
+		prev = v
+	}
+	if len(keep) == n {
+		return timeField, valueField
+	}
+
+	newTime := data.NewFieldFromFieldType(timeField.Type(), len(keep))
+	newTime.Name = timeField.Name
+	newValue := data.NewFieldFromFieldType(valueField.Type(), len(keep))
+	newValue.Name = valueField.Name
+	newValue.Labels = valueField.Labels
+	newValue.Config = valueField.Config
+	for i, idx := range keep {
+		newTime.Set(i, timeField.At(idx))
+		newValue.Set(i, valueField.At(idx))
+	}
+	return newTime, newValue
+}
+
+// nullRepeatedValues clears the values of a wide frame field that repeat the previous value,
+// the first and last values are always kept
+func nullRepeatedValues(field *data.Field) {
+	last := -1
+	for i := field.Len() - 1; i >= 0; i-- {
+		if _, ok := field.ConcreteAt(i); ok {
+			last = i
+			break
+		}
+	}
+
+	var prev interface{}
+	hasPrev := false
+	for i := 0; i < last; i++ {
+		v, ok := field.ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		if hasPrev && sameValue(v, prev) {
+			field.Set(i, nil)
+			continue
+		}
+		prev = v
+		hasPrev = true
+	}
+}
@@ -0,0 +1,167 @@
+package converter
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonFiniteValues(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"a":"1"},"values":[[1641889530,"1"],[1641889545,"NaN"],[1641889560,"+Inf"],[1641889575,"-Inf"]]}
+	]}}`
+
+	read := func(opt Options) *data.Field {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Frames, 1)
+		return rsp.Frames[0].Fields[1]
+	}
+
+	for _, wide := range []bool{false, true} {
+		keep := read(Options{MatrixWideSeries: wide})
+		require.Equal(t, 4, keep.Len())
+		v, _ := keep.ConcreteAt(1)
+		require.True(t, math.IsNaN(v.(float64)))
+
+		null := read(Options{MatrixWideSeries: wide, NonFiniteValues: NonFiniteNull})
+		require.Equal(t, data.FieldTypeNullableFloat64, null.Type())
+		require.Equal(t, 4, null.Len())
+		for i := 1; i < 4; i++ {
+			_, ok := null.ConcreteAt(i)
+			require.False(t, ok)
+		}
+
+		drop := read(Options{MatrixWideSeries: wide, NonFiniteValues: NonFiniteDrop})
+		require.Equal(t, 1, drop.Len())
+	}
+
+	t.Run("scalar", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault,
+			`{"status":"success","data":{"resultType":"scalar","result":[1641889530,"NaN"]}}`), Options{NonFiniteValues: NonFiniteDrop})
+		require.NoError(t, rsp.Error)
+		require.Equal(t, 0, rsp.Frames[0].Rows())
+	})
+}
+
+func TestNonFiniteMapping(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"a":"1"},"values":[[1,"1"],[2,"NaN"],[3,"+Inf"],[4,"-Inf"],[5,"2"],[6,"Inf"],[7,"nan"]]}
+	]}}`
+	opt := Options{
+		NonFiniteValues: NonFiniteDrop,
+		NonFiniteMapping: map[NonFiniteValue]NonFiniteValues{
+			NonFiniteNaN:    NonFiniteNull,
+			NonFiniteNegInf: NonFiniteKeep,
+		},
+	}
+
+	for _, wide := range []bool{false, true} {
+		opt.MatrixWideSeries = wide
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), opt)
+		require.NoError(t, rsp.Error)
+		field := rsp.Frames[0].Fields[1]
+		require.Equal(t, data.FieldTypeNullableFloat64, field.Type())
+		require.Empty(t, rsp.Frames[0].Meta.Notices)
+
+		// the +Inf samples are dropped
+		values := []interface{}{}
+		for i := 0; i < field.Len(); i++ {
+			v, _ := field.NullableFloatAt(i)
+			if v == nil {
+				values = append(values, nil)
+				continue
+			}
+			values = append(values, *v)
+		}
+		require.Equal(t, []interface{}{1.0, nil, math.Inf(-1), 2.0, nil}, values)
+	}
+
+	t.Run("stale markers", func(t *testing.T) {
+		c := chunkenc.NewXORChunk()
+		app, err := c.Appender()
+		require.NoError(t, err)
+		app.Append(1000, 1)
+		app.Append(2000, math.Float64frombits(staleNaN))
+		app.Append(3000, 2)
+		app.Append(4000, math.NaN())
+		app.Append(5000, math.Float64frombits(staleNaN))
+		body := writeChunkedMessages(t, &prompb.ChunkedReadResponse{ChunkedSeries: []*prompb.ChunkedSeries{{
+			Labels: []prompb.Label{{Name: "__name__", Value: "up"}},
+			Chunks: []prompb.Chunk{{MinTimeMs: 1000, MaxTimeMs: 5000, Type: prompb.Chunk_XOR, Data: c.Bytes()}},
+		}}})
+
+		read := func(mapping map[NonFiniteValue]NonFiniteValues) []string {
+			rsp := ReadRemoteReadChunks(bytes.NewReader(body), Options{NonFiniteMapping: mapping})
+			require.NoError(t, rsp.Error)
+			field := rsp.Frames[0].Fields[1]
+			values := []string{}
+			for i := 0; i < field.Len(); i++ {
+				v, _ := field.NullableFloatAt(i)
+				if v == nil {
+					values = append(values, "null")
+					continue
+				}
+				values = append(values, strconv.FormatFloat(*v, 'g', -1, 64))
+			}
+			return values
+		}
+
+		// the stale markers break the line, the NaN is kept
+		require.Equal(t, []string{"1", "null", "2", "NaN", "null"}, read(map[NonFiniteValue]NonFiniteValues{NonFiniteStale: NonFiniteNull}))
+		// without a mapping for them they are converted like NaN
+		require.Equal(t, []string{"1", "2"}, read(map[NonFiniteValue]NonFiniteValues{NonFiniteNaN: NonFiniteDrop}))
+		require.Equal(t, []string{"1", "NaN", "2", "NaN", "NaN"}, read(nil))
+	})
+}
+
+func TestDropRepeatedValues(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"a":"1"},"values":[[1641889530,"1"],[1641889545,"1"],[1641889560,"0"],[1641889575,"0"],[1641889590,"0"]]}
+	]}}`
+
+	t.Run("multi", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{DropRepeatedValues: true})
+		require.NoError(t, rsp.Error)
+		frame := rsp.Frames[0]
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, []float64{1, 0, 0}, []float64{frame.Fields[1].At(0).(float64), frame.Fields[1].At(1).(float64), frame.Fields[1].At(2).(float64)})
+		require.Equal(t, map[string]string{"a": "1"}, map[string]string(frame.Fields[1].Labels))
+	})
+
+	t.Run("wide", func(t *testing.T) {
+		rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, body), Options{DropRepeatedValues: true, MatrixWideSeries: true})
+		require.NoError(t, rsp.Error)
+		field := rsp.Frames[0].Fields[1]
+		require.Equal(t, 5, field.Len())
+		present := []bool{}
+		for i := 0; i < field.Len(); i++ {
+			_, ok := field.ConcreteAt(i)
+			present = append(present, ok)
+		}
+		require.Equal(t, []bool{true, false, true, false, true}, present)
+	})
+}
+
+func TestNullableMultiValues(t *testing.T) {
+	rsp := ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrixResponse(2)), Options{NullableMultiValues: true})
+	require.NoError(t, rsp.Error)
+	require.Len(t, rsp.Frames, 2)
+	for _, frame := range rsp.Frames {
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+		v, ok := frame.Fields[1].ConcreteAt(1)
+		require.True(t, ok)
+		require.Equal(t, 2.0, v)
+	}
+
+	rsp = ReadPrometheusStyleResult(jsoniter.ParseString(jsoniter.ConfigDefault, matrixResponse(1)), Options{})
+	require.NoError(t, rsp.Error)
+	require.Equal(t, data.FieldTypeFloat64, rsp.Frames[0].Fields[1].Type())
+}
@@ -0,0 +1,234 @@
+package converter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// FrameWriter receives the frames of a streamed conversion as soon as they are complete.
+// Writes block while the client is slow, so the conversion never gets ahead of the client.
+type FrameWriter interface {
+	WriteFrame(frame *data.Frame) error
+}
+
+// FrameWriterFunc adapts a function, like a gRPC stream Send, to a FrameWriter
+type FrameWriterFunc func(frame *data.Frame) error
+
+func (f FrameWriterFunc) WriteFrame(frame *data.Frame) error {
+	return f(frame)
+}
+
+// JSONFrameWriter writes every frame as a line of JSON, and flushes it when
+// writing to an http.ResponseWriter
+type JSONFrameWriter struct {
+	ctx     context.Context
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func NewJSONFrameWriter(ctx context.Context, w io.Writer) *JSONFrameWriter {
+	flusher, _ := w.(http.Flusher)
+	return &JSONFrameWriter{
+		ctx:     ctx,
+		w:       w,
+		flusher: flusher,
+	}
+}
+
+func (w *JSONFrameWriter) WriteFrame(frame *data.Frame) error {
+	// the request context is cancelled when the client disconnects
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	b, err := data.FrameToJSON(frame, data.IncludeAll)
+	if err != nil {
+		return err
+	}
+	if _, err := w.w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if w.flusher != nil {
+		w.flusher.Flush()
+	}
+	return nil
+}
+
+// QueryResponseWriter writes the frames of a query as the JSON of a backend.QueryDataResponse,
+// {"results":{"A":{"frames":[...],"status":200}}}, flushing every frame when writing to an
+// http.ResponseWriter. The response is sent with chunked transfer encoding, so the client gets
+// the first frames while the rest of the response is converted. Close must be called once
+// the frames are written, with the error of the conversion if any.
+type QueryResponseWriter struct {
+	ctx     context.Context
+	w       io.Writer
+	flusher http.Flusher
+	refID   string
+	frames  int
+	closed  bool
+}
+
+func NewQueryResponseWriter(ctx context.Context, w io.Writer, refID string) *QueryResponseWriter {
+	flusher, _ := w.(http.Flusher)
+	return &QueryResponseWriter{
+		ctx:     ctx,
+		w:       w,
+		flusher: flusher,
+		refID:   refID,
+	}
+}
+
+func (w *QueryResponseWriter) WriteFrame(frame *data.Frame) error {
+	// the request context is cancelled when the client disconnects
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	if w.closed {
+		return errors.New("query response already closed")
+	}
+	b, err := data.FrameToJSON(frame, data.IncludeAll)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, 0, len(b)+len(w.refID)+32)
+	if w.frames == 0 {
+		buf = w.appendStart(buf)
+	} else {
+		buf = append(buf, ',')
+	}
+	buf = append(buf, b...)
+	w.frames++
+	return w.write(buf)
+}
+
+// Close ends the frames and the response. The error is written like backend.DataResponse
+// writes it, with an internal status, the frames already written are kept.
+func (w *QueryResponseWriter) Close(err error) error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	var buf []byte
+	if w.frames == 0 {
+		buf = w.appendStart(buf)
+	}
+	buf = append(buf, ']')
+	if err != nil {
+		msg, merr := json.Marshal(err.Error())
+		if merr != nil {
+			return merr
+		}
+		buf = append(buf, `,"error":`...)
+		buf = append(buf, msg...)
+		buf = append(buf, `,"status":`...)
+		buf = strconv.AppendInt(buf, int64(backend.StatusInternal), 10)
+	} else {
+		buf = append(buf, `,"status":`...)
+		buf = strconv.AppendInt(buf, int64(backend.StatusOK), 10)
+	}
+	buf = append(buf, "}}}\n"...)
+	return w.write(buf)
+}
+
+func (w *QueryResponseWriter) appendStart(buf []byte) []byte {
+	refID, _ := json.Marshal(w.refID)
+	buf = append(buf, `{"results":{`...)
+	buf = append(buf, refID...)
+	return append(buf, `:{"frames":[`...)
+}
+
+func (w *QueryResponseWriter) write(b []byte) error {
+	if _, err := w.w.Write(b); err != nil {
+		return err
+	}
+	if w.flusher != nil {
+		w.flusher.Flush()
+	}
+	return nil
+}
+
+// StreamPrometheusStyleResult converts a prometheus or loki response like ReadPrometheusStyleResult,
+// but writes the frames to w instead of returning them. The series of matrix and vector results are
+// written one by one, so they are never all held in memory.
+//
+// The conversion is aborted as soon as the context is done or a write fails. Warnings are
+// only attached to the frames that are written once the whole response was read.
+func StreamPrometheusStyleResult(ctx context.Context, r io.Reader, opt Options, w FrameWriter) error {
+	sink := &frameSink{w: w, metadata: opt.MetricMetadata}
+	opt.sink = sink
+
+	iter := jsoniter.Parse(jsoniter.ConfigDefault, &contextReader{ctx: ctx, r: LimitBody(opt.MemoryStats.Reader(r), opt.MaxBodyBytes), sink: sink}, 1024)
+	rsp := ReadPrometheusStyleResult(iter, opt)
+	if sink.err != nil {
+		return sink.err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// the frames already written may be incomplete
+	if iter.Error != nil && !errors.Is(iter.Error, io.EOF) {
+		return iter.Error
+	}
+	if rsp.Error != nil {
+		return rsp.Error
+	}
+	for _, frame := range rsp.Frames {
+		if err := w.WriteFrame(frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type frameSink struct {
+	w        FrameWriter
+	metadata map[string]MetricMetadata
+	err      error
+}
+
+// appendFrame adds the frame to the response, or writes it right away when streaming
+func appendFrame(iter *jsoniter.Iterator, rsp *backend.DataResponse, frame *data.Frame, opt Options) {
+	if opt.sink == nil {
+		rsp.Frames = append(rsp.Frames, frame)
+		return
+	}
+	s := opt.sink
+	if s.err != nil {
+		return
+	}
+	if len(s.metadata) > 0 {
+		attachMetricMetadata(data.Frames{frame}, s.metadata)
+	}
+	finishFrames(data.Frames{frame}, opt)
+	if err := s.w.WriteFrame(frame); err != nil {
+		s.err = err
+		// stops reading the rest of the response
+		iter.ReportError("appendFrame", err.Error())
+	}
+}
+
+// contextReader stops reading when the context is done or a write failed
+type contextReader struct {
+	ctx  context.Context
+	r    io.Reader
+	sink *frameSink
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if r.sink.err != nil {
+		return 0, r.sink.err
+	}
+	return r.r.Read(p)
+}
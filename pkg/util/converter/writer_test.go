@@ -0,0 +1,117 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func matrixResponse(series int) string {
+	result := make([]string, 0, series)
+	for i := 0; i < series; i++ {
+		result = append(result, fmt.Sprintf(`{"metric":{"i":"%d"},"values":[[1641889530,"1"],[1641889545,"2"]]}`, i))
+	}
+	return `{"status":"success","data":{"resultType":"matrix","result":[` + strings.Join(result, ",") + `]}}`
+}
+
+func TestStreamPrometheusStyleResult(t *testing.T) {
+	t.Run("writes every series", func(t *testing.T) {
+		frames := []*data.Frame{}
+		err := StreamPrometheusStyleResult(context.Background(), strings.NewReader(matrixResponse(3)), Options{},
+			FrameWriterFunc(func(frame *data.Frame) error {
+				frames = append(frames, frame)
+				return nil
+			}))
+		require.NoError(t, err)
+		require.Len(t, frames, 3)
+		require.Equal(t, "2", frames[2].Fields[1].Labels["i"])
+	})
+
+	t.Run("aborts when a write fails", func(t *testing.T) {
+		gone := errors.New("client gone")
+		written := 0
+		err := StreamPrometheusStyleResult(context.Background(), strings.NewReader(matrixResponse(1000)), Options{},
+			FrameWriterFunc(func(frame *data.Frame) error {
+				written++
+				if written == 2 {
+					return gone
+				}
+				return nil
+			}))
+		require.ErrorIs(t, err, gone)
+		require.Equal(t, 2, written)
+	})
+
+	t.Run("aborts when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := StreamPrometheusStyleResult(ctx, strings.NewReader(matrixResponse(3)), Options{},
+			FrameWriterFunc(func(frame *data.Frame) error {
+				return nil
+			}))
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("json lines", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := StreamPrometheusStyleResult(context.Background(), strings.NewReader(matrixResponse(2)), Options{},
+			NewJSONFrameWriter(context.Background(), buf))
+		require.NoError(t, err)
+		require.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 2)
+	})
+}
+
+func TestQueryResponseWriter(t *testing.T) {
+	read := func(t *testing.T, b []byte) backend.DataResponse {
+		var rsp backend.QueryDataResponse
+		require.NoError(t, jsoniter.Unmarshal(b, &rsp))
+		require.Contains(t, rsp.Responses, "A")
+		return rsp.Responses["A"]
+	}
+
+	t.Run("frames", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := NewQueryResponseWriter(context.Background(), buf, "A")
+		require.NoError(t, StreamPrometheusStyleResult(context.Background(), strings.NewReader(matrixResponse(3)), Options{}, w))
+		require.NoError(t, w.Close(nil))
+
+		rsp := read(t, buf.Bytes())
+		require.NoError(t, rsp.Error)
+		require.Equal(t, backend.StatusOK, rsp.Status)
+		require.Len(t, rsp.Frames, 3)
+		require.Equal(t, "2", rsp.Frames[2].Fields[1].Labels["i"])
+	})
+
+	t.Run("error after some frames", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := NewQueryResponseWriter(context.Background(), buf, "A")
+		body := io.MultiReader(strings.NewReader(strings.TrimSuffix(matrixResponse(2), "]}}")), iotest.ErrReader(errors.New("connection reset")))
+		err := StreamPrometheusStyleResult(context.Background(), body, Options{}, w)
+		require.Error(t, err)
+		require.NoError(t, w.Close(err))
+
+		rsp := read(t, buf.Bytes())
+		require.Error(t, rsp.Error)
+		require.Equal(t, backend.StatusInternal, rsp.Status)
+		require.Len(t, rsp.Frames, 2)
+	})
+
+	t.Run("no frames", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := NewQueryResponseWriter(context.Background(), buf, "B\"")
+		require.NoError(t, w.Close(nil))
+		require.NoError(t, w.Close(nil))
+		require.Equal(t, `{"results":{"B\"":{"frames":[],"status":200}}}`+"\n", buf.String())
+		require.Error(t, w.WriteFrame(data.NewFrame("")))
+	})
+}
@@ -3,6 +3,7 @@
 # Check whether protobuf & go plugin are installed
 PROTOC_HELP_URL="http://google.github.io/proto-lens/installing-protoc.html"
 PROTOC_GEN_GO_HELP_URL="https://github.com/golang/protobuf/tree/v1.3.4#installation"
+PROTOC_GEN_VALIDATE_HELP_URL="https://github.com/envoyproxy/protoc-gen-validate#installation"
 
 EXIT_CODE=0
 
@@ -22,4 +23,12 @@ if ! [ -x "$(command -v protoc-gen-go)" ]; then
 	EXIT_CODE=1
 fi
 
+if ! [ -x "$(command -v protoc-gen-validate)" ]; then
+	echo "Protocol Buffers validation plugin not found."
+	echo "Please install the plugin and ensure 'protoc-gen-validate' is available in your PATH."
+	echo "See ${PROTOC_GEN_VALIDATE_HELP_URL} for more."
+	echo
+	EXIT_CODE=1
+fi
+
 exit $EXIT_CODE
\ No newline at end of file